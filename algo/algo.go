@@ -0,0 +1,206 @@
+// Package algo содержит обобщенные алгоритмы над последовательностями
+// iter.Seq - общим интерфейсом обхода контейнеров (All у массива, стека,
+// очереди и списков, ToSlice и FromSeq для остальных). Ленивые функции (Map,
+// Filter, Zip, Chunk, Distinct) возвращают новую последовательность и ничего
+// не вычисляют до обхода, остальные обходят seq сразу.
+//
+// MapArray, FilterArray, MapList и FilterList - быстрые пути: они пишут
+// результат сразу в новый массив или двусвязный список, без промежуточных
+// последовательностей и срезов.
+package algo
+
+import (
+	"iter"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+	"github.com/D4ROVAN1E/LR_3_Go/doublylist"
+)
+
+// Map возвращает последовательность fn(v) для каждого v из seq
+func Map[T, U any](seq iter.Seq[T], fn func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range seq {
+			if !yield(fn(v)) {
+				return
+			}
+		}
+	}
+}
+
+// Filter возвращает элементы seq, для которых pred возвращает true
+func Filter[T any](seq iter.Seq[T], pred func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if pred(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Reduce сворачивает seq слева направо, начиная с init
+func Reduce[T, A any](seq iter.Seq[T], init A, fn func(acc A, v T) A) A {
+	acc := init
+	for v := range seq {
+		acc = fn(acc, v)
+	}
+	return acc
+}
+
+// Any сообщает, есть ли в seq элемент, для которого pred возвращает true.
+// Обход останавливается на первом таком элементе.
+func Any[T any](seq iter.Seq[T], pred func(T) bool) bool {
+	for v := range seq {
+		if pred(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// All сообщает, возвращает ли pred true для всех элементов seq.
+// Для пустой последовательности результат - true.
+func All[T any](seq iter.Seq[T], pred func(T) bool) bool {
+	for v := range seq {
+		if !pred(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find возвращает первый элемент, для которого pred возвращает true
+func Find[T any](seq iter.Seq[T], pred func(T) bool) (T, bool) {
+	for v := range seq {
+		if pred(v) {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// GroupBy раскладывает элементы seq по ключу key. Внутри группы сохраняется
+// порядок обхода.
+func GroupBy[T any, K comparable](seq iter.Seq[T], key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for v := range seq {
+		k := key(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
+
+// Partition делит seq на элементы, для которых pred возвращает true, и остальные
+func Partition[T any](seq iter.Seq[T], pred func(T) bool) (matched, rest []T) {
+	for v := range seq {
+		if pred(v) {
+			matched = append(matched, v)
+		} else {
+			rest = append(rest, v)
+		}
+	}
+	return matched, rest
+}
+
+// Zip возвращает пары элементов a и b с одинаковыми номерами.
+// Последовательность заканчивается вместе с более короткой из двух.
+func Zip[A, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		nextB, stop := iter.Pull(b)
+		defer stop()
+		for va := range a {
+			vb, ok := nextB()
+			if !ok || !yield(va, vb) {
+				return
+			}
+		}
+	}
+}
+
+// Chunk возвращает элементы seq группами по n; последняя группа может быть
+// короче. Каждая группа - новый срез. Chunk паникует, если n < 1.
+func Chunk[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	if n < 1 {
+		panic("algo: Chunk size must be positive")
+	}
+	return func(yield func([]T) bool) {
+		var chunk []T
+		for v := range seq {
+			if chunk == nil {
+				chunk = make([]T, 0, n)
+			}
+			chunk = append(chunk, v)
+			if len(chunk) == n {
+				if !yield(chunk) {
+					return
+				}
+				chunk = nil
+			}
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// Distinct возвращает элементы seq без повторов, оставляя первое вхождение.
+// Уже встреченные элементы хранятся в памяти до конца обхода.
+func Distinct[T comparable](seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		seen := make(map[T]struct{})
+		for v := range seq {
+			if _, ok := seen[v]; ok {
+				continue
+			}
+			seen[v] = struct{}{}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Быстрые пути
+
+// MapArray создает массив из fn(v) для элементов a. Емкость результата
+// выделяется один раз.
+func MapArray[T, U any](a *array.Array[T], fn func(T) U) *array.Array[U] {
+	result := array.NewArray[U]()
+	_ = result.SetCapacity(a.GetSize())
+	for v := range a.All() {
+		result.PushBack(fn(v))
+	}
+	return result
+}
+
+// FilterArray создает массив из элементов a, для которых pred возвращает true
+func FilterArray[T any](a *array.Array[T], pred func(T) bool) *array.Array[T] {
+	result := array.NewArray[T]()
+	for v := range a.All() {
+		if pred(v) {
+			result.PushBack(v)
+		}
+	}
+	return result
+}
+
+// MapList создает двусвязный список из fn(v) для элементов l
+func MapList[T, U comparable](l *doublylist.DoublyList[T], fn func(T) U) *doublylist.DoublyList[U] {
+	result := doublylist.NewDoublyList[U]()
+	for current := l.Head; current != nil; current = current.Next {
+		result.LPushBack(fn(current.Key))
+	}
+	return result
+}
+
+// FilterList создает двусвязный список из элементов l, для которых pred возвращает true
+func FilterList[T comparable](l *doublylist.DoublyList[T], pred func(T) bool) *doublylist.DoublyList[T] {
+	result := doublylist.NewDoublyList[T]()
+	for current := l.Head; current != nil; current = current.Next {
+		if pred(current.Key) {
+			result.LPushBack(current.Key)
+		}
+	}
+	return result
+}
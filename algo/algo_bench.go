@@ -0,0 +1,43 @@
+package algo
+
+import (
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+)
+
+const DataSize = 100000
+
+func square(v int) int { return v * v }
+
+// BenchmarkMapArray сравнивает быстрый путь MapArray с Map и array.FromSeq
+func BenchmarkMapArray(b *testing.B) {
+	arr := array.NewArray[int]()
+	for i := 0; i < DataSize; i++ {
+		arr.PushBack(i)
+	}
+
+	b.Run("FastPath", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = MapArray(arr, square)
+		}
+	})
+
+	b.Run("Seq", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = array.FromSeq(Map(arr.All(), square))
+		}
+	})
+}
+
+// BenchmarkReduce измеряет свертку последовательности массива
+func BenchmarkReduce(b *testing.B) {
+	arr := array.NewArray[int]()
+	for i := 0; i < DataSize; i++ {
+		arr.PushBack(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Reduce(arr.All(), 0, func(acc, v int) int { return acc + v })
+	}
+}
@@ -0,0 +1,195 @@
+package algo
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+	"github.com/D4ROVAN1E/LR_3_Go/doublylist"
+	"github.com/D4ROVAN1E/LR_3_Go/queue"
+	"github.com/D4ROVAN1E/LR_3_Go/singlylist"
+)
+
+func isEven(v int) bool { return v%2 == 0 }
+
+func TestMapFilterReduce(t *testing.T) {
+	a := array.FromSlice([]int{1, 2, 3, 4, 5, 6})
+
+	squares := slices.Collect(Map(a.All(), func(v int) int { return v * v }))
+	if !slices.Equal(squares, []int{1, 4, 9, 16, 25, 36}) {
+		t.Errorf("Map = %v", squares)
+	}
+
+	even := slices.Collect(Filter(a.All(), isEven))
+	if !slices.Equal(even, []int{2, 4, 6}) {
+		t.Errorf("Filter = %v", even)
+	}
+
+	sum := Reduce(a.All(), 0, func(acc, v int) int { return acc + v })
+	if sum != 21 {
+		t.Errorf("Reduce sum = %d, want 21", sum)
+	}
+	joined := Reduce(a.All(), "", func(acc string, v int) string { return acc + strconv.Itoa(v) })
+	if joined != "123456" {
+		t.Errorf("Reduce join = %q", joined)
+	}
+
+	// Цепочка ленивых функций над списком
+	l := singlylist.FromSlice([]int{1, 2, 3, 4, 5, 6})
+	got := slices.Collect(Map(Filter(l.All(), isEven), strconv.Itoa))
+	if !slices.Equal(got, []string{"2", "4", "6"}) {
+		t.Errorf("Map(Filter) = %v", got)
+	}
+}
+
+func TestLaziness(t *testing.T) {
+	calls := 0
+	seq := Map(array.FromSlice([]int{1, 2, 3, 4}).All(), func(v int) int {
+		calls++
+		return v
+	})
+	if calls != 0 {
+		t.Fatalf("Map called fn %d times before iteration", calls)
+	}
+	for v := range seq {
+		if v == 2 {
+			break
+		}
+	}
+	if calls != 2 {
+		t.Errorf("fn called %d times, want 2 after break", calls)
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	q := queue.FromSlice([]int{3, 5, 8, 11})
+
+	if !Any(q.All(), isEven) {
+		t.Error("Any(isEven) = false")
+	}
+	if All(q.All(), isEven) {
+		t.Error("All(isEven) = true")
+	}
+	if !All(q.All(), func(v int) bool { return v > 0 }) {
+		t.Error("All(positive) = false")
+	}
+	if v, ok := Find(q.All(), isEven); !ok || v != 8 {
+		t.Errorf("Find = %d, %t, want 8, true", v, ok)
+	}
+	if _, ok := Find(q.All(), func(v int) bool { return v > 100 }); ok {
+		t.Error("Find of missing element returned true")
+	}
+
+	empty := queue.NewQueue[int](1)
+	if Any(empty.All(), isEven) || !All(empty.All(), isEven) {
+		t.Error("Any/All on empty sequence are wrong")
+	}
+}
+
+func TestGroupByPartition(t *testing.T) {
+	words := slices.Values([]string{"go", "rust", "c", "java", "zig", "d"})
+
+	groups := GroupBy(words, func(s string) int { return len(s) })
+	want := map[int][]string{1: {"c", "d"}, 2: {"go"}, 3: {"zig"}, 4: {"rust", "java"}}
+	if !maps.EqualFunc(groups, want, slices.Equal) {
+		t.Errorf("GroupBy = %v", groups)
+	}
+
+	short, long := Partition(words, func(s string) bool { return len(s) <= 2 })
+	if !slices.Equal(short, []string{"go", "c", "d"}) || !slices.Equal(long, []string{"rust", "java", "zig"}) {
+		t.Errorf("Partition = %v, %v", short, long)
+	}
+}
+
+func TestZip(t *testing.T) {
+	keys := slices.Values([]string{"a", "b", "c"})
+	values := doublylist.FromSlice([]int{1, 2, 3, 4})
+
+	var got []string
+	for k, v := range Zip(keys, values.All()) {
+		got = append(got, k+strconv.Itoa(v))
+	}
+	if !slices.Equal(got, []string{"a1", "b2", "c3"}) {
+		t.Errorf("Zip = %v", got)
+	}
+
+	got = got[:0]
+	for k := range Zip(keys, values.All()) {
+		got = append(got, k)
+		break
+	}
+	if !slices.Equal(got, []string{"a"}) {
+		t.Errorf("Zip with break = %v", got)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	seq := array.FromSlice([]int{1, 2, 3, 4, 5}).All()
+	got := slices.Collect(Chunk(seq, 2))
+	want := [][]int{{1, 2}, {3, 4}, {5}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("Chunk = %v", got)
+	}
+	// Группы не делят память
+	got[0][0] = 100
+	if got[1][0] != 3 {
+		t.Error("chunks share memory")
+	}
+
+	if got := slices.Collect(Chunk(slices.Values([]int{}), 3)); len(got) != 0 {
+		t.Errorf("Chunk of empty = %v", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Chunk(0) did not panic")
+		}
+	}()
+	Chunk(seq, 0)
+}
+
+func TestDistinct(t *testing.T) {
+	seq := slices.Values([]int{3, 1, 3, 2, 1, 4})
+	if got := slices.Collect(Distinct(seq)); !slices.Equal(got, []int{3, 1, 2, 4}) {
+		t.Errorf("Distinct = %v", got)
+	}
+	// Повторный обход начинается с чистого множества
+	if got := slices.Collect(Distinct(seq)); len(got) != 4 {
+		t.Errorf("second Distinct pass = %v", got)
+	}
+}
+
+func TestFastPaths(t *testing.T) {
+	a := array.FromSlice([]int{1, 2, 3, 4})
+	mapped := MapArray(a, strconv.Itoa)
+	if got := mapped.ToSlice(); !slices.Equal(got, []string{"1", "2", "3", "4"}) {
+		t.Errorf("MapArray = %v", got)
+	}
+	if mapped.GetCapacity() != 4 {
+		t.Errorf("MapArray capacity = %d, want 4", mapped.GetCapacity())
+	}
+	if got := FilterArray(a, isEven).ToSlice(); !slices.Equal(got, []int{2, 4}) {
+		t.Errorf("FilterArray = %v", got)
+	}
+	if MapArray(array.NewArray[int](), strconv.Itoa).GetSize() != 0 {
+		t.Error("MapArray of empty array is not empty")
+	}
+
+	l := doublylist.FromSlice([]int{1, 2, 3, 4})
+	ml := MapList(l, func(v int) int { return v * 10 })
+	if got := ml.ToSlice(); !slices.Equal(got, []int{10, 20, 30, 40}) {
+		t.Errorf("MapList = %v", got)
+	}
+	if ml.Tail == nil || ml.Tail.Key != 40 {
+		t.Error("MapList tail is wrong")
+	}
+	if got := FilterList(l, isEven).ToSlice(); !slices.Equal(got, []int{2, 4}) {
+		t.Errorf("FilterList = %v", got)
+	}
+	// Исходные контейнеры не меняются
+	if got := l.ToSlice(); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("source list changed: %v", got)
+	}
+}
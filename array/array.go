@@ -1,216 +1,468 @@
-package array
-
-import (
-	"encoding/binary"
-	"fmt"
-	"os"
-)
-
-// Array представляет собой динамический массив с дженериками
-type Array[T any] struct {
-	data []T
-}
-
-// NewArray создает пустой массив
-func NewArray[T any]() *Array[T] {
-	return &Array[T]{
-		data: make([]T, 0, 1), // size 0, capacity 1
-	}
-}
-
-// NewArrayWithCap создает массив с заданной емкостью
-func NewArrayWithCap[T any](cap int) *Array[T] {
-	if cap < 1 {
-		cap = 1
-	}
-	// Создаем слайс длиной cap-1 и емкостью cap
-	return &Array[T]{
-		data: make([]T, cap-1, cap),
-	}
-}
-
-// GetSize возвращает текущий размер
-func (a *Array[T]) GetSize() int {
-	return len(a.data)
-}
-
-// GetCapacity возвращает текущую емкость
-func (a *Array[T]) GetCapacity() int {
-	return cap(a.data)
-}
-
-// PushBack добавляет элемент в конец
-func (a *Array[T]) PushBack(value T) {
-	// Go append автоматически управляет capacity (удваивает при необходимости)
-	a.data = append(a.data, value)
-}
-
-// Get возвращает элемент по индексу или ошибку
-func (a *Array[T]) Get(index int) (T, error) {
-	if index < 0 || index >= len(a.data) {
-		var empty T
-		return empty, fmt.Errorf("error: Index %d is out of bounds (size %d)", index, len(a.data))
-	}
-	return a.data[index], nil
-}
-
-// Set заменяет элемент по индексу
-func (a *Array[T]) Set(index int, value T) error {
-	if index < 0 || index >= len(a.data) {
-		return fmt.Errorf("error: Index %d is out of bounds", index)
-	}
-	a.data[index] = value
-	return nil
-}
-
-// InsertByInd вставляет элемент по индексу.
-func (a *Array[T]) InsertByInd(index int, value T) error {
-	if index < 0 || index > len(a.data) {
-		return fmt.Errorf("error: Index %d is out of bounds for insertion", index)
-	}
-	// Трюк со слайсами для вставки элемента
-	if index == len(a.data) {
-		a.PushBack(value)
-		return nil
-	}
-	a.data = append(a.data[:index+1], a.data[index:]...) // Раздвигаем
-	a.data[index] = value
-	return nil
-}
-
-// DeleteByInd удаляет элемент по индексу
-func (a *Array[T]) DeleteByInd(index int) error {
-	if index < 0 || index >= len(a.data) {
-		return fmt.Errorf("error: Index %d is out of bounds for deletion", index)
-	}
-	a.data = append(a.data[:index], a.data[index+1:]...)
-	return nil
-}
-
-// SwapByInd заменяет значение
-func (a *Array[T]) SwapByInd(index int, value T) error {
-	if index < 0 || index >= len(a.data) {
-		return fmt.Errorf("error: Index %d is out of bounds for swap", index)
-	}
-	a.data[index] = value
-	return nil
-}
-
-// Clone создает глубокую копию массива
-func (a *Array[T]) Clone() *Array[T] {
-	newData := make([]T, len(a.data), cap(a.data))
-	copy(newData, a.data)
-	return &Array[T]{data: newData}
-}
-
-// SetCapacity изменяет емкость массива вручную
-func (a *Array[T]) SetCapacity(newCap int) error {
-	if newCap < len(a.data) {
-		return fmt.Errorf("error: New capacity cannot be smaller than current size")
-	}
-	// Создаем новый слайс и копируем данные
-	newData := make([]T, len(a.data), newCap)
-	copy(newData, a.data)
-	a.data = newData
-	return nil
-}
-
-// SetSize изменяет размер массива
-func (a *Array[T]) SetSize(newSize int) error {
-	if newSize > cap(a.data) {
-		return fmt.Errorf("error: New size exceeds current capacity")
-	}
-	a.data = a.data[:newSize]
-	return nil
-}
-
-// SaveText сохраняет массив в текстовый файл
-func (a *Array[T]) SaveText(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error: Unable to open file for writing: %v", err)
-	}
-	defer file.Close()
-
-	fmt.Fprintln(file, len(a.data))
-	for _, val := range a.data {
-		// Fprint работает с любым типом, поддерживающим форматирование
-		fmt.Fprintf(file, "%v ", val)
-	}
-	return nil
-}
-
-// LoadText загружает массив из текстового файла
-func (a *Array[T]) LoadText(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("error: Unable to open file for reading: %v", err)
-	}
-	defer file.Close()
-
-	var newSize int
-	if _, err := fmt.Fscan(file, &newSize); err != nil {
-		return fmt.Errorf("error: Failed to read size")
-	}
-
-	// Сброс массива
-	a.data = make([]T, 0, newSize)
-
-	for i := 0; i < newSize; i++ {
-		var val T
-		// Fscan требует, чтобы T был сканируемым (int, float, string и т.д.)
-		if _, err := fmt.Fscan(file, &val); err != nil {
-			// Если данные кончились раньше времени
-			break
-		}
-		a.data = append(a.data, val)
-	}
-
-	if len(a.data) != newSize {
-		return fmt.Errorf("error: File corrupted or incomplete data")
-	}
-	return nil
-}
-
-// SaveBinary сохраняет массив в бинарном формате.
-func (a *Array[T]) SaveBinary(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error: Unable to open file: %v", err)
-	}
-	defer file.Close()
-
-	size := int32(len(a.data)) // Используем int32 для совместимости
-	if err := binary.Write(file, binary.LittleEndian, size); err != nil {
-		return err
-	}
-
-	if size > 0 {
-		if err := binary.Write(file, binary.LittleEndian, a.data); err != nil {
-			return fmt.Errorf("error: Write operation failed (type might not be fixed-size): %v", err)
-		}
-	}
-	return nil
-}
-
-// LoadBinary загружает массив из бинарного файла.
-func (a *Array[T]) LoadBinary(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("error: Unable to open file: %v", err)
-	}
-	defer file.Close()
-
-	var newSize int32
-	if err := binary.Read(file, binary.LittleEndian, &newSize); err != nil {
-		return fmt.Errorf("error: Failed to read size")
-	}
-
-	a.data = make([]T, newSize)
-	if newSize > 0 {
-		if err := binary.Read(file, binary.LittleEndian, a.data); err != nil {
-			return fmt.Errorf("error: Failed to read data (incomplete or type mismatch): %v", err)
-		}
-	}
-	return nil
-}
+package array
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Array представляет собой динамический массив с дженериками
+type Array[T any] struct {
+	data []T
+
+	// Кодек элементов для бинарного формата; nil - codec.Default
+	elemCodec codec.ElementCodec[T]
+}
+
+// NewArray создает пустой массив
+func NewArray[T any]() *Array[T] {
+	return &Array[T]{
+		data: make([]T, 0, 1), // size 0, capacity 1
+	}
+}
+
+// NewArrayWithCap создает массив с заданной емкостью
+func NewArrayWithCap[T any](cap int) *Array[T] {
+	if cap < 1 {
+		cap = 1
+	}
+	// Создаем слайс длиной cap-1 и емкостью cap
+	return &Array[T]{
+		data: make([]T, cap-1, cap),
+	}
+}
+
+// GetSize возвращает текущий размер
+func (a *Array[T]) GetSize() int {
+	return len(a.data)
+}
+
+// GetCapacity возвращает текущую емкость
+func (a *Array[T]) GetCapacity() int {
+	return cap(a.data)
+}
+
+// PushBack добавляет элемент в конец
+func (a *Array[T]) PushBack(value T) {
+	// Go append автоматически управляет capacity (удваивает при необходимости)
+	a.data = append(a.data, value)
+}
+
+// Get возвращает элемент по индексу или ошибку
+func (a *Array[T]) Get(index int) (T, error) {
+	if index < 0 || index >= len(a.data) {
+		var empty T
+		return empty, fmt.Errorf("error: Index %d is out of bounds (size %d)", index, len(a.data))
+	}
+	return a.data[index], nil
+}
+
+// Set заменяет элемент по индексу
+func (a *Array[T]) Set(index int, value T) error {
+	if index < 0 || index >= len(a.data) {
+		return fmt.Errorf("error: Index %d is out of bounds", index)
+	}
+	a.data[index] = value
+	return nil
+}
+
+// InsertByInd вставляет элемент по индексу.
+func (a *Array[T]) InsertByInd(index int, value T) error {
+	if index < 0 || index > len(a.data) {
+		return fmt.Errorf("error: Index %d is out of bounds for insertion", index)
+	}
+	// Трюк со слайсами для вставки элемента
+	if index == len(a.data) {
+		a.PushBack(value)
+		return nil
+	}
+	a.data = append(a.data[:index+1], a.data[index:]...) // Раздвигаем
+	a.data[index] = value
+	return nil
+}
+
+// DeleteByInd удаляет элемент по индексу. Емкость не меняется.
+func (a *Array[T]) DeleteByInd(index int) error {
+	if index < 0 || index >= len(a.data) {
+		return fmt.Errorf("error: Index %d is out of bounds for deletion", index)
+	}
+	a.data = slices.Delete(a.data, index, index+1)
+	return nil
+}
+
+// SwapByInd заменяет значение
+func (a *Array[T]) SwapByInd(index int, value T) error {
+	if index < 0 || index >= len(a.data) {
+		return fmt.Errorf("error: Index %d is out of bounds for swap", index)
+	}
+	a.data[index] = value
+	return nil
+}
+
+// Clone создает глубокую копию массива
+func (a *Array[T]) Clone() *Array[T] {
+	newData := make([]T, len(a.data), cap(a.data))
+	copy(newData, a.data)
+	return &Array[T]{data: newData, elemCodec: a.elemCodec}
+}
+
+// SetCapacity изменяет емкость массива вручную
+func (a *Array[T]) SetCapacity(newCap int) error {
+	if newCap < len(a.data) {
+		return fmt.Errorf("error: New capacity cannot be smaller than current size")
+	}
+	a.resize(newCap)
+	return nil
+}
+
+// SetSize изменяет размер массива
+func (a *Array[T]) SetSize(newSize int) error {
+	if newSize > cap(a.data) {
+		return fmt.Errorf("error: New size exceeds current capacity")
+	}
+	a.data = a.data[:newSize]
+	return nil
+}
+
+// SaveText сохраняет массив в текстовый файл
+func (a *Array[T]) SaveText(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, a.writeText, opts...)
+}
+
+// LoadText загружает массив из текстового файла
+func (a *Array[T]) LoadText(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Unable to open file for reading: %v", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, a.readText, opts...)
+}
+
+// WriteTextTo записывает массив в w в текстовом формате
+func (a *Array[T]) WriteTextTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, a.writeText)
+}
+
+// ReadTextFrom читает массив в текстовом формате из r
+func (a *Array[T]) ReadTextFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, a.readText)
+}
+
+func (a *Array[T]) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, len(a.data)); err != nil {
+		return err
+	}
+	for _, val := range a.data {
+		// Строки пишутся в кавычках, поэтому могут содержать пробелы
+		tok, err := codec.FormatText(val)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, tok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Array[T]) readText(r io.Reader) error {
+	s := codec.NewTextScanner(r)
+	newSize, err := codec.ScanText[int](s)
+	if err != nil || newSize < 0 {
+		return fmt.Errorf("error: Failed to read size")
+	}
+
+	// Сброс массива; растим по частям, как codec.DecodeN
+	a.data = make([]T, 0, min(newSize, codec.ChunkSize))
+
+	for i := 0; i < newSize; i++ {
+		val, err := codec.ScanText[T](s)
+		if err != nil {
+			// Если данные кончились раньше времени
+			break
+		}
+		a.data = append(a.data, val)
+	}
+
+	if len(a.data) != newSize {
+		return fmt.Errorf("error: File corrupted or incomplete data")
+	}
+	return nil
+}
+
+// SetCodec задает кодек элементов для бинарного формата массива.
+// По умолчанию используется codec.Default, который поддерживает строки,
+// []byte, encoding.BinaryMarshaler и типы фиксированного размера.
+func (a *Array[T]) SetCodec(c codec.ElementCodec[T]) {
+	a.elemCodec = c
+}
+
+func (a *Array[T]) elementCodec() (codec.ElementCodec[T], error) {
+	if a.elemCodec != nil {
+		return a.elemCodec, nil
+	}
+	return codec.Default[T]()
+}
+
+// SaveBinary сохраняет массив в бинарном формате.
+func (a *Array[T]) SaveBinary(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, a.writeBinary, opts...)
+}
+
+// LoadBinary загружает массив из бинарного файла.
+func (a *Array[T]) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Unable to open file: %v", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, a.readBinary, opts...)
+}
+
+// WriteTo записывает массив в w в бинарном формате (io.WriterTo)
+func (a *Array[T]) WriteTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, a.writeBinary)
+}
+
+// ReadFrom читает массив в бинарном формате из r (io.ReaderFrom).
+// Читается ровно столько байт, сколько занимает массив.
+func (a *Array[T]) ReadFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, a.readBinary)
+}
+
+// writeBinary пишет массив в конверте persist с видом и типом элементов
+func (a *Array[T]) writeBinary(w io.Writer) error {
+	return persist.WriteEnvelope(w, persist.KindArray, persist.TypeName[T](), a.writePayload)
+}
+
+// readBinary проверяет конверт и читает массив
+func (a *Array[T]) readBinary(r io.Reader) error {
+	return persist.ReadEnvelope(r, persist.KindArray, persist.TypeName[T](), a.readPayload)
+}
+
+func (a *Array[T]) writePayload(w io.Writer) error {
+	c, err := a.elementCodec()
+	if err != nil {
+		return err
+	}
+
+	size := int32(len(a.data)) // Используем int32 для совместимости
+	if err := binary.Write(w, binary.LittleEndian, size); err != nil {
+		return err
+	}
+
+	if err := codec.EncodeSlice(w, c, a.data); err != nil {
+		return fmt.Errorf("error: Write operation failed: %w", err)
+	}
+	return nil
+}
+
+func (a *Array[T]) readPayload(r io.Reader) error {
+	c, err := a.elementCodec()
+	if err != nil {
+		return err
+	}
+
+	var newSize int32
+	if err := binary.Read(r, binary.LittleEndian, &newSize); err != nil {
+		return fmt.Errorf("error: Failed to read size")
+	}
+	if newSize < 0 {
+		return fmt.Errorf("error: Negative size %d", newSize)
+	}
+
+	data, err := codec.DecodeN(r, c, int(newSize))
+	if err != nil {
+		return fmt.Errorf("error: Failed to read data (incomplete or type mismatch): %v", err)
+	}
+	a.data = data
+	return nil
+}
+
+// Срезы и последовательности
+
+// FromSlice создает массив из копии s
+func FromSlice[T any](s []T) *Array[T] {
+	data := make([]T, len(s), max(len(s), 1))
+	copy(data, s)
+	return &Array[T]{data: data}
+}
+
+// FromSeq создает массив из элементов seq в порядке обхода
+func FromSeq[T any](seq iter.Seq[T]) *Array[T] {
+	a := NewArray[T]()
+	for v := range seq {
+		a.PushBack(v)
+	}
+	return a
+}
+
+// ToSlice возвращает копию элементов массива
+func (a *Array[T]) ToSlice() []T {
+	return slices.Clone(a.data)
+}
+
+// Итераторы
+//
+// Итераторы не копируют массив: шаг i читает элемент с индексом i в текущем
+// состоянии. Поэтому, если тело цикла меняет массив, All и Enumerate проходят
+// добавленные в конец элементы, а после удаления пропускают элемент, который
+// сдвинулся на место уже пройденного. Backward начинает с последнего элемента
+// на момент вызова и пропускает индексы, которых уже нет. Массив не
+// синхронизирован: изменение из другой горутины во время обхода - гонка данных.
+
+// All возвращает итератор по элементам от начала к концу
+func (a *Array[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < len(a.data); i++ {
+			if !yield(a.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward возвращает итератор по элементам от конца к началу
+func (a *Array[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(a.data) - 1; i >= 0; i-- {
+			if i >= len(a.data) {
+				continue
+			}
+			if !yield(a.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Enumerate возвращает итератор по парам (индекс, элемент)
+func (a *Array[T]) Enumerate() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < len(a.data); i++ {
+			if !yield(i, a.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// encoding и gob
+
+// MarshalBinary возвращает массив в бинарном формате с конвертом
+// (encoding.BinaryMarshaler), поэтому его можно вкладывать в другие структуры
+func (a *Array[T]) MarshalBinary() ([]byte, error) {
+	return persist.Marshal(a.writeBinary)
+}
+
+// UnmarshalBinary читает массив из данных MarshalBinary (encoding.BinaryUnmarshaler).
+// Данные должны содержать ровно один конверт.
+func (a *Array[T]) UnmarshalBinary(data []byte) error {
+	return persist.Unmarshal(data, a.readBinary)
+}
+
+// GobEncode кодирует массив для encoding/gob (gob.GobEncoder)
+func (a *Array[T]) GobEncode() ([]byte, error) {
+	return a.MarshalBinary()
+}
+
+// GobDecode читает массив, закодированный GobEncode (gob.GobDecoder)
+func (a *Array[T]) GobDecode(data []byte) error {
+	return a.UnmarshalBinary(data)
+}
+
+// JSON
+
+// MarshalJSON кодирует массив как JSON-массив (json.Marshaler)
+func (a *Array[T]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := a.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON читает массив из JSON-массива (json.Unmarshaler).
+// При ошибке массив не меняется.
+func (a *Array[T]) UnmarshalJSON(data []byte) error {
+	return a.readJSON(bytes.NewReader(data))
+}
+
+// WriteJSONTo пишет массив в w как JSON-массив, не собирая его в памяти
+func (a *Array[T]) WriteJSONTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, a.writeJSON)
+}
+
+// ReadJSONFrom читает массив из JSON-массива в r до конца потока
+func (a *Array[T]) ReadJSONFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, a.readJSON)
+}
+
+func (a *Array[T]) writeJSON(w io.Writer) error {
+	return codec.EncodeJSONArray(w, func(emit func(T) error) error {
+		for _, val := range a.data {
+			if err := emit(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (a *Array[T]) readJSON(r io.Reader) error {
+	data := make([]T, 0, 1)
+	err := codec.ReadJSON(r, func(dec *json.Decoder) error {
+		return codec.DecodeJSONArray(dec, "$", func(val T) error {
+			data = append(data, val)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	a.data = data
+	return nil
+}
+
+// CSV
+
+// ExportCSV пишет массив в w в формате CSV: заголовок и по строке на элемент.
+// Колонки задаются codec.DefaultCSVMapper или опцией codec.WithCSVMapper.
+func (a *Array[T]) ExportCSV(w io.Writer, opts ...codec.CSVOption) error {
+	return codec.WriteCSV(w, func(emit func(T) error) error {
+		for _, val := range a.data {
+			if err := emit(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts...)
+}
+
+// ImportCSV заменяет содержимое массива строками CSV из r. Строки, которые
+// не удалось разобрать, пропускаются и возвращаются в codec.RowErrors
+// с номерами строк. При ошибке чтения или заголовка массив не меняется.
+func (a *Array[T]) ImportCSV(r io.Reader, opts ...codec.CSVOption) error {
+	data := make([]T, 0, 1)
+	rowErrs, err := codec.ReadCSV(r, func(val T) error {
+		data = append(data, val)
+		return nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	a.data = data
+	return rowErrs.Err()
+}
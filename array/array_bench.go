@@ -1,123 +1,185 @@
-package array
-
-import (
-	"os"
-	"testing"
-)
-
-const (
-	SmallDataSize = 10000
-	LargeDataSize = 1000000
-)
-
-// BenchmarkPushBack измеряет скорость добавления в конец.
-func BenchmarkPushBack(b *testing.B) {
-	// b.N — это количество итераций, которое Go подберет сам для точности замера
-	for i := 0; i < b.N; i++ {
-		arr := NewArray[int]()
-		for j := 0; j < SmallDataSize; j++ {
-			arr.PushBack(j)
-		}
-	}
-}
-
-// BenchmarkPushBack_PreAllocated измеряет скорость, если мы заранее знаем размер (NewArrayWithCap).
-func BenchmarkPushBack_PreAllocated(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		arr := NewArrayWithCap[int](SmallDataSize)
-		for j := 0; j < SmallDataSize; j++ {
-			arr.PushBack(j)
-		}
-	}
-}
-
-// BenchmarkInsertMiddle измеряет вставку в середину.
-func BenchmarkInsertMiddle(b *testing.B) {
-	// Создаем массив
-	b.StopTimer() // Останавливаем таймер на время подготовки
-	arr := NewArray[int]()
-	for i := 0; i < SmallDataSize; i++ {
-		arr.PushBack(i)
-	}
-	b.StartTimer() // Запускаем таймер
-
-	// Вставляем b.N раз в середину
-	for i := 0; i < b.N; i++ {
-		// Вставляем всегда в середину текущего размера
-		mid := arr.GetSize() / 2
-		_ = arr.InsertByInd(mid, i)
-	}
-}
-
-// BenchmarkAccessGet измеряет скорость чтения по индексу.
-func BenchmarkAccessGet(b *testing.B) {
-	// Подготовка
-	arr := NewArray[int]()
-	for i := 0; i < LargeDataSize; i++ {
-		arr.PushBack(i)
-	}
-
-	size := arr.GetSize()
-
-	b.ResetTimer() // Сбрасываем таймер перед началом цикла
-	for i := 0; i < b.N; i++ {
-		// Используем i как псевдослучайный индекс
-		idx := i % size
-		_, _ = arr.Get(idx)
-	}
-}
-
-// BenchmarkBinaryIO измеряет скорость сохранения и загрузки в бинарном формате.
-func BenchmarkBinaryIO(b *testing.B) {
-	filename := "bench_test.bin"
-	defer os.Remove(filename) // Удаляем файл после теста
-
-	// Подготовка данных
-	arr := NewArray[int32]()
-	for i := 0; i < SmallDataSize; i++ { // 10k элементов для IO теста
-		arr.PushBack(int32(i))
-	}
-
-	b.ResetTimer()
-
-	for i := 0; i < b.N; i++ {
-		// Замеряем запись
-		err := arr.SaveBinary(filename)
-		if err != nil {
-			b.Fatal(err)
-		}
-
-		// Замеряем чтение
-		loadedArr := NewArray[int32]()
-		err = loadedArr.LoadBinary(filename)
-		if err != nil {
-			b.Fatal(err)
-		}
-	}
-}
-
-// BenchmarkTextIO измеряет скорость сохранения и загрузки в текстовом формате.
-func BenchmarkTextIO(b *testing.B) {
-	filename := "bench_test.txt"
-	defer os.Remove(filename)
-
-	arr := NewArray[int]()
-	for i := 0; i < 1000; i++ { // Меньше элементов, т.к. текст медленный
-		arr.PushBack(i)
-	}
-
-	b.ResetTimer()
-
-	for i := 0; i < b.N; i++ {
-		err := arr.SaveText(filename)
-		if err != nil {
-			b.Fatal(err)
-		}
-
-		loadedArr := NewArray[int]()
-		err = loadedArr.LoadText(filename)
-		if err != nil {
-			b.Fatal(err)
-		}
-	}
-}
+package array
+
+import (
+	"os"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+)
+
+const (
+	SmallDataSize = 10000
+	LargeDataSize = 1000000
+)
+
+// BenchmarkPushBack измеряет скорость добавления в конец.
+func BenchmarkPushBack(b *testing.B) {
+	// b.N — это количество итераций, которое Go подберет сам для точности замера
+	for i := 0; i < b.N; i++ {
+		arr := NewArray[int]()
+		for j := 0; j < SmallDataSize; j++ {
+			arr.PushBack(j)
+		}
+	}
+}
+
+// BenchmarkPushBack_PreAllocated измеряет скорость, если мы заранее знаем размер (NewArrayWithCap).
+func BenchmarkPushBack_PreAllocated(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		arr := NewArrayWithCap[int](SmallDataSize)
+		for j := 0; j < SmallDataSize; j++ {
+			arr.PushBack(j)
+		}
+	}
+}
+
+// BenchmarkInsertMiddle измеряет вставку в середину.
+func BenchmarkInsertMiddle(b *testing.B) {
+	// Создаем массив
+	b.StopTimer() // Останавливаем таймер на время подготовки
+	arr := NewArray[int]()
+	for i := 0; i < SmallDataSize; i++ {
+		arr.PushBack(i)
+	}
+	b.StartTimer() // Запускаем таймер
+
+	// Вставляем b.N раз в середину
+	for i := 0; i < b.N; i++ {
+		// Вставляем всегда в середину текущего размера
+		mid := arr.GetSize() / 2
+		_ = arr.InsertByInd(mid, i)
+	}
+}
+
+// BenchmarkAccessGet измеряет скорость чтения по индексу.
+func BenchmarkAccessGet(b *testing.B) {
+	// Подготовка
+	arr := NewArray[int]()
+	for i := 0; i < LargeDataSize; i++ {
+		arr.PushBack(i)
+	}
+
+	size := arr.GetSize()
+
+	b.ResetTimer() // Сбрасываем таймер перед началом цикла
+	for i := 0; i < b.N; i++ {
+		// Используем i как псевдослучайный индекс
+		idx := i % size
+		_, _ = arr.Get(idx)
+	}
+}
+
+// BenchmarkBinaryIO измеряет скорость сохранения и загрузки в бинарном формате.
+func BenchmarkBinaryIO(b *testing.B) {
+	filename := "bench_test.bin"
+	defer os.Remove(filename) // Удаляем файл после теста
+
+	// Подготовка данных
+	arr := NewArray[int32]()
+	for i := 0; i < SmallDataSize; i++ { // 10k элементов для IO теста
+		arr.PushBack(int32(i))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// Замеряем запись
+		err := arr.SaveBinary(filename)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		// Замеряем чтение
+		loadedArr := NewArray[int32]()
+		err = loadedArr.LoadBinary(filename)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkTextIO измеряет скорость сохранения и загрузки в текстовом формате.
+func BenchmarkTextIO(b *testing.B) {
+	filename := "bench_test.txt"
+	defer os.Remove(filename)
+
+	arr := NewArray[int]()
+	for i := 0; i < 1000; i++ { // Меньше элементов, т.к. текст медленный
+		arr.PushBack(i)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := arr.SaveText(filename)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		loadedArr := NewArray[int]()
+		err = loadedArr.LoadText(filename)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCompressedIO измеряет сохранение и загрузку бинарного снимка с каждым
+// алгоритмом сжатия и сообщает размер файла.
+func BenchmarkCompressedIO(b *testing.B) {
+	filename := "bench_test.bin.z"
+	defer os.Remove(filename)
+
+	// Повторяющиеся значения, как в реальных снимках
+	arr := NewArray[int32]()
+	for i := 0; i < LargeDataSize; i++ {
+		arr.PushBack(int32(i % 100))
+	}
+
+	for _, alg := range []compression.Algorithm{compression.None, compression.Gzip, compression.Flate, compression.LZ} {
+		b.Run(alg.String(), func(b *testing.B) {
+			b.SetBytes(int64(arr.GetSize()) * 4)
+			for i := 0; i < b.N; i++ {
+				if err := arr.SaveBinary(filename, compression.With(alg)); err != nil {
+					b.Fatal(err)
+				}
+				loadedArr := NewArray[int32]()
+				if err := loadedArr.LoadBinary(filename); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if info, err := os.Stat(filename); err == nil {
+				b.ReportMetric(float64(info.Size()), "file-bytes")
+			}
+		})
+	}
+}
+
+// BenchmarkIterate сравнивает обход через All с циклом по индексам через Get
+func BenchmarkIterate(b *testing.B) {
+	arr := NewArrayWithCap[int](LargeDataSize)
+	for j := 0; j < LargeDataSize; j++ {
+		arr.PushBack(j)
+	}
+
+	b.Run("All", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sum := 0
+			for v := range arr.All() {
+				sum += v
+			}
+			_ = sum
+		}
+	})
+
+	b.Run("Get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sum := 0
+			for j := 0; j < arr.GetSize(); j++ {
+				v, _ := arr.Get(j)
+				sum += v
+			}
+			_ = sum
+		}
+	})
+}
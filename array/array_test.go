@@ -1,306 +1,820 @@
-package array
-
-import (
-	"math"
-	"os"
-	"testing"
-)
-
-// cleanFile — вспомогательная функция для очистки после тестов
-func cleanFile(filename string) {
-	os.Remove(filename)
-}
-
-// TestConstructors покрывает NewArray и NewArrayWithCap
-func TestConstructors(t *testing.T) {
-	// Стандартный конструктор
-	arr := NewArray[int]()
-	if arr.GetSize() != 0 || arr.GetCapacity() != 1 {
-		t.Errorf("NewArray failed: size=%d, cap=%d", arr.GetSize(), arr.GetCapacity())
-	}
-
-	// Конструктор с емкостью
-	arrCap := NewArrayWithCap[int](10)
-	if arrCap.GetCapacity() != 10 {
-		t.Errorf("NewArrayWithCap failed capacity: got %d", arrCap.GetCapacity())
-	}
-	if arrCap.GetSize() != 9 {
-		t.Errorf("NewArrayWithCap logic mismatch: expected size 9, got %d", arrCap.GetSize())
-	}
-
-	// Edge Case: Емкость < 1
-	arrNeg := NewArrayWithCap[int](-5)
-	if arrNeg.GetCapacity() != 1 {
-		t.Errorf("NewArrayWithCap negative check failed: expected cap 1, got %d", arrNeg.GetCapacity())
-	}
-	// size = cap - 1 => 1 - 1 = 0
-	if arrNeg.GetSize() != 0 {
-		t.Errorf("NewArrayWithCap negative check failed: expected size 0, got %d", arrNeg.GetSize())
-	}
-}
-
-// TestCoreOperations покрывает Get, Set, PushBack
-func TestCoreOperations(t *testing.T) {
-	arr := NewArray[string]()
-	arr.PushBack("A")
-	arr.PushBack("B")
-
-	// Valid Get
-	val, err := arr.Get(1)
-	if err != nil || val != "B" {
-		t.Errorf("Get failed")
-	}
-
-	// Invalid Get (Index < 0)
-	_, err = arr.Get(-1)
-	if err == nil {
-		t.Error("Expected error for Get(-1)")
-	}
-
-	// Invalid Get (Index >= Size)
-	_, err = arr.Get(2)
-	if err == nil {
-		t.Error("Expected error for Get(2)")
-	}
-
-	// Valid Set
-	err = arr.Set(0, "C")
-	if err != nil {
-		t.Errorf("Set failed: %v", err)
-	}
-	v, _ := arr.Get(0)
-	if v != "C" {
-		t.Errorf("Set did not update value")
-	}
-
-	// Invalid Set
-	if err := arr.Set(100, "Z"); err == nil {
-		t.Error("Expected error for Set out of bounds")
-	}
-}
-
-// TestInsertDeleteSwap покрывает InsertByInd, DeleteByInd, SwapByInd
-func TestInsertDeleteSwap(t *testing.T) {
-	arr := NewArray[int]()
-	arr.PushBack(10) // [10]
-
-	// Insert в конец (особая ветка if index == len)
-	err := arr.InsertByInd(1, 20) // [10, 20]
-	if err != nil {
-		t.Errorf("Insert at end failed: %v", err)
-	}
-	if arr.GetSize() != 2 {
-		t.Errorf("Size incorrect after insert")
-	}
-
-	// Insert в середину
-	arr.InsertByInd(0, 5) // [5, 10, 20]
-	v, _ := arr.Get(0)
-	if v != 5 {
-		t.Errorf("Insert at 0 failed")
-	}
-
-	// Insert Error
-	if err := arr.InsertByInd(50, 1); err == nil {
-		t.Error("Insert out of bounds should fail")
-	}
-
-	// Delete
-	arr.DeleteByInd(1) // удаляем 10 -> [5, 20]
-	if arr.GetSize() != 2 {
-		t.Errorf("Delete size check failed")
-	}
-	v2, _ := arr.Get(1)
-	if v2 != 20 {
-		t.Errorf("Delete logic check failed")
-	}
-
-	// Delete Error
-	if err := arr.DeleteByInd(5); err == nil {
-		t.Error("Delete out of bounds should fail")
-	}
-
-	// Swap
-	arr.SwapByInd(0, 99)
-	v0, _ := arr.Get(0)
-	if v0 != 99 {
-		t.Errorf("Swap failed")
-	}
-
-	// Swap Error
-	if err := arr.SwapByInd(-1, 1); err == nil {
-		t.Error("Swap invalid index should fail")
-	}
-}
-
-// TestSizeAndCapacity покрывает SetCapacity, SetSize, Clone
-func TestSizeAndCapacity(t *testing.T) {
-	arr := NewArray[int]()
-	arr.PushBack(1)
-	arr.PushBack(2)
-
-	// Clone
-	clone := arr.Clone()
-	if clone.GetSize() != 2 {
-		t.Error("Clone size mismatch")
-	}
-	clone.Set(0, 100)
-	origVal, _ := arr.Get(0)
-	if origVal == 100 {
-		t.Error("Clone is shallow, expected deep copy")
-	}
-
-	// SetCapacity Valid
-	if err := arr.SetCapacity(10); err != nil {
-		t.Error(err)
-	}
-	if arr.GetCapacity() != 10 {
-		t.Error("SetCapacity failed")
-	}
-
-	// SetCapacity Invalid (New < Size)
-	if err := arr.SetCapacity(1); err == nil {
-		t.Error("SetCapacity should fail if new cap < size")
-	}
-
-	// SetSize Valid
-	if err := arr.SetSize(1); err != nil {
-		t.Error(err)
-	}
-	if arr.GetSize() != 1 {
-		t.Error("SetSize failed")
-	}
-
-	// SetSize Invalid (New > Cap)
-	if err := arr.SetSize(20); err == nil {
-		t.Error("SetSize should fail if new size > cap")
-	}
-}
-
-// TestIO_Text покрывает SaveText и LoadText со всеми ошибками
-func TestIO_Text(t *testing.T) {
-	filename := "test_text.txt"
-	defer cleanFile(filename)
-
-	arr := NewArray[int]()
-	arr.PushBack(10)
-	arr.PushBack(20)
-
-	// Успешное сохранение
-	if err := arr.SaveText(filename); err != nil {
-		t.Fatalf("SaveText failed: %v", err)
-	}
-
-	// Успешная загрузка
-	arrLoad := NewArray[int]()
-	if err := arrLoad.LoadText(filename); err != nil {
-		t.Fatalf("LoadText failed: %v", err)
-	}
-	if arrLoad.GetSize() != 2 {
-		t.Error("LoadText size mismatch")
-	}
-
-	// Ошибка сохранения (недопустимое имя файла/папки)
-	if err := arr.SaveText("."); err == nil {
-		// Если вдруг "." разрешено, попробуем несуществующую директорию
-		if err := arr.SaveText("/non_existent_dir/file.txt"); err == nil {
-			t.Error("SaveText should fail for bad path")
-		}
-	}
-
-	// Ошибка загрузки (файл не найден)
-	if err := arrLoad.LoadText("missing.txt"); err == nil {
-		t.Error("LoadText should fail for missing file")
-	}
-
-	// Ошибка загрузки (битый файл: нет размера)
-	emptyFile := "empty.txt"
-	os.WriteFile(emptyFile, []byte(""), 0644)
-	defer cleanFile(emptyFile)
-	if err := arrLoad.LoadText(emptyFile); err == nil {
-		t.Error("LoadText should fail on empty file (no size)")
-	}
-
-	// Ошибка загрузки (битый файл: размер есть, данных нет)
-	corruptFile := "corrupt.txt"
-	// Пишем: "5 10" (размер 5, а элементов только 1)
-	os.WriteFile(corruptFile, []byte("5 10"), 0644)
-	defer cleanFile(corruptFile)
-	if err := arrLoad.LoadText(corruptFile); err == nil {
-		t.Error("LoadText should fail when data is incomplete")
-	}
-}
-
-// TestIO_Binary покрывает SaveBinary и LoadBinary
-func TestIO_Binary(t *testing.T) {
-	filename := "test_bin.bin"
-	defer cleanFile(filename)
-
-	arr := NewArray[float64]()
-	arr.PushBack(1.23)
-	arr.PushBack(4.56)
-
-	// Успешное сохранение
-	if err := arr.SaveBinary(filename); err != nil {
-		t.Fatalf("SaveBinary failed: %v", err)
-	}
-
-	// Успешная загрузка
-	arrLoad := NewArray[float64]()
-	if err := arrLoad.LoadBinary(filename); err != nil {
-		t.Fatalf("LoadBinary failed: %v", err)
-	}
-	val, _ := arrLoad.Get(0)
-	if math.Abs(val-1.23) > 0.0001 {
-		t.Error("Binary load value mismatch")
-	}
-
-	// Ошибка сохранения: недопустимый тип для binary.Write
-	// binary.Write не умеет писать string автоматически
-	arrStr := NewArray[string]()
-	arrStr.PushBack("Fail")
-	if err := arrStr.SaveBinary("fail.bin"); err == nil {
-		cleanFile("fail.bin")
-		t.Error("SaveBinary should fail for non-fixed size types (string)")
-	} else {
-		cleanFile("fail.bin") // на случай если файл создался 0 байт
-	}
-
-	// Ошибка сохранения: ошибка открытия файла
-	if err := arr.SaveBinary("/nop/file.bin"); err == nil {
-		t.Error("SaveBinary should fail for bad path")
-	}
-
-	// Ошибка загрузки: файл не найден
-	if err := arrLoad.LoadBinary("missing.bin"); err == nil {
-		t.Error("LoadBinary should fail for missing file")
-	}
-
-	// Ошибка загрузки: битый размер (пустой файл)
-	badSizeFile := "bad_size.bin"
-	os.WriteFile(badSizeFile, []byte{}, 0644)
-	defer cleanFile(badSizeFile)
-	if err := arrLoad.LoadBinary(badSizeFile); err == nil {
-		t.Error("LoadBinary should fail reading size from empty file")
-	}
-
-	// Ошибка загрузки: неполные данные
-	// Создадим файл с корректным заголовком (размер 10), но без данных
-	incompleteFile := "incomplete.bin"
-	f, _ := os.Create(incompleteFile)
-	// Записываем int32 = 10
-	importBinaryWrite(t, f, int32(10))
-	f.Close()
-	defer cleanFile(incompleteFile)
-
-	if err := arrLoad.LoadBinary(incompleteFile); err == nil {
-		t.Error("LoadBinary should fail when data is incomplete")
-	}
-}
-
-// Вспомогательная функция для теста бинарной записи
-func importBinaryWrite(t *testing.T, f *os.File, val int32) {
-	// Ручная запись little endian int32 (10, 0, 0, 0)
-	data := []byte{byte(val), byte(val >> 8), byte(val >> 16), byte(val >> 24)}
-	f.Write(data)
-}
+package array
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+	"github.com/D4ROVAN1E/LR_3_Go/persist/persisttest"
+)
+
+// cleanFile — вспомогательная функция для очистки после тестов
+func cleanFile(filename string) {
+	os.Remove(filename)
+}
+
+// TestConstructors покрывает NewArray и NewArrayWithCap
+func TestConstructors(t *testing.T) {
+	// Стандартный конструктор
+	arr := NewArray[int]()
+	if arr.GetSize() != 0 || arr.GetCapacity() != 1 {
+		t.Errorf("NewArray failed: size=%d, cap=%d", arr.GetSize(), arr.GetCapacity())
+	}
+
+	// Конструктор с емкостью
+	arrCap := NewArrayWithCap[int](10)
+	if arrCap.GetCapacity() != 10 {
+		t.Errorf("NewArrayWithCap failed capacity: got %d", arrCap.GetCapacity())
+	}
+	if arrCap.GetSize() != 9 {
+		t.Errorf("NewArrayWithCap logic mismatch: expected size 9, got %d", arrCap.GetSize())
+	}
+
+	// Edge Case: Емкость < 1
+	arrNeg := NewArrayWithCap[int](-5)
+	if arrNeg.GetCapacity() != 1 {
+		t.Errorf("NewArrayWithCap negative check failed: expected cap 1, got %d", arrNeg.GetCapacity())
+	}
+	// size = cap - 1 => 1 - 1 = 0
+	if arrNeg.GetSize() != 0 {
+		t.Errorf("NewArrayWithCap negative check failed: expected size 0, got %d", arrNeg.GetSize())
+	}
+}
+
+// TestCoreOperations покрывает Get, Set, PushBack
+func TestCoreOperations(t *testing.T) {
+	arr := NewArray[string]()
+	arr.PushBack("A")
+	arr.PushBack("B")
+
+	// Valid Get
+	val, err := arr.Get(1)
+	if err != nil || val != "B" {
+		t.Errorf("Get failed")
+	}
+
+	// Invalid Get (Index < 0)
+	_, err = arr.Get(-1)
+	if err == nil {
+		t.Error("Expected error for Get(-1)")
+	}
+
+	// Invalid Get (Index >= Size)
+	_, err = arr.Get(2)
+	if err == nil {
+		t.Error("Expected error for Get(2)")
+	}
+
+	// Valid Set
+	err = arr.Set(0, "C")
+	if err != nil {
+		t.Errorf("Set failed: %v", err)
+	}
+	v, _ := arr.Get(0)
+	if v != "C" {
+		t.Errorf("Set did not update value")
+	}
+
+	// Invalid Set
+	if err := arr.Set(100, "Z"); err == nil {
+		t.Error("Expected error for Set out of bounds")
+	}
+}
+
+// TestInsertDeleteSwap покрывает InsertByInd, DeleteByInd, SwapByInd
+func TestInsertDeleteSwap(t *testing.T) {
+	arr := NewArray[int]()
+	arr.PushBack(10) // [10]
+
+	// Insert в конец (особая ветка if index == len)
+	err := arr.InsertByInd(1, 20) // [10, 20]
+	if err != nil {
+		t.Errorf("Insert at end failed: %v", err)
+	}
+	if arr.GetSize() != 2 {
+		t.Errorf("Size incorrect after insert")
+	}
+
+	// Insert в середину
+	arr.InsertByInd(0, 5) // [5, 10, 20]
+	v, _ := arr.Get(0)
+	if v != 5 {
+		t.Errorf("Insert at 0 failed")
+	}
+
+	// Insert Error
+	if err := arr.InsertByInd(50, 1); err == nil {
+		t.Error("Insert out of bounds should fail")
+	}
+
+	// Delete
+	arr.DeleteByInd(1) // удаляем 10 -> [5, 20]
+	if arr.GetSize() != 2 {
+		t.Errorf("Delete size check failed")
+	}
+	v2, _ := arr.Get(1)
+	if v2 != 20 {
+		t.Errorf("Delete logic check failed")
+	}
+
+	// Delete Error
+	if err := arr.DeleteByInd(5); err == nil {
+		t.Error("Delete out of bounds should fail")
+	}
+
+	// Swap
+	arr.SwapByInd(0, 99)
+	v0, _ := arr.Get(0)
+	if v0 != 99 {
+		t.Errorf("Swap failed")
+	}
+
+	// Swap Error
+	if err := arr.SwapByInd(-1, 1); err == nil {
+		t.Error("Swap invalid index should fail")
+	}
+}
+
+// TestSizeAndCapacity покрывает SetCapacity, SetSize, Clone
+func TestSizeAndCapacity(t *testing.T) {
+	arr := NewArray[int]()
+	arr.PushBack(1)
+	arr.PushBack(2)
+
+	// Clone
+	clone := arr.Clone()
+	if clone.GetSize() != 2 {
+		t.Error("Clone size mismatch")
+	}
+	clone.Set(0, 100)
+	origVal, _ := arr.Get(0)
+	if origVal == 100 {
+		t.Error("Clone is shallow, expected deep copy")
+	}
+
+	// SetCapacity Valid
+	if err := arr.SetCapacity(10); err != nil {
+		t.Error(err)
+	}
+	if arr.GetCapacity() != 10 {
+		t.Error("SetCapacity failed")
+	}
+
+	// SetCapacity Invalid (New < Size)
+	if err := arr.SetCapacity(1); err == nil {
+		t.Error("SetCapacity should fail if new cap < size")
+	}
+
+	// SetSize Valid
+	if err := arr.SetSize(1); err != nil {
+		t.Error(err)
+	}
+	if arr.GetSize() != 1 {
+		t.Error("SetSize failed")
+	}
+
+	// SetSize Invalid (New > Cap)
+	if err := arr.SetSize(20); err == nil {
+		t.Error("SetSize should fail if new size > cap")
+	}
+}
+
+// TestIO_Text покрывает SaveText и LoadText со всеми ошибками
+func TestIO_Text(t *testing.T) {
+	filename := "test_text.txt"
+	defer cleanFile(filename)
+
+	arr := NewArray[int]()
+	arr.PushBack(10)
+	arr.PushBack(20)
+
+	// Успешное сохранение
+	if err := arr.SaveText(filename); err != nil {
+		t.Fatalf("SaveText failed: %v", err)
+	}
+
+	// Успешная загрузка
+	arrLoad := NewArray[int]()
+	if err := arrLoad.LoadText(filename); err != nil {
+		t.Fatalf("LoadText failed: %v", err)
+	}
+	if arrLoad.GetSize() != 2 {
+		t.Error("LoadText size mismatch")
+	}
+
+	// Ошибка сохранения (недопустимое имя файла/папки)
+	if err := arr.SaveText("."); err == nil {
+		// Если вдруг "." разрешено, попробуем несуществующую директорию
+		if err := arr.SaveText("/non_existent_dir/file.txt"); err == nil {
+			t.Error("SaveText should fail for bad path")
+		}
+	}
+
+	// Ошибка загрузки (файл не найден)
+	if err := arrLoad.LoadText("missing.txt"); err == nil {
+		t.Error("LoadText should fail for missing file")
+	}
+
+	// Ошибка загрузки (битый файл: нет размера)
+	emptyFile := "empty.txt"
+	os.WriteFile(emptyFile, []byte(""), 0644)
+	defer cleanFile(emptyFile)
+	if err := arrLoad.LoadText(emptyFile); err == nil {
+		t.Error("LoadText should fail on empty file (no size)")
+	}
+
+	// Ошибка загрузки (битый файл: размер есть, данных нет)
+	corruptFile := "corrupt.txt"
+	// Пишем: "5 10" (размер 5, а элементов только 1)
+	os.WriteFile(corruptFile, []byte("5 10"), 0644)
+	defer cleanFile(corruptFile)
+	if err := arrLoad.LoadText(corruptFile); err == nil {
+		t.Error("LoadText should fail when data is incomplete")
+	}
+}
+
+// TestIO_Binary покрывает SaveBinary и LoadBinary
+func TestIO_Binary(t *testing.T) {
+	filename := "test_bin.bin"
+	defer cleanFile(filename)
+
+	arr := NewArray[float64]()
+	arr.PushBack(1.23)
+	arr.PushBack(4.56)
+
+	// Успешное сохранение
+	if err := arr.SaveBinary(filename); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+
+	// Успешная загрузка
+	arrLoad := NewArray[float64]()
+	if err := arrLoad.LoadBinary(filename); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+	val, _ := arrLoad.Get(0)
+	if math.Abs(val-1.23) > 0.0001 {
+		t.Error("Binary load value mismatch")
+	}
+
+	// Ошибка сохранения: для map нет кодека по умолчанию
+	arrMap := NewArray[map[string]int]()
+	arrMap.PushBack(map[string]int{"a": 1})
+	if err := arrMap.SaveBinary("fail.bin"); !errors.Is(err, codec.ErrUnsupportedType) {
+		t.Errorf("SaveBinary should fail with ErrUnsupportedType for map, got %v", err)
+	}
+	cleanFile("fail.bin") // на случай если файл создался
+
+	// Ошибка сохранения: ошибка открытия файла
+	if err := arr.SaveBinary("/nop/file.bin"); err == nil {
+		t.Error("SaveBinary should fail for bad path")
+	}
+
+	// Ошибка загрузки: файл не найден
+	if err := arrLoad.LoadBinary("missing.bin"); err == nil {
+		t.Error("LoadBinary should fail for missing file")
+	}
+
+	// Ошибка загрузки: битый размер (пустой файл)
+	badSizeFile := "bad_size.bin"
+	os.WriteFile(badSizeFile, []byte{}, 0644)
+	defer cleanFile(badSizeFile)
+	if err := arrLoad.LoadBinary(badSizeFile); err == nil {
+		t.Error("LoadBinary should fail reading size from empty file")
+	}
+
+	// Ошибка загрузки: неполные данные
+	// Создадим файл с корректным заголовком (размер 10), но без данных
+	incompleteFile := "incomplete.bin"
+	f, _ := os.Create(incompleteFile)
+	// Записываем int32 = 10
+	importBinaryWrite(t, f, int32(10))
+	f.Close()
+	defer cleanFile(incompleteFile)
+
+	if err := arrLoad.LoadBinary(incompleteFile); err == nil {
+		t.Error("LoadBinary should fail when data is incomplete")
+	}
+}
+
+// Вспомогательная функция для теста бинарной записи
+func importBinaryWrite(t *testing.T, f *os.File, val int32) {
+	// Ручная запись little endian int32 (10, 0, 0, 0)
+	data := []byte{byte(val), byte(val >> 8), byte(val >> 16), byte(val >> 24)}
+	f.Write(data)
+}
+
+// Тесты атомарного сохранения
+
+// TestSaveFailureKeepsFile обрывает запись на разных байтах и проверяет,
+// что ранее сохраненный файл не изменился, а временные файлы удалены
+func TestSaveFailureKeepsFile(t *testing.T) {
+	arr := NewArray[int32]()
+	for i := 0; i < 100; i++ {
+		arr.PushBack(int32(i))
+	}
+	persisttest.SaveFailureKeepsFile(t, map[string]func(string, ...persist.Option) error{
+		"text":   arr.SaveText,
+		"binary": arr.SaveBinary,
+	}, func() { arr.PushBack(1000) })
+}
+
+// streamFormats - форматы потокового сохранения массива
+func streamFormats[T any]() []persisttest.Format[*Array[T]] {
+	return []persisttest.Format[*Array[T]]{
+		{Name: "text", Write: (*Array[T]).WriteTextTo, Read: (*Array[T]).ReadTextFrom},
+		{Name: "binary", Write: (*Array[T]).WriteTo, Read: (*Array[T]).ReadFrom},
+		{Name: "json", Write: (*Array[T]).WriteJSONTo, Read: (*Array[T]).ReadJSONFrom},
+	}
+}
+
+// sameArray сравнивает элементы массивов
+func sameArray[T comparable](a, b *Array[T]) bool {
+	return slices.Equal(a.ToSlice(), b.ToSlice())
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	src := NewArray[int32]()
+	for i := 0; i < 50; i++ {
+		src.PushBack(int32(i))
+	}
+	var _ io.WriterTo = src
+	var _ io.ReaderFrom = src
+
+	persisttest.RoundTrip(t, src, NewArray[int32], streamFormats[int32](), sameArray[int32])
+}
+
+func TestReadFromStopsAtEnd(t *testing.T) {
+	first, second := NewArray[int32](), NewArray[int32]()
+	first.PushBack(1)
+	first.PushBack(2)
+	second.PushBack(3)
+
+	// Два массива подряд в одном потоке
+	var buf bytes.Buffer
+	first.WriteTo(&buf)
+	second.WriteTo(&buf)
+
+	a, b := NewArray[int32](), NewArray[int32]()
+	if _, err := a.ReadFrom(&buf); err != nil {
+		t.Fatalf("First ReadFrom failed: %v", err)
+	}
+	if _, err := b.ReadFrom(&buf); err != nil {
+		t.Fatalf("Second ReadFrom failed: %v", err)
+	}
+	if a.GetSize() != 2 || b.GetSize() != 1 {
+		t.Errorf("Expected sizes 2 and 1, got %d and %d", a.GetSize(), b.GetSize())
+	}
+	if v, _ := b.Get(0); v != 3 {
+		t.Errorf("Expected 3, got %d", v)
+	}
+}
+
+func TestLoadBinaryValidatesEnvelope(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.bin")
+	arr := NewArray[int32]()
+	arr.PushBack(7)
+	if err := arr.SaveBinary(path); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+
+	// Другой тип элементов
+	if err := NewArray[int64]().LoadBinary(path); !errors.Is(err, persist.ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch, got %v", err)
+	}
+
+	// Файл другой структуры
+	os.WriteFile(path, persisttest.Envelope[int32](persist.KindStack, nil), 0644)
+	if err := arr.LoadBinary(path); !errors.Is(err, persist.ErrKindMismatch) {
+		t.Errorf("Expected ErrKindMismatch, got %v", err)
+	}
+
+	// Файл без конверта
+	os.WriteFile(path, []byte{1, 0, 0, 0, 7, 0, 0, 0}, 0644)
+	if err := arr.LoadBinary(path); !errors.Is(err, persist.ErrBadMagic) {
+		t.Errorf("Expected ErrBadMagic, got %v", err)
+	}
+}
+
+// Тесты кодеков элементов
+
+// upperCodec пишет строки в верхнем регистре, чтобы было видно, что используется именно он
+type upperCodec struct{ codec.String }
+
+func (upperCodec) Encode(w io.Writer, v string) error {
+	return codec.String{}.Encode(w, strings.ToUpper(v))
+}
+
+func TestBinaryVariableLength(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strings.bin")
+	arr := NewArray[string]()
+	for _, s := range []string{"alpha", "", "строка с пробелами"} {
+		arr.PushBack(s)
+	}
+	if err := arr.SaveBinary(path); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+	loaded := NewArray[string]()
+	if err := loaded.LoadBinary(path); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+	for i := 0; i < arr.GetSize(); i++ {
+		want, _ := arr.Get(i)
+		if got, _ := loaded.Get(i); got != want {
+			t.Errorf("Index %d: expected %q, got %q", i, want, got)
+		}
+	}
+
+	// []byte и типы с BinaryMarshaler
+	blobs := NewArray[[]byte]()
+	blobs.PushBack([]byte{1, 2, 3})
+	blobs.PushBack(nil)
+	var buf bytes.Buffer
+	if _, err := blobs.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo []byte failed: %v", err)
+	}
+	loadedBlobs := NewArray[[]byte]()
+	if _, err := loadedBlobs.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom []byte failed: %v", err)
+	}
+	if v, _ := loadedBlobs.Get(0); !bytes.Equal(v, []byte{1, 2, 3}) {
+		t.Errorf("Expected [1 2 3], got %v", v)
+	}
+
+	times := NewArray[time.Time]()
+	moment := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	times.PushBack(moment)
+	buf.Reset()
+	times.WriteTo(&buf)
+	loadedTimes := NewArray[time.Time]()
+	if _, err := loadedTimes.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom time.Time failed: %v", err)
+	}
+	if v, _ := loadedTimes.Get(0); !v.Equal(moment) {
+		t.Errorf("Expected %v, got %v", moment, v)
+	}
+}
+
+func TestSetCodec(t *testing.T) {
+	arr := NewArray[string]()
+	arr.PushBack("mixed Case")
+	arr.SetCodec(upperCodec{})
+
+	var buf bytes.Buffer
+	if _, err := arr.Clone().WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	loaded := NewArray[string]()
+	if _, err := loaded.ReadFrom(&buf); err != nil {
+		t.Fatalf("ReadFrom failed: %v", err)
+	}
+	if v, _ := loaded.Get(0); v != "MIXED CASE" {
+		t.Errorf("Expected custom codec output, got %q", v)
+	}
+}
+
+// Fuzz-тест текстового формата: строки с пробелами, кавычками и переводами строк
+// должны читаться обратно без изменений
+func FuzzTextRoundTrip(f *testing.F) {
+	persisttest.FuzzText(f, func(a, b string) *Array[string] {
+		src := NewArray[string]()
+		src.PushBack(a)
+		src.PushBack(b)
+		src.PushBack(a + " " + b)
+		return src
+	}, NewArray[string], streamFormats[string]()[0], sameArray[string])
+}
+
+func TestJSON(t *testing.T) {
+	a := NewArray[string]()
+	a.PushBack("a")
+	a.PushBack("b c")
+
+	type response struct {
+		Items *Array[string] `json:"items"`
+	}
+	data, err := json.Marshal(response{Items: a})
+	if err != nil || string(data) != `{"items":["a","b c"]}` {
+		t.Fatalf("Unexpected JSON %s (%v)", data, err)
+	}
+
+	var decoded response
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Items.GetSize() != 2 {
+		t.Fatalf("Expected 2 items, got %d", decoded.Items.GetSize())
+	}
+	if val, _ := decoded.Items.Get(1); val != "b c" {
+		t.Errorf("Expected 'b c', got %q", val)
+	}
+
+	empty, _ := json.Marshal(NewArray[int]())
+	if string(empty) != "[]" {
+		t.Errorf("Expected [], got %s", empty)
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	a := NewArray[int]()
+	a.PushBack(7)
+
+	// Ошибка указывает на элемент и его смещение, массив не меняется
+	err := a.UnmarshalJSON([]byte(`[1, 2, "x"]`))
+	var je *codec.JSONError
+	if !errors.As(err, &je) || je.Path != "$[2]" || je.Offset != 7 {
+		t.Fatalf("Expected error at $[2] offset 7, got %v", err)
+	}
+	if a.GetSize() != 1 {
+		t.Errorf("Array changed after failed unmarshal: size %d", a.GetSize())
+	}
+
+	cases := map[string]error{
+		`{"a":1}`: codec.ErrJSONSyntax,
+		`[1] [2]`: codec.ErrJSONSyntax,
+		`[1, 2`:   codec.ErrJSONSyntax,
+		``:        io.ErrUnexpectedEOF,
+	}
+	for input, want := range cases {
+		if _, err := a.ReadJSONFrom(strings.NewReader(input)); !errors.Is(err, want) {
+			t.Errorf("%q: expected %v, got %v", input, want, err)
+		}
+	}
+}
+
+func TestCSV(t *testing.T) {
+	type row struct {
+		Name  string `csv:"name"`
+		Score int    `csv:"score"`
+	}
+	a := NewArray[row]()
+	a.PushBack(row{"Иванов, И.", 5})
+	a.PushBack(row{"Petrov", 4})
+
+	var buf bytes.Buffer
+	if err := a.ExportCSV(&buf, codec.WithCSVComma(';')); err != nil {
+		t.Fatalf("ExportCSV failed: %v", err)
+	}
+	if buf.String() != "name;score\nИванов, И.;5\nPetrov;4\n" {
+		t.Fatalf("Unexpected CSV %q", buf.String())
+	}
+
+	loaded := NewArray[row]()
+	if err := loaded.ImportCSV(&buf, codec.WithCSVComma(';')); err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+	if loaded.GetSize() != 2 {
+		t.Fatalf("Expected 2 rows, got %d", loaded.GetSize())
+	}
+	if r, _ := loaded.Get(0); r.Name != "Иванов, И." || r.Score != 5 {
+		t.Errorf("Unexpected first row %+v", r)
+	}
+
+	// Плохие строки пропускаются с номерами строк, остальные загружаются
+	err := loaded.ImportCSV(strings.NewReader("score,name\n3,a\nx,b\n4,c\n"))
+	var rowErrs codec.RowErrors
+	if !errors.As(err, &rowErrs) || len(rowErrs) != 1 || rowErrs[0].Line != 3 {
+		t.Fatalf("Expected error on line 3, got %v", err)
+	}
+	if loaded.GetSize() != 2 {
+		t.Errorf("Expected 2 good rows, got %d", loaded.GetSize())
+	}
+
+	// Ошибка заголовка не меняет массив
+	if err := loaded.ImportCSV(strings.NewReader("name\nz\n")); !errors.Is(err, codec.ErrCSVHeader) {
+		t.Errorf("Expected ErrCSVHeader, got %v", err)
+	}
+	if r, _ := loaded.Get(1); r.Name != "c" {
+		t.Errorf("Array changed after header error: %+v", r)
+	}
+}
+
+func TestGobEmbedding(t *testing.T) {
+	src := NewArray[string]()
+	src.PushBack("a")
+	src.PushBack("b c")
+	var _ encoding.BinaryMarshaler = src
+	var _ gob.GobDecoder = src
+
+	// Массив внутри структуры - и по указателю, и по значению
+	persisttest.GobRoundTrip(t, src, sameArray[string])
+	persisttest.GobRoundTrip(t, *src.Clone(), func(a, b Array[string]) bool { return sameArray(&a, &b) })
+
+	data, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	if err := NewArray[string]().UnmarshalBinary(append(data, 0)); !errors.Is(err, persist.ErrTrailingData) {
+		t.Errorf("Expected ErrTrailingData, got %v", err)
+	}
+	if err := NewArray[int]().UnmarshalBinary(data); !errors.Is(err, persist.ErrTypeMismatch) {
+		t.Errorf("Expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestCompressedSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	arr := NewArray[int32]()
+	for i := 0; i < 5000; i++ {
+		arr.PushBack(int32(i % 10))
+	}
+
+	for _, alg := range []compression.Algorithm{compression.None, compression.Gzip, compression.Flate, compression.LZ} {
+		binFile := filepath.Join(dir, alg.String()+".bin")
+		txtFile := filepath.Join(dir, alg.String()+".txt")
+		if err := arr.SaveBinary(binFile, compression.With(alg)); err != nil {
+			t.Fatalf("%v: SaveBinary failed: %v", alg, err)
+		}
+		if err := arr.SaveText(txtFile, compression.With(alg)); err != nil {
+			t.Fatalf("%v: SaveText failed: %v", alg, err)
+		}
+
+		// Загрузчики определяют сжатие сами
+		fromBin, fromTxt := NewArray[int32](), NewArray[int32]()
+		if err := fromBin.LoadBinary(binFile); err != nil {
+			t.Fatalf("%v: LoadBinary failed: %v", alg, err)
+		}
+		if err := fromTxt.LoadText(txtFile); err != nil {
+			t.Fatalf("%v: LoadText failed: %v", alg, err)
+		}
+		for _, loaded := range []*Array[int32]{fromBin, fromTxt} {
+			if loaded.GetSize() != arr.GetSize() {
+				t.Fatalf("%v: size %d, want %d", alg, loaded.GetSize(), arr.GetSize())
+			}
+			if v, _ := loaded.Get(4321); v != 1 {
+				t.Errorf("%v: element 4321 = %d, want 1", alg, v)
+			}
+		}
+
+		info, _ := os.Stat(binFile)
+		if alg != compression.None && info.Size() > 5000 {
+			t.Errorf("%v: compressed file is %d bytes", alg, info.Size())
+		}
+	}
+
+	// Испорченный сжатый файл не меняет массив
+	path := filepath.Join(dir, "lz.bin")
+	data, _ := os.ReadFile(path)
+	data[len(data)/2] ^= 0xFF
+	os.WriteFile(path, data, 0644)
+	if err := arr.LoadBinary(path); err == nil {
+		t.Error("LoadBinary should fail for corrupt compressed file")
+	}
+	if arr.GetSize() != 5000 {
+		t.Errorf("Failed load changed the array: size %d", arr.GetSize())
+	}
+}
+
+func TestBoundedLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	// Заголовок обещает 2 млрд элементов, данных - два
+	textFile := filepath.Join(dir, "huge.txt")
+	os.WriteFile(textFile, []byte("2000000000\n1\n2\n"), 0644)
+	if err := NewArray[int64]().LoadText(textFile); err == nil {
+		t.Error("LoadText should fail for size larger than data")
+	}
+
+	var payload bytes.Buffer
+	binary.Write(&payload, binary.LittleEndian, int32(math.MaxInt32))
+	binary.Write(&payload, binary.LittleEndian, []int64{1, 2})
+	binFile := filepath.Join(dir, "huge.bin")
+	os.WriteFile(binFile, persisttest.Envelope[int64](persist.KindArray, payload.Bytes()), 0644)
+	if err := NewArray[int64]().LoadBinary(binFile); err == nil {
+		t.Error("LoadBinary should fail for size larger than data")
+	}
+
+	// Предел и прогресс на настоящем файле
+	arr := NewArray[int64]()
+	for i := 0; i < 3*codec.ChunkSize; i++ {
+		arr.PushBack(int64(i))
+	}
+	path := filepath.Join(dir, "arr.bin")
+	arr.SaveBinary(path, compression.With(compression.LZ))
+	info, _ := os.Stat(path)
+
+	var read, total int64
+	loaded := NewArray[int64]()
+	err := loaded.LoadBinary(path, persist.WithLimit(1<<20), persist.WithProgress(func(n, size int64) {
+		read, total = n, size
+	}))
+	if err != nil || loaded.GetSize() != arr.GetSize() {
+		t.Fatalf("LoadBinary: size %d, %v", loaded.GetSize(), err)
+	}
+	if read != info.Size() || total != info.Size() {
+		t.Errorf("Progress %d/%d, file is %d bytes", read, total, info.Size())
+	}
+
+	// Предел считается по распакованным данным
+	if err := loaded.LoadBinary(path, persist.WithLimit(info.Size())); !errors.Is(err, persist.ErrLimitExceeded) {
+		t.Errorf("Expected ErrLimitExceeded, got %v", err)
+	}
+	if err := loaded.LoadText(textFile, persist.WithLimit(4)); !errors.Is(err, persist.ErrLimitExceeded) {
+		t.Errorf("LoadText: expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestFromSliceToSlice(t *testing.T) {
+	src := []int{3, 1, 2}
+	arr := FromSlice(src)
+	src[0] = 100 // Массив хранит копию
+	if got := arr.ToSlice(); !slices.Equal(got, []int{3, 1, 2}) {
+		t.Errorf("ToSlice = %v", got)
+	}
+	// ToSlice тоже возвращает копию
+	arr.ToSlice()[0] = 100
+	if v, _ := arr.Get(0); v != 3 {
+		t.Errorf("ToSlice shares memory with the array")
+	}
+	arr.PushBack(4)
+	if arr.GetSize() != 4 {
+		t.Errorf("Expected size 4, got %d", arr.GetSize())
+	}
+
+	if got := FromSeq(slices.Values([]string{"a", "b"})).ToSlice(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("FromSeq = %v", got)
+	}
+	empty := FromSlice[int](nil)
+	empty.PushBack(1)
+	if empty.GetSize() != 1 {
+		t.Error("Empty FromSlice array is not usable")
+	}
+}
+
+func TestIterators(t *testing.T) {
+	a := FromSlice([]int{1, 2, 3, 4})
+	if got := slices.Collect(a.All()); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("All = %v", got)
+	}
+	if got := slices.Collect(a.Backward()); !slices.Equal(got, []int{4, 3, 2, 1}) {
+		t.Errorf("Backward = %v", got)
+	}
+	for i, v := range a.Enumerate() {
+		if v != i+1 {
+			t.Errorf("Enumerate: index %d has %d", i, v)
+		}
+	}
+
+	// Досрочный выход
+	var first []int
+	for v := range a.All() {
+		first = append(first, v)
+		if len(first) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(first, []int{1, 2}) {
+		t.Errorf("break after 2: got %v", first)
+	}
+
+	// Добавленные в цикле элементы проходятся
+	var seen []int
+	for v := range a.All() {
+		seen = append(seen, v)
+		if v == 4 {
+			a.PushBack(5)
+		}
+	}
+	if !slices.Equal(seen, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("All with PushBack = %v", seen)
+	}
+
+	// Удаление сдвигает элементы: следующий за удаленным пропускается
+	seen = seen[:0]
+	for i, v := range a.Enumerate() {
+		seen = append(seen, v)
+		if v == 2 {
+			_ = a.DeleteByInd(i)
+		}
+	}
+	if !slices.Equal(seen, []int{1, 2, 4, 5}) {
+		t.Errorf("Enumerate with DeleteByInd = %v", seen)
+	}
+
+	// Backward пропускает исчезнувшие индексы
+	seen = seen[:0]
+	for v := range a.Backward() {
+		seen = append(seen, v)
+		if v == 5 {
+			_ = a.SetSize(2)
+		}
+	}
+	if !slices.Equal(seen, []int{5, 3, 1}) {
+		t.Errorf("Backward with shrink = %v", seen)
+	}
+}
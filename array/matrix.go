@@ -0,0 +1,541 @@
+package array
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// ErrShapeMismatch - размеры матриц не подходят для операции
+var ErrShapeMismatch = errors.New("matrix shape mismatch")
+
+// DefaultBlockSize - сторона блока для MulBlocked по умолчанию. Три блока
+// 64x64 из float64 занимают 96 КБ и помещаются в кэш L2.
+const DefaultBlockSize = 64
+
+// Number - числовые типы элементов матрицы
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Matrix - матрица, хранящая элементы по строкам в одном массиве Array:
+// элемент (r, c) лежит по индексу r*Cols()+c. Матрицу создают через
+// NewMatrix и другие конструкторы; нулевое значение - пустая матрица 0x0.
+type Matrix[T Number] struct {
+	rows, cols int
+	storage    *Array[T]
+}
+
+// NewMatrix создает нулевую матрицу rows x cols
+func NewMatrix[T Number](rows, cols int) (*Matrix[T], error) {
+	if rows < 0 || cols < 0 {
+		return nil, fmt.Errorf("error: Negative matrix size %dx%d", rows, cols)
+	}
+	if cols != 0 && rows > maxInt/cols {
+		return nil, fmt.Errorf("error: Matrix size %dx%d is too large", rows, cols)
+	}
+	n := rows * cols
+	return &Matrix[T]{
+		rows:    rows,
+		cols:    cols,
+		storage: &Array[T]{data: make([]T, n, max(n, 1))},
+	}, nil
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// Identity создает единичную матрицу n x n
+func Identity[T Number](n int) (*Matrix[T], error) {
+	m, err := NewMatrix[T](n, n)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		m.storage.data[i*n+i] = 1
+	}
+	return m, nil
+}
+
+// MatrixFromRows создает матрицу из копии строк. Все строки должны быть одной длины.
+func MatrixFromRows[T Number](rows [][]T) (*Matrix[T], error) {
+	cols := 0
+	if len(rows) > 0 {
+		cols = len(rows[0])
+	}
+	m, err := NewMatrix[T](len(rows), cols)
+	if err != nil {
+		return nil, err
+	}
+	for r, row := range rows {
+		if len(row) != cols {
+			return nil, fmt.Errorf("%w: row %d has %d elements, want %d", ErrShapeMismatch, r, len(row), cols)
+		}
+		copy(m.storage.data[r*cols:], row)
+	}
+	return m, nil
+}
+
+// MatrixFromArrays создает матрицу из массива строк-массивов
+func MatrixFromArrays[T Number](grid *Array[Array[T]]) (*Matrix[T], error) {
+	rows := make([][]T, len(grid.data))
+	for i := range grid.data {
+		rows[i] = grid.data[i].data
+	}
+	return MatrixFromRows(rows)
+}
+
+// Rows возвращает количество строк
+func (m *Matrix[T]) Rows() int {
+	return m.rows
+}
+
+// Cols возвращает количество столбцов
+func (m *Matrix[T]) Cols() int {
+	return m.cols
+}
+
+// AsArray возвращает массив, в котором матрица хранит элементы по строкам.
+// Массив общий с матрицей: запись в него меняет матрицу, а изменение его
+// размера ломает ее.
+func (m *Matrix[T]) AsArray() *Array[T] {
+	if m.storage == nil {
+		m.set(0, 0, nil)
+	}
+	return m.storage
+}
+
+// elems возвращает элементы по строкам; у нулевой матрицы хранилища нет
+func (m *Matrix[T]) elems() []T {
+	if m.storage == nil {
+		return nil
+	}
+	return m.storage.data
+}
+
+func (m *Matrix[T]) checkIndex(r, c int) error {
+	if r < 0 || r >= m.rows || c < 0 || c >= m.cols {
+		return fmt.Errorf("error: Index (%d, %d) is out of bounds (size %dx%d)", r, c, m.rows, m.cols)
+	}
+	return nil
+}
+
+// Get возвращает элемент (r, c)
+func (m *Matrix[T]) Get(r, c int) (T, error) {
+	if err := m.checkIndex(r, c); err != nil {
+		var zero T
+		return zero, err
+	}
+	return m.storage.data[r*m.cols+c], nil
+}
+
+// Set заменяет элемент (r, c)
+func (m *Matrix[T]) Set(r, c int, value T) error {
+	if err := m.checkIndex(r, c); err != nil {
+		return err
+	}
+	m.storage.data[r*m.cols+c] = value
+	return nil
+}
+
+// Clone создает глубокую копию матрицы
+func (m *Matrix[T]) Clone() *Matrix[T] {
+	if m.storage == nil {
+		return &Matrix[T]{}
+	}
+	return &Matrix[T]{rows: m.rows, cols: m.cols, storage: m.storage.Clone()}
+}
+
+// ToRows возвращает копию матрицы в виде среза строк
+func (m *Matrix[T]) ToRows() [][]T {
+	rows := make([][]T, m.rows)
+	for r := range rows {
+		rows[r] = append([]T(nil), m.storage.data[r*m.cols:(r+1)*m.cols]...)
+	}
+	return rows
+}
+
+// Equal сообщает, совпадают ли размеры и элементы матриц
+func (m *Matrix[T]) Equal(other *Matrix[T]) bool {
+	if m.rows != other.rows || m.cols != other.cols {
+		return false
+	}
+	b := other.elems()
+	for i, v := range m.elems() {
+		if b[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Строки и столбцы
+
+// Vector - строка или столбец матрицы без копирования. Запись через Set
+// меняет матрицу. Вектор действителен до загрузки в матрицу: LoadText,
+// LoadBinary и ReadFrom заменяют ее память, остальные методы - нет.
+type Vector[T Number] struct {
+	data   []T
+	stride int
+	n      int
+}
+
+// Row возвращает строку r
+func (m *Matrix[T]) Row(r int) (Vector[T], error) {
+	if r < 0 || r >= m.rows {
+		return Vector[T]{}, fmt.Errorf("error: Row %d is out of bounds (rows %d)", r, m.rows)
+	}
+	return Vector[T]{data: m.storage.data[r*m.cols:], stride: 1, n: m.cols}, nil
+}
+
+// Col возвращает столбец c
+func (m *Matrix[T]) Col(c int) (Vector[T], error) {
+	if c < 0 || c >= m.cols {
+		return Vector[T]{}, fmt.Errorf("error: Column %d is out of bounds (cols %d)", c, m.cols)
+	}
+	return Vector[T]{data: m.storage.data[c:], stride: m.cols, n: m.rows}, nil
+}
+
+// Len возвращает количество элементов вектора
+func (v Vector[T]) Len() int {
+	return v.n
+}
+
+// Get возвращает i-й элемент вектора
+func (v Vector[T]) Get(i int) (T, error) {
+	if i < 0 || i >= v.n {
+		var zero T
+		return zero, fmt.Errorf("error: Index %d is out of bounds (size %d)", i, v.n)
+	}
+	return v.data[i*v.stride], nil
+}
+
+// Set заменяет i-й элемент вектора
+func (v Vector[T]) Set(i int, value T) error {
+	if i < 0 || i >= v.n {
+		return fmt.Errorf("error: Index %d is out of bounds (size %d)", i, v.n)
+	}
+	v.data[i*v.stride] = value
+	return nil
+}
+
+// All возвращает итератор по элементам вектора
+func (v Vector[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < v.n; i++ {
+			if !yield(v.data[i*v.stride]) {
+				return
+			}
+		}
+	}
+}
+
+// ToSlice возвращает копию элементов вектора
+func (v Vector[T]) ToSlice() []T {
+	s := make([]T, v.n)
+	for i := range s {
+		s[i] = v.data[i*v.stride]
+	}
+	return s
+}
+
+// Операции
+
+// transposeBlock - сторона блока транспонирования: строки блока источника
+// и результата одновременно остаются в кэше
+const transposeBlock = 32
+
+// Transpose возвращает транспонированную матрицу. Обход идет блоками, чтобы
+// и чтение, и запись шли по соседним адресам.
+func (m *Matrix[T]) Transpose() *Matrix[T] {
+	t, _ := NewMatrix[T](m.cols, m.rows)
+	src, dst := m.elems(), t.storage.data
+	for r0 := 0; r0 < m.rows; r0 += transposeBlock {
+		for c0 := 0; c0 < m.cols; c0 += transposeBlock {
+			for r := r0; r < min(r0+transposeBlock, m.rows); r++ {
+				for c := c0; c < min(c0+transposeBlock, m.cols); c++ {
+					dst[c*m.rows+r] = src[r*m.cols+c]
+				}
+			}
+		}
+	}
+	return t
+}
+
+// elementWise применяет op к парам элементов матриц одинакового размера
+func (m *Matrix[T]) elementWise(other *Matrix[T], op func(a, b T) T) (*Matrix[T], error) {
+	if m.rows != other.rows || m.cols != other.cols {
+		return nil, fmt.Errorf("%w: %dx%d and %dx%d", ErrShapeMismatch, m.rows, m.cols, other.rows, other.cols)
+	}
+	result, _ := NewMatrix[T](m.rows, m.cols)
+	b := other.elems()
+	for i, v := range m.elems() {
+		result.storage.data[i] = op(v, b[i])
+	}
+	return result, nil
+}
+
+// Add возвращает поэлементную сумму матриц
+func (m *Matrix[T]) Add(other *Matrix[T]) (*Matrix[T], error) {
+	return m.elementWise(other, func(a, b T) T { return a + b })
+}
+
+// Sub возвращает поэлементную разность матриц
+func (m *Matrix[T]) Sub(other *Matrix[T]) (*Matrix[T], error) {
+	return m.elementWise(other, func(a, b T) T { return a - b })
+}
+
+// MulElem возвращает поэлементное произведение матриц (произведение Адамара)
+func (m *Matrix[T]) MulElem(other *Matrix[T]) (*Matrix[T], error) {
+	return m.elementWise(other, func(a, b T) T { return a * b })
+}
+
+// Scale возвращает матрицу, умноженную на число k
+func (m *Matrix[T]) Scale(k T) *Matrix[T] {
+	return m.Apply(func(v T) T { return v * k })
+}
+
+// Apply возвращает матрицу из fn(v) для каждого элемента
+func (m *Matrix[T]) Apply(fn func(T) T) *Matrix[T] {
+	result, _ := NewMatrix[T](m.rows, m.cols)
+	for i, v := range m.elems() {
+		result.storage.data[i] = fn(v)
+	}
+	return result
+}
+
+func (m *Matrix[T]) checkMul(other *Matrix[T]) (*Matrix[T], error) {
+	if m.cols != other.rows {
+		return nil, fmt.Errorf("%w: cannot multiply %dx%d by %dx%d", ErrShapeMismatch, m.rows, m.cols, other.rows, other.cols)
+	}
+	return NewMatrix[T](m.rows, other.cols)
+}
+
+// Mul возвращает матричное произведение m * other за O(n*k*p). Внутренний
+// цикл идет по строке other, а не по столбцу, поэтому память читается подряд.
+func (m *Matrix[T]) Mul(other *Matrix[T]) (*Matrix[T], error) {
+	result, err := m.checkMul(other)
+	if err != nil {
+		return nil, err
+	}
+	a, b, c := m.elems(), other.elems(), result.storage.data
+	n, p := m.cols, other.cols
+	for i := 0; i < m.rows; i++ {
+		rowC := c[i*p : (i+1)*p]
+		for k := 0; k < n; k++ {
+			aik := a[i*n+k]
+			rowB := b[k*p : (k+1)*p]
+			for j := range rowC {
+				rowC[j] += aik * rowB[j]
+			}
+		}
+	}
+	return result, nil
+}
+
+// MulBlocked возвращает то же произведение, что Mul, но считает его блоками
+// block x block (DefaultBlockSize, если block <= 0). Блоки всех трех матриц
+// помещаются в кэш, поэтому на больших матрицах это быстрее Mul. Для чисел
+// с плавающей точкой порядок сложения совпадает с Mul, и результат тоже.
+func (m *Matrix[T]) MulBlocked(other *Matrix[T], block int) (*Matrix[T], error) {
+	result, err := m.checkMul(other)
+	if err != nil {
+		return nil, err
+	}
+	if block <= 0 {
+		block = DefaultBlockSize
+	}
+	a, b, c := m.elems(), other.elems(), result.storage.data
+	rows, n, p := m.rows, m.cols, other.cols
+	for i0 := 0; i0 < rows; i0 += block {
+		iEnd := min(i0+block, rows)
+		for k0 := 0; k0 < n; k0 += block {
+			kEnd := min(k0+block, n)
+			for j0 := 0; j0 < p; j0 += block {
+				jEnd := min(j0+block, p)
+				for i := i0; i < iEnd; i++ {
+					rowC := c[i*p+j0 : i*p+jEnd]
+					for k := k0; k < kEnd; k++ {
+						aik := a[i*n+k]
+						rowB := b[k*p+j0 : k*p+jEnd]
+						for j := range rowC {
+							rowC[j] += aik * rowB[j]
+						}
+					}
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// Сохранение и загрузка
+//
+// Текстовый формат: первая строка - "строки столбцы", дальше по строке файла
+// на строку матрицы. Бинарный формат - конверт persist вида KindMatrix:
+// размеры (int32, как размер Array) и элементы по строкам через кодек по
+// умолчанию. При ошибке загрузки матрица не меняется.
+
+// SaveText сохраняет матрицу в текстовый файл
+func (m *Matrix[T]) SaveText(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, m.writeText, opts...)
+}
+
+// LoadText загружает матрицу из текстового файла
+func (m *Matrix[T]) LoadText(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Unable to open file for reading: %v", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, m.readText, opts...)
+}
+
+func (m *Matrix[T]) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, m.rows, m.cols); err != nil {
+		return err
+	}
+	for r := 0; r < m.rows; r++ {
+		for c, v := range m.elems()[r*m.cols : (r+1)*m.cols] {
+			tok, err := codec.FormatText(v)
+			if err != nil {
+				return err
+			}
+			if c > 0 {
+				tok = " " + tok
+			}
+			if _, err := io.WriteString(w, tok); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Matrix[T]) readText(r io.Reader) error {
+	s := codec.NewTextScanner(r)
+	rows, err := codec.ScanText[int](s)
+	if err != nil {
+		return fmt.Errorf("error: Failed to read matrix size")
+	}
+	cols, err := codec.ScanText[int](s)
+	if err != nil {
+		return fmt.Errorf("error: Failed to read matrix size")
+	}
+	if rows < 0 || cols < 0 || cols != 0 && rows > maxInt/cols {
+		return fmt.Errorf("error: Invalid matrix size %dx%d", rows, cols)
+	}
+
+	// Растим по частям, как codec.DecodeN
+	n := rows * cols
+	data := make([]T, 0, min(n, codec.ChunkSize))
+	for len(data) < n {
+		v, err := codec.ScanText[T](s)
+		if err != nil {
+			return fmt.Errorf("error: File corrupted or incomplete data")
+		}
+		data = append(data, v)
+	}
+	// Лишние токены после rows*cols значений - признак испорченного файла
+	if _, err := s.Next(); err == nil {
+		return fmt.Errorf("error: %w", persist.ErrTrailingData)
+	} else if err != io.EOF {
+		return err
+	}
+	m.set(rows, cols, data)
+	return nil
+}
+
+// SaveBinary сохраняет матрицу в бинарном формате
+func (m *Matrix[T]) SaveBinary(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, m.writeBinary, opts...)
+}
+
+// LoadBinary загружает матрицу из бинарного файла
+func (m *Matrix[T]) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Unable to open file: %v", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, m.readBinary, opts...)
+}
+
+// WriteTo записывает матрицу в w в бинарном формате (io.WriterTo)
+func (m *Matrix[T]) WriteTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, m.writeBinary)
+}
+
+// ReadFrom читает матрицу в бинарном формате из r (io.ReaderFrom)
+func (m *Matrix[T]) ReadFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, m.readBinary)
+}
+
+func (m *Matrix[T]) writeBinary(w io.Writer) error {
+	return persist.WriteEnvelope(w, persist.KindMatrix, persist.TypeName[T](), m.writePayload)
+}
+
+func (m *Matrix[T]) readBinary(r io.Reader) error {
+	return persist.ReadEnvelope(r, persist.KindMatrix, persist.TypeName[T](), m.readPayload)
+}
+
+func (m *Matrix[T]) writePayload(w io.Writer) error {
+	c, err := codec.Default[T]()
+	if err != nil {
+		return err
+	}
+	if int64(m.rows) > 1<<31-1 || int64(m.cols) > 1<<31-1 {
+		return fmt.Errorf("error: Matrix size %dx%d does not fit the format", m.rows, m.cols)
+	}
+	if err := binary.Write(w, binary.LittleEndian, [2]int32{int32(m.rows), int32(m.cols)}); err != nil {
+		return err
+	}
+	if err := codec.EncodeSlice(w, c, m.elems()); err != nil {
+		return fmt.Errorf("error: Write operation failed: %w", err)
+	}
+	return nil
+}
+
+func (m *Matrix[T]) readPayload(r io.Reader) error {
+	c, err := codec.Default[T]()
+	if err != nil {
+		return err
+	}
+	var size [2]int32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return fmt.Errorf("error: Failed to read matrix size")
+	}
+	rows, cols := int(size[0]), int(size[1])
+	if rows < 0 || cols < 0 || cols != 0 && rows > maxInt/cols {
+		return fmt.Errorf("error: Invalid matrix size %dx%d", rows, cols)
+	}
+
+	data, err := codec.DecodeN(r, c, rows*cols)
+	if err != nil {
+		return fmt.Errorf("error: Failed to read data (incomplete or type mismatch): %v", err)
+	}
+	m.set(rows, cols, data)
+	return nil
+}
+
+// set заменяет размеры и элементы матрицы прочитанными
+func (m *Matrix[T]) set(rows, cols int, data []T) {
+	if cap(data) == 0 {
+		data = make([]T, 0, 1)
+	}
+	m.rows, m.cols = rows, cols
+	m.storage = &Array[T]{data: data}
+}
@@ -0,0 +1,49 @@
+package array
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"testing"
+)
+
+// MatrixSize - сторона квадратных матриц в бенчмарках умножения
+const MatrixSize = 256
+
+// randomFloatMatrix создает матрицу n x n из случайных чисел в [0, 1)
+func randomFloatMatrix(n int, seed uint64) *Matrix[float64] {
+	r := rand.New(rand.NewPCG(seed, 2))
+	m, _ := NewMatrix[float64](n, n)
+	for i := range m.storage.data {
+		m.storage.data[i] = r.Float64()
+	}
+	return m
+}
+
+// BenchmarkMatrixMul сравнивает простое и блочное умножение
+func BenchmarkMatrixMul(b *testing.B) {
+	x := randomFloatMatrix(MatrixSize, 1)
+	y := randomFloatMatrix(MatrixSize, 2)
+
+	b.Run("Mul", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = x.Mul(y)
+		}
+	})
+
+	for _, block := range []int{16, 32, DefaultBlockSize, 128} {
+		b.Run("MulBlocked/"+strconv.Itoa(block), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = x.MulBlocked(y, block)
+			}
+		})
+	}
+}
+
+// BenchmarkTranspose измеряет блочное транспонирование
+func BenchmarkTranspose(b *testing.B) {
+	m := randomFloatMatrix(MatrixSize*4, 3)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m.Transpose()
+	}
+}
@@ -0,0 +1,331 @@
+package array
+
+import (
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+func mustMatrix[T Number](t *testing.T, rows [][]T) *Matrix[T] {
+	t.Helper()
+	m, err := MatrixFromRows(rows)
+	if err != nil {
+		t.Fatalf("MatrixFromRows error: %v", err)
+	}
+	return m
+}
+
+// randomMatrix создает матрицу rows x cols из небольших случайных целых
+func randomMatrix(rows, cols int, seed uint64) *Matrix[int] {
+	r := rand.New(rand.NewPCG(seed, 1))
+	m, _ := NewMatrix[int](rows, cols)
+	for i := range m.storage.data {
+		m.storage.data[i] = r.IntN(21) - 10
+	}
+	return m
+}
+
+func TestMatrixBasics(t *testing.T) {
+	m := mustMatrix(t, [][]float64{{1, 2, 3}, {4, 5, 6}})
+	if m.Rows() != 2 || m.Cols() != 3 {
+		t.Fatalf("size = %dx%d, want 2x3", m.Rows(), m.Cols())
+	}
+	if v, err := m.Get(1, 2); err != nil || v != 6 {
+		t.Errorf("Get(1, 2) = %v, %v", v, err)
+	}
+	if err := m.Set(0, 1, 20); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	// Хранение по строкам в общем массиве
+	if got := m.AsArray().ToSlice(); !slices.Equal(got, []float64{1, 20, 3, 4, 5, 6}) {
+		t.Errorf("storage = %v", got)
+	}
+	for _, idx := range [][2]int{{-1, 0}, {2, 0}, {0, 3}} {
+		if _, err := m.Get(idx[0], idx[1]); err == nil {
+			t.Errorf("Get(%d, %d) returned nil error", idx[0], idx[1])
+		}
+		if err := m.Set(idx[0], idx[1], 0); err == nil {
+			t.Errorf("Set(%d, %d) returned nil error", idx[0], idx[1])
+		}
+	}
+
+	if _, err := NewMatrix[int](-1, 2); err == nil {
+		t.Error("NewMatrix with negative size returned nil error")
+	}
+	if _, err := MatrixFromRows([][]int{{1, 2}, {3}}); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("ragged rows error = %v, want ErrShapeMismatch", err)
+	}
+
+	grid := NewArray[Array[int]]()
+	grid.PushBack(*FromSlice([]int{1, 2}))
+	grid.PushBack(*FromSlice([]int{3, 4}))
+	fromArrays, err := MatrixFromArrays(grid)
+	if err != nil {
+		t.Fatalf("MatrixFromArrays error: %v", err)
+	}
+	if !fromArrays.Equal(mustMatrix(t, [][]int{{1, 2}, {3, 4}})) {
+		t.Errorf("MatrixFromArrays = %v", fromArrays.ToRows())
+	}
+
+	clone := fromArrays.Clone()
+	_ = clone.Set(0, 0, 100)
+	if v, _ := fromArrays.Get(0, 0); v != 1 {
+		t.Error("Clone shares memory")
+	}
+}
+
+func TestMatrixViews(t *testing.T) {
+	m := mustMatrix(t, [][]int{{1, 2, 3}, {4, 5, 6}})
+
+	row, err := m.Row(1)
+	if err != nil {
+		t.Fatalf("Row error: %v", err)
+	}
+	if got := row.ToSlice(); !slices.Equal(got, []int{4, 5, 6}) {
+		t.Errorf("Row(1) = %v", got)
+	}
+	col, err := m.Col(2)
+	if err != nil {
+		t.Fatalf("Col error: %v", err)
+	}
+	if got := slices.Collect(col.All()); !slices.Equal(got, []int{3, 6}) {
+		t.Errorf("Col(2) = %v", got)
+	}
+
+	// Запись через вектор видна в матрице и в других векторах
+	if err := col.Set(0, 30); err != nil {
+		t.Fatalf("Vector.Set error: %v", err)
+	}
+	if v, _ := m.Get(0, 2); v != 30 {
+		t.Errorf("matrix after Col.Set = %d, want 30", v)
+	}
+	row0, _ := m.Row(0)
+	if v, _ := row0.Get(2); v != 30 {
+		t.Errorf("row view after Col.Set = %d, want 30", v)
+	}
+
+	if _, err := col.Get(2); err == nil {
+		t.Error("Vector.Get out of bounds returned nil error")
+	}
+	if _, err := m.Row(2); err == nil {
+		t.Error("Row out of bounds returned nil error")
+	}
+	if _, err := m.Col(-1); err == nil {
+		t.Error("Col out of bounds returned nil error")
+	}
+}
+
+func TestMatrixOps(t *testing.T) {
+	a := mustMatrix(t, [][]int{{1, 2}, {3, 4}})
+	b := mustMatrix(t, [][]int{{5, 6}, {7, 8}})
+
+	tests := []struct {
+		name string
+		op   func(*Matrix[int]) (*Matrix[int], error)
+		want [][]int
+	}{
+		{"Add", a.Add, [][]int{{6, 8}, {10, 12}}},
+		{"Sub", a.Sub, [][]int{{-4, -4}, {-4, -4}}},
+		{"MulElem", a.MulElem, [][]int{{5, 12}, {21, 32}}},
+		{"Mul", a.Mul, [][]int{{19, 22}, {43, 50}}},
+	}
+	for _, tt := range tests {
+		got, err := tt.op(b)
+		if err != nil {
+			t.Fatalf("%s error: %v", tt.name, err)
+		}
+		if !got.Equal(mustMatrix(t, tt.want)) {
+			t.Errorf("%s = %v, want %v", tt.name, got.ToRows(), tt.want)
+		}
+	}
+
+	if got := a.Scale(3).ToRows(); !slices.EqualFunc(got, [][]int{{3, 6}, {9, 12}}, slices.Equal) {
+		t.Errorf("Scale = %v", got)
+	}
+	if got := a.Apply(func(v int) int { return -v }).ToRows(); !slices.EqualFunc(got, [][]int{{-1, -2}, {-3, -4}}, slices.Equal) {
+		t.Errorf("Apply = %v", got)
+	}
+
+	wide := mustMatrix(t, [][]int{{1, 2, 3}})
+	if _, err := a.Add(wide); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("Add shape error = %v", err)
+	}
+	if _, err := wide.Mul(a); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("Mul shape error = %v", err)
+	}
+
+	id, _ := Identity[int](2)
+	if got, _ := a.Mul(id); !got.Equal(a) {
+		t.Errorf("a * I = %v", got.ToRows())
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	m := mustMatrix(t, [][]int{{1, 2, 3}, {4, 5, 6}})
+	if got := m.Transpose(); !got.Equal(mustMatrix(t, [][]int{{1, 4}, {2, 5}, {3, 6}})) {
+		t.Errorf("Transpose = %v", got.ToRows())
+	}
+
+	// Размер, не кратный блоку транспонирования
+	big := randomMatrix(transposeBlock*2+5, transposeBlock+3, 1)
+	tr := big.Transpose()
+	for r := 0; r < big.Rows(); r++ {
+		for c := 0; c < big.Cols(); c++ {
+			v1, _ := big.Get(r, c)
+			v2, _ := tr.Get(c, r)
+			if v1 != v2 {
+				t.Fatalf("Transpose mismatch at (%d, %d)", r, c)
+			}
+		}
+	}
+	if !tr.Transpose().Equal(big) {
+		t.Error("double Transpose differs from original")
+	}
+}
+
+func TestMulBlocked(t *testing.T) {
+	// Размеры не кратны блокам, чтобы проверить неполные блоки
+	a := randomMatrix(37, 53, 2)
+	b := randomMatrix(53, 29, 3)
+	want, _ := a.Mul(b)
+	for _, block := range []int{0, 1, 8, 16, 100} {
+		got, err := a.MulBlocked(b, block)
+		if err != nil {
+			t.Fatalf("block %d: MulBlocked error: %v", block, err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("block %d: MulBlocked differs from Mul", block)
+		}
+	}
+	if _, err := a.MulBlocked(a, 8); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("MulBlocked shape error = %v", err)
+	}
+
+	// Для float64 порядок сложения тот же, результат совпадает точно
+	fa := mustMatrix(t, [][]float64{{0.1, 0.2, 0.3}, {1e-9, 1e9, 3.5}})
+	fb := mustMatrix(t, [][]float64{{1.5, -2}, {0.25, 1e-3}, {7, 0.1}})
+	m1, _ := fa.Mul(fb)
+	m2, _ := fa.MulBlocked(fb, 2)
+	if !m1.Equal(m2) {
+		t.Errorf("float Mul %v != MulBlocked %v", m1.ToRows(), m2.ToRows())
+	}
+}
+
+func TestMatrixSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	src := mustMatrix(t, [][]float64{{1.5, -2}, {0, 3.25}, {1e10, -1e-10}})
+
+	formats := []struct {
+		name string
+		save func(*Matrix[float64], string) error
+		load func(*Matrix[float64], string) error
+	}{
+		{"Text", func(m *Matrix[float64], f string) error { return m.SaveText(f) }, func(m *Matrix[float64], f string) error { return m.LoadText(f) }},
+		{"Binary", func(m *Matrix[float64], f string) error { return m.SaveBinary(f) }, func(m *Matrix[float64], f string) error { return m.LoadBinary(f) }},
+		{"Compressed", func(m *Matrix[float64], f string) error {
+			return m.SaveBinary(f, compression.With(compression.LZ))
+		}, func(m *Matrix[float64], f string) error { return m.LoadBinary(f) }},
+	}
+	for _, format := range formats {
+		t.Run(format.name, func(t *testing.T) {
+			path := filepath.Join(dir, format.name)
+			if err := format.save(src, path); err != nil {
+				t.Fatalf("save error: %v", err)
+			}
+			var dst Matrix[float64]
+			if err := format.load(&dst, path); err != nil {
+				t.Fatalf("load error: %v", err)
+			}
+			if !dst.Equal(src) {
+				t.Errorf("loaded %v, want %v", dst.ToRows(), src.ToRows())
+			}
+		})
+	}
+
+	// Текстовый формат: по строке файла на строку матрицы
+	textPath := filepath.Join(dir, "Text")
+	data, _ := os.ReadFile(textPath)
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 4 || lines[0] != "3 2" {
+		t.Errorf("text layout = %q", data)
+	}
+
+	empty, _ := NewMatrix[int](0, 5)
+	emptyPath := filepath.Join(dir, "empty")
+	if err := empty.SaveBinary(emptyPath); err != nil {
+		t.Fatalf("save empty error: %v", err)
+	}
+	loaded := randomMatrix(2, 2, 4)
+	if err := loaded.LoadBinary(emptyPath); err != nil || loaded.Rows() != 0 || loaded.Cols() != 5 {
+		t.Errorf("load empty: err=%v size=%dx%d", err, loaded.Rows(), loaded.Cols())
+	}
+
+	// Нулевое значение - матрица 0x0
+	var zero Matrix[int]
+	zeroPath := filepath.Join(dir, "zero")
+	if err := zero.SaveBinary(zeroPath); err != nil {
+		t.Fatalf("save zero error: %v", err)
+	}
+	if err := zero.SaveText(zeroPath + ".txt"); err != nil {
+		t.Fatalf("save zero text error: %v", err)
+	}
+	if err := loaded.LoadBinary(zeroPath); err != nil || loaded.Rows() != 0 || loaded.Cols() != 0 {
+		t.Errorf("load zero: err=%v size=%dx%d", err, loaded.Rows(), loaded.Cols())
+	}
+	if !zero.Equal(loaded) || !zero.Clone().Equal(zero.Transpose()) || zero.AsArray().GetSize() != 0 {
+		t.Error("zero matrix should behave as an empty 0x0 matrix")
+	}
+}
+
+func TestMatrixLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	keep := mustMatrix(t, [][]int{{1, 2}})
+
+	// Текст с недостающими элементами
+	short := filepath.Join(dir, "short.txt")
+	_ = os.WriteFile(short, []byte("2 2\n1 2\n3\n"), 0644)
+	if err := keep.LoadText(short); err == nil {
+		t.Error("LoadText of incomplete data returned nil error")
+	}
+	// Лишние значения после данных
+	long := filepath.Join(dir, "long.txt")
+	_ = os.WriteFile(long, []byte("1 2\n1 2\n3\n"), 0644)
+	if err := keep.LoadText(long); !errors.Is(err, persist.ErrTrailingData) {
+		t.Errorf("LoadText with trailing data error = %v, want ErrTrailingData", err)
+	}
+	bad := filepath.Join(dir, "bad.txt")
+	_ = os.WriteFile(bad, []byte("-1 2\n"), 0644)
+	if err := keep.LoadText(bad); err == nil {
+		t.Error("LoadText with negative size returned nil error")
+	}
+
+	// Массив - другой вид конверта
+	arrPath := filepath.Join(dir, "array.bin")
+	_ = FromSlice([]int{1, 2}).SaveBinary(arrPath)
+	if err := keep.LoadBinary(arrPath); !errors.Is(err, persist.ErrKindMismatch) {
+		t.Errorf("LoadBinary of Array error = %v, want ErrKindMismatch", err)
+	}
+
+	// Матрица float64 не читается как матрица int
+	floatPath := filepath.Join(dir, "float.bin")
+	fm, _ := NewMatrix[float64](1, 1)
+	_ = fm.SaveBinary(floatPath)
+	if err := keep.LoadBinary(floatPath); !errors.Is(err, persist.ErrTypeMismatch) {
+		t.Errorf("LoadBinary of float matrix error = %v, want ErrTypeMismatch", err)
+	}
+
+	if err := keep.LoadBinary(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadBinary of missing file returned nil error")
+	}
+
+	// При ошибке матрица не меняется
+	if !keep.Equal(mustMatrix(t, [][]int{{1, 2}})) {
+		t.Errorf("matrix changed after failed loads: %v", keep.ToRows())
+	}
+}
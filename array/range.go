@@ -0,0 +1,150 @@
+package array
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Политика емкости: после удаления диапазона (DeleteRange, Splice) массив
+// сам уменьшает емкость вдвое от нужной, если занято не больше четверти,
+// а емкость больше ShrinkMinCapacity. Порог в четверть, а не в половину,
+// не дает чередованию вставок и удалений перевыделять память на каждом шаге.
+// Compact применяет ту же политику вручную, ShrinkToFit убирает весь запас.
+// DeleteByInd емкость не трогает: запас, заданный через SetCapacity или
+// NewArrayWithCap, сохраняется.
+
+// ShrinkMinCapacity - емкость, до которой массив не уменьшается автоматически
+const ShrinkMinCapacity = 64
+
+// checkRange проверяет полуинтервал [i, j)
+func (a *Array[T]) checkRange(i, j int) error {
+	if i < 0 || j > len(a.data) || i > j {
+		return fmt.Errorf("error: Range [%d, %d) is out of bounds (size %d)", i, j, len(a.data))
+	}
+	return nil
+}
+
+// InsertRange вставляет values перед индексом i за O(n + k).
+// i == GetSize() добавляет их в конец.
+func (a *Array[T]) InsertRange(i int, values ...T) error {
+	if i < 0 || i > len(a.data) {
+		return fmt.Errorf("error: Index %d is out of bounds for insertion", i)
+	}
+	a.data = slices.Insert(a.data, i, values...)
+	return nil
+}
+
+// DeleteRange удаляет элементы [i, j) за O(n)
+func (a *Array[T]) DeleteRange(i, j int) error {
+	if err := a.checkRange(i, j); err != nil {
+		return err
+	}
+	// slices.Delete обнуляет освободившийся хвост, чтобы не держать ссылки
+	a.data = slices.Delete(a.data, i, j)
+	a.shrink()
+	return nil
+}
+
+// Splice заменяет элементы [i, j) на values и возвращает копию удаленных
+func (a *Array[T]) Splice(i, j int, values ...T) ([]T, error) {
+	if err := a.checkRange(i, j); err != nil {
+		return nil, err
+	}
+	removed := slices.Clone(a.data[i:j])
+	a.data = slices.Replace(a.data, i, j, values...)
+	a.shrink()
+	return removed, nil
+}
+
+// AppendArray добавляет в конец копии элементов other. Массив можно
+// дописать сам к себе.
+func (a *Array[T]) AppendArray(other *Array[T]) {
+	a.data = append(a.data, other.data...)
+}
+
+// Slice возвращает новый массив с копией элементов [i, j)
+func (a *Array[T]) Slice(i, j int) (*Array[T], error) {
+	if err := a.checkRange(i, j); err != nil {
+		return nil, err
+	}
+	result := FromSlice(a.data[i:j])
+	result.elemCodec = a.elemCodec
+	return result, nil
+}
+
+// View возвращает срез элементов [i, j) без копирования. Запись в срез
+// меняет массив. Емкость среза ограничена j, поэтому append к нему не
+// затирает элементы массива. Срез действителен до следующего изменения
+// размера или емкости массива.
+func (a *Array[T]) View(i, j int) ([]T, error) {
+	if err := a.checkRange(i, j); err != nil {
+		return nil, err
+	}
+	return a.data[i:j:j], nil
+}
+
+// Fill записывает value во все элементы [i, j)
+func (a *Array[T]) Fill(i, j int, value T) error {
+	if err := a.checkRange(i, j); err != nil {
+		return err
+	}
+	for k := i; k < j; k++ {
+		a.data[k] = value
+	}
+	return nil
+}
+
+// Reverse переставляет элементы в обратном порядке
+func (a *Array[T]) Reverse() {
+	slices.Reverse(a.data)
+}
+
+// Rotate сдвигает элементы влево на k позиций по кругу: элемент с индексом k
+// становится первым. Отрицательное k сдвигает вправо.
+func (a *Array[T]) Rotate(k int) {
+	n := len(a.data)
+	if n == 0 {
+		return
+	}
+	k %= n
+	if k < 0 {
+		k += n
+	}
+	if k == 0 {
+		return
+	}
+	// Три разворота: O(n) без дополнительной памяти
+	slices.Reverse(a.data[:k])
+	slices.Reverse(a.data[k:])
+	slices.Reverse(a.data)
+}
+
+// Compact уменьшает емкость по политике автоматического сжатия. Возвращает
+// true, если память была перевыделена.
+func (a *Array[T]) Compact() bool {
+	return a.shrink()
+}
+
+// ShrinkToFit уменьшает емкость до размера массива (но не меньше 1,
+// как у NewArray)
+func (a *Array[T]) ShrinkToFit() {
+	if cap(a.data) > max(len(a.data), 1) {
+		a.resize(max(len(a.data), 1))
+	}
+}
+
+// shrink уменьшает емкость до удвоенного размера, если занято не больше четверти
+func (a *Array[T]) shrink() bool {
+	if cap(a.data) <= ShrinkMinCapacity || len(a.data) > cap(a.data)/4 {
+		return false
+	}
+	a.resize(max(2*len(a.data), ShrinkMinCapacity))
+	return true
+}
+
+// resize перевыделяет память под емкость newCap >= GetSize()
+func (a *Array[T]) resize(newCap int) {
+	newData := make([]T, len(a.data), newCap)
+	copy(newData, a.data)
+	a.data = newData
+}
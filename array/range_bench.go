@@ -0,0 +1,45 @@
+package array
+
+import "testing"
+
+// BenchmarkInsertRange сравнивает вставку блока через InsertRange
+// с вставкой по одному элементу через InsertByInd
+func BenchmarkInsertRange(b *testing.B) {
+	block := make([]int, 1000)
+
+	b.Run("InsertRange", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			arr := NewArrayWithCap[int](SmallDataSize)
+			_ = arr.InsertRange(arr.GetSize()/2, block...)
+		}
+	})
+
+	b.Run("InsertByInd", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			arr := NewArrayWithCap[int](SmallDataSize)
+			mid := arr.GetSize() / 2
+			for j, v := range block {
+				_ = arr.InsertByInd(mid+j, v)
+			}
+		}
+	})
+}
+
+// BenchmarkDeleteRange сравнивает удаление блока с удалением по одному
+func BenchmarkDeleteRange(b *testing.B) {
+	b.Run("DeleteRange", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			arr := NewArrayWithCap[int](SmallDataSize)
+			_ = arr.DeleteRange(1000, 2000)
+		}
+	})
+
+	b.Run("DeleteByInd", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			arr := NewArrayWithCap[int](SmallDataSize)
+			for j := 0; j < 1000; j++ {
+				_ = arr.DeleteByInd(1000)
+			}
+		}
+	})
+}
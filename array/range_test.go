@@ -0,0 +1,192 @@
+package array
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInsertDeleteRange(t *testing.T) {
+	a := FromSlice([]int{1, 2, 3})
+	if err := a.InsertRange(1, 10, 11); err != nil {
+		t.Fatalf("InsertRange error: %v", err)
+	}
+	if err := a.InsertRange(a.GetSize(), 20); err != nil {
+		t.Fatalf("InsertRange at end error: %v", err)
+	}
+	if got := a.ToSlice(); !slices.Equal(got, []int{1, 10, 11, 2, 3, 20}) {
+		t.Errorf("after InsertRange = %v", got)
+	}
+	if err := a.InsertRange(7, 1); err == nil {
+		t.Error("InsertRange out of bounds returned nil error")
+	}
+
+	if err := a.DeleteRange(1, 3); err != nil {
+		t.Fatalf("DeleteRange error: %v", err)
+	}
+	if got := a.ToSlice(); !slices.Equal(got, []int{1, 2, 3, 20}) {
+		t.Errorf("after DeleteRange = %v", got)
+	}
+	if err := a.DeleteRange(2, 2); err != nil || a.GetSize() != 4 {
+		t.Errorf("empty DeleteRange: err=%v size=%d", err, a.GetSize())
+	}
+	for _, r := range [][2]int{{-1, 1}, {3, 2}, {0, 5}} {
+		if err := a.DeleteRange(r[0], r[1]); err == nil {
+			t.Errorf("DeleteRange(%d, %d) returned nil error", r[0], r[1])
+		}
+	}
+}
+
+func TestSplice(t *testing.T) {
+	a := FromSlice([]int{1, 2, 3, 4, 5})
+	removed, err := a.Splice(1, 4, 7, 8)
+	if err != nil {
+		t.Fatalf("Splice error: %v", err)
+	}
+	if !slices.Equal(removed, []int{2, 3, 4}) {
+		t.Errorf("removed = %v", removed)
+	}
+	if got := a.ToSlice(); !slices.Equal(got, []int{1, 7, 8, 5}) {
+		t.Errorf("after Splice = %v", got)
+	}
+
+	// Удаленные элементы - копия, а не срез массива
+	removed[0] = 100
+	if got := a.ToSlice(); !slices.Equal(got, []int{1, 7, 8, 5}) {
+		t.Errorf("Splice result shares memory: %v", got)
+	}
+
+	if _, err := a.Splice(3, 9); err == nil {
+		t.Error("Splice out of bounds returned nil error")
+	}
+}
+
+func TestSliceViewAppend(t *testing.T) {
+	a := FromSlice([]int{1, 2, 3, 4})
+
+	copied, err := a.Slice(1, 3)
+	if err != nil {
+		t.Fatalf("Slice error: %v", err)
+	}
+	_ = copied.Set(0, 100)
+	if v, _ := a.Get(1); v != 2 {
+		t.Error("Slice shares memory with the array")
+	}
+
+	view, err := a.View(1, 3)
+	if err != nil {
+		t.Fatalf("View error: %v", err)
+	}
+	view[0] = 200
+	if v, _ := a.Get(1); v != 200 {
+		t.Error("write to View is not visible in the array")
+	}
+	_ = append(view, 300)
+	if v, _ := a.Get(3); v != 4 {
+		t.Error("append to View overwrote the array")
+	}
+	if _, err := a.View(2, 1); err == nil {
+		t.Error("View with i > j returned nil error")
+	}
+
+	a.AppendArray(FromSlice([]int{5, 6}))
+	a.AppendArray(a)
+	if got := a.ToSlice(); !slices.Equal(got, []int{1, 200, 3, 4, 5, 6, 1, 200, 3, 4, 5, 6}) {
+		t.Errorf("after AppendArray = %v", got)
+	}
+}
+
+func TestFillReverseRotate(t *testing.T) {
+	a := FromSlice([]int{1, 2, 3, 4, 5})
+	if err := a.Fill(1, 3, 0); err != nil {
+		t.Fatalf("Fill error: %v", err)
+	}
+	if got := a.ToSlice(); !slices.Equal(got, []int{1, 0, 0, 4, 5}) {
+		t.Errorf("after Fill = %v", got)
+	}
+	if err := a.Fill(4, 6, 0); err == nil {
+		t.Error("Fill out of bounds returned nil error")
+	}
+
+	a.Reverse()
+	if got := a.ToSlice(); !slices.Equal(got, []int{5, 4, 0, 0, 1}) {
+		t.Errorf("after Reverse = %v", got)
+	}
+
+	tests := []struct {
+		k    int
+		want []int
+	}{
+		{0, []int{1, 2, 3, 4, 5}},
+		{2, []int{3, 4, 5, 1, 2}},
+		{-1, []int{5, 1, 2, 3, 4}},
+		{7, []int{3, 4, 5, 1, 2}},
+		{-10, []int{1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		r := FromSlice([]int{1, 2, 3, 4, 5})
+		r.Rotate(tt.k)
+		if got := r.ToSlice(); !slices.Equal(got, tt.want) {
+			t.Errorf("Rotate(%d) = %v, want %v", tt.k, got, tt.want)
+		}
+	}
+	NewArray[int]().Rotate(3) // не паникует на пустом массиве
+}
+
+func TestCapacityPolicy(t *testing.T) {
+	a := NewArray[int]()
+	for i := 0; i < 1000; i++ {
+		a.PushBack(i)
+	}
+	grown := a.GetCapacity()
+
+	// Удаление до половины не трогает емкость
+	_ = a.DeleteRange(0, 500)
+	if a.GetCapacity() != grown {
+		t.Errorf("capacity changed at half load: %d -> %d", grown, a.GetCapacity())
+	}
+
+	// Меньше четверти - емкость уменьшается до удвоенного размера
+	_ = a.DeleteRange(0, 400)
+	if a.GetCapacity() != 2*a.GetSize() {
+		t.Errorf("capacity after large delete = %d, want %d", a.GetCapacity(), 2*a.GetSize())
+	}
+	if v, _ := a.Get(0); v != 900 {
+		t.Errorf("first element after shrink = %d, want 900", v)
+	}
+
+	// Автоматическое сжатие не опускается ниже ShrinkMinCapacity
+	_ = a.DeleteRange(0, a.GetSize()-1)
+	if a.GetCapacity() != ShrinkMinCapacity {
+		t.Errorf("capacity = %d, want %d", a.GetCapacity(), ShrinkMinCapacity)
+	}
+	if a.Compact() {
+		t.Error("Compact reallocated below ShrinkMinCapacity")
+	}
+
+	a.ShrinkToFit()
+	if a.GetCapacity() != 1 || a.GetSize() != 1 {
+		t.Errorf("after ShrinkToFit size=%d cap=%d, want 1, 1", a.GetSize(), a.GetCapacity())
+	}
+
+	// DeleteByInd не отнимает емкость, заданную через SetCapacity
+	b := NewArray[int]()
+	_ = b.SetCapacity(1024)
+	for i := 0; i < 300; i++ {
+		b.PushBack(i)
+	}
+	for b.GetSize() > 10 {
+		_ = b.DeleteByInd(0)
+	}
+	if b.GetCapacity() != 1024 {
+		t.Errorf("capacity after DeleteByInd = %d, want 1024", b.GetCapacity())
+	}
+	if err := b.SetSize(900); err != nil {
+		t.Errorf("SetSize within reserved capacity failed: %v", err)
+	}
+
+	// Compact срабатывает после SetSize, который сам емкость не меняет
+	_ = b.SetSize(10)
+	if !b.Compact() || b.GetCapacity() != ShrinkMinCapacity {
+		t.Errorf("Compact after SetSize: cap=%d", b.GetCapacity())
+	}
+}
@@ -0,0 +1,187 @@
+package array
+
+import (
+	"fmt"
+	"math/bits"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// Функции сравнения cmp возвращают отрицательное число, если a < b, ноль, если
+// a == b, и положительное, если a > b. Для упорядоченных типов подходит
+// cmp.Compare. Поиск (BinarySearch, LowerBound, UpperBound) требует массива,
+// отсортированного тем же cmp.
+
+// ParallelThreshold - размер, начиная с которого ParallelSort делит работу
+// между горутинами. Меньшие части сортируются в одной горутине.
+const ParallelThreshold = 1 << 13
+
+// Sort сортирует массив за O(n log n). Сортировка неустойчивая: порядок
+// равных элементов может измениться.
+func (a *Array[T]) Sort(cmp func(a, b T) int) {
+	slices.SortFunc(a.data, cmp)
+}
+
+// StableSort сортирует массив, сохраняя порядок равных элементов
+func (a *Array[T]) StableSort(cmp func(a, b T) int) {
+	slices.SortStableFunc(a.data, cmp)
+}
+
+// IsSorted проверяет, что массив отсортирован по cmp
+func (a *Array[T]) IsSorted(cmp func(a, b T) int) bool {
+	return slices.IsSortedFunc(a.data, cmp)
+}
+
+// ParallelSort сортирует массив слиянием, разбивая большие массивы между
+// горутинами (не больше GOMAXPROCS). Сортировка устойчивая. Нужен буфер
+// размером с массив. cmp вызывается из нескольких горутин одновременно.
+func (a *Array[T]) ParallelSort(cmp func(a, b T) int) {
+	if len(a.data) < ParallelThreshold {
+		slices.SortStableFunc(a.data, cmp)
+		return
+	}
+	buf := make([]T, len(a.data))
+	// Глубина, на которой число горутин достигает GOMAXPROCS
+	depth := bits.Len(uint(runtime.GOMAXPROCS(0)))
+	parallelMergeSort(a.data, buf, cmp, depth)
+}
+
+func parallelMergeSort[T any](s, buf []T, cmp func(a, b T) int, depth int) {
+	if depth <= 0 || len(s) < ParallelThreshold {
+		slices.SortStableFunc(s, cmp)
+		return
+	}
+	mid := len(s) / 2
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		parallelMergeSort(s[:mid], buf[:mid], cmp, depth-1)
+	}()
+	parallelMergeSort(s[mid:], buf[mid:], cmp, depth-1)
+	wg.Wait()
+
+	// Половины уже упорядочены друг относительно друга - слияние не нужно
+	if cmp(s[mid-1], s[mid]) <= 0 {
+		return
+	}
+	merge(s[:mid], s[mid:], buf, cmp)
+	copy(s, buf)
+}
+
+// merge сливает отсортированные left и right в dst. При равенстве берется
+// элемент из left, поэтому слияние устойчиво.
+func merge[T any](left, right, dst []T, cmp func(a, b T) int) {
+	i, j, k := 0, 0, 0
+	for i < len(left) && j < len(right) {
+		if cmp(left[i], right[j]) <= 0 {
+			dst[k] = left[i]
+			i++
+		} else {
+			dst[k] = right[j]
+			j++
+		}
+		k++
+	}
+	k += copy(dst[k:], left[i:])
+	copy(dst[k:], right[j:])
+}
+
+// Find возвращает индекс первого элемента, для которого pred возвращает true,
+// или -1
+func (a *Array[T]) Find(pred func(T) bool) int {
+	return slices.IndexFunc(a.data, pred)
+}
+
+// BinarySearch ищет target в отсортированном массиве за O(log n). Возвращает
+// индекс первого равного элемента и true или место вставки и false.
+func (a *Array[T]) BinarySearch(target T, cmp func(a, b T) int) (int, bool) {
+	i := a.LowerBound(target, cmp)
+	return i, i < len(a.data) && cmp(a.data[i], target) == 0
+}
+
+// LowerBound возвращает индекс первого элемента, не меньшего target
+// (GetSize, если такого нет)
+func (a *Array[T]) LowerBound(target T, cmp func(a, b T) int) int {
+	lo, hi := 0, len(a.data)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if cmp(a.data[mid], target) < 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// UpperBound возвращает индекс первого элемента, большего target
+// (GetSize, если такого нет)
+func (a *Array[T]) UpperBound(target T, cmp func(a, b T) int) int {
+	lo, hi := 0, len(a.data)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if cmp(a.data[mid], target) <= 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// NthElement переставляет элементы так, что на месте n оказывается элемент,
+// который стоял бы там после сортировки, левее - не большие, правее - не
+// меньшие. Быстрый выбор (quickselect) работает в среднем за O(n).
+func (a *Array[T]) NthElement(n int, cmp func(a, b T) int) error {
+	if n < 0 || n >= len(a.data) {
+		return fmt.Errorf("error: Index %d is out of bounds (size %d)", n, len(a.data))
+	}
+	s := a.data
+	lo, hi := 0, len(s)
+	for hi-lo > 1 {
+		pivot := medianOfThree(s[lo], s[lo+(hi-lo)/2], s[hi-1], cmp)
+
+		// Разбиение на три части: [lo, lt) < pivot, [lt, gt) == pivot, [gt, hi) > pivot.
+		// Отдельная часть равных не дает повторам вырождать выбор в O(n^2).
+		lt, i, gt := lo, lo, hi
+		for i < gt {
+			switch c := cmp(s[i], pivot); {
+			case c < 0:
+				s[lt], s[i] = s[i], s[lt]
+				lt++
+				i++
+			case c > 0:
+				gt--
+				s[i], s[gt] = s[gt], s[i]
+			default:
+				i++
+			}
+		}
+
+		switch {
+		case n < lt:
+			hi = lt
+		case n >= gt:
+			lo = gt
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func medianOfThree[T any](x, y, z T, cmp func(a, b T) int) T {
+	if cmp(x, y) > 0 {
+		x, y = y, x
+	}
+	if cmp(y, z) > 0 {
+		y = z
+		if cmp(x, y) > 0 {
+			y = x
+		}
+	}
+	return y
+}
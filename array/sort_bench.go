@@ -0,0 +1,85 @@
+package array
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// Сортировки сравниваются на размерах из array_bench.go. Каждая итерация
+// сортирует свежую копию одних и тех же случайных данных; копирование
+// исключено из замера.
+
+// randomArray создает массив из n случайных чисел в [0, limit)
+func randomArray(n, limit int, seed uint64) *Array[int] {
+	r := rand.New(rand.NewPCG(seed, seed))
+	a := NewArray[int]()
+	for i := 0; i < n; i++ {
+		a.PushBack(r.IntN(limit))
+	}
+	return a
+}
+
+// benchSort прогоняет sort на копиях массива src
+func benchSort(b *testing.B, src *Array[int], sort func(a *Array[int])) {
+	b.StopTimer()
+	for i := 0; i < b.N; i++ {
+		a := src.Clone()
+		b.StartTimer()
+		sort(a)
+		b.StopTimer()
+	}
+}
+
+// BenchmarkSort сравнивает сортировки массива с slices.Sort
+func BenchmarkSort(b *testing.B) {
+	sizes := []struct {
+		name string
+		n    int
+	}{
+		{"Small", SmallDataSize},
+		{"Large", LargeDataSize},
+	}
+
+	for _, size := range sizes {
+		src := randomArray(size.n, size.n, 42)
+
+		b.Run(size.name+"/slices.Sort", func(b *testing.B) {
+			benchSort(b, src, func(a *Array[int]) { slices.Sort(a.data) })
+		})
+		b.Run(size.name+"/Sort", func(b *testing.B) {
+			benchSort(b, src, func(a *Array[int]) { a.Sort(cmp.Compare[int]) })
+		})
+		b.Run(size.name+"/StableSort", func(b *testing.B) {
+			benchSort(b, src, func(a *Array[int]) { a.StableSort(cmp.Compare[int]) })
+		})
+		b.Run(size.name+"/ParallelSort", func(b *testing.B) {
+			benchSort(b, src, func(a *Array[int]) { a.ParallelSort(cmp.Compare[int]) })
+		})
+	}
+}
+
+// BenchmarkNthElement сравнивает выбор медианы с полной сортировкой
+func BenchmarkNthElement(b *testing.B) {
+	src := randomArray(LargeDataSize, LargeDataSize, 7)
+
+	b.Run("NthElement", func(b *testing.B) {
+		benchSort(b, src, func(a *Array[int]) { _ = a.NthElement(a.GetSize()/2, cmp.Compare[int]) })
+	})
+	b.Run("slices.Sort", func(b *testing.B) {
+		benchSort(b, src, func(a *Array[int]) { slices.Sort(a.data) })
+	})
+}
+
+// BenchmarkBinarySearch измеряет поиск в отсортированном массиве
+func BenchmarkBinarySearch(b *testing.B) {
+	arr := NewArray[int]()
+	for i := 0; i < LargeDataSize; i++ {
+		arr.PushBack(i * 2)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = arr.BinarySearch(i%(2*LargeDataSize), cmp.Compare[int])
+	}
+}
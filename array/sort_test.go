@@ -0,0 +1,157 @@
+package array
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 17, 1000} {
+		a := randomArray(n, 50, uint64(n))
+		want := a.ToSlice()
+		slices.Sort(want)
+
+		a.Sort(cmp.Compare[int])
+		if got := a.ToSlice(); !slices.Equal(got, want) {
+			t.Errorf("n=%d: Sort = %v", n, got)
+		}
+		if !a.IsSorted(cmp.Compare[int]) {
+			t.Errorf("n=%d: IsSorted = false after Sort", n)
+		}
+	}
+
+	desc := FromSlice([]int{1, 3, 2})
+	desc.Sort(func(a, b int) int { return cmp.Compare(b, a) })
+	if got := desc.ToSlice(); !slices.Equal(got, []int{3, 2, 1}) {
+		t.Errorf("descending Sort = %v", got)
+	}
+	if desc.IsSorted(cmp.Compare[int]) {
+		t.Error("IsSorted(ascending) = true for descending array")
+	}
+}
+
+type pair struct {
+	key, order int
+}
+
+func byKey(a, b pair) int { return cmp.Compare(a.key, b.key) }
+
+// checkStable проверяет порядок ключей и исходный порядок равных
+func checkStable(t *testing.T, name string, data []pair) {
+	t.Helper()
+	for i := 1; i < len(data); i++ {
+		prev, cur := data[i-1], data[i]
+		if prev.key > cur.key || prev.key == cur.key && prev.order > cur.order {
+			t.Fatalf("%s: not stable at %d: %v then %v", name, i, prev, cur)
+		}
+	}
+}
+
+func TestStableAndParallelSort(t *testing.T) {
+	// Больше ParallelThreshold, чтобы ParallelSort действительно делил работу
+	for _, n := range []int{10, ParallelThreshold*4 + 3} {
+		r := rand.New(rand.NewPCG(1, uint64(n)))
+		data := make([]pair, n)
+		for i := range data {
+			data[i] = pair{key: r.IntN(100), order: i}
+		}
+
+		stable := FromSlice(data)
+		stable.StableSort(byKey)
+		checkStable(t, "StableSort", stable.ToSlice())
+
+		parallel := FromSlice(data)
+		parallel.ParallelSort(byKey)
+		checkStable(t, "ParallelSort", parallel.ToSlice())
+
+		if !slices.Equal(stable.ToSlice(), parallel.ToSlice()) {
+			t.Errorf("n=%d: ParallelSort differs from StableSort", n)
+		}
+	}
+
+	// Уже отсортированный массив проходит без слияний
+	sorted := FromSlice(make([]int, ParallelThreshold*2))
+	for i := range sorted.GetSize() {
+		_ = sorted.Set(i, i)
+	}
+	sorted.ParallelSort(cmp.Compare[int])
+	if !sorted.IsSorted(cmp.Compare[int]) {
+		t.Error("ParallelSort broke a sorted array")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	a := FromSlice([]int{1, 3, 3, 3, 5, 8})
+	tests := []struct {
+		target       int
+		lower, upper int
+		found        bool
+	}{
+		{0, 0, 0, false},
+		{1, 0, 1, true},
+		{3, 1, 4, true},
+		{4, 4, 4, false},
+		{8, 5, 6, true},
+		{9, 6, 6, false},
+	}
+	for _, tt := range tests {
+		if got := a.LowerBound(tt.target, cmp.Compare[int]); got != tt.lower {
+			t.Errorf("LowerBound(%d) = %d, want %d", tt.target, got, tt.lower)
+		}
+		if got := a.UpperBound(tt.target, cmp.Compare[int]); got != tt.upper {
+			t.Errorf("UpperBound(%d) = %d, want %d", tt.target, got, tt.upper)
+		}
+		i, found := a.BinarySearch(tt.target, cmp.Compare[int])
+		if i != tt.lower || found != tt.found {
+			t.Errorf("BinarySearch(%d) = %d, %t, want %d, %t", tt.target, i, found, tt.lower, tt.found)
+		}
+	}
+
+	empty := NewArray[int]()
+	if i, found := empty.BinarySearch(1, cmp.Compare[int]); i != 0 || found {
+		t.Errorf("BinarySearch on empty = %d, %t", i, found)
+	}
+
+	if i := a.Find(func(v int) bool { return v > 3 }); i != 4 {
+		t.Errorf("Find(>3) = %d, want 4", i)
+	}
+	if i := a.Find(func(v int) bool { return v > 100 }); i != -1 {
+		t.Errorf("Find(>100) = %d, want -1", i)
+	}
+}
+
+func TestNthElement(t *testing.T) {
+	for _, limit := range []int{3, 1000} { // много повторов и почти без повторов
+		for _, n := range []int{1, 2, 5, 200} {
+			base := randomArray(n, limit, uint64(n*limit))
+			want := base.ToSlice()
+			slices.Sort(want)
+
+			for k := 0; k < n; k++ {
+				a := base.Clone()
+				if err := a.NthElement(k, cmp.Compare[int]); err != nil {
+					t.Fatalf("NthElement(%d) error: %v", k, err)
+				}
+				got := a.ToSlice()
+				if got[k] != want[k] {
+					t.Fatalf("n=%d limit=%d: element %d = %d, want %d", n, limit, k, got[k], want[k])
+				}
+				for i := range got {
+					if i < k && got[i] > got[k] || i > k && got[i] < got[k] {
+						t.Fatalf("n=%d limit=%d k=%d: element %d = %d is on the wrong side", n, limit, k, i, got[i])
+					}
+				}
+			}
+		}
+	}
+
+	a := FromSlice([]int{1, 2})
+	if err := a.NthElement(2, cmp.Compare[int]); err == nil {
+		t.Error("NthElement out of bounds returned nil error")
+	}
+	if err := NewArray[int]().NthElement(0, cmp.Compare[int]); err == nil {
+		t.Error("NthElement on empty array returned nil error")
+	}
+}
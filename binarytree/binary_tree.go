@@ -1,359 +1,658 @@
-package binarytree
-
-import (
-	"cmp"
-	"encoding/binary"
-	"errors"
-	"fmt"
-	"io"
-	"os"
-)
-
-// Константы для визуализации
-const (
-	branchRight = "|--- "
-	branchLeft  = "|___ "
-)
-
-// TreeNode представляет узел дерева
-type TreeNode[T cmp.Ordered] struct {
-	Key   T
-	Left  *TreeNode[T]
-	Right *TreeNode[T]
-}
-
-// FullBinaryTree представляет обертку над деревом
-type FullBinaryTree[T cmp.Ordered] struct {
-	root *TreeNode[T]
-}
-
-// NewFullBinaryTree создает новое пустое дерево
-func NewFullBinaryTree[T cmp.Ordered]() *FullBinaryTree[T] {
-	return &FullBinaryTree[T]{}
-}
-
-// GetRoot возвращает корень
-func (t *FullBinaryTree[T]) GetRoot() *TreeNode[T] {
-	return t.root
-}
-
-// Insert вставка элемента по принципу BST
-func (t *FullBinaryTree[T]) Insert(value T) {
-	newNode := &TreeNode[T]{Key: value}
-
-	if t.root == nil {
-		t.root = newNode
-		return
-	}
-
-	current := t.root
-	var parent *TreeNode[T]
-
-	for current != nil {
-		parent = current
-		if value < current.Key {
-			current = current.Left
-		} else {
-			current = current.Right
-		}
-	}
-
-	if value < parent.Key {
-		parent.Left = newNode
-	} else {
-		parent.Right = newNode
-	}
-}
-
-// IsFull проверяет, является ли дерево полным
-func (t *FullBinaryTree[T]) IsFull() bool {
-	if t.root == nil {
-		return true
-	}
-	return isFullRecursive(t.root)
-}
-
-func isFullRecursive[T cmp.Ordered](node *TreeNode[T]) bool {
-	// Если ((left == nil) XOR (right == nil)) == true, то это неполное дерево
-	hasLeft := node.Left != nil
-	hasRight := node.Right != nil
-
-	if hasLeft != hasRight {
-		return false
-	}
-
-	if hasLeft && hasRight {
-		return isFullRecursive(node.Left) && isFullRecursive(node.Right)
-	}
-
-	return true
-}
-
-// Clone создает глубокую копию дерева
-func (t *FullBinaryTree[T]) Clone() *FullBinaryTree[T] {
-	newTree := NewFullBinaryTree[T]()
-	newTree.root = copyTreeRecursive(t.root)
-	return newTree
-}
-
-func copyTreeRecursive[T cmp.Ordered](node *TreeNode[T]) *TreeNode[T] {
-	if node == nil {
-		return nil
-	}
-	newNode := &TreeNode[T]{Key: node.Key}
-	newNode.Left = copyTreeRecursive(node.Left)
-	newNode.Right = copyTreeRecursive(node.Right)
-	return newNode
-}
-
-// Print - единый метод вывода.
-// w - куда писать (os.Stdout для консоли или bytes.Buffer для тестов)
-func (t *FullBinaryTree[T]) Print(choice int, w io.Writer) error {
-	switch choice {
-	case 1:
-		t.printBreadthFirst(w)
-	case 2:
-		t.preOrderRecursive(t.root, w)
-		fmt.Fprintln(w)
-	case 3:
-		t.inOrderRecursive(t.root, w)
-		fmt.Fprintln(w)
-	case 4:
-		t.postOrderRecursive(t.root, w)
-		fmt.Fprintln(w)
-	case 5:
-		t.printTreeVisual(t.root, w)
-	default:
-		return fmt.Errorf("invalid print operation code: %d", choice)
-	}
-	return nil
-}
-
-// Методы обхода
-
-func (t *FullBinaryTree[T]) printBreadthFirst(w io.Writer) {
-	if t.root == nil {
-		return
-	}
-	// Простая реализация очереди на слайсе
-	queue := []*TreeNode[T]{t.root}
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:] // pop
-
-		fmt.Fprintf(w, "%v ", current.Key)
-
-		if current.Left != nil {
-			queue = append(queue, current.Left)
-		}
-		if current.Right != nil {
-			queue = append(queue, current.Right)
-		}
-	}
-	fmt.Fprintln(w)
-}
-
-func (t *FullBinaryTree[T]) preOrderRecursive(node *TreeNode[T], w io.Writer) {
-	if node != nil {
-		fmt.Fprintf(w, "%v ", node.Key)
-		t.preOrderRecursive(node.Left, w)
-		t.preOrderRecursive(node.Right, w)
-	}
-}
-
-func (t *FullBinaryTree[T]) inOrderRecursive(node *TreeNode[T], w io.Writer) {
-	if node != nil {
-		t.inOrderRecursive(node.Left, w)
-		fmt.Fprintf(w, "%v ", node.Key)
-		t.inOrderRecursive(node.Right, w)
-	}
-}
-
-func (t *FullBinaryTree[T]) postOrderRecursive(node *TreeNode[T], w io.Writer) {
-	if node != nil {
-		t.postOrderRecursive(node.Left, w)
-		t.postOrderRecursive(node.Right, w)
-		fmt.Fprintf(w, "%v ", node.Key)
-	}
-}
-
-// Визуализация
-
-func (t *FullBinaryTree[T]) printTreeVisual(node *TreeNode[T], w io.Writer) {
-	if node == nil {
-		fmt.Fprintln(w, "Дерево пустое.")
-		return
-	}
-	fmt.Fprintln(w, node.Key)
-	printTreeVisualRecursive(node.Right, "", false, w)
-	printTreeVisualRecursive(node.Left, "", true, w)
-}
-
-func printTreeVisualRecursive[T cmp.Ordered](node *TreeNode[T], prefix string, isLeft bool, w io.Writer) {
-	if node != nil {
-		fmt.Fprint(w, prefix)
-		if isLeft {
-			fmt.Fprint(w, branchLeft)
-		} else {
-			fmt.Fprint(w, branchRight)
-		}
-		fmt.Fprintln(w, node.Key)
-
-		newPrefix := prefix
-		if isLeft {
-			newPrefix += "|   "
-		} else {
-			newPrefix += "    "
-		}
-
-		printTreeVisualRecursive(node.Right, newPrefix, false, w)
-		printTreeVisualRecursive(node.Left, newPrefix, true, w)
-	}
-}
-
-// Файловый ввод-вывод (Текстовый)
-
-func (t *FullBinaryTree[T]) SaveText(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("couldn't open the file for writing: %s (%w)", filename, err)
-	}
-	defer file.Close()
-
-	if t.root == nil {
-		return nil
-	}
-
-	queue := []*TreeNode[T]{t.root}
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-
-		if _, err := fmt.Fprintf(file, "%v ", current.Key); err != nil {
-			return fmt.Errorf("error writing data to file: %w", err)
-		}
-
-		if current.Left != nil {
-			queue = append(queue, current.Left)
-		}
-		if current.Right != nil {
-			queue = append(queue, current.Right)
-		}
-	}
-	return nil
-}
-
-func (t *FullBinaryTree[T]) LoadText(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("couldn't open the file for reading: %s (%w)", filename, err)
-	}
-	defer file.Close()
-
-	// Очистка текущего дерева
-	t.root = nil
-
-	var value T
-	for {
-		// Fscan автоматически пропускает пробелы
-		_, err := fmt.Fscan(file, &value)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("critical I/O error when reading file: %w", err)
-		}
-		t.Insert(value)
-	}
-	return nil
-}
-
-// Файловый ввод-вывод (Бинарный)
-
-func (t *FullBinaryTree[T]) SaveBinary(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("binary file could not be opened: %w", err)
-	}
-	defer file.Close()
-
-	return serializeRecursive(t.root, file)
-}
-
-func serializeRecursive[T cmp.Ordered](node *TreeNode[T], w io.Writer) error {
-	exists := node != nil
-	// Пишем маркер (bool как int8/byte для простоты переносимости)
-	var marker int8
-	if exists {
-		marker = 1
-	}
-	if err := binary.Write(w, binary.LittleEndian, marker); err != nil {
-		return err
-	}
-
-	if exists {
-		if err := binary.Write(w, binary.LittleEndian, node.Key); err != nil {
-			return err
-		}
-		if err := serializeRecursive(node.Left, w); err != nil {
-			return err
-		}
-		if err := serializeRecursive(node.Right, w); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (t *FullBinaryTree[T]) LoadBinary(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("the binary file could not be opened: %w", err)
-	}
-	defer file.Close()
-
-	t.root = nil
-	var root *TreeNode[T]
-
-	err = deserializeRecursive(&root, file)
-	if err != nil {
-		// В случае ошибки дерево остается пустым или частично заполненным
-		t.root = nil
-		return err
-	}
-	t.root = root
-	return nil
-}
-
-func deserializeRecursive[T cmp.Ordered](node **TreeNode[T], r io.Reader) error {
-	var marker int8
-	if err := binary.Read(r, binary.LittleEndian, &marker); err != nil {
-		if err == io.EOF {
-			return nil // Нормальный конец рекурсии, если структура соблюдена
-		}
-		return err
-	}
-
-	if marker != 0 && marker != 1 {
-		return fmt.Errorf("invalid file format: expected marker 0 or 1, got %d", marker)
-	}
-
-	if marker == 1 {
-		var val T
-		if err := binary.Read(r, binary.LittleEndian, &val); err != nil {
-			return errors.New("unexpected end of file or node data reading error")
-		}
-		newNode := &TreeNode[T]{Key: val}
-		*node = newNode
-		if err := deserializeRecursive(&newNode.Left, r); err != nil {
-			return err
-		}
-		if err := deserializeRecursive(&newNode.Right, r); err != nil {
-			return err
-		}
-	} else {
-		*node = nil
-	}
-	return nil
-}
+package binarytree
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Константы для визуализации
+const (
+	branchRight = "|--- "
+	branchLeft  = "|___ "
+)
+
+// TreeNode представляет узел дерева
+type TreeNode[T cmp.Ordered] struct {
+	Key   T
+	Left  *TreeNode[T]
+	Right *TreeNode[T]
+}
+
+// FullBinaryTree представляет обертку над деревом
+type FullBinaryTree[T cmp.Ordered] struct {
+	root *TreeNode[T]
+
+	// Кодек элементов для бинарного формата; nil - codec.Default
+	elemCodec codec.ElementCodec[T]
+}
+
+// NewFullBinaryTree создает новое пустое дерево
+func NewFullBinaryTree[T cmp.Ordered]() *FullBinaryTree[T] {
+	return &FullBinaryTree[T]{}
+}
+
+// GetRoot возвращает корень
+func (t *FullBinaryTree[T]) GetRoot() *TreeNode[T] {
+	return t.root
+}
+
+// Insert вставка элемента по принципу BST
+func (t *FullBinaryTree[T]) Insert(value T) {
+	newNode := &TreeNode[T]{Key: value}
+
+	if t.root == nil {
+		t.root = newNode
+		return
+	}
+
+	current := t.root
+	var parent *TreeNode[T]
+
+	for current != nil {
+		parent = current
+		if value < current.Key {
+			current = current.Left
+		} else {
+			current = current.Right
+		}
+	}
+
+	if value < parent.Key {
+		parent.Left = newNode
+	} else {
+		parent.Right = newNode
+	}
+}
+
+// Clear удаляет все узлы дерева
+func (t *FullBinaryTree[T]) Clear() {
+	t.root = nil
+}
+
+// IsFull проверяет, является ли дерево полным
+func (t *FullBinaryTree[T]) IsFull() bool {
+	if t.root == nil {
+		return true
+	}
+	return isFullRecursive(t.root)
+}
+
+func isFullRecursive[T cmp.Ordered](node *TreeNode[T]) bool {
+	// Если ((left == nil) XOR (right == nil)) == true, то это неполное дерево
+	hasLeft := node.Left != nil
+	hasRight := node.Right != nil
+
+	if hasLeft != hasRight {
+		return false
+	}
+
+	if hasLeft && hasRight {
+		return isFullRecursive(node.Left) && isFullRecursive(node.Right)
+	}
+
+	return true
+}
+
+// Clone создает глубокую копию дерева
+func (t *FullBinaryTree[T]) Clone() *FullBinaryTree[T] {
+	newTree := NewFullBinaryTree[T]()
+	newTree.root = copyTreeRecursive(t.root)
+	newTree.elemCodec = t.elemCodec
+	return newTree
+}
+
+func copyTreeRecursive[T cmp.Ordered](node *TreeNode[T]) *TreeNode[T] {
+	if node == nil {
+		return nil
+	}
+	newNode := &TreeNode[T]{Key: node.Key}
+	newNode.Left = copyTreeRecursive(node.Left)
+	newNode.Right = copyTreeRecursive(node.Right)
+	return newNode
+}
+
+// Print - единый метод вывода.
+// w - куда писать (os.Stdout для консоли или bytes.Buffer для тестов)
+func (t *FullBinaryTree[T]) Print(choice int, w io.Writer) error {
+	switch choice {
+	case 1:
+		t.printBreadthFirst(w)
+	case 2:
+		t.preOrderRecursive(t.root, w)
+		fmt.Fprintln(w)
+	case 3:
+		t.inOrderRecursive(t.root, w)
+		fmt.Fprintln(w)
+	case 4:
+		t.postOrderRecursive(t.root, w)
+		fmt.Fprintln(w)
+	case 5:
+		t.printTreeVisual(t.root, w)
+	default:
+		return fmt.Errorf("invalid print operation code: %d", choice)
+	}
+	return nil
+}
+
+// Методы обхода
+
+func (t *FullBinaryTree[T]) printBreadthFirst(w io.Writer) {
+	if t.root == nil {
+		return
+	}
+	// Простая реализация очереди на слайсе
+	queue := []*TreeNode[T]{t.root}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:] // pop
+
+		fmt.Fprintf(w, "%v ", current.Key)
+
+		if current.Left != nil {
+			queue = append(queue, current.Left)
+		}
+		if current.Right != nil {
+			queue = append(queue, current.Right)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func (t *FullBinaryTree[T]) preOrderRecursive(node *TreeNode[T], w io.Writer) {
+	if node != nil {
+		fmt.Fprintf(w, "%v ", node.Key)
+		t.preOrderRecursive(node.Left, w)
+		t.preOrderRecursive(node.Right, w)
+	}
+}
+
+func (t *FullBinaryTree[T]) inOrderRecursive(node *TreeNode[T], w io.Writer) {
+	if node != nil {
+		t.inOrderRecursive(node.Left, w)
+		fmt.Fprintf(w, "%v ", node.Key)
+		t.inOrderRecursive(node.Right, w)
+	}
+}
+
+func (t *FullBinaryTree[T]) postOrderRecursive(node *TreeNode[T], w io.Writer) {
+	if node != nil {
+		t.postOrderRecursive(node.Left, w)
+		t.postOrderRecursive(node.Right, w)
+		fmt.Fprintf(w, "%v ", node.Key)
+	}
+}
+
+// Визуализация
+
+func (t *FullBinaryTree[T]) printTreeVisual(node *TreeNode[T], w io.Writer) {
+	if node == nil {
+		fmt.Fprintln(w, "Дерево пустое.")
+		return
+	}
+	fmt.Fprintln(w, node.Key)
+	printTreeVisualRecursive(node.Right, "", false, w)
+	printTreeVisualRecursive(node.Left, "", true, w)
+}
+
+func printTreeVisualRecursive[T cmp.Ordered](node *TreeNode[T], prefix string, isLeft bool, w io.Writer) {
+	if node != nil {
+		fmt.Fprint(w, prefix)
+		if isLeft {
+			fmt.Fprint(w, branchLeft)
+		} else {
+			fmt.Fprint(w, branchRight)
+		}
+		fmt.Fprintln(w, node.Key)
+
+		newPrefix := prefix
+		if isLeft {
+			newPrefix += "|   "
+		} else {
+			newPrefix += "    "
+		}
+
+		printTreeVisualRecursive(node.Right, newPrefix, false, w)
+		printTreeVisualRecursive(node.Left, newPrefix, true, w)
+	}
+}
+
+// Файловый ввод-вывод (Текстовый)
+
+func (t *FullBinaryTree[T]) SaveText(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, t.writeText, opts...)
+}
+
+func (t *FullBinaryTree[T]) LoadText(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("couldn't open the file for reading: %s (%w)", filename, err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, t.readText, opts...)
+}
+
+// WriteTextTo записывает ключи дерева в w в порядке обхода в ширину
+func (t *FullBinaryTree[T]) WriteTextTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, t.writeText)
+}
+
+// ReadTextFrom читает ключи из r до конца потока и вставляет их в пустое дерево
+func (t *FullBinaryTree[T]) ReadTextFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, t.readText)
+}
+
+func (t *FullBinaryTree[T]) writeText(w io.Writer) error {
+	if t.root == nil {
+		return nil
+	}
+
+	queue := []*TreeNode[T]{t.root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		tok, err := codec.FormatText(current.Key)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, tok); err != nil {
+			return fmt.Errorf("error writing data to file: %w", err)
+		}
+
+		if current.Left != nil {
+			queue = append(queue, current.Left)
+		}
+		if current.Right != nil {
+			queue = append(queue, current.Right)
+		}
+	}
+	return nil
+}
+
+func (t *FullBinaryTree[T]) readText(r io.Reader) error {
+	// Очистка текущего дерева
+	t.root = nil
+
+	s := codec.NewTextScanner(r)
+	for {
+		value, err := codec.ScanText[T](s)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("critical I/O error when reading file: %w", err)
+		}
+		t.Insert(value)
+	}
+	return nil
+}
+
+// Файловый ввод-вывод (Бинарный)
+
+func (t *FullBinaryTree[T]) SaveBinary(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, t.writeBinary, opts...)
+}
+
+// WriteTo записывает дерево в w в бинарном формате с маркерами пустых узлов (io.WriterTo)
+func (t *FullBinaryTree[T]) WriteTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, t.writeBinary)
+}
+
+// ReadFrom читает дерево в бинарном формате из r (io.ReaderFrom).
+// Читается ровно столько байт, сколько занимает дерево.
+func (t *FullBinaryTree[T]) ReadFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, t.readBinary)
+}
+
+// SetCodec задает кодек элементов для бинарного формата дерева.
+// По умолчанию используется codec.Default, который поддерживает строки,
+// []byte, encoding.BinaryMarshaler и типы фиксированного размера.
+func (t *FullBinaryTree[T]) SetCodec(c codec.ElementCodec[T]) {
+	t.elemCodec = c
+}
+
+func (t *FullBinaryTree[T]) elementCodec() (codec.ElementCodec[T], error) {
+	if t.elemCodec != nil {
+		return t.elemCodec, nil
+	}
+	return codec.Default[T]()
+}
+
+// writeBinary пишет дерево в конверте persist с видом и типом элементов
+func (t *FullBinaryTree[T]) writeBinary(w io.Writer) error {
+	return persist.WriteEnvelope(w, persist.KindBinaryTree, persist.TypeName[T](), t.writePayload)
+}
+
+// readBinary проверяет конверт и читает дерево
+func (t *FullBinaryTree[T]) readBinary(r io.Reader) error {
+	return persist.ReadEnvelope(r, persist.KindBinaryTree, persist.TypeName[T](), t.readPayload)
+}
+
+func (t *FullBinaryTree[T]) writePayload(w io.Writer) error {
+	c, err := t.elementCodec()
+	if err != nil {
+		return err
+	}
+	return serializeRecursive(t.root, w, c)
+}
+
+func serializeRecursive[T cmp.Ordered](node *TreeNode[T], w io.Writer, c codec.ElementCodec[T]) error {
+	exists := node != nil
+	// Пишем маркер (bool как int8/byte для простоты переносимости)
+	var marker int8
+	if exists {
+		marker = 1
+	}
+	if err := binary.Write(w, binary.LittleEndian, marker); err != nil {
+		return err
+	}
+
+	if exists {
+		if err := c.Encode(w, node.Key); err != nil {
+			return err
+		}
+		if err := serializeRecursive(node.Left, w, c); err != nil {
+			return err
+		}
+		if err := serializeRecursive(node.Right, w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *FullBinaryTree[T]) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("the binary file could not be opened: %w", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, t.readBinary, opts...)
+}
+
+func (t *FullBinaryTree[T]) readPayload(r io.Reader) error {
+	c, err := t.elementCodec()
+	if err != nil {
+		return err
+	}
+
+	var root *TreeNode[T]
+	if err := deserializeRecursive(&root, r, c); err != nil {
+		// В случае ошибки дерево не меняется
+		return err
+	}
+	t.root = root
+	return nil
+}
+
+func deserializeRecursive[T cmp.Ordered](node **TreeNode[T], r io.Reader, c codec.ElementCodec[T]) error {
+	var marker int8
+	if err := binary.Read(r, binary.LittleEndian, &marker); err != nil {
+		if err == io.EOF {
+			return nil // Нормальный конец рекурсии, если структура соблюдена
+		}
+		return err
+	}
+
+	if marker != 0 && marker != 1 {
+		return fmt.Errorf("invalid file format: expected marker 0 or 1, got %d", marker)
+	}
+
+	if marker == 1 {
+		val, err := c.Decode(r)
+		if err != nil {
+			return errors.New("unexpected end of file or node data reading error")
+		}
+		newNode := &TreeNode[T]{Key: val}
+		*node = newNode
+		if err := deserializeRecursive(&newNode.Left, r, c); err != nil {
+			return err
+		}
+		if err := deserializeRecursive(&newNode.Right, r, c); err != nil {
+			return err
+		}
+	} else {
+		*node = nil
+	}
+	return nil
+}
+
+// Срезы и последовательности
+
+// ErrNotSorted - FromSorted получил неотсортированный срез
+var ErrNotSorted = errors.New("slice is not sorted")
+
+// ErrNotSearchTree - ключи загруженного дерева нарушают порядок BST
+var ErrNotSearchTree = errors.New("keys violate binary search tree order")
+
+// FromSlice строит сбалансированное дерево из элементов s.
+// s не меняется: сортируется копия, поэтому сложность O(n log n).
+func FromSlice[T cmp.Ordered](s []T) *FullBinaryTree[T] {
+	sorted := slices.Clone(s)
+	slices.Sort(sorted)
+	t := NewFullBinaryTree[T]()
+	t.root = buildBalanced(sorted)
+	return t
+}
+
+// FromSorted строит сбалансированное дерево из отсортированного s за O(n)
+// без вызовов Insert. Для неотсортированного s возвращается ErrNotSorted.
+func FromSorted[T cmp.Ordered](s []T) (*FullBinaryTree[T], error) {
+	if !slices.IsSorted(s) {
+		return nil, ErrNotSorted
+	}
+	t := NewFullBinaryTree[T]()
+	t.root = buildBalanced(s)
+	return t, nil
+}
+
+// FromSeq строит сбалансированное дерево из элементов seq
+func FromSeq[T cmp.Ordered](seq iter.Seq[T]) *FullBinaryTree[T] {
+	sorted := slices.Sorted(seq)
+	t := NewFullBinaryTree[T]()
+	t.root = buildBalanced(sorted)
+	return t
+}
+
+// buildBalanced делает корнем середину s. Равные ключи Insert отправляет
+// вправо, поэтому корнем берется первый из равных середине; его индекс
+// ищется двоичным поиском, чтобы длинные серии повторов не давали O(n²).
+func buildBalanced[T cmp.Ordered](s []T) *TreeNode[T] {
+	if len(s) == 0 {
+		return nil
+	}
+	mid, _ := slices.BinarySearch(s, s[len(s)/2])
+	return &TreeNode[T]{
+		Key:   s[mid],
+		Left:  buildBalanced(s[:mid]),
+		Right: buildBalanced(s[mid+1:]),
+	}
+}
+
+// ToSlice возвращает ключи дерева в симметричном порядке (по возрастанию)
+func (t *FullBinaryTree[T]) ToSlice() []T {
+	var s []T
+	appendInOrder(t.root, &s)
+	return s
+}
+
+func appendInOrder[T cmp.Ordered](node *TreeNode[T], s *[]T) {
+	if node != nil {
+		appendInOrder(node.Left, s)
+		*s = append(*s, node.Key)
+		appendInOrder(node.Right, s)
+	}
+}
+
+// encoding и gob
+
+// MarshalBinary возвращает дерево в бинарном формате с конвертом
+// (encoding.BinaryMarshaler), поэтому его можно вкладывать в другие структуры
+func (t *FullBinaryTree[T]) MarshalBinary() ([]byte, error) {
+	return persist.Marshal(t.writeBinary)
+}
+
+// UnmarshalBinary читает дерево из данных MarshalBinary (encoding.BinaryUnmarshaler).
+// Данные должны содержать ровно один конверт.
+func (t *FullBinaryTree[T]) UnmarshalBinary(data []byte) error {
+	return persist.Unmarshal(data, t.readBinary)
+}
+
+// GobEncode кодирует дерево для encoding/gob (gob.GobEncoder)
+func (t *FullBinaryTree[T]) GobEncode() ([]byte, error) {
+	return t.MarshalBinary()
+}
+
+// GobDecode читает дерево, закодированное GobEncode (gob.GobDecoder)
+func (t *FullBinaryTree[T]) GobDecode(data []byte) error {
+	return t.UnmarshalBinary(data)
+}
+
+// JSON
+
+// MarshalJSON кодирует дерево как вложенные объекты {"key","left","right"};
+// пустое поддерево - null (json.Marshaler)
+func (t *FullBinaryTree[T]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON читает дерево из вложенных объектов (json.Unmarshaler).
+// Форма дерева сохраняется как есть, но ключи должны соблюдать порядок BST,
+// иначе возвращается ErrNotSearchTree. При ошибке дерево не меняется.
+func (t *FullBinaryTree[T]) UnmarshalJSON(data []byte) error {
+	return t.readJSON(bytes.NewReader(data))
+}
+
+// WriteJSONTo пишет дерево в w, не собирая JSON в памяти
+func (t *FullBinaryTree[T]) WriteJSONTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, t.writeJSON)
+}
+
+// ReadJSONFrom читает дерево из JSON в r до конца потока
+func (t *FullBinaryTree[T]) ReadJSONFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, t.readJSON)
+}
+
+func (t *FullBinaryTree[T]) writeJSON(w io.Writer) error {
+	return writeJSONRecursive(t.root, w)
+}
+
+func writeJSONRecursive[T cmp.Ordered](node *TreeNode[T], w io.Writer) error {
+	if node == nil {
+		_, err := io.WriteString(w, "null")
+		return err
+	}
+
+	if _, err := io.WriteString(w, `{"key":`); err != nil {
+		return err
+	}
+	if err := codec.EncodeJSONValue(w, node.Key); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `,"left":`); err != nil {
+		return err
+	}
+	if err := writeJSONRecursive(node.Left, w); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `,"right":`); err != nil {
+		return err
+	}
+	if err := writeJSONRecursive(node.Right, w); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+func (t *FullBinaryTree[T]) readJSON(r io.Reader) error {
+	var root *TreeNode[T]
+	err := codec.ReadJSON(r, func(dec *json.Decoder) error {
+		var err error
+		root, _, _, err = readJSONRecursive[T](dec, "$")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	t.root = root
+	return nil
+}
+
+// jsonKey - ключ узла и его положение в JSON для сообщения об ошибке
+type jsonKey[T cmp.Ordered] struct {
+	key    T
+	path   string
+	offset int64
+}
+
+// readJSONRecursive читает узел или null. Поле key обязательно,
+// left и right можно опустить. Возвращает наименьший и наибольший ключ
+// поддерева, чтобы проверить порядок BST в каждом узле за O(n): ключи
+// слева меньше ключа узла, справа - не меньше (равные Insert кладет вправо).
+func readJSONRecursive[T cmp.Ordered](dec *json.Decoder, path string) (*TreeNode[T], jsonKey[T], jsonKey[T], error) {
+	start := dec.InputOffset()
+	node := &TreeNode[T]{}
+	var own, leftMin, leftMax, rightMin, rightMax jsonKey[T]
+	hasKey := false
+
+	ok, err := codec.DecodeJSONFields(dec, path, func(name, fieldPath string) error {
+		var err error
+		switch name {
+		case "key":
+			own.path, own.offset = fieldPath, dec.InputOffset()
+			node.Key, err = codec.DecodeJSONValue[T](dec, fieldPath)
+			own.key = node.Key
+			hasKey = true
+		case "left":
+			node.Left, leftMin, leftMax, err = readJSONRecursive[T](dec, fieldPath)
+		case "right":
+			node.Right, rightMin, rightMax, err = readJSONRecursive[T](dec, fieldPath)
+		default:
+			err = fmt.Errorf("%w: unknown field %q", codec.ErrJSONSyntax, name)
+		}
+		return err
+	})
+	if err != nil || !ok {
+		return nil, own, own, err
+	}
+	if !hasKey {
+		return nil, own, own, &codec.JSONError{Path: path, Offset: start, Err: fmt.Errorf("%w: node without key", codec.ErrJSONSyntax)}
+	}
+
+	lo, hi := own, own
+	if node.Left != nil {
+		if !(leftMax.key < own.key) {
+			return nil, own, own, &codec.JSONError{Path: leftMax.path, Offset: leftMax.offset,
+				Err: fmt.Errorf("%w: key %v in left subtree of %v", ErrNotSearchTree, leftMax.key, own.key)}
+		}
+		lo = leftMin
+	}
+	if node.Right != nil {
+		if rightMin.key < own.key {
+			return nil, own, own, &codec.JSONError{Path: rightMin.path, Offset: rightMin.offset,
+				Err: fmt.Errorf("%w: key %v in right subtree of %v", ErrNotSearchTree, rightMin.key, own.key)}
+		}
+		hi = rightMax
+	}
+	return node, lo, hi, nil
+}
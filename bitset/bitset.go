@@ -0,0 +1,275 @@
+// Package bitset реализует плотное множество неотрицательных чисел на битах:
+// флаг занимает один бит вместо байта в array.Array[bool].
+package bitset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"iter"
+	"math/bits"
+	"os"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+const wordBits = 64
+
+// BitSet - набор битов переменной длины. Set за пределами длины расширяет
+// набор, Test и Clear за пределами длины видят нулевые биты. Нулевое
+// значение - пустой набор, готовый к использованию.
+type BitSet struct {
+	words []uint64
+	n     uint64 // Длина в битах; биты words за пределами n всегда нулевые
+}
+
+// New создает набор из n нулевых битов
+func New(n uint64) *BitSet {
+	return &BitSet{words: make([]uint64, wordsFor(n)), n: n}
+}
+
+func wordsFor(n uint64) uint64 {
+	return (n + wordBits - 1) / wordBits
+}
+
+// Len возвращает длину набора в битах
+func (b *BitSet) Len() uint64 {
+	return b.n
+}
+
+// grow расширяет набор до n битов
+func (b *BitSet) grow(n uint64) {
+	if n <= b.n {
+		return
+	}
+	if need := wordsFor(n); need > uint64(len(b.words)) {
+		if need <= uint64(cap(b.words)) {
+			b.words = b.words[:need]
+		} else {
+			// Запас вдвое, как у append, чтобы последовательные Set не копировали набор каждый раз
+			words := make([]uint64, need, max(need, 2*uint64(len(b.words))))
+			copy(words, b.words)
+			b.words = words
+		}
+	}
+	b.n = n
+}
+
+// Set устанавливает бит i, расширяя набор при необходимости
+func (b *BitSet) Set(i uint64) {
+	if i >= b.n {
+		b.grow(i + 1)
+	}
+	b.words[i/wordBits] |= 1 << (i % wordBits)
+}
+
+// Clear сбрасывает бит i
+func (b *BitSet) Clear(i uint64) {
+	if i < b.n {
+		b.words[i/wordBits] &^= 1 << (i % wordBits)
+	}
+}
+
+// Flip инвертирует бит i, расширяя набор при необходимости
+func (b *BitSet) Flip(i uint64) {
+	if i >= b.n {
+		b.grow(i + 1)
+	}
+	b.words[i/wordBits] ^= 1 << (i % wordBits)
+}
+
+// Test сообщает, установлен ли бит i
+func (b *BitSet) Test(i uint64) bool {
+	return i < b.n && b.words[i/wordBits]&(1<<(i%wordBits)) != 0
+}
+
+// Count возвращает количество установленных битов
+func (b *BitSet) Count() uint64 {
+	var count uint64
+	for _, w := range b.words {
+		count += uint64(bits.OnesCount64(w))
+	}
+	return count
+}
+
+// ClearAll сбрасывает все биты, сохраняя длину
+func (b *BitSet) ClearAll() {
+	clear(b.words)
+}
+
+// Clone создает копию набора
+func (b *BitSet) Clone() *BitSet {
+	words := make([]uint64, len(b.words))
+	copy(words, b.words)
+	return &BitSet{words: words, n: b.n}
+}
+
+// Equal сообщает, совпадают ли установленные биты. Длина не учитывается:
+// набор с лишними нулевыми битами равен исходному.
+func (b *BitSet) Equal(other *BitSet) bool {
+	short, long := b.words, other.words
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	for i, w := range short {
+		if long[i] != w {
+			return false
+		}
+	}
+	for _, w := range long[len(short):] {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Операции над множествами меняют b. Длина результата - большая из двух.
+
+// Or объединяет b с other
+func (b *BitSet) Or(other *BitSet) {
+	b.grow(other.n)
+	for i, w := range other.words {
+		b.words[i] |= w
+	}
+}
+
+// And оставляет в b только биты, установленные и в other
+func (b *BitSet) And(other *BitSet) {
+	b.grow(other.n)
+	for i := range b.words {
+		if i < len(other.words) {
+			b.words[i] &= other.words[i]
+		} else {
+			b.words[i] = 0
+		}
+	}
+}
+
+// Xor оставляет в b биты, установленные ровно в одном из наборов
+func (b *BitSet) Xor(other *BitSet) {
+	b.grow(other.n)
+	for i, w := range other.words {
+		b.words[i] ^= w
+	}
+}
+
+// AndNot сбрасывает в b биты, установленные в other
+func (b *BitSet) AndNot(other *BitSet) {
+	b.grow(other.n)
+	for i, w := range other.words {
+		b.words[i] &^= w
+	}
+}
+
+// NextSet возвращает индекс первого установленного бита, не меньшего i
+func (b *BitSet) NextSet(i uint64) (uint64, bool) {
+	if i >= b.n {
+		return 0, false
+	}
+	w := i / wordBits
+	// Биты текущего слова ниже i отбрасываются сдвигом
+	if word := b.words[w] >> (i % wordBits); word != 0 {
+		return i + uint64(bits.TrailingZeros64(word)), true
+	}
+	for w++; w < uint64(len(b.words)); w++ {
+		if b.words[w] != 0 {
+			return w*wordBits + uint64(bits.TrailingZeros64(b.words[w])), true
+		}
+	}
+	return 0, false
+}
+
+// All возвращает итератор по установленным битам по возрастанию. Нулевые
+// слова пропускаются целиком. Набор читается на каждом шаге, поэтому биты,
+// установленные телом цикла впереди текущего, будут пройдены.
+func (b *BitSet) All() iter.Seq[uint64] {
+	return func(yield func(uint64) bool) {
+		for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// Сохранение и загрузка
+//
+// Бинарный формат - конверт persist вида KindBitSet: длина в битах (uint64)
+// и слова по 64 бита в little-endian.
+
+// SaveBinary сохраняет набор в бинарный файл
+func (b *BitSet) SaveBinary(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, b.writeBinary, opts...)
+}
+
+// LoadBinary загружает набор из бинарного файла. При ошибке набор не меняется.
+func (b *BitSet) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Unable to open file: %v", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, b.readBinary, opts...)
+}
+
+// WriteTo записывает набор в w в бинарном формате (io.WriterTo)
+func (b *BitSet) WriteTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, b.writeBinary)
+}
+
+// ReadFrom читает набор в бинарном формате из r (io.ReaderFrom)
+func (b *BitSet) ReadFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, b.readBinary)
+}
+
+// MarshalBinary возвращает набор в бинарном формате с конвертом (encoding.BinaryMarshaler)
+func (b *BitSet) MarshalBinary() ([]byte, error) {
+	return persist.Marshal(b.writeBinary)
+}
+
+// UnmarshalBinary читает набор из данных MarshalBinary (encoding.BinaryUnmarshaler)
+func (b *BitSet) UnmarshalBinary(data []byte) error {
+	return persist.Unmarshal(data, b.readBinary)
+}
+
+func (b *BitSet) writeBinary(w io.Writer) error {
+	return persist.WriteEnvelope(w, persist.KindBitSet, "uint64", b.writePayload)
+}
+
+func (b *BitSet) readBinary(r io.Reader) error {
+	return persist.ReadEnvelope(r, persist.KindBitSet, "uint64", b.readPayload)
+}
+
+func (b *BitSet) writePayload(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, b.n); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, b.words[:wordsFor(b.n)])
+}
+
+func (b *BitSet) readPayload(r io.Reader) error {
+	var n uint64
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return fmt.Errorf("error: Failed to read bit count")
+	}
+	count := wordsFor(n)
+	if err := persist.CheckCount(r, count, 8); err != nil {
+		return err
+	}
+	words, err := codec.DecodeN(r, codec.Fixed[uint64]{}, int(count))
+	if err != nil {
+		return fmt.Errorf("error: Failed to read bits: %v", err)
+	}
+	// Биты за пределами длины должны быть нулевыми, иначе Count и Equal ошибутся
+	if tail := n % wordBits; tail != 0 && words[count-1]>>tail != 0 {
+		return fmt.Errorf("error: Bits set beyond length %d", n)
+	}
+
+	b.words = words
+	b.n = n
+	return nil
+}
@@ -0,0 +1,113 @@
+package bitset
+
+import (
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+)
+
+const NumBits = 1 << 20 // Количество флагов в бенчмарках
+
+// BenchmarkSet сравнивает установку флагов в BitSet и в array.Array[bool]
+func BenchmarkSet(b *testing.B) {
+	b.Run("BitSet", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bs := New(NumBits)
+			for j := uint64(0); j < NumBits; j += 3 {
+				bs.Set(j)
+			}
+		}
+		b.ReportMetric(float64(NumBits/8), "bytes/set")
+	})
+
+	b.Run("ArrayBool", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			arr := array.FromSlice(make([]bool, NumBits))
+			for j := 0; j < NumBits; j += 3 {
+				_ = arr.Set(j, true)
+			}
+		}
+		b.ReportMetric(float64(NumBits), "bytes/set")
+	})
+}
+
+// BenchmarkTest сравнивает проверку флагов
+func BenchmarkTest(b *testing.B) {
+	bs := New(NumBits)
+	flags := make([]bool, NumBits)
+	for j := uint64(0); j < NumBits; j += 3 {
+		bs.Set(j)
+		flags[j] = true
+	}
+	arr := array.FromSlice(flags)
+	b.ResetTimer()
+
+	b.Run("BitSet", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = bs.Test(uint64(i) % NumBits)
+		}
+	})
+
+	b.Run("ArrayBool", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = arr.Get(i % NumBits)
+		}
+	})
+}
+
+// BenchmarkCount сравнивает подсчет установленных флагов
+func BenchmarkCount(b *testing.B) {
+	bs := New(NumBits)
+	flags := make([]bool, NumBits)
+	for j := uint64(0); j < NumBits; j += 3 {
+		bs.Set(j)
+		flags[j] = true
+	}
+	arr := array.FromSlice(flags)
+	b.ResetTimer()
+
+	b.Run("BitSet", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = bs.Count()
+		}
+	})
+
+	b.Run("ArrayBool", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			count := 0
+			for v := range arr.All() {
+				if v {
+					count++
+				}
+			}
+			_ = count
+		}
+	})
+}
+
+// BenchmarkIterate измеряет обход установленных битов разреженного набора
+func BenchmarkIterate(b *testing.B) {
+	bs := New(NumBits)
+	for j := uint64(0); j < NumBits; j += 1000 {
+		bs.Set(j)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range bs.All() {
+		}
+	}
+}
+
+// BenchmarkOr измеряет объединение двух наборов
+func BenchmarkOr(b *testing.B) {
+	x, y := New(NumBits), New(NumBits)
+	for j := uint64(0); j < NumBits; j += 2 {
+		x.Set(j)
+		y.Set(j + 1)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		z := x.Clone()
+		z.Or(y)
+	}
+}
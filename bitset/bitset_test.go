@@ -0,0 +1,226 @@
+package bitset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+func fromIndices(idx ...uint64) *BitSet {
+	var b BitSet
+	for _, i := range idx {
+		b.Set(i)
+	}
+	return &b
+}
+
+func TestSetClearTest(t *testing.T) {
+	b := New(10)
+	if b.Len() != 10 || b.Count() != 0 {
+		t.Fatalf("New(10): Len=%d Count=%d", b.Len(), b.Count())
+	}
+
+	for _, i := range []uint64{0, 3, 9} {
+		b.Set(i)
+	}
+	for i := uint64(0); i < 10; i++ {
+		want := i == 0 || i == 3 || i == 9
+		if b.Test(i) != want {
+			t.Errorf("Test(%d) = %t, want %t", i, b.Test(i), want)
+		}
+	}
+	if b.Count() != 3 {
+		t.Errorf("Count = %d, want 3", b.Count())
+	}
+
+	b.Clear(3)
+	b.Clear(1000) // за пределами длины - ничего не делает
+	b.Flip(0)
+	b.Flip(1)
+	if got := slices.Collect(b.All()); !slices.Equal(got, []uint64{1, 9}) {
+		t.Errorf("after Clear/Flip = %v", got)
+	}
+	if b.Len() != 10 {
+		t.Errorf("Clear beyond length changed Len to %d", b.Len())
+	}
+
+	// Set за пределами длины расширяет набор
+	b.Set(200)
+	if b.Len() != 201 || !b.Test(200) || b.Test(199) || b.Test(201) {
+		t.Errorf("after Set(200): Len=%d", b.Len())
+	}
+
+	b.ClearAll()
+	if b.Count() != 0 || b.Len() != 201 {
+		t.Errorf("after ClearAll: Count=%d Len=%d", b.Count(), b.Len())
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	a := fromIndices(1, 2, 64, 100)
+	b := fromIndices(2, 3, 100, 300)
+
+	tests := []struct {
+		name string
+		op   func(x, y *BitSet)
+		want []uint64
+	}{
+		{"Or", (*BitSet).Or, []uint64{1, 2, 3, 64, 100, 300}},
+		{"And", (*BitSet).And, []uint64{2, 100}},
+		{"Xor", (*BitSet).Xor, []uint64{1, 3, 64, 300}},
+		{"AndNot", (*BitSet).AndNot, []uint64{1, 64}},
+	}
+	for _, tt := range tests {
+		x := a.Clone()
+		tt.op(x, b)
+		if got := slices.Collect(x.All()); !slices.Equal(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+		}
+		if x.Len() != 301 {
+			t.Errorf("%s: Len = %d, want 301", tt.name, x.Len())
+		}
+	}
+
+	// Короткий набор справа: And обнуляет хвост длинного
+	long := fromIndices(1, 500)
+	long.And(fromIndices(1))
+	if got := slices.Collect(long.All()); !slices.Equal(got, []uint64{1}) {
+		t.Errorf("And with shorter set = %v", got)
+	}
+
+	// Исходные наборы не меняются
+	if got := slices.Collect(b.All()); !slices.Equal(got, []uint64{2, 3, 100, 300}) {
+		t.Errorf("argument changed: %v", got)
+	}
+}
+
+func TestNextSetAndEqual(t *testing.T) {
+	b := fromIndices(5, 63, 64, 1000)
+	tests := []struct {
+		from uint64
+		want uint64
+		ok   bool
+	}{
+		{0, 5, true},
+		{5, 5, true},
+		{6, 63, true},
+		{64, 64, true},
+		{65, 1000, true},
+		{1001, 0, false},
+		{5000, 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := b.NextSet(tt.from); got != tt.want || ok != tt.ok {
+			t.Errorf("NextSet(%d) = %d, %t, want %d, %t", tt.from, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	var got []uint64
+	for i := range b.All() {
+		got = append(got, i)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !slices.Equal(got, []uint64{5, 63}) {
+		t.Errorf("All with break = %v", got)
+	}
+
+	longer := b.Clone()
+	longer.Set(2000)
+	longer.Clear(2000)
+	if !b.Equal(longer) || !longer.Equal(b) {
+		t.Error("sets differing only in length are not Equal")
+	}
+	longer.Set(7)
+	if b.Equal(longer) {
+		t.Error("different sets are Equal")
+	}
+
+	var empty BitSet
+	if _, ok := empty.NextSet(0); ok || empty.Count() != 0 {
+		t.Error("zero BitSet is not empty")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	src := fromIndices(0, 64, 65, 129, 777)
+
+	for _, tc := range []struct {
+		name string
+		opts []persist.Option
+	}{
+		{"Plain", nil},
+		{"Compressed", []persist.Option{compression.With(compression.Gzip)}},
+	} {
+		path := filepath.Join(dir, tc.name)
+		if err := src.SaveBinary(path, tc.opts...); err != nil {
+			t.Fatalf("%s: SaveBinary error: %v", tc.name, err)
+		}
+		var dst BitSet
+		if err := dst.LoadBinary(path); err != nil {
+			t.Fatalf("%s: LoadBinary error: %v", tc.name, err)
+		}
+		if !dst.Equal(src) || dst.Len() != src.Len() {
+			t.Errorf("%s: loaded %v", tc.name, slices.Collect(dst.All()))
+		}
+	}
+
+	data, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary error: %v", err)
+	}
+	var u BitSet
+	if err := u.UnmarshalBinary(data); err != nil || !u.Equal(src) {
+		t.Errorf("UnmarshalBinary: err=%v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := New(0).WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo empty error: %v", err)
+	}
+	loaded := fromIndices(3)
+	if _, err := loaded.ReadFrom(&buf); err != nil || loaded.Len() != 0 {
+		t.Errorf("ReadFrom empty: err=%v Len=%d", err, loaded.Len())
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	keep := fromIndices(1, 2)
+
+	// Заявленная длина больше данных
+	data, _ := persist.Marshal(func(w io.Writer) error {
+		return persist.WriteEnvelope(w, persist.KindBitSet, "uint64", func(w io.Writer) error {
+			return binary.Write(w, binary.LittleEndian, uint64(1<<40))
+		})
+	})
+	if err := keep.UnmarshalBinary(data); !errors.Is(err, persist.ErrTruncated) {
+		t.Errorf("huge length error = %v, want ErrTruncated", err)
+	}
+
+	// Биты за пределами длины
+	data, _ = persist.Marshal(func(w io.Writer) error {
+		return persist.WriteEnvelope(w, persist.KindBitSet, "uint64", func(w io.Writer) error {
+			return binary.Write(w, binary.LittleEndian, []uint64{3, 1 << 10})
+		})
+	})
+	if err := keep.UnmarshalBinary(data); err == nil {
+		t.Error("bits beyond length accepted")
+	}
+
+	if err := keep.LoadBinary(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("LoadBinary of missing file returned nil error")
+	}
+
+	if got := slices.Collect(keep.All()); !slices.Equal(got, []uint64{1, 2}) {
+		t.Errorf("set changed after failed loads: %v", got)
+	}
+}
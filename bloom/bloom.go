@@ -0,0 +1,205 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/hashing"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Ошибки, которые может вернуть фильтр
+var (
+	ErrInvalidParams = errors.New("bloom filter size and hash count must be greater than zero")
+	ErrIncompatible  = errors.New("bloom filters have different size or hash count")
+)
+
+// BloomFilter реализует фильтр Блума над строковыми ключами.
+// Индексы битов получаются последовательностью двойного хеширования h1 + i*h2,
+// той же, что используется для проб в dhash.
+type BloomFilter struct {
+	bits  []uint64
+	m     uint64 // Количество битов
+	k     uint32 // Количество хеш-функций
+	count uint64 // Количество добавленных ключей
+}
+
+// NewBloomFilter создает фильтр из m битов с k хеш-функциями
+func NewBloomFilter(m uint64, k uint32) (*BloomFilter, error) {
+	if m == 0 || k == 0 {
+		return nil, ErrInvalidParams
+	}
+	return &BloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}, nil
+}
+
+// NewBloomFilterWithEstimates подбирает размер и число хешей под ожидаемое
+// количество ключей n и желаемую вероятность ложного срабатывания fpr
+func NewBloomFilterWithEstimates(n uint64, fpr float64) (*BloomFilter, error) {
+	if n == 0 {
+		return nil, fmt.Errorf("expected number of keys must be greater than zero")
+	}
+	if fpr <= 0 || fpr >= 1 {
+		return nil, fmt.Errorf("false positive rate must be in (0, 1), got %v", fpr)
+	}
+	m, k := EstimateParameters(n, fpr)
+	return NewBloomFilter(m, k)
+}
+
+// EstimateParameters возвращает оптимальные m и k:
+// m = -n*ln(p) / ln(2)^2, k = m/n * ln(2)
+func EstimateParameters(n uint64, fpr float64) (m uint64, k uint32) {
+	ln2 := math.Ln2
+	m = uint64(math.Ceil(-float64(n) * math.Log(fpr) / (ln2 * ln2)))
+	if m == 0 {
+		m = 1
+	}
+	k = uint32(math.Round(float64(m) / float64(n) * ln2))
+	if k == 0 {
+		k = 1
+	}
+	return m, k
+}
+
+// Add добавляет ключ в фильтр
+func (bf *BloomFilter) Add(key string) {
+	h1, h2 := hashing.Pair(key)
+	for i := uint64(0); i < uint64(bf.k); i++ {
+		idx := hashing.Probe(h1, h2, i, bf.m)
+		bf.bits[idx/64] |= 1 << (idx % 64)
+	}
+	bf.count++
+}
+
+// Contains возвращает false, если ключ точно не добавлялся,
+// и true, если ключ, вероятно, есть в фильтре
+func (bf *BloomFilter) Contains(key string) bool {
+	h1, h2 := hashing.Pair(key)
+	for i := uint64(0); i < uint64(bf.k); i++ {
+		idx := hashing.Probe(h1, h2, i, bf.m)
+		if bf.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Union объединяет other в текущий фильтр. Параметры фильтров должны совпадать.
+func (bf *BloomFilter) Union(other *BloomFilter) error {
+	if bf.m != other.m || bf.k != other.k {
+		return ErrIncompatible
+	}
+	for i := range bf.bits {
+		bf.bits[i] |= other.bits[i]
+	}
+	bf.count += other.count
+	return nil
+}
+
+// Count возвращает количество вызовов Add (с учетом объединений)
+func (bf *BloomFilter) Count() uint64 {
+	return bf.count
+}
+
+// BitSize возвращает размер фильтра в битах
+func (bf *BloomFilter) BitSize() uint64 {
+	return bf.m
+}
+
+// HashCount возвращает количество хеш-функций
+func (bf *BloomFilter) HashCount() uint32 {
+	return bf.k
+}
+
+// EstimatedFPR оценивает текущую вероятность ложного срабатывания по доле единичных битов
+func (bf *BloomFilter) EstimatedFPR() float64 {
+	var ones int
+	for _, w := range bf.bits {
+		ones += bits.OnesCount64(w)
+	}
+	return math.Pow(float64(ones)/float64(bf.m), float64(bf.k))
+}
+
+// Clear сбрасывает все биты
+func (bf *BloomFilter) Clear() {
+	for i := range bf.bits {
+		bf.bits[i] = 0
+	}
+	bf.count = 0
+}
+
+// Clone создает глубокую копию фильтра
+func (bf *BloomFilter) Clone() *BloomFilter {
+	newBits := make([]uint64, len(bf.bits))
+	copy(newBits, bf.bits)
+	return &BloomFilter{bits: newBits, m: bf.m, k: bf.k, count: bf.count}
+}
+
+// SaveBinary сохраняет фильтр в бинарный файл
+func (bf *BloomFilter) SaveBinary(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, func(file io.Writer) error {
+		// Заголовок: m, k, count
+		if err := binary.Write(file, binary.LittleEndian, bf.m); err != nil {
+			return err
+		}
+		if err := binary.Write(file, binary.LittleEndian, bf.k); err != nil {
+			return err
+		}
+		if err := binary.Write(file, binary.LittleEndian, bf.count); err != nil {
+			return err
+		}
+		return binary.Write(file, binary.LittleEndian, bf.bits)
+	}, opts...)
+}
+
+// LoadBinary загружает фильтр из бинарного файла (в том числе сжатого)
+func (bf *BloomFilter) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Could not open binary file for reading: %w", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, bf.readBinary, opts...)
+}
+
+func (bf *BloomFilter) readBinary(r io.Reader) error {
+	var m, count uint64
+	var k uint32
+	if err := binary.Read(r, binary.LittleEndian, &m); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &k); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if m == 0 || k == 0 || m > math.MaxInt-63 {
+		return ErrInvalidParams
+	}
+
+	newBits, err := codec.DecodeN(r, codec.Fixed[uint64]{}, int((m+63)/64))
+	if err != nil {
+		return fmt.Errorf("failed to read filter bits: %w", err)
+	}
+	if err := persist.CheckEOF(r); err != nil {
+		return err
+	}
+
+	bf.bits = newBits
+	bf.m = m
+	bf.k = k
+	bf.count = count
+	return nil
+}
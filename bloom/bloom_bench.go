@@ -0,0 +1,43 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+)
+
+const NumElements = 100000 // Количество ключей для теста
+
+func generateKeys(prefix string, count int) []string {
+	keys := make([]string, count)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return keys
+}
+
+// BenchmarkAdd измеряет добавление ключей в фильтр на 1% ложных срабатываний.
+func BenchmarkAdd(b *testing.B) {
+	keys := generateKeys("key", NumElements)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bf, _ := NewBloomFilterWithEstimates(NumElements, 0.01)
+		for _, k := range keys {
+			bf.Add(k)
+		}
+	}
+}
+
+// BenchmarkContainsMiss измеряет отрицательный поиск - основной сценарий фильтра.
+func BenchmarkContainsMiss(b *testing.B) {
+	bf, _ := NewBloomFilterWithEstimates(NumElements, 0.01)
+	for _, k := range generateKeys("key", NumElements) {
+		bf.Add(k)
+	}
+	missing := generateKeys("missing", NumElements)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = bf.Contains(missing[i%NumElements])
+	}
+}
@@ -0,0 +1,179 @@
+package bloom
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+func TestConstructor(t *testing.T) {
+	if _, err := NewBloomFilter(0, 3); err != ErrInvalidParams {
+		t.Errorf("Expected ErrInvalidParams for m=0, got %v", err)
+	}
+	if _, err := NewBloomFilter(100, 0); err != ErrInvalidParams {
+		t.Errorf("Expected ErrInvalidParams for k=0, got %v", err)
+	}
+	if _, err := NewBloomFilterWithEstimates(0, 0.01); err == nil {
+		t.Error("Expected error for n=0")
+	}
+	if _, err := NewBloomFilterWithEstimates(100, 1.5); err == nil {
+		t.Error("Expected error for fpr >= 1")
+	}
+
+	bf, err := NewBloomFilterWithEstimates(1000, 0.01)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Для 1% теория дает ~9.6 бита на ключ и 7 хешей
+	if bf.BitSize() != 9586 || bf.HashCount() != 7 {
+		t.Errorf("Unexpected parameters: m=%d, k=%d", bf.BitSize(), bf.HashCount())
+	}
+}
+
+func TestAddContains(t *testing.T) {
+	bf, _ := NewBloomFilterWithEstimates(1000, 0.01)
+
+	for i := 0; i < 1000; i++ {
+		bf.Add(fmt.Sprintf("key%d", i))
+	}
+	if bf.Count() != 1000 {
+		t.Errorf("Expected count 1000, got %d", bf.Count())
+	}
+
+	// Ложноотрицательных ответов быть не может
+	for i := 0; i < 1000; i++ {
+		if !bf.Contains(fmt.Sprintf("key%d", i)) {
+			t.Fatalf("False negative for key%d", i)
+		}
+	}
+
+	// Доля ложных срабатываний должна быть близка к целевой
+	falsePositives := 0
+	const probes = 100000
+	for i := 0; i < probes; i++ {
+		if bf.Contains(fmt.Sprintf("missing%d", i)) {
+			falsePositives++
+		}
+	}
+	rate := float64(falsePositives) / probes
+	if rate > 0.02 {
+		t.Errorf("False positive rate too high: %.4f", rate)
+	}
+	if est := bf.EstimatedFPR(); est > 0.02 {
+		t.Errorf("Estimated FPR too high: %.4f", est)
+	}
+}
+
+func TestUnionCloneClear(t *testing.T) {
+	a, _ := NewBloomFilter(1024, 4)
+	b, _ := NewBloomFilter(1024, 4)
+	a.Add("left")
+	b.Add("right")
+
+	clone := a.Clone()
+	if err := a.Union(b); err != nil {
+		t.Fatalf("Union failed: %v", err)
+	}
+	if !a.Contains("left") || !a.Contains("right") || a.Count() != 2 {
+		t.Error("Union lost keys")
+	}
+	if clone.Contains("right") {
+		t.Error("Clone shares bits with original")
+	}
+
+	other, _ := NewBloomFilter(2048, 4)
+	if err := a.Union(other); err != ErrIncompatible {
+		t.Errorf("Expected ErrIncompatible, got %v", err)
+	}
+
+	a.Clear()
+	if a.Contains("left") || a.Count() != 0 {
+		t.Error("Filter not empty after Clear")
+	}
+}
+
+func TestSaveLoadBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "bloom.bin")
+
+	bf, _ := NewBloomFilterWithEstimates(100, 0.05)
+	for i := 0; i < 100; i++ {
+		bf.Add(fmt.Sprintf("key%d", i))
+	}
+	if err := bf.SaveBinary(file); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+
+	loaded, _ := NewBloomFilter(1, 1)
+	if err := loaded.LoadBinary(file); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+	if loaded.BitSize() != bf.BitSize() || loaded.HashCount() != bf.HashCount() || loaded.Count() != 100 {
+		t.Error("Parameters lost after load")
+	}
+	for i := 0; i < 100; i++ {
+		if !loaded.Contains(fmt.Sprintf("key%d", i)) {
+			t.Fatalf("key%d lost after load", i)
+		}
+	}
+
+	// Ошибки загрузки
+	if err := loaded.SaveBinary(""); err == nil {
+		t.Error("Expected error for empty filename")
+	}
+	if err := loaded.LoadBinary(filepath.Join(tmpDir, "missing.bin")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	short := filepath.Join(tmpDir, "short.bin")
+	os.WriteFile(short, []byte{1, 2, 3}, 0644)
+	if err := loaded.LoadBinary(short); err == nil {
+		t.Error("Expected error for short header")
+	}
+
+	// Заголовок обещает больше битов, чем есть в файле
+	data, _ := os.ReadFile(file)
+	truncated := filepath.Join(tmpDir, "truncated.bin")
+	os.WriteFile(truncated, data[:len(data)-8], 0644)
+	if err := loaded.LoadBinary(truncated); err == nil {
+		t.Error("Expected error for truncated file")
+	}
+	trailing := filepath.Join(tmpDir, "trailing.bin")
+	os.WriteFile(trailing, append(data, 0), 0644)
+	if err := loaded.LoadBinary(trailing); err == nil {
+		t.Error("Expected error for trailing data")
+	}
+	if !loaded.Contains("key1") {
+		t.Error("Failed load must not modify the filter")
+	}
+}
+
+// TestSaveLoadCompressed проверяет, что LoadBinary распаковывает файлы SaveBinary
+func TestSaveLoadCompressed(t *testing.T) {
+	bf, _ := NewBloomFilterWithEstimates(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		bf.Add(fmt.Sprintf("key%d", i))
+	}
+	for _, alg := range []compression.Algorithm{compression.Gzip, compression.Flate, compression.LZ} {
+		file := filepath.Join(t.TempDir(), "bloom.bin")
+		if err := bf.SaveBinary(file, compression.With(alg)); err != nil {
+			t.Fatalf("%v: SaveBinary failed: %v", alg, err)
+		}
+		loaded, _ := NewBloomFilter(1, 1)
+		if err := loaded.LoadBinary(file); err != nil {
+			t.Fatalf("%v: LoadBinary failed: %v", alg, err)
+		}
+		if loaded.BitSize() != bf.BitSize() || loaded.Count() != bf.Count() || !loaded.Contains("key999") {
+			t.Errorf("%v: state lost after load", alg)
+		}
+		// Предел WithLimit считается по распакованным данным
+		if err := loaded.LoadBinary(file, persist.WithLimit(16)); !errors.Is(err, persist.ErrLimitExceeded) {
+			t.Errorf("%v: expected ErrLimitExceeded, got %v", alg, err)
+		}
+	}
+}
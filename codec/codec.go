@@ -0,0 +1,280 @@
+package codec
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// ErrUnsupportedType - для типа нет кодека по умолчанию
+var ErrUnsupportedType = errors.New("no default codec for type")
+
+// ElementCodec кодирует элементы типа T в бинарный поток и обратно.
+// Decode возвращает io.EOF, если поток закончился ровно перед элементом,
+// и io.ErrUnexpectedEOF, если элемент оборван.
+type ElementCodec[T any] interface {
+	Encode(w io.Writer, v T) error
+	Decode(r io.Reader) (T, error)
+}
+
+// SliceCodec - необязательное расширение кодека для записи среза целиком
+type SliceCodec[T any] interface {
+	EncodeSlice(w io.Writer, s []T) error
+	DecodeSlice(r io.Reader, s []T) error
+}
+
+// Default подбирает кодек для T: строки, []byte, int и uint, типы
+// с encoding.BinaryMarshaler и типы фиксированного размера для encoding/binary.
+func Default[T any]() (ElementCodec[T], error) {
+	var zero T
+	var c any
+	switch any(zero).(type) {
+	case string:
+		c = String{}
+	case []byte:
+		c = Bytes{}
+	case int:
+		c = Int{}
+	case uint:
+		c = Uint{}
+	}
+	if c != nil {
+		return c.(ElementCodec[T]), nil
+	}
+
+	_, marshaler := any(zero).(encoding.BinaryMarshaler)
+	_, unmarshaler := any(&zero).(encoding.BinaryUnmarshaler)
+	if marshaler && unmarshaler {
+		return Marshaler[T]{}, nil
+	}
+
+	// binary.Size для пустого среза возвращает 0, поэтому срезы отсекаем отдельно
+	if kind := reflect.TypeFor[T]().Kind(); kind != reflect.Slice && binary.Size(zero) >= 0 {
+		return Fixed[T]{}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, reflect.TypeFor[T]())
+}
+
+// EncodeSlice пишет элементы s, используя SliceCodec, если кодек его поддерживает
+func EncodeSlice[T any](w io.Writer, c ElementCodec[T], s []T) error {
+	if sc, ok := c.(SliceCodec[T]); ok {
+		return sc.EncodeSlice(w, s)
+	}
+	for _, v := range s {
+		if err := c.Encode(w, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeSlice заполняет s элементами из r. Конец потока посреди среза - io.ErrUnexpectedEOF.
+func DecodeSlice[T any](r io.Reader, c ElementCodec[T], s []T) error {
+	if sc, ok := c.(SliceCodec[T]); ok {
+		return sc.DecodeSlice(r, s)
+	}
+	for i := range s {
+		v, err := c.Decode(r)
+		if err != nil {
+			if err == io.EOF && i > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		s[i] = v
+	}
+	return nil
+}
+
+// ChunkSize - сколько элементов загрузчики выделяют заранее и читают за раз
+const ChunkSize = 4096
+
+// DecodeN читает n элементов частями по ChunkSize. Память растет по мере
+// чтения, поэтому испорченное n в заголовке не приводит к огромному выделению:
+// короткие данные заканчиваются ошибкой раньше.
+func DecodeN[T any](r io.Reader, c ElementCodec[T], n int) ([]T, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("negative element count %d", n)
+	}
+	s := make([]T, 0, min(n, ChunkSize))
+	for len(s) < n {
+		chunk := min(n-len(s), ChunkSize)
+		s = append(s, make([]T, chunk)...)
+		if err := DecodeSlice(r, c, s[len(s)-chunk:]); err != nil {
+			if err == io.EOF && len(s) > chunk {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Fixed кодирует типы фиксированного размера через encoding/binary (little-endian)
+type Fixed[T any] struct{}
+
+// Encode пишет значение в little-endian
+func (Fixed[T]) Encode(w io.Writer, v T) error {
+	return binary.Write(w, binary.LittleEndian, v)
+}
+
+// Decode читает значение в little-endian
+func (Fixed[T]) Decode(r io.Reader) (T, error) {
+	var v T
+	err := binary.Read(r, binary.LittleEndian, &v)
+	return v, err
+}
+
+// EncodeSlice пишет срез одним вызовом binary.Write
+func (Fixed[T]) EncodeSlice(w io.Writer, s []T) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return binary.Write(w, binary.LittleEndian, s)
+}
+
+// DecodeSlice читает срез одним вызовом binary.Read
+func (Fixed[T]) DecodeSlice(r io.Reader, s []T) error {
+	if len(s) == 0 {
+		return nil
+	}
+	return binary.Read(r, binary.LittleEndian, s)
+}
+
+// Int кодирует int как 64-битное число, чтобы формат не зависел от платформы
+type Int struct{}
+
+// Encode пишет int как int64
+func (Int) Encode(w io.Writer, v int) error {
+	return binary.Write(w, binary.LittleEndian, int64(v))
+}
+
+// Decode читает int64 и приводит его к int
+func (Int) Decode(r io.Reader) (int, error) {
+	var v int64
+	err := binary.Read(r, binary.LittleEndian, &v)
+	return int(v), err
+}
+
+// Uint кодирует uint как 64-битное число
+type Uint struct{}
+
+// Encode пишет uint как uint64
+func (Uint) Encode(w io.Writer, v uint) error {
+	return binary.Write(w, binary.LittleEndian, uint64(v))
+}
+
+// Decode читает uint64 и приводит его к uint
+func (Uint) Decode(r io.Reader) (uint, error) {
+	var v uint64
+	err := binary.Read(r, binary.LittleEndian, &v)
+	return uint(v), err
+}
+
+// String кодирует строку как длину (uint32) и байты
+type String struct{}
+
+// Encode пишет длину строки и ее байты
+func (String) Encode(w io.Writer, v string) error {
+	if err := writeLen(w, len(v)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, v)
+	return err
+}
+
+// Decode читает строку с префиксом длины
+func (String) Decode(r io.Reader) (string, error) {
+	data, err := readPrefixed(r)
+	return string(data), err
+}
+
+// Bytes кодирует срез байт как длину (uint32) и байты
+type Bytes struct{}
+
+// Encode пишет длину среза и его байты
+func (Bytes) Encode(w io.Writer, v []byte) error {
+	if err := writeLen(w, len(v)); err != nil {
+		return err
+	}
+	_, err := w.Write(v)
+	return err
+}
+
+// Decode читает срез с префиксом длины
+func (Bytes) Decode(r io.Reader) ([]byte, error) {
+	return readPrefixed(r)
+}
+
+// Marshaler кодирует типы с encoding.BinaryMarshaler как длину (uint32) и
+// результат MarshalBinary. *T должен реализовывать encoding.BinaryUnmarshaler.
+type Marshaler[T any] struct{}
+
+// Encode пишет результат MarshalBinary с префиксом длины
+func (Marshaler[T]) Encode(w io.Writer, v T) error {
+	m, ok := any(v).(encoding.BinaryMarshaler)
+	if !ok {
+		return fmt.Errorf("%w: %T does not implement encoding.BinaryMarshaler", ErrUnsupportedType, v)
+	}
+	data, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return Bytes{}.Encode(w, data)
+}
+
+// Decode читает данные с префиксом длины и передает их в UnmarshalBinary
+func (Marshaler[T]) Decode(r io.Reader) (T, error) {
+	var v T
+	u, ok := any(&v).(encoding.BinaryUnmarshaler)
+	if !ok {
+		return v, fmt.Errorf("%w: *%T does not implement encoding.BinaryUnmarshaler", ErrUnsupportedType, v)
+	}
+	data, err := readPrefixed(r)
+	if err != nil {
+		return v, err
+	}
+	return v, u.UnmarshalBinary(data)
+}
+
+func writeLen(w io.Writer, n int) error {
+	if uint64(n) > math.MaxUint32 {
+		return fmt.Errorf("element is too long: %d bytes", n)
+	}
+	return binary.Write(w, binary.LittleEndian, uint32(n))
+}
+
+// readPrefixed читает длину и данные. Большие элементы читаются частями,
+// чтобы испорченная длина не приводила к огромному выделению памяти.
+func readPrefixed(r io.Reader) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+
+	const chunk = 64 << 10
+	if n <= chunk {
+		data := make([]byte, n)
+		if _, err := io.ReadFull(r, data); err != nil {
+			if err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r, int64(n)); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,47 @@
+package codec
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// BenchmarkFixedSlice измеряет запись среза чисел одним вызовом binary.Write.
+func BenchmarkFixedSlice(b *testing.B) {
+	values := make([]int32, 1024)
+	for i := range values {
+		values[i] = int32(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = EncodeSlice[int32](io.Discard, Fixed[int32]{}, values)
+	}
+}
+
+// BenchmarkStringEncode измеряет запись строк с префиксом длины.
+func BenchmarkStringEncode(b *testing.B) {
+	value := strings.Repeat("x", 64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = String{}.Encode(io.Discard, value)
+	}
+}
+
+// BenchmarkJSONArray измеряет потоковую запись JSON-массива по одному элементу.
+func BenchmarkJSONArray(b *testing.B) {
+	values := make([]int, 1024)
+	for i := range values {
+		values[i] = i
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = EncodeJSONArray(io.Discard, func(emit func(int) error) error {
+			for _, v := range values {
+				if err := emit(v); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Вспомогательные функции
+
+type point struct {
+	X, Y int32
+	Tag  [4]byte
+}
+
+// roundTrip кодирует значения кодеком по умолчанию и читает их обратно
+func roundTrip[T any](t *testing.T, values []T) {
+	t.Helper()
+	c, err := Default[T]()
+	if err != nil {
+		t.Fatalf("Default[%T] failed: %v", values, err)
+	}
+
+	var buf bytes.Buffer
+	for _, v := range values {
+		if err := c.Encode(&buf, v); err != nil {
+			t.Fatalf("Encode %v failed: %v", v, err)
+		}
+	}
+	for i, want := range values {
+		got, err := c.Decode(&buf)
+		if err != nil {
+			t.Fatalf("Decode #%d failed: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Decode #%d: expected %v, got %v", i, want, got)
+		}
+	}
+	if _, err := c.Decode(&buf); err != io.EOF {
+		t.Errorf("Expected io.EOF after last element, got %v", err)
+	}
+}
+
+func mustDefault[T any](t *testing.T) ElementCodec[T] {
+	t.Helper()
+	c, err := Default[T]()
+	if err != nil {
+		t.Fatalf("Default failed: %v", err)
+	}
+	return c
+}
+
+// Основные функциональные тесты
+
+func TestRoundTrip(t *testing.T) {
+	t.Run("int32", func(t *testing.T) { roundTrip(t, []int32{0, -1, 1 << 30}) })
+	t.Run("float64", func(t *testing.T) { roundTrip(t, []float64{0, -1.5, 3.25}) })
+	t.Run("bool", func(t *testing.T) { roundTrip(t, []bool{true, false}) })
+	t.Run("int", func(t *testing.T) { roundTrip(t, []int{0, -7, 1 << 40}) })
+	t.Run("uint", func(t *testing.T) { roundTrip(t, []uint{0, 7, 1 << 40}) })
+	t.Run("string", func(t *testing.T) { roundTrip(t, []string{"", "hello", "строка с пробелами\n"}) })
+	t.Run("bytes", func(t *testing.T) { roundTrip(t, [][]byte{{}, {0, 1, 2}, bytes.Repeat([]byte{0xAB}, 100)}) })
+	t.Run("struct", func(t *testing.T) { roundTrip(t, []point{{1, 2, [4]byte{'a'}}, {-3, 4, [4]byte{}}}) })
+	t.Run("marshaler", func(t *testing.T) {
+		roundTrip(t, []time.Time{time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC), {}})
+	})
+}
+
+func TestDefaultSelection(t *testing.T) {
+	if _, ok := mustDefault[string](t).(String); !ok {
+		t.Error("Expected String codec for string")
+	}
+	if _, ok := mustDefault[[]byte](t).(Bytes); !ok {
+		t.Error("Expected Bytes codec for []byte")
+	}
+	if _, ok := mustDefault[time.Time](t).(Marshaler[time.Time]); !ok {
+		t.Error("Expected Marshaler codec for time.Time")
+	}
+	if _, ok := mustDefault[int64](t).(Fixed[int64]); !ok {
+		t.Error("Expected Fixed codec for int64")
+	}
+
+	if _, err := Default[map[string]int](); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for map, got %v", err)
+	}
+	if _, err := Default[[]int32](); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for slice, got %v", err)
+	}
+	if _, err := Default[*int32](); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for pointer, got %v", err)
+	}
+	if _, err := Default[struct{ S string }](); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for struct with string, got %v", err)
+	}
+}
+
+func TestSlices(t *testing.T) {
+	values := []string{"a", "bb", "ccc"}
+	var buf bytes.Buffer
+	if err := EncodeSlice[string](&buf, String{}, values); err != nil {
+		t.Fatalf("EncodeSlice failed: %v", err)
+	}
+	got := make([]string, 3)
+	if err := DecodeSlice[string](&buf, String{}, got); err != nil || !reflect.DeepEqual(got, values) {
+		t.Errorf("Expected %v, got %v (%v)", values, got, err)
+	}
+
+	// Быстрый путь Fixed пишет то же, что и поэлементная запись
+	nums := []int32{1, 2, 3}
+	var fast, slow bytes.Buffer
+	EncodeSlice[int32](&fast, Fixed[int32]{}, nums)
+	for _, v := range nums {
+		Fixed[int32]{}.Encode(&slow, v)
+	}
+	if !bytes.Equal(fast.Bytes(), slow.Bytes()) {
+		t.Error("Fixed slice encoding differs from element encoding")
+	}
+
+	// Поток кончился посреди среза
+	buf.Reset()
+	EncodeSlice[string](&buf, String{}, values[:1])
+	if err := DecodeSlice[string](&buf, String{}, make([]string, 2)); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+// Тесты поврежденных данных
+
+func TestTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	String{}.Encode(&buf, "hello")
+	data := buf.Bytes()
+
+	for cut := 1; cut < len(data); cut++ {
+		if _, err := (String{}).Decode(bytes.NewReader(data[:cut])); err != io.ErrUnexpectedEOF {
+			t.Errorf("cut at %d: expected io.ErrUnexpectedEOF, got %v", cut, err)
+		}
+	}
+
+	// Огромная длина при коротком потоке не приводит к выделению 4 ГБ
+	huge := binary.LittleEndian.AppendUint32(nil, 0xFFFFFFFF)
+	huge = append(huge, "short"...)
+	if _, err := (Bytes{}).Decode(bytes.NewReader(huge)); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF for huge length, got %v", err)
+	}
+
+	// Ошибка UnmarshalBinary возвращается как есть
+	var bad bytes.Buffer
+	Bytes{}.Encode(&bad, []byte{0xFF})
+	if _, err := (Marshaler[time.Time]{}).Decode(&bad); err == nil {
+		t.Error("Expected UnmarshalBinary error")
+	}
+}
+
+func TestDecodeN(t *testing.T) {
+	values := make([]string, 3*ChunkSize+5)
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+	var buf bytes.Buffer
+	EncodeSlice[string](&buf, String{}, values)
+	data := buf.Bytes()
+
+	got, err := DecodeN[string](bytes.NewReader(data), String{}, len(values))
+	if err != nil || !reflect.DeepEqual(got, values) {
+		t.Fatalf("DecodeN: %d values, %v", len(got), err)
+	}
+
+	// Огромное n при коротких данных: ошибка после первой части, а не выделение памяти
+	if _, err := DecodeN[int64](bytes.NewReader(make([]byte, 16)), Fixed[int64]{}, 1<<40); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF for huge count, got %v", err)
+	}
+	// Данные кончились ровно на границе части
+	if _, err := DecodeN[string](bytes.NewReader(data), String{}, len(values)+ChunkSize); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF at chunk boundary, got %v", err)
+	}
+	if _, err := DecodeN[string](bytes.NewReader(nil), String{}, -1); err == nil {
+		t.Error("Expected error for negative count")
+	}
+	if got, err := DecodeN[string](bytes.NewReader(nil), String{}, 0); err != nil || len(got) != 0 {
+		t.Errorf("Empty: %v, %v", got, err)
+	}
+}
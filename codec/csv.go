@@ -0,0 +1,476 @@
+package codec
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CSV-формат контейнеров: одна запись на элемент, первая строка - заголовок
+// с именами колонок. Простые значения занимают колонку "value", структуры -
+// по колонке на экспортируемое поле. У хэш-таблиц перед значением идет колонка "key".
+// Строки с ошибками при импорте пропускаются и возвращаются в RowErrors.
+
+// ErrCSVHeader - заголовок CSV не совпадает с колонками
+var ErrCSVHeader = errors.New("csv header mismatch")
+
+// KeyColumn - имя колонки ключа хэш-таблиц
+const KeyColumn = "key"
+
+// CSVMapper переводит значение в запись CSV и обратно
+type CSVMapper[T any] interface {
+	Columns() []string
+	Format(v T) ([]string, error)
+	Parse(record []string) (T, error)
+}
+
+// CSVFuncs - CSVMapper из имен колонок и двух функций
+type CSVFuncs[T any] struct {
+	Names      []string
+	FormatFunc func(v T) ([]string, error)
+	ParseFunc  func(record []string) (T, error)
+}
+
+// Columns возвращает имена колонок
+func (m CSVFuncs[T]) Columns() []string { return m.Names }
+
+// Format вызывает FormatFunc
+func (m CSVFuncs[T]) Format(v T) ([]string, error) { return m.FormatFunc(v) }
+
+// Parse вызывает ParseFunc
+func (m CSVFuncs[T]) Parse(record []string) (T, error) { return m.ParseFunc(record) }
+
+// CSVOption настраивает импорт и экспорт CSV
+type CSVOption func(*csvOptions)
+
+type csvOptions struct {
+	comma    rune
+	noHeader bool
+	mapper   any
+}
+
+// WithCSVComma задает разделитель колонок (по умолчанию ',')
+func WithCSVComma(comma rune) CSVOption {
+	return func(o *csvOptions) {
+		o.comma = comma
+	}
+}
+
+// WithoutCSVHeader отключает строку заголовка: колонки идут в порядке Columns
+func WithoutCSVHeader() CSVOption {
+	return func(o *csvOptions) {
+		o.noHeader = true
+	}
+}
+
+// WithCSVMapper задает преобразование элементов вместо DefaultCSVMapper
+func WithCSVMapper[T any](m CSVMapper[T]) CSVOption {
+	return func(o *csvOptions) {
+		o.mapper = m
+	}
+}
+
+func buildCSVOptions[T any](opts []CSVOption) (csvOptions, CSVMapper[T], error) {
+	o := csvOptions{comma: ','}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.mapper == nil {
+		m, err := DefaultCSVMapper[T]()
+		return o, m, err
+	}
+	m, ok := o.mapper.(CSVMapper[T])
+	if !ok {
+		return o, nil, fmt.Errorf("%w: mapper %T is not a CSVMapper[%s]", ErrUnsupportedType, o.mapper, reflect.TypeFor[T]())
+	}
+	return o, m, nil
+}
+
+// RowError - ошибка разбора одной строки CSV
+type RowError struct {
+	Line int // Номер строки файла, начиная с 1
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("csv line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// RowErrors - строки, пропущенные при импорте; остальные строки загружены
+type RowErrors []*RowError
+
+func (e RowErrors) Error() string {
+	const shown = 3
+	parts := make([]string, 0, shown)
+	for i := 0; i < len(e) && i < shown; i++ {
+		parts = append(parts, e[i].Error())
+	}
+	if len(e) > shown {
+		parts = append(parts, fmt.Sprintf("and %d more", len(e)-shown))
+	}
+	return fmt.Sprintf("%d bad csv rows: %s", len(e), strings.Join(parts, "; "))
+}
+
+func (e RowErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Err возвращает nil для пустого списка, чтобы не получить ненулевой интерфейс
+func (e RowErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// WriteCSV пишет заголовок и по записи на каждый элемент, который each передает в emit
+func WriteCSV[T any](w io.Writer, each func(emit func(v T) error) error, opts ...CSVOption) error {
+	o, m, err := buildCSVOptions[T](opts)
+	if err != nil {
+		return err
+	}
+	return writeCSV(w, o, m.Columns(), func(emit func([]string) error) error {
+		return each(func(v T) error {
+			record, err := m.Format(v)
+			if err != nil {
+				return err
+			}
+			return emit(record)
+		})
+	})
+}
+
+// WriteKeyedCSV пишет пары ключ-значение: колонка KeyColumn и колонки значения
+func WriteKeyedCSV[T any](w io.Writer, each func(emit func(key string, v T) error) error, opts ...CSVOption) error {
+	o, m, err := buildCSVOptions[T](opts)
+	if err != nil {
+		return err
+	}
+	columns := append([]string{KeyColumn}, m.Columns()...)
+	return writeCSV(w, o, columns, func(emit func([]string) error) error {
+		return each(func(key string, v T) error {
+			record, err := m.Format(v)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			return emit(append([]string{key}, record...))
+		})
+	})
+}
+
+func writeCSV(w io.Writer, o csvOptions, columns []string, each func(emit func([]string) error) error) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = o.comma
+	if !o.noHeader {
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+	}
+	err := each(func(record []string) error {
+		if len(record) != len(columns) {
+			return fmt.Errorf("csv record has %d fields, expected %d", len(record), len(columns))
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV читает записи и передает разобранные элементы в fn по порядку.
+// Строки, которые не удалось разобрать, пропускаются и возвращаются
+// в RowErrors; ошибка чтения или заголовка прерывает импорт.
+func ReadCSV[T any](r io.Reader, fn func(v T) error, opts ...CSVOption) (RowErrors, error) {
+	o, m, err := buildCSVOptions[T](opts)
+	if err != nil {
+		return nil, err
+	}
+	return readCSV(r, o, m.Columns(), func(record []string) error {
+		v, err := m.Parse(record)
+		if err != nil {
+			return err
+		}
+		return fn(v)
+	})
+}
+
+// ReadKeyedCSV читает пары ключ-значение, записанные WriteKeyedCSV.
+// Повторяющийся ключ - ошибка строки ErrDuplicateKey.
+func ReadKeyedCSV[T any](r io.Reader, fn func(key string, v T) error, opts ...CSVOption) (RowErrors, error) {
+	o, m, err := buildCSVOptions[T](opts)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	columns := append([]string{KeyColumn}, m.Columns()...)
+	return readCSV(r, o, columns, func(record []string) error {
+		key := record[0]
+		if _, dup := seen[key]; dup {
+			return fmt.Errorf("%w: %q", ErrDuplicateKey, key)
+		}
+		v, err := m.Parse(record[1:])
+		if err != nil {
+			return err
+		}
+		seen[key] = struct{}{}
+		return fn(key, v)
+	})
+}
+
+// readCSV читает заголовок и передает в fn записи с колонками в порядке columns
+func readCSV(r io.Reader, o csvOptions, columns []string, fn func(record []string) error) (RowErrors, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = o.comma
+	cr.FieldsPerRecord = -1 // Число полей проверяется построчно
+
+	// order[i] - номер колонки файла для колонки i
+	order := make([]int, len(columns))
+	for i := range order {
+		order[i] = i
+	}
+	if !o.noHeader {
+		header, err := cr.Read()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: empty input", ErrCSVHeader)
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Таблицы из Excel начинаются с метки порядка байт
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+		if order, err = headerOrder(header, columns); err != nil {
+			return nil, &RowError{Line: 1, Err: err}
+		}
+	}
+
+	var rowErrs RowErrors
+	record := make([]string, len(columns))
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return rowErrs, nil
+		}
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			rowErrs = append(rowErrs, &RowError{Line: pe.StartLine, Err: pe.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		if len(fields) != len(columns) {
+			rowErrs = append(rowErrs, &RowError{Line: line, Err: fmt.Errorf("expected %d fields, got %d", len(columns), len(fields))})
+			continue
+		}
+		for i, j := range order {
+			record[i] = fields[j]
+		}
+		if err := fn(record); err != nil {
+			rowErrs = append(rowErrs, &RowError{Line: line, Err: err})
+		}
+	}
+}
+
+// headerOrder сопоставляет заголовок файла с колонками по именам
+func headerOrder(header, columns []string) ([]int, error) {
+	if len(header) != len(columns) {
+		return nil, fmt.Errorf("%w: expected columns %v, got %v", ErrCSVHeader, columns, header)
+	}
+	index := make(map[string]int, len(header))
+	for j, name := range header {
+		if _, dup := index[name]; dup {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrCSVHeader, name)
+		}
+		index[name] = j
+	}
+	order := make([]int, len(columns))
+	for i, name := range columns {
+		j, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrCSVHeader, name)
+		}
+		order[i] = j
+	}
+	return order, nil
+}
+
+// DefaultCSVMapper подбирает преобразование для T: простые значения
+// (строки, числа, bool, encoding.TextMarshaler) занимают колонку "value",
+// структуры - по колонке на экспортируемое поле. Имя колонки берется
+// из тега `csv:"name"`, поле с тегом `csv:"-"` пропускается.
+func DefaultCSVMapper[T any]() (CSVMapper[T], error) {
+	typ := reflect.TypeFor[T]()
+	if isCSVScalar(typ) {
+		return valueMapper[T]{}, nil
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s in csv", ErrUnsupportedType, typ)
+	}
+
+	var m structMapper[T]
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, tagged := field.Tag.Lookup("csv")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if !tagged || name == "" {
+			name = field.Name
+		}
+		if !isCSVScalar(field.Type) {
+			return nil, fmt.Errorf("%w: field %s.%s of type %s in csv", ErrUnsupportedType, typ, field.Name, field.Type)
+		}
+		m.names = append(m.names, name)
+		m.fields = append(m.fields, i)
+	}
+	if len(m.fields) == 0 {
+		return nil, fmt.Errorf("%w: struct %s has no exported fields", ErrUnsupportedType, typ)
+	}
+	return m, nil
+}
+
+// valueMapper пишет значение в одну колонку "value"
+type valueMapper[T any] struct{}
+
+func (valueMapper[T]) Columns() []string { return []string{"value"} }
+
+func (valueMapper[T]) Format(v T) ([]string, error) {
+	cell, err := formatCell(reflect.ValueOf(&v).Elem())
+	return []string{cell}, err
+}
+
+func (valueMapper[T]) Parse(record []string) (T, error) {
+	var v T
+	err := parseCell(record[0], reflect.ValueOf(&v).Elem())
+	return v, err
+}
+
+// structMapper пишет экспортируемые поля структуры в отдельные колонки
+type structMapper[T any] struct {
+	names  []string
+	fields []int
+}
+
+func (m structMapper[T]) Columns() []string { return m.names }
+
+func (m structMapper[T]) Format(v T) ([]string, error) {
+	rv := reflect.ValueOf(&v).Elem()
+	record := make([]string, len(m.fields))
+	for i, f := range m.fields {
+		cell, err := formatCell(rv.Field(f))
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", m.names[i], err)
+		}
+		record[i] = cell
+	}
+	return record, nil
+}
+
+func (m structMapper[T]) Parse(record []string) (T, error) {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	for i, f := range m.fields {
+		if err := parseCell(record[i], rv.Field(f)); err != nil {
+			return v, fmt.Errorf("column %q: %w", m.names[i], err)
+		}
+	}
+	return v, nil
+}
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// isCSVScalar сообщает, помещается ли значение типа в одну ячейку
+func isCSVScalar(typ reflect.Type) bool {
+	if typ.Implements(textMarshalerType) && reflect.PointerTo(typ).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func formatCell(v reflect.Value) (string, error) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("%w: %s in csv", ErrUnsupportedType, v.Type())
+}
+
+// parseCell разбирает ячейку в v; v должно быть адресуемым
+func parseCell(s string, v reflect.Value) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	}
+	return fmt.Errorf("%w: %s in csv", ErrUnsupportedType, v.Type())
+}
@@ -0,0 +1,149 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type csvPoint struct {
+	Name   string `csv:"name"`
+	X      int    `csv:"x"`
+	Y      float64
+	Hidden bool `csv:"-"`
+	secret int
+}
+
+// Основные функциональные тесты
+
+func TestCSVRoundTrip(t *testing.T) {
+	points := []csvPoint{{Name: "a, b", X: 1, Y: 2.5}, {Name: "\"q\"", X: -3}}
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, func(emit func(csvPoint) error) error {
+		for _, p := range points {
+			if err := emit(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	want := "name,x,Y\n\"a, b\",1,2.5\n\"\"\"q\"\"\",-3,0\n"
+	if err != nil || buf.String() != want {
+		t.Fatalf("Unexpected CSV %q (%v)", buf.String(), err)
+	}
+
+	var got []csvPoint
+	rowErrs, err := ReadCSV(&buf, func(p csvPoint) error {
+		got = append(got, p)
+		return nil
+	})
+	if err != nil || rowErrs.Err() != nil || len(got) != 2 || got[0] != points[0] || got[1] != points[1] {
+		t.Errorf("Unexpected result %v (%v, %v)", got, rowErrs, err)
+	}
+}
+
+func TestCSVHeaderAndDelimiter(t *testing.T) {
+	// Колонки сопоставляются по именам, метка порядка байт пропускается
+	input := "\ufeffY;x;name\n1.5;2;p\n"
+	var got []csvPoint
+	_, err := ReadCSV(strings.NewReader(input), func(p csvPoint) error {
+		got = append(got, p)
+		return nil
+	}, WithCSVComma(';'))
+	if err != nil || len(got) != 1 || got[0] != (csvPoint{Name: "p", X: 2, Y: 1.5}) {
+		t.Fatalf("Unexpected result %v (%v)", got, err)
+	}
+
+	var values []int
+	_, err = ReadCSV(strings.NewReader("1\n2\n"), func(v int) error {
+		values = append(values, v)
+		return nil
+	}, WithoutCSVHeader())
+	if err != nil || len(values) != 2 || values[1] != 2 {
+		t.Errorf("Unexpected values %v (%v)", values, err)
+	}
+
+	for _, header := range []string{"x,name\n", "name,x,Z\n", "name,x,x\n", ""} {
+		_, err := ReadCSV(strings.NewReader(header), func(csvPoint) error { return nil })
+		if !errors.Is(err, ErrCSVHeader) {
+			t.Errorf("%q: expected ErrCSVHeader, got %v", header, err)
+		}
+	}
+}
+
+func TestCSVRowErrors(t *testing.T) {
+	input := "value\n1\nx\n3\n4,5\n\"bad\"quote\n6\n"
+	var values []int
+	rowErrs, err := ReadCSV(strings.NewReader(input), func(v int) error {
+		values = append(values, v)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected fatal error: %v", err)
+	}
+	if len(values) != 3 || values[2] != 6 {
+		t.Errorf("Expected good rows 1, 3, 6, got %v", values)
+	}
+
+	lines := []int{}
+	for _, re := range rowErrs {
+		lines = append(lines, re.Line)
+	}
+	if len(lines) != 3 || lines[0] != 3 || lines[1] != 5 || lines[2] != 6 {
+		t.Errorf("Expected errors on lines 3, 5, 6, got %v (%v)", lines, rowErrs)
+	}
+	var re *RowError
+	if !errors.As(rowErrs.Err(), &re) || re.Line != 3 {
+		t.Errorf("Expected RowError for line 3 via errors.As, got %v", re)
+	}
+
+	// Повторяющиеся ключи хэш-таблиц
+	rowErrs, err = ReadKeyedCSV(strings.NewReader("key,value\na,1\na,2\n"), func(string, int) error { return nil })
+	if err != nil || len(rowErrs) != 1 || rowErrs[0].Line != 3 || !errors.Is(rowErrs[0], ErrDuplicateKey) {
+		t.Errorf("Expected duplicate key on line 3, got %v (%v)", rowErrs, err)
+	}
+}
+
+func TestCSVMapper(t *testing.T) {
+	// Пользовательское преобразование: время в двух колонках
+	m := CSVFuncs[time.Duration]{
+		Names: []string{"minutes", "seconds"},
+		FormatFunc: func(d time.Duration) ([]string, error) {
+			return []string{strconv.Itoa(int(d / time.Minute)), strconv.Itoa(int(d % time.Minute / time.Second))}, nil
+		},
+		ParseFunc: func(record []string) (time.Duration, error) {
+			d, err := time.ParseDuration(record[0] + "m" + record[1] + "s")
+			return d, err
+		},
+	}
+	var buf bytes.Buffer
+	err := WriteKeyedCSV(&buf, func(emit func(string, time.Duration) error) error {
+		return emit("run", 90*time.Second)
+	}, WithCSVMapper[time.Duration](m))
+	if err != nil || buf.String() != "key,minutes,seconds\nrun,1,30\n" {
+		t.Fatalf("Unexpected CSV %q (%v)", buf.String(), err)
+	}
+
+	var got time.Duration
+	_, err = ReadKeyedCSV(&buf, func(key string, d time.Duration) error {
+		got = d
+		return nil
+	}, WithCSVMapper[time.Duration](m))
+	if err != nil || got != 90*time.Second {
+		t.Errorf("Expected 1m30s, got %v (%v)", got, err)
+	}
+
+	// Преобразование для другого типа
+	if _, err := ReadCSV(strings.NewReader(""), func(int) error { return nil }, WithCSVMapper[time.Duration](m)); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for mismatched mapper, got %v", err)
+	}
+	if _, err := DefaultCSVMapper[[]int](); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for slice, got %v", err)
+	}
+	if _, err := DefaultCSVMapper[struct{ P *int }](); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for pointer field, got %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// JSON-формат контейнеров: линейные структуры пишутся массивами, хэш-таблицы -
+// объектами. Элементы кодируются encoding/json по одному, поэтому большие
+// структуры не собираются в памяти целиком. Разбор строгий: неизвестные поля
+// структур, повторяющиеся ключи и данные после значения считаются ошибкой.
+
+// Ошибки разбора JSON
+var (
+	ErrJSONSyntax   = errors.New("json format error")
+	ErrDuplicateKey = errors.New("duplicate key")
+)
+
+// JSONError - ошибка разбора JSON с положением элемента
+type JSONError struct {
+	Path   string // Путь к элементу: $[3], $["key"], $["left"]["key"]
+	Offset int64  // Смещение в байтах от начала JSON
+	Err    error
+}
+
+func (e *JSONError) Error() string {
+	return fmt.Sprintf("json: %s at offset %d: %v", e.Path, e.Offset, e.Err)
+}
+
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+// NewJSONDecoder создает декодер, который не допускает неизвестные поля структур
+func NewJSONDecoder(r io.Reader) *json.Decoder {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	return dec
+}
+
+// ReadJSON разбирает r функцией read и проверяет, что после значения нет данных
+func ReadJSON(r io.Reader, read func(dec *json.Decoder) error) error {
+	dec := NewJSONDecoder(r)
+	if err := read(dec); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return &JSONError{Path: "$", Offset: dec.InputOffset(), Err: fmt.Errorf("%w: unexpected data after value", ErrJSONSyntax)}
+	}
+	return nil
+}
+
+// EncodeJSONValue пишет v в w через json.Marshal
+func EncodeJSONValue[T any](w io.Writer, v T) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// EncodeJSONArray пишет JSON-массив из элементов, которые each передает в emit
+func EncodeJSONArray[T any](w io.Writer, each func(emit func(v T) error) error) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	i := 0
+	err := each(func(v T) error {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := EncodeJSONValue(w, v); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+		i++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+// EncodeJSONObject пишет JSON-объект из пар, которые each передает в emit
+func EncodeJSONObject[T any](w io.Writer, each func(emit func(key string, v T) error) error) error {
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	first := true
+	err := each(func(key string, v T) error {
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		if err := EncodeJSONValue(w, key); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, ":"); err != nil {
+			return err
+		}
+		if err := EncodeJSONValue(w, v); err != nil {
+			return fmt.Errorf("value of %q: %w", key, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "}")
+	return err
+}
+
+// DecodeJSONValue читает следующее значение. Ошибка содержит путь и смещение элемента.
+func DecodeJSONValue[T any](dec *json.Decoder, path string) (T, error) {
+	v, _, err := decodeJSONValue[T](dec, path)
+	return v, err
+}
+
+// decodeJSONValue читает значение и возвращает смещение его начала
+func decodeJSONValue[T any](dec *json.Decoder, path string) (T, int64, error) {
+	var v T
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return v, dec.InputOffset(), tokenError(dec, path, err)
+	}
+	// Значение сначала читается целиком, чтобы знать, где оно начинается
+	start := dec.InputOffset() - int64(len(raw))
+	if err := NewJSONDecoder(bytes.NewReader(raw)).Decode(&v); err != nil {
+		return v, start, &JSONError{Path: path, Offset: start, Err: err}
+	}
+	return v, start, nil
+}
+
+// DecodeJSONArray читает JSON-массив и передает элементы в fn по порядку.
+// null читается как пустой массив.
+func DecodeJSONArray[T any](dec *json.Decoder, path string, fn func(v T) error) error {
+	ok, err := openJSON(dec, path, '[')
+	if err != nil || !ok {
+		return err
+	}
+	for i := 0; dec.More(); i++ {
+		elemPath := path + "[" + strconv.Itoa(i) + "]"
+		v, start, err := decodeJSONValue[T](dec, elemPath)
+		if err != nil {
+			return err
+		}
+		if err := fn(v); err != nil {
+			return wrapJSON(elemPath, start, err)
+		}
+	}
+	return closeJSON(dec, path)
+}
+
+// DecodeJSONFields читает JSON-объект и для каждого поля вызывает fn с именем
+// и путем поля; fn должна прочитать значение поля из dec. Повторяющиеся
+// поля - ErrDuplicateKey. Для null fn не вызывается и возвращается false.
+func DecodeJSONFields(dec *json.Decoder, path string, fn func(name, path string) error) (bool, error) {
+	ok, err := openJSON(dec, path, '{')
+	if err != nil || !ok {
+		return false, err
+	}
+	seen := make(map[string]struct{})
+	for dec.More() {
+		start := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return false, tokenError(dec, path, err)
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return false, &JSONError{Path: path, Offset: start, Err: fmt.Errorf("%w: expected field name, got %v", ErrJSONSyntax, tok)}
+		}
+		fieldPath := path + "[" + strconv.Quote(name) + "]"
+		if _, dup := seen[name]; dup {
+			return false, &JSONError{Path: fieldPath, Offset: start, Err: ErrDuplicateKey}
+		}
+		seen[name] = struct{}{}
+		if err := fn(name, fieldPath); err != nil {
+			return false, wrapJSON(fieldPath, start, err)
+		}
+	}
+	return true, closeJSON(dec, path)
+}
+
+// DecodeJSONObject читает JSON-объект и передает пары в fn в порядке записи.
+// null читается как пустой объект.
+func DecodeJSONObject[T any](dec *json.Decoder, path string, fn func(key string, v T) error) error {
+	_, err := DecodeJSONFields(dec, path, func(key, keyPath string) error {
+		v, err := DecodeJSONValue[T](dec, keyPath)
+		if err != nil {
+			return err
+		}
+		return fn(key, v)
+	})
+	return err
+}
+
+// openJSON читает открывающую скобку delim или null (тогда возвращается false)
+func openJSON(dec *json.Decoder, path string, delim json.Delim) (bool, error) {
+	start := dec.InputOffset()
+	tok, err := dec.Token()
+	if err != nil {
+		return false, tokenError(dec, path, err)
+	}
+	if tok == nil {
+		return false, nil
+	}
+	if tok != delim {
+		return false, &JSONError{Path: path, Offset: start, Err: fmt.Errorf("%w: expected %v, got %v", ErrJSONSyntax, delim, tok)}
+	}
+	return true, nil
+}
+
+// closeJSON читает закрывающую скобку после dec.More() == false
+func closeJSON(dec *json.Decoder, path string) error {
+	if _, err := dec.Token(); err != nil {
+		return tokenError(dec, path, err)
+	}
+	return nil
+}
+
+// tokenError добавляет к ошибке декодера путь и смещение. Синтаксические
+// ошибки и обрыв данных оборачивают ErrJSONSyntax.
+func tokenError(dec *json.Decoder, path string, err error) error {
+	var je *JSONError
+	if errors.As(err, &je) {
+		return err
+	}
+	offset := dec.InputOffset()
+	var se *json.SyntaxError
+	if errors.As(err, &se) {
+		offset = se.Offset
+		err = fmt.Errorf("%w: %w", ErrJSONSyntax, err)
+	}
+	if err == io.EOF {
+		err = fmt.Errorf("%w: %w", ErrJSONSyntax, io.ErrUnexpectedEOF)
+	}
+	return &JSONError{Path: path, Offset: offset, Err: err}
+}
+
+// wrapJSON добавляет путь к ошибке, если она его еще не содержит
+func wrapJSON(path string, offset int64, err error) error {
+	var je *JSONError
+	if errors.As(err, &je) {
+		return err
+	}
+	return &JSONError{Path: path, Offset: offset, Err: err}
+}
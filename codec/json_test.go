@@ -0,0 +1,143 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Основные функциональные тесты
+
+func TestEncodeJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := EncodeJSONArray(&buf, func(emit func(string) error) error {
+		for _, s := range []string{"a", "b \"c\"", "<d>"} {
+			if err := emit(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	// Результат совпадает с json.Marshal для среза
+	want, _ := json.Marshal([]string{"a", "b \"c\"", "<d>"})
+	if err != nil || buf.String() != string(want) {
+		t.Errorf("Expected %s, got %s (%v)", want, buf.String(), err)
+	}
+
+	buf.Reset()
+	err = EncodeJSONObject(&buf, func(emit func(string, int) error) error {
+		if err := emit("x", 1); err != nil {
+			return err
+		}
+		return emit("y z", 2)
+	})
+	if err != nil || buf.String() != `{"x":1,"y z":2}` {
+		t.Errorf("Unexpected object %s (%v)", buf.String(), err)
+	}
+
+	buf.Reset()
+	EncodeJSONArray(&buf, func(func(int) error) error { return nil })
+	if buf.String() != "[]" {
+		t.Errorf("Expected [], got %s", buf.String())
+	}
+
+	// Ошибка кодирования элемента содержит его номер
+	err = EncodeJSONArray(io.Discard, func(emit func(any) error) error {
+		emit(1)
+		return emit(make(chan int))
+	})
+	if err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Errorf("Expected error for element 1, got %v", err)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	var got []point
+	err := ReadJSON(strings.NewReader(` [{"X":1,"Y":2}, {"Y":3}] `), func(dec *json.Decoder) error {
+		return DecodeJSONArray(dec, "$", func(p point) error {
+			got = append(got, p)
+			return nil
+		})
+	})
+	if err != nil || len(got) != 2 || got[1] != (point{0, 3}) {
+		t.Fatalf("Unexpected result %v (%v)", got, err)
+	}
+
+	pairs := map[string]int{}
+	err = ReadJSON(strings.NewReader(`{"a":1,"b":2}`), func(dec *json.Decoder) error {
+		return DecodeJSONObject(dec, "$", func(key string, v int) error {
+			pairs[key] = v
+			return nil
+		})
+	})
+	if err != nil || len(pairs) != 2 || pairs["b"] != 2 {
+		t.Errorf("Unexpected pairs %v (%v)", pairs, err)
+	}
+
+	// null - пустой контейнер
+	err = ReadJSON(strings.NewReader("null"), func(dec *json.Decoder) error {
+		return DecodeJSONArray(dec, "$", func(int) error {
+			t.Error("Callback called for null")
+			return nil
+		})
+	})
+	if err != nil {
+		t.Errorf("Expected null to be accepted, got %v", err)
+	}
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	errStop := errors.New("stop")
+	cases := []struct {
+		input  string
+		path   string
+		offset int64
+		err    error
+	}{
+		{`[{"X":1}, {"Z":2}]`, "$[1]", 10, nil},
+		{`[{"X":1}, {"X":"a"}]`, "$[1]", 10, nil},
+		{`[{"X":1} {"X":2}]`, "$[1]", 10, ErrJSONSyntax},
+		{`[{"X":1}, {"X":2}, {"X":3}]`, "$[2]", 19, errStop},
+		{`{"X":1}`, "$", 0, ErrJSONSyntax},
+		{`[{"X":1}] x`, "$", 9, ErrJSONSyntax},
+		{`[{"X":1},`, "$[1]", 9, ErrJSONSyntax},
+		{``, "$", 0, io.ErrUnexpectedEOF},
+	}
+	for _, c := range cases {
+		err := ReadJSON(strings.NewReader(c.input), func(dec *json.Decoder) error {
+			return DecodeJSONArray(dec, "$", func(p point) error {
+				if p.X == 3 {
+					return errStop
+				}
+				return nil
+			})
+		})
+		var je *JSONError
+		if !errors.As(err, &je) || je.Path != c.path || je.Offset != c.offset {
+			t.Errorf("%q: expected error at %s offset %d, got %v", c.input, c.path, c.offset, err)
+			continue
+		}
+		if c.err != nil && !errors.Is(err, c.err) {
+			t.Errorf("%q: expected %v, got %v", c.input, c.err, err)
+		}
+	}
+
+	dec := json.NewDecoder(strings.NewReader(`{"a":1, "a":2}`))
+	_, err := DecodeJSONFields(dec, "$", func(name, path string) error {
+		_, err := DecodeJSONValue[int](dec, path)
+		return err
+	})
+	var je *JSONError
+	if !errors.Is(err, ErrDuplicateKey) || !errors.As(err, &je) || je.Path != `$["a"]` {
+		t.Errorf("Expected duplicate key at $[\"a\"], got %v", err)
+	}
+}
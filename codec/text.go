@@ -0,0 +1,181 @@
+package codec
+
+import (
+	"bufio"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Текстовый формат: элементы разделяются пробельными символами, строки
+// и типы с encoding.TextMarshaler пишутся в кавычках Go (strconv.Quote),
+// поэтому могут содержать пробелы, переводы строк и любые байты.
+// Токен без кавычек читается как есть, что сохраняет совместимость
+// с файлами, записанными до появления кавычек.
+
+// ErrTextSyntax - ошибка разбора текстового формата
+var ErrTextSyntax = errors.New("text format syntax error")
+
+// FormatText возвращает текстовое представление v в виде одного токена
+func FormatText[T any](v T) (string, error) {
+	if m, ok := any(v).(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return strconv.Quote(string(text)), nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+		return strconv.Quote(rv.String()), nil
+	}
+
+	s := fmt.Sprint(v)
+	if s == "" || s[0] == '"' || strings.IndexFunc(s, isSpaceRune) >= 0 {
+		return "", fmt.Errorf("%w: %T formats as %q", ErrUnsupportedType, v, s)
+	}
+	return s, nil
+}
+
+// ParseText разбирает токен, записанный FormatText
+func ParseText[T any](tok string) (T, error) {
+	var v T
+	if u, ok := any(&v).(encoding.TextUnmarshaler); ok {
+		text, err := unquoteToken(tok)
+		if err != nil {
+			return v, err
+		}
+		return v, u.UnmarshalText([]byte(text))
+	}
+	if rv := reflect.ValueOf(&v).Elem(); rv.Kind() == reflect.String {
+		text, err := unquoteToken(tok)
+		if err != nil {
+			return v, err
+		}
+		rv.SetString(text)
+		return v, nil
+	}
+
+	r := strings.NewReader(tok)
+	if _, err := fmt.Fscan(r, &v); err != nil {
+		return v, fmt.Errorf("%w: cannot parse %q as %T: %v", ErrTextSyntax, tok, v, err)
+	}
+	if r.Len() != 0 {
+		return v, fmt.Errorf("%w: unexpected %q after %T", ErrTextSyntax, tok[len(tok)-r.Len():], v)
+	}
+	return v, nil
+}
+
+// unquoteToken снимает кавычки; токен без кавычек возвращается как есть
+func unquoteToken(tok string) (string, error) {
+	if !strings.HasPrefix(tok, `"`) {
+		return tok, nil
+	}
+	s, err := strconv.Unquote(tok)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad quoted string %s", ErrTextSyntax, tok)
+	}
+	return s, nil
+}
+
+// TextScanner читает токены текстового формата: строки в кавычках
+// или последовательности непробельных символов
+type TextScanner struct {
+	r io.ByteReader
+}
+
+// NewTextScanner создает сканер. Если r не умеет читать по байту,
+// он оборачивается в bufio.Reader и может прочитать больше, чем нужно.
+func NewTextScanner(r io.Reader) *TextScanner {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &TextScanner{r: br}
+}
+
+// Next возвращает следующий токен как есть (строки - вместе с кавычками).
+// В конце данных возвращается io.EOF.
+func (s *TextScanner) Next() (string, error) {
+	var c byte
+	for {
+		b, err := s.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if !isSpace(b) {
+			c = b
+			break
+		}
+	}
+
+	tok := []byte{c}
+	if c != '"' {
+		for {
+			b, err := s.r.ReadByte()
+			if err == io.EOF {
+				return string(tok), nil
+			}
+			if err != nil {
+				return "", err
+			}
+			if isSpace(b) {
+				return string(tok), nil
+			}
+			tok = append(tok, b)
+		}
+	}
+
+	escaped := false
+	for {
+		b, err := s.r.ReadByte()
+		if err == io.EOF {
+			return "", fmt.Errorf("%w: unterminated quoted string", ErrTextSyntax)
+		}
+		if err != nil {
+			return "", err
+		}
+		tok = append(tok, b)
+		switch {
+		case escaped:
+			escaped = false
+		case b == '\\':
+			escaped = true
+		case b == '"':
+			// После закрывающей кавычки допускается только разделитель или конец данных
+			next, err := s.r.ReadByte()
+			if err == nil && !isSpace(next) {
+				return "", fmt.Errorf("%w: unexpected %q after quoted string", ErrTextSyntax, next)
+			}
+			if err != nil && err != io.EOF {
+				return "", err
+			}
+			return string(tok), nil
+		}
+	}
+}
+
+// ScanText читает и разбирает следующий токен. В конце данных возвращается io.EOF.
+func ScanText[T any](s *TextScanner) (T, error) {
+	tok, err := s.Next()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return ParseText[T](tok)
+}
+
+func isSpace(b byte) bool {
+	switch b {
+	case ' ', '\t', '\n', '\r', '\v', '\f':
+		return true
+	}
+	return false
+}
+
+func isSpaceRune(r rune) bool {
+	return r < 0x80 && isSpace(byte(r))
+}
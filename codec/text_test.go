@@ -0,0 +1,139 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/D4ROVAN1E/LR_3_Go/persist/persisttest"
+)
+
+// Основные функциональные тесты
+
+func TestFormatParseText(t *testing.T) {
+	tok, err := FormatText("hello world")
+	if err != nil || tok != `"hello world"` {
+		t.Errorf("Expected quoted string, got %s (%v)", tok, err)
+	}
+	if s, err := ParseText[string](tok); err != nil || s != "hello world" {
+		t.Errorf("Expected 'hello world', got %q (%v)", s, err)
+	}
+
+	// Токен без кавычек - старый формат
+	if s, _ := ParseText[string]("plain"); s != "plain" {
+		t.Errorf("Expected unquoted token as is, got %q", s)
+	}
+
+	if tok, _ := FormatText(-1.5); tok != "-1.5" {
+		t.Errorf("Expected -1.5, got %s", tok)
+	}
+	if v, err := ParseText[float64]("-1.5"); err != nil || v != -1.5 {
+		t.Errorf("Expected -1.5, got %v (%v)", v, err)
+	}
+	if _, err := ParseText[int]("12abc"); !errors.Is(err, ErrTextSyntax) {
+		t.Errorf("Expected ErrTextSyntax for trailing garbage, got %v", err)
+	}
+
+	// Именованный строковый тип и TextMarshaler
+	type name string
+	if tok, _ := FormatText(name("a b")); tok != `"a b"` {
+		t.Errorf("Expected named string to be quoted, got %s", tok)
+	}
+	moment := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tok, _ = FormatText(moment)
+	if v, err := ParseText[time.Time](tok); err != nil || !v.Equal(moment) {
+		t.Errorf("Expected %v, got %v (%v)", moment, v, err)
+	}
+
+	if _, err := FormatText([]int{1, 2}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expected ErrUnsupportedType for slice, got %v", err)
+	}
+}
+
+func TestTextScanner(t *testing.T) {
+	input := "3  \"a b\"\n\t\"line\\nbreak\" plain\r\n\"\"  "
+	s := NewTextScanner(strings.NewReader(input))
+	want := []string{"3", `"a b"`, `"line\nbreak"`, "plain", `""`}
+	for i, w := range want {
+		tok, err := s.Next()
+		if err != nil || tok != w {
+			t.Fatalf("Token %d: expected %s, got %s (%v)", i, w, tok, err)
+		}
+	}
+	if _, err := s.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+
+	for _, bad := range []string{`"unterminated`, `"a"b`, `"bad \q"`} {
+		_, err := ScanText[string](NewTextScanner(strings.NewReader(bad)))
+		if !errors.Is(err, ErrTextSyntax) {
+			t.Errorf("%s: expected ErrTextSyntax, got %v", bad, err)
+		}
+	}
+}
+
+// Fuzz-тесты: запись и чтение должны давать исходное значение
+
+// stringPair - текстовый формат пары строк: два токена FormatText, после
+// которых данных быть не должно
+var stringPair = persisttest.Format[*[2]string]{
+	Name: "text",
+	Write: func(p *[2]string, w io.Writer) (int64, error) {
+		var sb strings.Builder
+		for _, v := range p {
+			tok, err := FormatText(v)
+			if err != nil {
+				return 0, err
+			}
+			sb.WriteString(tok + "\n")
+		}
+		n, err := io.WriteString(w, sb.String())
+		return int64(n), err
+	},
+	Read: func(p *[2]string, r io.Reader) (int64, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return 0, err
+		}
+		s := NewTextScanner(bytes.NewReader(data))
+		for i := range p {
+			if p[i], err = ScanText[string](s); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := s.Next(); err != io.EOF {
+			return 0, fmt.Errorf("expected io.EOF, got %v", err)
+		}
+		return int64(len(data)), nil
+	},
+}
+
+func FuzzTextString(f *testing.F) {
+	persisttest.FuzzText(f, func(a, b string) *[2]string { return &[2]string{a, b} },
+		func() *[2]string { return new([2]string) }, stringPair,
+		func(a, b *[2]string) bool { return *a == *b })
+}
+
+func FuzzTextFloat(f *testing.F) {
+	f.Add(0.0)
+	f.Add(-1e-300)
+	f.Add(math.MaxFloat64)
+	f.Fuzz(func(t *testing.T, v float64) {
+		tok, err := FormatText(v)
+		if err != nil {
+			t.Fatalf("FormatText failed: %v", err)
+		}
+		got, err := ParseText[float64](tok)
+		if err != nil {
+			t.Fatalf("ParseText(%s) failed: %v", tok, err)
+		}
+		if got != v && !(math.IsNaN(v) && math.IsNaN(got)) {
+			t.Fatalf("Expected %v, got %v", v, got)
+		}
+	})
+}
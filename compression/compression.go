@@ -0,0 +1,155 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Сжатые данные начинаются с заголовка magic(4) algorithm(1), за которым
+// идет поток выбранного алгоритма. По заголовку NewReader определяет
+// алгоритм, а данные без заголовка читает как есть, поэтому загрузчики
+// открывают и сжатые, и обычные файлы. Файлы, сжатые внешним gzip,
+// распознаются по собственной сигнатуре gzip.
+
+// Ошибки сжатия
+var (
+	ErrUnknownAlgorithm = errors.New("unknown compression algorithm")
+	ErrCorrupt          = errors.New("compressed data is corrupt")
+)
+
+var magic = [4]byte{'L', 'R', '3', 'C'}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Algorithm - алгоритм сжатия
+type Algorithm uint8
+
+const (
+	None  Algorithm = iota // Без сжатия и без заголовка
+	Gzip                   // compress/gzip
+	Flate                  // compress/flate без обертки gzip
+	LZ                     // Быстрый блочный LZ77 (lz.go)
+)
+
+var algorithmNames = map[Algorithm]string{
+	None:  "none",
+	Gzip:  "gzip",
+	Flate: "flate",
+	LZ:    "lz",
+}
+
+func (a Algorithm) String() string {
+	if name, ok := algorithmNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("Algorithm(%d)", uint8(a))
+}
+
+// DefaultLevel - уровень сжатия по умолчанию для Gzip и Flate
+const DefaultLevel = flate.DefaultCompression
+
+// With возвращает опцию сохранения, которая сжимает файл алгоритмом alg
+func With(alg Algorithm) persist.Option {
+	return WithLevel(alg, DefaultLevel)
+}
+
+// WithLevel как With, но с уровнем сжатия для Gzip и Flate (flate.BestSpeed ...
+// flate.BestCompression). LZ уровень не использует.
+func WithLevel(alg Algorithm, level int) persist.Option {
+	return persist.WithWriter(func(w io.Writer) io.Writer {
+		cw, err := NewWriter(w, alg, level)
+		if err != nil {
+			return errWriter{err}
+		}
+		return cw
+	})
+}
+
+// NewWriter пишет в w заголовок и возвращает поток, который сжимает данные.
+// Close дописывает конец потока, но не закрывает w.
+func NewWriter(w io.Writer, alg Algorithm, level int) (io.WriteCloser, error) {
+	if alg == None {
+		return nopCloser{w}, nil
+	}
+	if _, ok := algorithmNames[alg]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownAlgorithm, uint8(alg))
+	}
+	if _, err := w.Write(append(magic[:], byte(alg))); err != nil {
+		return nil, err
+	}
+
+	switch alg {
+	case Gzip:
+		return gzip.NewWriterLevel(w, level)
+	case Flate:
+		return flate.NewWriter(w, level)
+	default:
+		return newLZWriter(w), nil
+	}
+}
+
+// NewReader определяет сжатие по заголовку и возвращает поток распакованных
+// данных. Данные без заголовка возвращаются без изменений (через буфер).
+func NewReader(r io.Reader) (io.Reader, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	alg, err := Detect(br)
+	if err != nil {
+		return nil, err
+	}
+
+	switch alg {
+	case None:
+		return br, nil
+	case Gzip:
+		// Внешний gzip-файл идет без нашего заголовка
+		if head, _ := br.Peek(len(magic)); bytes.Equal(head, magic[:]) {
+			br.Discard(len(magic) + 1)
+		}
+		return gzip.NewReader(br)
+	case Flate:
+		br.Discard(len(magic) + 1)
+		return flate.NewReader(br), nil
+	default:
+		br.Discard(len(magic) + 1)
+		return newLZReader(br), nil
+	}
+}
+
+// Detect определяет алгоритм по началу данных, не продвигая br
+func Detect(br *bufio.Reader) (Algorithm, error) {
+	head, _ := br.Peek(len(magic) + 1)
+	if len(head) == len(magic)+1 && bytes.Equal(head[:len(magic)], magic[:]) {
+		alg := Algorithm(head[len(magic)])
+		if _, ok := algorithmNames[alg]; !ok || alg == None {
+			return None, fmt.Errorf("%w: %d", ErrUnknownAlgorithm, uint8(alg))
+		}
+		return alg, nil
+	}
+	if bytes.HasPrefix(head, gzipMagic) {
+		return Gzip, nil
+	}
+	return None, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// errWriter возвращает ошибку создания потока при первой записи
+type errWriter struct {
+	err error
+}
+
+func (e errWriter) Write([]byte) (int, error) { return 0, e.err }
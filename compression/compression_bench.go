@@ -0,0 +1,55 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// benchData - повторяющиеся данные, похожие на текстовый снимок таблицы
+var benchData = []byte(strings.Repeat("key_000123 4567\nkey_000124 4568\n", 1<<13))
+
+// benchmarkCompress измеряет скорость сжатия и степень сжатия алгоритма.
+func benchmarkCompress(b *testing.B, alg Algorithm) {
+	var buf bytes.Buffer
+	b.SetBytes(int64(len(benchData)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		w, _ := NewWriter(&buf, alg, DefaultLevel)
+		w.Write(benchData)
+		if err := w.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(len(benchData))/float64(buf.Len()), "ratio")
+}
+
+// benchmarkDecompress измеряет скорость распаковки.
+func benchmarkDecompress(b *testing.B, alg Algorithm) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, alg, DefaultLevel)
+	w.Write(benchData)
+	w.Close()
+	compressed := buf.Bytes()
+
+	b.SetBytes(int64(len(benchData)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r, err := NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompressGzip(b *testing.B)    { benchmarkCompress(b, Gzip) }
+func BenchmarkCompressFlate(b *testing.B)   { benchmarkCompress(b, Flate) }
+func BenchmarkCompressLZ(b *testing.B)      { benchmarkCompress(b, LZ) }
+func BenchmarkDecompressGzip(b *testing.B)  { benchmarkDecompress(b, Gzip) }
+func BenchmarkDecompressFlate(b *testing.B) { benchmarkDecompress(b, Flate) }
+func BenchmarkDecompressLZ(b *testing.B)    { benchmarkDecompress(b, LZ) }
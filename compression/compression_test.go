@@ -0,0 +1,212 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Вспомогательные функции
+
+// compress сжимает data алгоритмом alg в памяти
+func compress(t *testing.T, alg Algorithm, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, alg, DefaultLevel)
+	if err != nil {
+		t.Fatalf("NewWriter(%v) failed: %v", alg, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("Write(%v) failed: %v", alg, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close(%v) failed: %v", alg, err)
+	}
+	return buf.Bytes()
+}
+
+// decompress читает все данные через NewReader
+func decompress(data []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// testInputs - данные разного вида: пустые, повторяющиеся, случайные и больше блока LZ
+func testInputs() map[string][]byte {
+	random := make([]byte, 3*blockSize+17)
+	rand.New(rand.NewSource(1)).Read(random)
+	return map[string][]byte{
+		"empty":      nil,
+		"short":      []byte("abc"),
+		"repetitive": []byte(strings.Repeat("key value 12345\n", 20000)),
+		"overlap":    bytes.Repeat([]byte{'a'}, 1000),
+		"random":     random,
+	}
+}
+
+// Основные функциональные тесты
+
+func TestRoundTrip(t *testing.T) {
+	for _, alg := range []Algorithm{None, Gzip, Flate, LZ} {
+		for name, data := range testInputs() {
+			t.Run(alg.String()+"/"+name, func(t *testing.T) {
+				got, err := decompress(compress(t, alg, data))
+				if err != nil {
+					t.Fatalf("decompress failed: %v", err)
+				}
+				if !bytes.Equal(got, data) {
+					t.Fatalf("Round trip mismatch: got %d bytes, want %d", len(got), len(data))
+				}
+			})
+		}
+	}
+}
+
+func TestCompressesRepetitiveData(t *testing.T) {
+	data := testInputs()["repetitive"]
+	for _, alg := range []Algorithm{Gzip, Flate, LZ} {
+		if n := len(compress(t, alg, data)); n > len(data)/10 {
+			t.Errorf("%v: compressed %d bytes to %d, expected at least 10x", alg, len(data), n)
+		}
+	}
+	// Несжимаемые блоки LZ хранятся как есть с небольшим заголовком
+	random := testInputs()["random"]
+	if n := len(compress(t, LZ, random)); n > len(random)+64 {
+		t.Errorf("LZ expanded random data from %d to %d bytes", len(random), n)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	var plainGzip bytes.Buffer
+	zw := gzip.NewWriter(&plainGzip)
+	zw.Write([]byte("hello"))
+	zw.Close()
+
+	cases := []struct {
+		name string
+		data []byte
+		want Algorithm
+	}{
+		{"plain", []byte("3\n1\n2\n3\n"), None},
+		{"empty", nil, None},
+		{"gzip", compress(t, Gzip, []byte("x")), Gzip},
+		{"flate", compress(t, Flate, []byte("x")), Flate},
+		{"lz", compress(t, LZ, []byte("x")), LZ},
+		{"external gzip", plainGzip.Bytes(), Gzip},
+	}
+	for _, tc := range cases {
+		br := bufio.NewReader(bytes.NewReader(tc.data))
+		alg, err := Detect(br)
+		if err != nil || alg != tc.want {
+			t.Errorf("%s: Detect = %v, %v; want %v", tc.name, alg, err, tc.want)
+		}
+		// Detect не продвигает поток
+		if rest, _ := io.ReadAll(br); !bytes.Equal(rest, tc.data) {
+			t.Errorf("%s: Detect consumed input", tc.name)
+		}
+	}
+
+	// Файл, сжатый внешним gzip, тоже читается
+	if got, err := decompress(plainGzip.Bytes()); err != nil || string(got) != "hello" {
+		t.Errorf("External gzip: got %q, %v", got, err)
+	}
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	if _, err := NewWriter(io.Discard, Algorithm(42), DefaultLevel); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Errorf("NewWriter: expected ErrUnknownAlgorithm, got %v", err)
+	}
+	data := append(magic[:], 42, 0, 0)
+	if _, err := decompress(data); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Errorf("NewReader: expected ErrUnknownAlgorithm, got %v", err)
+	}
+	if s := Algorithm(42).String(); s != "Algorithm(42)" {
+		t.Errorf("Unexpected name %q", s)
+	}
+}
+
+func TestLZCorrupt(t *testing.T) {
+	data := compress(t, LZ, testInputs()["repetitive"])
+	header := len(magic) + 1
+
+	// Обрыв в любом месте потока
+	for _, n := range []int{header, header + 1, header + 5, len(data) / 2, len(data) - 1} {
+		if _, err := decompress(data[:n]); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("Truncated at %d: expected io.ErrUnexpectedEOF, got %v", n, err)
+		}
+	}
+
+	// Испорченный байт данных ловит контрольная сумма или проверка блока
+	bad := bytes.Clone(data)
+	bad[len(bad)/2] ^= 0xFF
+	if _, err := decompress(bad); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Flipped byte: expected ErrCorrupt, got %v", err)
+	}
+
+	// Неизвестный тип блока
+	bad = bytes.Clone(data)
+	bad[header] = 7
+	if _, err := decompress(bad); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Bad flag: expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestDecompressBlockValidates(t *testing.T) {
+	cases := map[string][]byte{
+		"offset before start": {0, 4, 1},            // Совпадение без данных перед ним
+		"zero offset":         {1, 'a', 4, 0},       // Смещение 0
+		"literals overflow":   {9, 'a'},             // Литералов меньше, чем заявлено
+		"too long":            {1, 'a', 200, 1, 0},  // Больше rawLen
+		"missing end":         {1, 'a'},             // Нет завершающего нуля
+		"trailing bytes":      {1, 'a', 0, 'x'},     // Данные после конца блока
+		"short":               {1, 'a', 3, 1, 0, 0}, // Меньше rawLen
+	}
+	for name, src := range cases {
+		if _, err := decompressBlock(nil, src, 8); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%s: expected ErrCorrupt, got %v", name, err)
+		}
+	}
+}
+
+func TestWithOption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	data := []byte(strings.Repeat("persist ", 1000))
+	write := func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}
+
+	for _, alg := range []Algorithm{None, Gzip, Flate, LZ} {
+		if err := persist.WriteFile(path, write, With(alg)); err != nil {
+			t.Fatalf("%v: WriteFile failed: %v", alg, err)
+		}
+		raw, _ := os.ReadFile(path)
+		if alg == None && !bytes.Equal(raw, data) {
+			t.Errorf("None should write data as is")
+		}
+		if got, err := decompress(raw); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%v: read back %d bytes, %v", alg, len(got), err)
+		}
+	}
+
+	// Ошибка создания потока возвращается из WriteFile, старый файл цел
+	before, _ := os.ReadFile(path)
+	if err := persist.WriteFile(path, write, WithLevel(Gzip, 100)); err == nil {
+		t.Error("Expected error for bad gzip level")
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(before, after) {
+		t.Error("Failed write changed the file")
+	}
+}
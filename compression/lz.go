@@ -0,0 +1,263 @@
+package compression
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Поток LZ состоит из блоков до blockSize байт исходных данных:
+//
+//	flag(1) rawLen(uvarint) dataLen(uvarint) crc32c(4) data[dataLen]
+//
+// flag - blockStored (данные как есть) или blockLZ; blockEnd завершает поток.
+// Контрольная сумма считается по исходным данным блока.
+//
+// Сжатый блок - последовательность
+//
+//	litLen(uvarint) literals[litLen] matchLen(uvarint) [offset(uvarint)]
+//
+// matchLen == 0 бывает только в конце блока, тогда offset не пишется.
+// Совпадения ищутся жадно по хэш-таблице 4-байтовых префиксов, как в snappy.
+const (
+	blockSize = 64 << 10
+	minMatch  = 4
+	hashBits  = 14
+
+	blockStored byte = 0
+	blockLZ     byte = 1
+	blockEnd    byte = 0xFF
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// compressBlock сжимает src и дописывает результат к dst
+func compressBlock(dst, src []byte) []byte {
+	var table [1 << hashBits]int32 // Позиция+1 последнего префикса с таким хэшем
+	anchor := 0
+
+	for i := 0; i+minMatch <= len(src); {
+		seq := binary.LittleEndian.Uint32(src[i:])
+		h := (seq * 0x1e35a7bd) >> (32 - hashBits)
+		cand := int(table[h]) - 1
+		table[h] = int32(i + 1)
+
+		if cand < 0 || binary.LittleEndian.Uint32(src[cand:]) != seq {
+			i++
+			continue
+		}
+
+		n := minMatch
+		for i+n < len(src) && src[cand+n] == src[i+n] {
+			n++
+		}
+		dst = binary.AppendUvarint(dst, uint64(i-anchor))
+		dst = append(dst, src[anchor:i]...)
+		dst = binary.AppendUvarint(dst, uint64(n))
+		dst = binary.AppendUvarint(dst, uint64(i-cand))
+		i += n
+		anchor = i
+	}
+
+	dst = binary.AppendUvarint(dst, uint64(len(src)-anchor))
+	dst = append(dst, src[anchor:]...)
+	return binary.AppendUvarint(dst, 0)
+}
+
+// decompressBlock распаковывает блок, сжатый compressBlock, в rawLen байт
+// и дописывает их к dst
+func decompressBlock(dst, src []byte, rawLen int) ([]byte, error) {
+	base := len(dst)
+	for {
+		litLen, n := binary.Uvarint(src)
+		if n <= 0 || litLen > uint64(len(src)-n) || litLen > uint64(rawLen-(len(dst)-base)) {
+			return nil, fmt.Errorf("%w: bad literal length", ErrCorrupt)
+		}
+		src = src[n:]
+		dst = append(dst, src[:litLen]...)
+		src = src[litLen:]
+
+		matchLen, n := binary.Uvarint(src)
+		if n <= 0 {
+			return nil, fmt.Errorf("%w: bad match length", ErrCorrupt)
+		}
+		src = src[n:]
+		if matchLen == 0 {
+			break
+		}
+		offset, n := binary.Uvarint(src)
+		if n <= 0 || offset == 0 || offset > uint64(len(dst)-base) || matchLen > uint64(rawLen-(len(dst)-base)) {
+			return nil, fmt.Errorf("%w: bad match", ErrCorrupt)
+		}
+		src = src[n:]
+
+		// Совпадение может перекрывать само себя: копируем кусками не длиннее offset
+		from := len(dst) - int(offset)
+		for remaining := int(matchLen); remaining > 0; {
+			n := min(remaining, len(dst)-from)
+			dst = append(dst, dst[from:from+n]...)
+			from += n
+			remaining -= n
+		}
+	}
+	if len(src) != 0 || len(dst)-base != rawLen {
+		return nil, fmt.Errorf("%w: block length mismatch", ErrCorrupt)
+	}
+	return dst, nil
+}
+
+// lzWriter копит данные в блоки и пишет их сжатыми
+type lzWriter struct {
+	w   io.Writer
+	buf []byte
+	out []byte
+	err error
+}
+
+func newLZWriter(w io.Writer) *lzWriter {
+	return &lzWriter{w: w, buf: make([]byte, 0, blockSize)}
+}
+
+func (z *lzWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 && z.err == nil {
+		n := copy(z.buf[len(z.buf):blockSize], p)
+		z.buf = z.buf[:len(z.buf)+n]
+		p = p[n:]
+		written += n
+		if len(z.buf) == blockSize {
+			z.err = z.flushBlock()
+		}
+	}
+	return written, z.err
+}
+
+// Close пишет последний блок и маркер конца потока
+func (z *lzWriter) Close() error {
+	if z.err != nil {
+		return z.err
+	}
+	if len(z.buf) > 0 {
+		if z.err = z.flushBlock(); z.err != nil {
+			return z.err
+		}
+	}
+	_, z.err = z.w.Write([]byte{blockEnd})
+	if z.err == nil {
+		z.err = errClosed
+		return nil
+	}
+	return z.err
+}
+
+var errClosed = errors.New("compression: write to closed stream")
+
+func (z *lzWriter) flushBlock() error {
+	z.out = compressBlock(z.out[:0], z.buf)
+	flag, data := blockLZ, z.out
+	// Несжимаемые данные хранятся как есть
+	if len(z.out) >= len(z.buf) {
+		flag, data = blockStored, z.buf
+	}
+
+	header := make([]byte, 0, 1+2*binary.MaxVarintLen64+4)
+	header = append(header, flag)
+	header = binary.AppendUvarint(header, uint64(len(z.buf)))
+	header = binary.AppendUvarint(header, uint64(len(data)))
+	header = binary.LittleEndian.AppendUint32(header, crc32.Checksum(z.buf, castagnoli))
+	if _, err := z.w.Write(header); err != nil {
+		return err
+	}
+	if _, err := z.w.Write(data); err != nil {
+		return err
+	}
+	z.buf = z.buf[:0]
+	return nil
+}
+
+// lzReader читает блоки и отдает распакованные данные
+type lzReader struct {
+	r    *bufio.Reader
+	data []byte // Распакованный блок
+	pos  int
+	comp []byte
+	err  error
+}
+
+func newLZReader(r *bufio.Reader) *lzReader {
+	return &lzReader{r: r}
+}
+
+func (z *lzReader) Read(p []byte) (int, error) {
+	for z.pos == len(z.data) {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.err = z.readBlock()
+	}
+	n := copy(p, z.data[z.pos:])
+	z.pos += n
+	return n, nil
+}
+
+func (z *lzReader) readBlock() error {
+	flag, err := z.r.ReadByte()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if flag == blockEnd {
+		return io.EOF
+	}
+	if flag != blockStored && flag != blockLZ {
+		return fmt.Errorf("%w: bad block flag %d", ErrCorrupt, flag)
+	}
+
+	rawLen, err := binary.ReadUvarint(z.r)
+	if err != nil {
+		return unexpected(err)
+	}
+	dataLen, err := binary.ReadUvarint(z.r)
+	if err != nil {
+		return unexpected(err)
+	}
+	// Сжатый блок не бывает больше исходного: иначе он хранится как есть
+	if rawLen == 0 || rawLen > blockSize || dataLen > rawLen || (flag == blockStored && dataLen != rawLen) {
+		return fmt.Errorf("%w: bad block size", ErrCorrupt)
+	}
+	var sum [4]byte
+	if _, err := io.ReadFull(z.r, sum[:]); err != nil {
+		return unexpected(err)
+	}
+
+	if cap(z.comp) < int(dataLen) {
+		z.comp = make([]byte, dataLen)
+	}
+	z.comp = z.comp[:dataLen]
+	if _, err := io.ReadFull(z.r, z.comp); err != nil {
+		return unexpected(err)
+	}
+
+	if flag == blockStored {
+		z.data = append(z.data[:0], z.comp...)
+	} else if z.data, err = decompressBlock(z.data[:0], z.comp, int(rawLen)); err != nil {
+		return err
+	}
+	if crc32.Checksum(z.data, castagnoli) != binary.LittleEndian.Uint32(sum[:]) {
+		return fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+	}
+	z.pos = 0
+	return nil
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
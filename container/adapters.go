@@ -0,0 +1,403 @@
+package container
+
+import (
+	"cmp"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+	"github.com/D4ROVAN1E/LR_3_Go/binarytree"
+	"github.com/D4ROVAN1E/LR_3_Go/cuckoo"
+	"github.com/D4ROVAN1E/LR_3_Go/dhash"
+	"github.com/D4ROVAN1E/LR_3_Go/doublylist"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+	"github.com/D4ROVAN1E/LR_3_Go/queue"
+	"github.com/D4ROVAN1E/LR_3_Go/singlylist"
+	"github.com/D4ROVAN1E/LR_3_Go/stack"
+)
+
+// Адаптеры встраивают указатель на структуру, поэтому ее собственные методы
+// остаются доступны, а адаптер и структура работают с одними данными.
+
+// Проверки соответствия интерфейсам при компиляции
+var (
+	_ Sequence[int]    = (*ArrayAdapter[int])(nil)
+	_ Persistable      = (*ArrayAdapter[int])(nil)
+	_ Sequence[int]    = (*StackAdapter[int])(nil)
+	_ Persistable      = (*StackAdapter[int])(nil)
+	_ Sequence[int]    = (*QueueAdapter[int])(nil)
+	_ Persistable      = (*QueueAdapter[int])(nil)
+	_ Sequence[int]    = (*ForwardListAdapter[int])(nil)
+	_ Persistable      = (*ForwardListAdapter[int])(nil)
+	_ Deque[int]       = (*DoublyListAdapter[int])(nil)
+	_ Persistable      = (*DoublyListAdapter[int])(nil)
+	_ OrderedSet[int]  = (*TreeAdapter[int])(nil)
+	_ Persistable      = (*TreeAdapter[int])(nil)
+	_ Map[string, int] = (*DoubleHashAdapter[int])(nil)
+	_ Persistable      = (*DoubleHashAdapter[int])(nil)
+	_ Map[string, int] = (*CuckooAdapter[int])(nil)
+	_ Persistable      = (*CuckooAdapter[int])(nil)
+)
+
+// Array
+
+// ArrayAdapter приводит array.Array к Sequence и Persistable
+type ArrayAdapter[T any] struct {
+	*array.Array[T]
+}
+
+// AdaptArray возвращает адаптер массива a
+func AdaptArray[T any](a *array.Array[T]) *ArrayAdapter[T] {
+	return &ArrayAdapter[T]{a}
+}
+
+// Len возвращает размер массива
+func (a *ArrayAdapter[T]) Len() int { return a.GetSize() }
+
+// Clear делает массив пустым, сохраняя вместимость
+func (a *ArrayAdapter[T]) Clear() { a.SetSize(0) }
+
+// Append добавляет элемент в конец массива
+func (a *ArrayAdapter[T]) Append(v T) { a.PushBack(v) }
+
+// Stack
+
+// StackAdapter приводит stack.Stack к Sequence и Persistable.
+// Порядок - от дна к вершине, Append кладет элемент на вершину.
+type StackAdapter[T any] struct {
+	*stack.Stack[T]
+}
+
+// AdaptStack возвращает адаптер стека s
+func AdaptStack[T any](s *stack.Stack[T]) *StackAdapter[T] {
+	return &StackAdapter[T]{s}
+}
+
+// Len возвращает количество элементов стека
+func (s *StackAdapter[T]) Len() int { return s.Size() }
+
+// Clear удаляет все элементы стека
+func (s *StackAdapter[T]) Clear() { *s.Stack = *stack.NewStack[T]() }
+
+// Append кладет элемент на вершину
+func (s *StackAdapter[T]) Append(v T) { s.Push(v) }
+
+// Queue
+
+// QueueAdapter приводит queue.Queue к Sequence и Persistable.
+// Порядок - от головы к хвосту, Append добавляет в хвост.
+type QueueAdapter[T any] struct {
+	*queue.Queue[T]
+}
+
+// AdaptQueue возвращает адаптер очереди q
+func AdaptQueue[T any](q *queue.Queue[T]) *QueueAdapter[T] {
+	return &QueueAdapter[T]{q}
+}
+
+// Len возвращает количество элементов очереди
+func (q *QueueAdapter[T]) Len() int { return q.Size() }
+
+// Clear удаляет все элементы очереди
+func (q *QueueAdapter[T]) Clear() { *q.Queue = *queue.NewQueue[T](1) }
+
+// Append добавляет элемент в хвост
+func (q *QueueAdapter[T]) Append(v T) { q.Push(v) }
+
+// ForwardList
+
+// ForwardListAdapter приводит singlylist.ForwardList к Sequence и Persistable
+type ForwardListAdapter[T comparable] struct {
+	*singlylist.ForwardList[T]
+}
+
+// AdaptForwardList возвращает адаптер односвязного списка l
+func AdaptForwardList[T comparable](l *singlylist.ForwardList[T]) *ForwardListAdapter[T] {
+	return &ForwardListAdapter[T]{l}
+}
+
+// Len считает элементы списка за O(n)
+func (l *ForwardListAdapter[T]) Len() int {
+	n := 0
+	for current := l.Head; current != nil; current = current.Next {
+		n++
+	}
+	return n
+}
+
+// Clear удаляет все элементы списка
+func (l *ForwardListAdapter[T]) Clear() { l.Head = nil }
+
+// Append добавляет элемент в конец списка
+func (l *ForwardListAdapter[T]) Append(v T) { l.PushBack(v) }
+
+// SaveText сохраняет список в текстовый файл (ForwardList.Save)
+func (l *ForwardListAdapter[T]) SaveText(filename string, opts ...persist.Option) error {
+	return l.Save(filename, opts...)
+}
+
+// LoadText загружает список из текстового файла (ForwardList.Load)
+func (l *ForwardListAdapter[T]) LoadText(filename string, opts ...persist.LoadOption) error {
+	return l.Load(filename, opts...)
+}
+
+// SaveBinary сохраняет список в бинарный файл (ForwardList.Serialize)
+func (l *ForwardListAdapter[T]) SaveBinary(filename string, opts ...persist.Option) error {
+	return l.Serialize(filename, opts...)
+}
+
+// LoadBinary загружает список из бинарного файла (ForwardList.Deserialize)
+func (l *ForwardListAdapter[T]) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	return l.Deserialize(filename, opts...)
+}
+
+// DoublyList
+
+// DoublyListAdapter приводит doublylist.DoublyList к Deque и Persistable
+type DoublyListAdapter[T comparable] struct {
+	*doublylist.DoublyList[T]
+}
+
+// AdaptDoublyList возвращает адаптер двусвязного списка l
+func AdaptDoublyList[T comparable](l *doublylist.DoublyList[T]) *DoublyListAdapter[T] {
+	return &DoublyListAdapter[T]{l}
+}
+
+// Len считает элементы списка за O(n)
+func (l *DoublyListAdapter[T]) Len() int {
+	n := 0
+	for current := l.Head; current != nil; current = current.Next {
+		n++
+	}
+	return n
+}
+
+// Clear удаляет все элементы списка
+func (l *DoublyListAdapter[T]) Clear() { l.Head, l.Tail = nil, nil }
+
+// Append добавляет элемент в конец списка
+func (l *DoublyListAdapter[T]) Append(v T) { l.LPushBack(v) }
+
+// PushFront добавляет элемент в начало списка
+func (l *DoublyListAdapter[T]) PushFront(v T) { l.LPushHead(v) }
+
+// PopFront извлекает первый элемент
+func (l *DoublyListAdapter[T]) PopFront() (T, error) {
+	if l.Head == nil {
+		var zero T
+		return zero, ErrEmpty
+	}
+	v := l.Head.Key
+	return v, l.LDelHead()
+}
+
+// PopBack извлекает последний элемент
+func (l *DoublyListAdapter[T]) PopBack() (T, error) {
+	if l.Tail == nil {
+		var zero T
+		return zero, ErrEmpty
+	}
+	v := l.Tail.Key
+	return v, l.LDelBack()
+}
+
+// SaveText сохраняет список в текстовый файл (DoublyList.LSave)
+func (l *DoublyListAdapter[T]) SaveText(filename string, opts ...persist.Option) error {
+	return l.LSave(filename, opts...)
+}
+
+// LoadText загружает список из текстового файла (DoublyList.LLoad)
+func (l *DoublyListAdapter[T]) LoadText(filename string, opts ...persist.LoadOption) error {
+	return l.LLoad(filename, opts...)
+}
+
+// SaveBinary сохраняет список в бинарный файл (DoublyList.LSaveBin)
+func (l *DoublyListAdapter[T]) SaveBinary(filename string, opts ...persist.Option) error {
+	return l.LSaveBin(filename, opts...)
+}
+
+// LoadBinary загружает список из бинарного файла (DoublyList.LLoadBin)
+func (l *DoublyListAdapter[T]) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	return l.LLoadBin(filename, opts...)
+}
+
+// FullBinaryTree
+
+// TreeAdapter приводит binarytree.FullBinaryTree к OrderedSet и Persistable.
+// Add не вставляет повторы, поэтому дерево, заполненное через адаптер,
+// остается множеством.
+type TreeAdapter[T cmp.Ordered] struct {
+	*binarytree.FullBinaryTree[T]
+}
+
+// AdaptTree возвращает адаптер дерева t
+func AdaptTree[T cmp.Ordered](t *binarytree.FullBinaryTree[T]) *TreeAdapter[T] {
+	return &TreeAdapter[T]{t}
+}
+
+// Len считает узлы дерева за O(n)
+func (t *TreeAdapter[T]) Len() int {
+	return countNodes(t.GetRoot())
+}
+
+func countNodes[T cmp.Ordered](node *binarytree.TreeNode[T]) int {
+	if node == nil {
+		return 0
+	}
+	return 1 + countNodes(node.Left) + countNodes(node.Right)
+}
+
+// Add вставляет элемент, если его еще нет
+func (t *TreeAdapter[T]) Add(v T) bool {
+	if t.Contains(v) {
+		return false
+	}
+	t.Insert(v)
+	return true
+}
+
+// Contains ищет элемент по правилу BST за O(h)
+func (t *TreeAdapter[T]) Contains(v T) bool {
+	node := t.GetRoot()
+	for node != nil {
+		switch {
+		case v == node.Key:
+			return true
+		case v < node.Key:
+			node = node.Left
+		default:
+			node = node.Right
+		}
+	}
+	return false
+}
+
+// Min возвращает наименьший элемент
+func (t *TreeAdapter[T]) Min() (T, bool) {
+	node := t.GetRoot()
+	if node == nil {
+		var zero T
+		return zero, false
+	}
+	for node.Left != nil {
+		node = node.Left
+	}
+	return node.Key, true
+}
+
+// Max возвращает наибольший элемент
+func (t *TreeAdapter[T]) Max() (T, bool) {
+	node := t.GetRoot()
+	if node == nil {
+		var zero T
+		return zero, false
+	}
+	for node.Right != nil {
+		node = node.Right
+	}
+	return node.Key, true
+}
+
+// DoubleHash
+
+// DoubleHashAdapter приводит dhash.DoubleHash к Map и Persistable
+type DoubleHashAdapter[V any] struct {
+	*dhash.DoubleHash[V]
+}
+
+// AdaptDoubleHash возвращает адаптер таблицы dh
+func AdaptDoubleHash[V any](dh *dhash.DoubleHash[V]) *DoubleHashAdapter[V] {
+	return &DoubleHashAdapter[V]{dh}
+}
+
+// Len возвращает количество пар
+func (dh *DoubleHashAdapter[V]) Len() int { return int(dh.Size()) }
+
+// Put вставляет пару или обновляет значение (DoubleHash.Insert)
+func (dh *DoubleHashAdapter[V]) Put(key string, value V) error {
+	return dh.Insert(key, value)
+}
+
+// Get возвращает значение по ключу
+func (dh *DoubleHashAdapter[V]) Get(key string) (V, bool) {
+	if v := dh.Find(key); v != nil {
+		return *v, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete удаляет ключ (DoubleHash.Remove). В режиме журнала false означает
+// и сбой записи: тогда ключ остается в таблице, а ошибка доступна через WALError.
+func (dh *DoubleHashAdapter[V]) Delete(key string) bool { return dh.Remove(key) }
+
+// SaveText сохраняет таблицу в текстовый файл (DoubleHash.SerializeText)
+func (dh *DoubleHashAdapter[V]) SaveText(filename string, opts ...persist.Option) error {
+	return dh.SerializeText(filename, opts...)
+}
+
+// LoadText загружает таблицу из текстового файла (DoubleHash.DeserializeText)
+func (dh *DoubleHashAdapter[V]) LoadText(filename string, opts ...persist.LoadOption) error {
+	return dh.DeserializeText(filename, opts...)
+}
+
+// SaveBinary сохраняет таблицу в бинарный файл (DoubleHash.SerializeBin)
+func (dh *DoubleHashAdapter[V]) SaveBinary(filename string, opts ...persist.Option) error {
+	return dh.SerializeBin(filename, opts...)
+}
+
+// LoadBinary загружает таблицу из бинарного файла (DoubleHash.DeserializeBin)
+func (dh *DoubleHashAdapter[V]) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	return dh.DeserializeBin(filename, opts...)
+}
+
+// CuckooHash
+
+// CuckooAdapter приводит cuckoo.CuckooHash к Map и Persistable
+type CuckooAdapter[V any] struct {
+	*cuckoo.CuckooHash[V]
+}
+
+// AdaptCuckoo возвращает адаптер таблицы ch
+func AdaptCuckoo[V any](ch *cuckoo.CuckooHash[V]) *CuckooAdapter[V] {
+	return &CuckooAdapter[V]{ch}
+}
+
+// Len возвращает количество пар
+func (ch *CuckooAdapter[V]) Len() int { return int(ch.Size()) }
+
+// Put вставляет пару или обновляет значение (CuckooHash.Insert)
+func (ch *CuckooAdapter[V]) Put(key string, value V) error {
+	return ch.Insert(key, value)
+}
+
+// Get возвращает значение по ключу
+func (ch *CuckooAdapter[V]) Get(key string) (V, bool) {
+	if v := ch.Find(key); v != nil {
+		return *v, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete удаляет ключ (CuckooHash.Remove). В режиме журнала false означает
+// и сбой записи: тогда ключ остается в таблице, а ошибка доступна через WALError.
+func (ch *CuckooAdapter[V]) Delete(key string) bool { return ch.Remove(key) }
+
+// SaveText сохраняет таблицу в текстовый файл (CuckooHash.SerializeText)
+func (ch *CuckooAdapter[V]) SaveText(filename string, opts ...persist.Option) error {
+	return ch.SerializeText(filename, opts...)
+}
+
+// LoadText загружает таблицу из текстового файла (CuckooHash.DeserializeText)
+func (ch *CuckooAdapter[V]) LoadText(filename string, opts ...persist.LoadOption) error {
+	return ch.DeserializeText(filename, opts...)
+}
+
+// SaveBinary сохраняет таблицу в бинарный файл (CuckooHash.SerializeBin)
+func (ch *CuckooAdapter[V]) SaveBinary(filename string, opts ...persist.Option) error {
+	return ch.SerializeBin(filename, opts...)
+}
+
+// LoadBinary загружает таблицу из бинарного файла (CuckooHash.DeserializeBin)
+func (ch *CuckooAdapter[V]) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	return ch.DeserializeBin(filename, opts...)
+}
@@ -0,0 +1,73 @@
+// Package container описывает общие интерфейсы структур данных. Пакеты
+// структур исторически называют одни и те же операции по-разному (GetSize и
+// Size, PushBack и LPushBack, Save и SerializeText), поэтому к интерфейсам их
+// приводят адаптеры из adapters.go.
+package container
+
+import (
+	"cmp"
+	"errors"
+
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// ErrEmpty - извлечение из пустого контейнера
+var ErrEmpty = errors.New("container is empty")
+
+// Container - общие операции всех контейнеров
+type Container interface {
+	// Len возвращает количество элементов
+	Len() int
+	// Clear удаляет все элементы
+	Clear()
+}
+
+// Sequence - контейнер с порядком элементов. Append добавляет элемент в
+// конец порядка ToSlice: для стека это вершина, для очереди - хвост.
+type Sequence[T any] interface {
+	Container
+	Append(v T)
+	ToSlice() []T
+}
+
+// Deque - последовательность с добавлением и извлечением с обоих концов.
+// PopFront и PopBack на пустом деке возвращают ErrEmpty.
+type Deque[T any] interface {
+	Sequence[T]
+	PushFront(v T)
+	PopFront() (T, error)
+	PopBack() (T, error)
+}
+
+// Map - ассоциативный массив
+type Map[K comparable, V any] interface {
+	Container
+	// Put вставляет пару или обновляет значение
+	Put(key K, value V) error
+	Get(key K) (V, bool)
+	// Delete удаляет ключ и сообщает, был ли он
+	Delete(key K) bool
+	// Range обходит пары, пока fn возвращает true
+	Range(fn func(key K, value V) bool)
+}
+
+// OrderedSet - множество с порядком элементов
+type OrderedSet[T cmp.Ordered] interface {
+	Container
+	// Add добавляет элемент и сообщает, что его не было
+	Add(v T) bool
+	Contains(v T) bool
+	// Min и Max возвращают false для пустого множества
+	Min() (T, bool)
+	Max() (T, bool)
+	// ToSlice возвращает элементы по возрастанию
+	ToSlice() []T
+}
+
+// Persistable - контейнер, который сохраняется в файл и загружается из него
+type Persistable interface {
+	SaveText(filename string, opts ...persist.Option) error
+	LoadText(filename string, opts ...persist.LoadOption) error
+	SaveBinary(filename string, opts ...persist.Option) error
+	LoadBinary(filename string, opts ...persist.LoadOption) error
+}
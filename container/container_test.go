@@ -0,0 +1,137 @@
+package container_test
+
+import (
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+	"github.com/D4ROVAN1E/LR_3_Go/binarytree"
+	"github.com/D4ROVAN1E/LR_3_Go/container"
+	"github.com/D4ROVAN1E/LR_3_Go/container/containertest"
+	"github.com/D4ROVAN1E/LR_3_Go/cuckoo"
+	"github.com/D4ROVAN1E/LR_3_Go/dhash"
+	"github.com/D4ROVAN1E/LR_3_Go/doublylist"
+	"github.com/D4ROVAN1E/LR_3_Go/queue"
+	"github.com/D4ROVAN1E/LR_3_Go/singlylist"
+	"github.com/D4ROVAN1E/LR_3_Go/stack"
+)
+
+func newDoubleHash() *container.DoubleHashAdapter[int] {
+	dh, err := dhash.NewDoubleHash[int](7)
+	if err != nil {
+		panic(err)
+	}
+	return container.AdaptDoubleHash(dh)
+}
+
+func TestSequences(t *testing.T) {
+	t.Run("Array", func(t *testing.T) {
+		containertest.Sequence(t, func() container.Sequence[int] { return container.AdaptArray(array.NewArray[int]()) })
+	})
+	t.Run("Stack", func(t *testing.T) {
+		containertest.Sequence(t, func() container.Sequence[int] { return container.AdaptStack(stack.NewStack[int]()) })
+	})
+	t.Run("Queue", func(t *testing.T) {
+		containertest.Sequence(t, func() container.Sequence[int] { return container.AdaptQueue(queue.NewQueue[int](1)) })
+	})
+	t.Run("ForwardList", func(t *testing.T) {
+		containertest.Sequence(t, func() container.Sequence[int] {
+			return container.AdaptForwardList(singlylist.NewForwardList[int]())
+		})
+	})
+}
+
+func TestDeque(t *testing.T) {
+	containertest.Deque(t, func() container.Deque[int] {
+		return container.AdaptDoublyList(doublylist.NewDoublyList[int]())
+	})
+}
+
+func TestMaps(t *testing.T) {
+	t.Run("DoubleHash", func(t *testing.T) {
+		containertest.Map(t, func() container.Map[string, int] { return newDoubleHash() })
+	})
+	t.Run("Cuckoo", func(t *testing.T) {
+		containertest.Map(t, func() container.Map[string, int] {
+			return container.AdaptCuckoo(cuckoo.NewCuckooHash[int](7))
+		})
+	})
+}
+
+func TestOrderedSet(t *testing.T) {
+	containertest.OrderedSet(t, func() container.OrderedSet[int] {
+		return container.AdaptTree(binarytree.NewFullBinaryTree[int]())
+	})
+}
+
+func fillSeq[S container.Sequence[int]](s S) {
+	for _, v := range []int{4, -2, 7, 0, 7} {
+		s.Append(v)
+	}
+}
+
+func seqSnapshot[S container.Sequence[int]](s S) any { return s.ToSlice() }
+
+func fillMap[M container.Map[string, int]](m M) {
+	for i, k := range []string{"one", "two", "three", "four"} {
+		_ = m.Put(k, i+1)
+	}
+}
+
+func TestPersistable(t *testing.T) {
+	t.Run("Array", func(t *testing.T) {
+		containertest.Persistable(t, func() *container.ArrayAdapter[int] {
+			return container.AdaptArray(array.NewArray[int]())
+		}, fillSeq, seqSnapshot)
+	})
+	t.Run("Stack", func(t *testing.T) {
+		containertest.Persistable(t, func() *container.StackAdapter[int] {
+			return container.AdaptStack(stack.NewStack[int]())
+		}, fillSeq, seqSnapshot)
+	})
+	t.Run("Queue", func(t *testing.T) {
+		containertest.Persistable(t, func() *container.QueueAdapter[int] {
+			return container.AdaptQueue(queue.NewQueue[int](1))
+		}, fillSeq, seqSnapshot)
+	})
+	t.Run("ForwardList", func(t *testing.T) {
+		containertest.Persistable(t, func() *container.ForwardListAdapter[int] {
+			return container.AdaptForwardList(singlylist.NewForwardList[int]())
+		}, fillSeq, seqSnapshot)
+	})
+	t.Run("DoublyList", func(t *testing.T) {
+		containertest.Persistable(t, func() *container.DoublyListAdapter[int] {
+			return container.AdaptDoublyList(doublylist.NewDoublyList[int]())
+		}, fillSeq, seqSnapshot)
+	})
+	t.Run("Tree", func(t *testing.T) {
+		containertest.Persistable(t, func() *container.TreeAdapter[int] {
+			return container.AdaptTree(binarytree.NewFullBinaryTree[int]())
+		}, func(s *container.TreeAdapter[int]) {
+			for _, v := range []int{50, 30, 70, 20, 40} {
+				s.Add(v)
+			}
+		}, func(s *container.TreeAdapter[int]) any { return s.ToSlice() })
+	})
+	t.Run("DoubleHash", func(t *testing.T) {
+		containertest.Persistable(t, newDoubleHash, fillMap, containertest.MapSnapshot)
+	})
+	t.Run("Cuckoo", func(t *testing.T) {
+		containertest.Persistable(t, func() *container.CuckooAdapter[int] {
+			return container.AdaptCuckoo(cuckoo.NewCuckooHash[int](7))
+		}, fillMap, containertest.MapSnapshot)
+	})
+}
+
+func TestAdapterSharesData(t *testing.T) {
+	a := array.NewArray[int]()
+	adapter := container.AdaptArray(a)
+	adapter.Append(1)
+	adapter.Append(2)
+	if a.GetSize() != 2 {
+		t.Errorf("underlying GetSize() = %d, want 2", a.GetSize())
+	}
+	adapter.Clear()
+	if a.GetSize() != 0 {
+		t.Errorf("underlying GetSize() after Clear = %d, want 0", a.GetSize())
+	}
+}
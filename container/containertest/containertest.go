@@ -0,0 +1,331 @@
+// Package containertest содержит тесты соответствия интерфейсам пакета
+// container. Каждая функция проверяет контракт интерфейса на реализации,
+// которую создает переданный конструктор.
+package containertest
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/container"
+)
+
+// Sequence проверяет контракт container.Sequence. newSeq должен возвращать
+// пустую последовательность.
+func Sequence(t *testing.T, newSeq func() container.Sequence[int]) {
+	t.Helper()
+
+	t.Run("Empty", func(t *testing.T) {
+		s := newSeq()
+		if s.Len() != 0 {
+			t.Errorf("Len() = %d, want 0", s.Len())
+		}
+		if got := s.ToSlice(); len(got) != 0 {
+			t.Errorf("ToSlice() = %v, want empty", got)
+		}
+	})
+
+	t.Run("AppendKeepsOrder", func(t *testing.T) {
+		s := newSeq()
+		want := []int{5, 3, 8, 3, 1}
+		for i, v := range want {
+			s.Append(v)
+			if s.Len() != i+1 {
+				t.Fatalf("Len() after %d appends = %d", i+1, s.Len())
+			}
+		}
+		if got := s.ToSlice(); !slices.Equal(got, want) {
+			t.Errorf("ToSlice() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("ClearAndReuse", func(t *testing.T) {
+		s := newSeq()
+		for i := range 100 {
+			s.Append(i)
+		}
+		s.Clear()
+		if s.Len() != 0 {
+			t.Errorf("Len() after Clear = %d, want 0", s.Len())
+		}
+		s.Append(42)
+		if got := s.ToSlice(); !slices.Equal(got, []int{42}) {
+			t.Errorf("ToSlice() after Clear and Append = %v, want [42]", got)
+		}
+	})
+}
+
+// Deque проверяет контракт container.Deque, включая контракт Sequence.
+// newDeque должен возвращать пустой дек.
+func Deque(t *testing.T, newDeque func() container.Deque[int]) {
+	t.Helper()
+	Sequence(t, func() container.Sequence[int] { return newDeque() })
+
+	t.Run("PopEmpty", func(t *testing.T) {
+		d := newDeque()
+		if _, err := d.PopFront(); !errors.Is(err, container.ErrEmpty) {
+			t.Errorf("PopFront() error = %v, want ErrEmpty", err)
+		}
+		if _, err := d.PopBack(); !errors.Is(err, container.ErrEmpty) {
+			t.Errorf("PopBack() error = %v, want ErrEmpty", err)
+		}
+	})
+
+	t.Run("BothEnds", func(t *testing.T) {
+		d := newDeque()
+		d.Append(2)
+		d.PushFront(1)
+		d.Append(3)
+		d.PushFront(0)
+		if got := d.ToSlice(); !slices.Equal(got, []int{0, 1, 2, 3}) {
+			t.Fatalf("ToSlice() = %v, want [0 1 2 3]", got)
+		}
+
+		if v, err := d.PopFront(); err != nil || v != 0 {
+			t.Errorf("PopFront() = %d, %v, want 0, nil", v, err)
+		}
+		if v, err := d.PopBack(); err != nil || v != 3 {
+			t.Errorf("PopBack() = %d, %v, want 3, nil", v, err)
+		}
+		if d.Len() != 2 {
+			t.Errorf("Len() = %d, want 2", d.Len())
+		}
+		if v, err := d.PopBack(); err != nil || v != 2 {
+			t.Errorf("PopBack() = %d, %v, want 2, nil", v, err)
+		}
+		if v, err := d.PopFront(); err != nil || v != 1 {
+			t.Errorf("PopFront() = %d, %v, want 1, nil", v, err)
+		}
+		if _, err := d.PopFront(); !errors.Is(err, container.ErrEmpty) {
+			t.Errorf("PopFront() on drained deque error = %v, want ErrEmpty", err)
+		}
+	})
+}
+
+// Map проверяет контракт container.Map. newMap должен возвращать пустую таблицу.
+func Map(t *testing.T, newMap func() container.Map[string, int]) {
+	t.Helper()
+
+	t.Run("PutGet", func(t *testing.T) {
+		m := newMap()
+		if _, ok := m.Get("missing"); ok {
+			t.Error("Get on empty map reported a value")
+		}
+		for i := range 50 {
+			if err := m.Put(key(i), i); err != nil {
+				t.Fatalf("Put(%q) error: %v", key(i), err)
+			}
+		}
+		if m.Len() != 50 {
+			t.Errorf("Len() = %d, want 50", m.Len())
+		}
+		for i := range 50 {
+			if v, ok := m.Get(key(i)); !ok || v != i {
+				t.Errorf("Get(%q) = %d, %t, want %d, true", key(i), v, ok, i)
+			}
+		}
+	})
+
+	t.Run("PutUpdates", func(t *testing.T) {
+		m := newMap()
+		_ = m.Put("a", 1)
+		if err := m.Put("a", 2); err != nil {
+			t.Fatalf("Put update error: %v", err)
+		}
+		if v, _ := m.Get("a"); v != 2 {
+			t.Errorf("Get after update = %d, want 2", v)
+		}
+		if m.Len() != 1 {
+			t.Errorf("Len() after update = %d, want 1", m.Len())
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		m := newMap()
+		_ = m.Put("a", 1)
+		_ = m.Put("b", 2)
+		if !m.Delete("a") {
+			t.Error("Delete of existing key returned false")
+		}
+		if m.Delete("a") {
+			t.Error("second Delete returned true")
+		}
+		if _, ok := m.Get("a"); ok {
+			t.Error("deleted key is still found")
+		}
+		if v, ok := m.Get("b"); !ok || v != 2 {
+			t.Errorf("Get(b) after Delete(a) = %d, %t", v, ok)
+		}
+		if m.Len() != 1 {
+			t.Errorf("Len() = %d, want 1", m.Len())
+		}
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		m := newMap()
+		want := make(map[string]int)
+		for i := range 20 {
+			_ = m.Put(key(i), i)
+			want[key(i)] = i
+		}
+		if got := collect(m); !reflect.DeepEqual(got, want) {
+			t.Errorf("Range visited %v, want %v", got, want)
+		}
+
+		calls := 0
+		m.Range(func(string, int) bool {
+			calls++
+			return false
+		})
+		if calls != 1 {
+			t.Errorf("Range after false made %d calls, want 1", calls)
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		m := newMap()
+		for i := range 10 {
+			_ = m.Put(key(i), i)
+		}
+		m.Clear()
+		if m.Len() != 0 {
+			t.Errorf("Len() after Clear = %d, want 0", m.Len())
+		}
+		if _, ok := m.Get(key(0)); ok {
+			t.Error("Get after Clear found a value")
+		}
+		if err := m.Put("x", 1); err != nil {
+			t.Fatalf("Put after Clear error: %v", err)
+		}
+		if v, ok := m.Get("x"); !ok || v != 1 {
+			t.Errorf("Get after Clear and Put = %d, %t", v, ok)
+		}
+	})
+}
+
+// OrderedSet проверяет контракт container.OrderedSet. newSet должен
+// возвращать пустое множество.
+func OrderedSet(t *testing.T, newSet func() container.OrderedSet[int]) {
+	t.Helper()
+
+	t.Run("Empty", func(t *testing.T) {
+		s := newSet()
+		if s.Len() != 0 {
+			t.Errorf("Len() = %d, want 0", s.Len())
+		}
+		if _, ok := s.Min(); ok {
+			t.Error("Min on empty set reported a value")
+		}
+		if _, ok := s.Max(); ok {
+			t.Error("Max on empty set reported a value")
+		}
+	})
+
+	t.Run("AddContains", func(t *testing.T) {
+		s := newSet()
+		input := []int{50, 20, 80, 20, 10, 90, 50, 60}
+		want := []int{10, 20, 50, 60, 80, 90}
+		added := 0
+		for _, v := range input {
+			if s.Add(v) {
+				added++
+			}
+		}
+		if added != len(want) || s.Len() != len(want) {
+			t.Errorf("added %d, Len() = %d, want %d", added, s.Len(), len(want))
+		}
+		if got := s.ToSlice(); !slices.Equal(got, want) {
+			t.Errorf("ToSlice() = %v, want %v", got, want)
+		}
+		for _, v := range want {
+			if !s.Contains(v) {
+				t.Errorf("Contains(%d) = false", v)
+			}
+		}
+		if s.Contains(55) {
+			t.Error("Contains(55) = true")
+		}
+		if v, ok := s.Min(); !ok || v != 10 {
+			t.Errorf("Min() = %d, %t, want 10, true", v, ok)
+		}
+		if v, ok := s.Max(); !ok || v != 90 {
+			t.Errorf("Max() = %d, %t, want 90, true", v, ok)
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		s := newSet()
+		s.Add(1)
+		s.Add(2)
+		s.Clear()
+		if s.Len() != 0 || s.Contains(1) {
+			t.Errorf("after Clear Len() = %d, Contains(1) = %t", s.Len(), s.Contains(1))
+		}
+		if !s.Add(1) {
+			t.Error("Add after Clear returned false")
+		}
+	})
+}
+
+// Persistable проверяет, что контейнер переживает сохранение и загрузку в
+// обоих форматах. newP возвращает пустой контейнер, fill заполняет его,
+// snapshot возвращает содержимое в виде, пригодном для reflect.DeepEqual.
+func Persistable[P container.Persistable](t *testing.T, newP func() P, fill func(P), snapshot func(P) any) {
+	t.Helper()
+
+	formats := []struct {
+		name string
+		save func(P, string) error
+		load func(P, string) error
+	}{
+		{"Text", func(p P, f string) error { return p.SaveText(f) }, func(p P, f string) error { return p.LoadText(f) }},
+		{"Binary", func(p P, f string) error { return p.SaveBinary(f) }, func(p P, f string) error { return p.LoadBinary(f) }},
+	}
+
+	for _, format := range formats {
+		t.Run(format.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "container.dat")
+			src := newP()
+			fill(src)
+			if err := format.save(src, path); err != nil {
+				t.Fatalf("save error: %v", err)
+			}
+
+			dst := newP()
+			if err := format.load(dst, path); err != nil {
+				t.Fatalf("load error: %v", err)
+			}
+			if got, want := snapshot(dst), snapshot(src); !reflect.DeepEqual(got, want) {
+				t.Errorf("loaded %v, want %v", got, want)
+			}
+		})
+
+		t.Run(format.name+"MissingFile", func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "missing.dat")
+			if err := format.load(newP(), path); err == nil {
+				t.Error("load of missing file returned nil error")
+			}
+		})
+	}
+}
+
+// MapSnapshot собирает пары таблицы в map для сравнения независимо от порядка
+func MapSnapshot[M container.Map[string, int]](m M) any {
+	return collect(m)
+}
+
+func collect(m container.Map[string, int]) map[string]int {
+	result := make(map[string]int)
+	m.Range(func(k string, v int) bool {
+		result[k] = v
+		return true
+	})
+	return result
+}
+
+func key(i int) string {
+	return "key-" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+}
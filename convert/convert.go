@@ -0,0 +1,136 @@
+// Package convert переносит данные между контейнерами без промежуточных циклов
+// в вызывающем коде. Каждая функция создает новый контейнер, исходный не меняется.
+package convert
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+	"github.com/D4ROVAN1E/LR_3_Go/binarytree"
+	"github.com/D4ROVAN1E/LR_3_Go/cuckoo"
+	"github.com/D4ROVAN1E/LR_3_Go/dhash"
+	"github.com/D4ROVAN1E/LR_3_Go/doublylist"
+	"github.com/D4ROVAN1E/LR_3_Go/queue"
+	"github.com/D4ROVAN1E/LR_3_Go/singlylist"
+	"github.com/D4ROVAN1E/LR_3_Go/stack"
+)
+
+// KeyRanger - хэш-таблица с обходом пар (DoubleHash, CuckooHash)
+type KeyRanger[V any] interface {
+	Range(fn func(key string, value V) bool)
+}
+
+// Стек и очередь сохраняют порядок извлечения: Pop нового контейнера
+// возвращает элементы в том же порядке, что и Pop исходного.
+
+// StackToQueue создает очередь, в голове которой вершина стека
+func StackToQueue[T any](s *stack.Stack[T]) *queue.Queue[T] {
+	data := s.ToSlice()
+	slices.Reverse(data)
+	return queue.FromSlice(data)
+}
+
+// QueueToStack создает стек, на вершине которого голова очереди
+func QueueToStack[T any](q *queue.Queue[T]) *stack.Stack[T] {
+	data := q.ToSlice()
+	slices.Reverse(data)
+	return stack.FromSlice(data)
+}
+
+// Линейные структуры сохраняют порядок: голова списка - нулевой элемент массива.
+
+// ArrayToForwardList создает односвязный список из элементов массива
+func ArrayToForwardList[T comparable](a *array.Array[T]) *singlylist.ForwardList[T] {
+	return singlylist.FromSlice(a.ToSlice())
+}
+
+// ForwardListToArray создает массив из элементов списка от головы к хвосту
+func ForwardListToArray[T comparable](l *singlylist.ForwardList[T]) *array.Array[T] {
+	return array.FromSlice(l.ToSlice())
+}
+
+// ArrayToDoublyList создает двусвязный список из элементов массива
+func ArrayToDoublyList[T comparable](a *array.Array[T]) *doublylist.DoublyList[T] {
+	return doublylist.FromSlice(a.ToSlice())
+}
+
+// DoublyListToArray создает массив из элементов списка от головы к хвосту
+func DoublyListToArray[T comparable](l *doublylist.DoublyList[T]) *array.Array[T] {
+	return array.FromSlice(l.ToSlice())
+}
+
+// ForwardListToDoublyList создает двусвязный список с теми же элементами
+func ForwardListToDoublyList[T comparable](l *singlylist.ForwardList[T]) *doublylist.DoublyList[T] {
+	dl := doublylist.NewDoublyList[T]()
+	for current := l.Head; current != nil; current = current.Next {
+		dl.LPushBack(current.Key)
+	}
+	return dl
+}
+
+// DoublyListToForwardList создает односвязный список с теми же элементами
+func DoublyListToForwardList[T comparable](l *doublylist.DoublyList[T]) *singlylist.ForwardList[T] {
+	return singlylist.FromSlice(l.ToSlice())
+}
+
+// Дерево
+
+// ArrayToTree строит сбалансированное дерево поиска из элементов массива.
+// Отсортированный массив превращается в дерево за O(n), остальные
+// сортируются (копия) за O(n log n).
+func ArrayToTree[T cmp.Ordered](a *array.Array[T]) *binarytree.FullBinaryTree[T] {
+	data := a.ToSlice()
+	if t, err := binarytree.FromSorted(data); err == nil {
+		return t
+	}
+	return binarytree.FromSlice(data)
+}
+
+// TreeToArray создает массив из ключей дерева по возрастанию
+func TreeToArray[T cmp.Ordered](t *binarytree.FullBinaryTree[T]) *array.Array[T] {
+	return array.FromSlice(t.ToSlice())
+}
+
+// KeysToTree строит сбалансированное дерево из ключей хэш-таблицы
+func KeysToTree[V any](h KeyRanger[V]) *binarytree.FullBinaryTree[string] {
+	return binarytree.FromSlice(keys(h))
+}
+
+// KeysToArray создает массив из ключей хэш-таблицы по возрастанию
+func KeysToArray[V any](h KeyRanger[V]) *array.Array[string] {
+	sorted := keys(h)
+	slices.Sort(sorted)
+	return array.FromSlice(sorted)
+}
+
+func keys[V any](h KeyRanger[V]) []string {
+	var result []string
+	h.Range(func(key string, _ V) bool {
+		result = append(result, key)
+		return true
+	})
+	return result
+}
+
+// Хэш-таблицы
+
+// DoubleHashToCuckoo создает кукушкину таблицу с теми же парами
+func DoubleHashToCuckoo[V any](dh *dhash.DoubleHash[V]) *cuckoo.CuckooHash[V] {
+	entries := dh.ToSlice()
+	converted := make([]cuckoo.HashNode[V], len(entries))
+	for i, e := range entries {
+		converted[i] = cuckoo.HashNode[V]{Key: e.Key, Value: e.Value, IsOccupied: true}
+	}
+	return cuckoo.FromSlice(converted)
+}
+
+// CuckooToDoubleHash создает таблицу с двойным хэшированием с теми же парами
+func CuckooToDoubleHash[V any](ch *cuckoo.CuckooHash[V]) (*dhash.DoubleHash[V], error) {
+	entries := ch.ToSlice()
+	converted := make([]dhash.HashNode[V], len(entries))
+	for i, e := range entries {
+		converted[i] = dhash.HashNode[V]{Key: e.Key, Value: e.Value, IsOccupied: true}
+	}
+	return dhash.FromSlice(converted)
+}
@@ -0,0 +1,40 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+	"github.com/D4ROVAN1E/LR_3_Go/binarytree"
+)
+
+const TreeSize = 100000
+
+// sortedArray создает отсортированный массив из n чисел
+func sortedArray(n int) *array.Array[int] {
+	arr := array.NewArray[int]()
+	for i := 0; i < n; i++ {
+		arr.PushBack(i)
+	}
+	return arr
+}
+
+// BenchmarkArrayToTree строит сбалансированное дерево из отсортированного массива за O(n).
+func BenchmarkArrayToTree(b *testing.B) {
+	arr := sortedArray(TreeSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ArrayToTree(arr)
+	}
+}
+
+// BenchmarkInsertSorted для сравнения вставляет те же числа по одному.
+// Отсортированные ключи вырождают дерево в список, поэтому берется меньше элементов.
+func BenchmarkInsertSorted(b *testing.B) {
+	const n = TreeSize / 20
+	for i := 0; i < b.N; i++ {
+		tree := binarytree.NewFullBinaryTree[int]()
+		for j := 0; j < n; j++ {
+			tree.Insert(j)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package convert
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+	"github.com/D4ROVAN1E/LR_3_Go/binarytree"
+	"github.com/D4ROVAN1E/LR_3_Go/cuckoo"
+	"github.com/D4ROVAN1E/LR_3_Go/dhash"
+	"github.com/D4ROVAN1E/LR_3_Go/doublylist"
+	"github.com/D4ROVAN1E/LR_3_Go/queue"
+	"github.com/D4ROVAN1E/LR_3_Go/singlylist"
+	"github.com/D4ROVAN1E/LR_3_Go/stack"
+)
+
+// Вспомогательные функции
+
+// height возвращает высоту поддерева
+func height(node *binarytree.TreeNode[int]) int {
+	if node == nil {
+		return 0
+	}
+	return 1 + max(height(node.Left), height(node.Right))
+}
+
+// Основные функциональные тесты
+
+func TestStackQueue(t *testing.T) {
+	s := stack.FromSlice([]int{1, 2, 3}) // Вершина - 3
+	q := StackToQueue(s)
+	for _, want := range []int{3, 2, 1} {
+		if got, _ := q.Pop(); got != want {
+			t.Errorf("StackToQueue: expected %d, got %d", want, got)
+		}
+	}
+	if s.Size() != 3 {
+		t.Error("Source stack changed")
+	}
+
+	back := QueueToStack(queue.FromSlice([]int{1, 2, 3})) // Голова - 1
+	for _, want := range []int{1, 2, 3} {
+		if got, _ := back.Pop(); got != want {
+			t.Errorf("QueueToStack: expected %d, got %d", want, got)
+		}
+	}
+}
+
+func TestLinear(t *testing.T) {
+	want := []string{"a", "b", "c"}
+	arr := array.FromSlice(want)
+
+	fl := ArrayToForwardList(arr)
+	if got := ForwardListToArray(fl).ToSlice(); !slices.Equal(got, want) {
+		t.Errorf("ForwardList round trip = %v", got)
+	}
+	dl := ArrayToDoublyList(arr)
+	if got := DoublyListToArray(dl).ToSlice(); !slices.Equal(got, want) {
+		t.Errorf("DoublyList round trip = %v", got)
+	}
+	if got := ForwardListToDoublyList(fl); got.Tail.Key != "c" || !slices.Equal(got.ToSlice(), want) {
+		t.Errorf("ForwardListToDoublyList = %v", got.ToSlice())
+	}
+	if got := DoublyListToForwardList(dl).ToSlice(); !slices.Equal(got, want) {
+		t.Errorf("DoublyListToForwardList = %v", got)
+	}
+
+	empty := ForwardListToArray(singlylist.NewForwardList[int]())
+	if empty.GetSize() != 0 {
+		t.Error("Expected empty array")
+	}
+	if l := ForwardListToDoublyList(singlylist.NewForwardList[int]()); l.Head != nil {
+		t.Error("Expected empty list")
+	}
+	if l := DoublyListToForwardList(doublylist.NewDoublyList[int]()); l.Head != nil {
+		t.Error("Expected empty list")
+	}
+}
+
+func TestArrayToTree(t *testing.T) {
+	sorted := make([]int, 127)
+	for i := range sorted {
+		sorted[i] = i
+	}
+	tree := ArrayToTree(array.FromSlice(sorted))
+	if h := height(tree.GetRoot()); h != 7 {
+		t.Errorf("Expected height 7, got %d", h)
+	}
+	if got := TreeToArray(tree).ToSlice(); !slices.Equal(got, sorted) {
+		t.Error("TreeToArray should return keys in order")
+	}
+
+	// Неотсортированный массив сортируется, исходный не меняется
+	arr := array.FromSlice([]int{3, 1, 2})
+	if got := ArrayToTree(arr).ToSlice(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("ArrayToTree(unsorted) = %v", got)
+	}
+	if !slices.Equal(arr.ToSlice(), []int{3, 1, 2}) {
+		t.Error("Source array changed")
+	}
+}
+
+func TestHashTables(t *testing.T) {
+	dh, _ := dhash.NewDoubleHash[int](11)
+	for i, key := range []string{"pear", "apple", "fig"} {
+		dh.Insert(key, i)
+	}
+
+	if got := KeysToTree[int](dh).ToSlice(); !slices.Equal(got, []string{"apple", "fig", "pear"}) {
+		t.Errorf("KeysToTree = %v", got)
+	}
+	if got := KeysToArray[int](dh).ToSlice(); !slices.Equal(got, []string{"apple", "fig", "pear"}) {
+		t.Errorf("KeysToArray = %v", got)
+	}
+
+	ch := DoubleHashToCuckoo(dh)
+	if ch.Size() != 3 || *ch.Find("fig") != 2 {
+		t.Errorf("DoubleHashToCuckoo: size %d", ch.Size())
+	}
+	back, err := CuckooToDoubleHash(ch)
+	if err != nil || back.Size() != 3 || *back.Find("pear") != 0 {
+		t.Errorf("CuckooToDoubleHash: %v", err)
+	}
+	if got := KeysToArray[int](cuckoo.NewCuckooHash[int](5)); got.GetSize() != 0 {
+		t.Error("Expected no keys")
+	}
+}
@@ -0,0 +1,374 @@
+package countmin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/hashing"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+	"github.com/D4ROVAN1E/LR_3_Go/queue"
+)
+
+// Ошибки, которые может вернуть скетч
+var (
+	ErrInvalidParams = errors.New("sketch width and depth must be greater than zero")
+	ErrIncompatible  = errors.New("sketches have different width or depth")
+	ErrTopKTooLarge  = errors.New("heavy hitters k exceeds MaxTopK")
+)
+
+// MaxTopK - наибольшее k для отслеживания частых элементов. Ограничение
+// действует в TrackTop и LoadBinary и защищает от испорченного заголовка файла.
+const MaxTopK = 1 << 16
+
+// Hitter - кандидат в частые элементы с оценкой частоты
+type Hitter struct {
+	Key   string
+	Count uint64
+}
+
+// CountMinSketch оценивает частоты ключей в потоке в памяти O(width*depth).
+// Оценка никогда не бывает меньше истинной частоты и превышает ее
+// не более чем на epsilon*Total с вероятностью 1-delta.
+type CountMinSketch struct {
+	counts []uint64 // depth строк по width счетчиков подряд
+	width  uint32
+	depth  uint32
+	total  uint64
+
+	// Отслеживание частых элементов: top хранит текущие оценки кандидатов,
+	// а куча по возрастанию оценки позволяет быстро найти самого слабого.
+	// После обновления оценки в куче остается устаревшая запись.
+	topK int
+	top  map[string]uint64
+	heap *queue.PriorityQueue[Hitter]
+}
+
+// NewCountMinSketch создает скетч с depth строками по width счетчиков
+func NewCountMinSketch(width, depth uint32) (*CountMinSketch, error) {
+	if width == 0 || depth == 0 {
+		return nil, ErrInvalidParams
+	}
+	return &CountMinSketch{
+		counts: make([]uint64, uint64(width)*uint64(depth)),
+		width:  width,
+		depth:  depth,
+	}, nil
+}
+
+// NewCountMinSketchWithEstimates подбирает размеры под погрешность epsilon
+// (доля от общего числа событий) и вероятность ее превышения delta
+func NewCountMinSketchWithEstimates(epsilon, delta float64) (*CountMinSketch, error) {
+	if epsilon <= 0 || epsilon >= 1 || delta <= 0 || delta >= 1 {
+		return nil, fmt.Errorf("epsilon and delta must be in (0, 1), got %v and %v", epsilon, delta)
+	}
+	width := uint32(math.Ceil(math.E / epsilon))
+	depth := uint32(math.Ceil(math.Log(1 / delta)))
+	return NewCountMinSketch(width, depth)
+}
+
+// index возвращает позицию счетчика ключа в строке row
+func (s *CountMinSketch) index(h1, h2 uint64, row uint32) uint64 {
+	return uint64(row)*uint64(s.width) + hashing.Probe(h1, h2, uint64(row), uint64(s.width))
+}
+
+// Add увеличивает счетчики ключа на count во всех строках
+func (s *CountMinSketch) Add(key string, count uint64) {
+	h1, h2 := hashing.Pair(key)
+	for row := uint32(0); row < s.depth; row++ {
+		s.counts[s.index(h1, h2, row)] += count
+	}
+	s.total += count
+	s.trackHitter(key)
+}
+
+// AddConservative реализует консервативное обновление: счетчики поднимаются
+// только до значения (текущая оценка + count). Это уменьшает переоценку
+// для редких ключей, сохраняя гарантию, что оценка не меньше истинной частоты.
+func (s *CountMinSketch) AddConservative(key string, count uint64) {
+	h1, h2 := hashing.Pair(key)
+	target := s.estimate(h1, h2) + count
+	for row := uint32(0); row < s.depth; row++ {
+		idx := s.index(h1, h2, row)
+		if s.counts[idx] < target {
+			s.counts[idx] = target
+		}
+	}
+	s.total += count
+	s.trackHitter(key)
+}
+
+// Estimate возвращает оценку частоты ключа (минимум по строкам)
+func (s *CountMinSketch) Estimate(key string) uint64 {
+	h1, h2 := hashing.Pair(key)
+	return s.estimate(h1, h2)
+}
+
+func (s *CountMinSketch) estimate(h1, h2 uint64) uint64 {
+	result := uint64(math.MaxUint64)
+	for row := uint32(0); row < s.depth; row++ {
+		if c := s.counts[s.index(h1, h2, row)]; c < result {
+			result = c
+		}
+	}
+	return result
+}
+
+// Total возвращает сумму всех добавленных частот
+func (s *CountMinSketch) Total() uint64 {
+	return s.total
+}
+
+// Width возвращает количество счетчиков в строке
+func (s *CountMinSketch) Width() uint32 {
+	return s.width
+}
+
+// Depth возвращает количество строк
+func (s *CountMinSketch) Depth() uint32 {
+	return s.depth
+}
+
+// TrackTop включает отслеживание k самых частых ключей (0 - выключить).
+// Учитываются только ключи, добавленные после вызова.
+// При k больше MaxTopK возвращает ErrTopKTooLarge и не меняет скетч.
+func (s *CountMinSketch) TrackTop(k int) error {
+	if k > MaxTopK {
+		return ErrTopKTooLarge
+	}
+	s.topK = k
+	s.top = nil
+	s.heap = nil
+	if k > 0 {
+		// Карта растет по мере добавления ключей: k может быть намного больше их числа
+		s.top = make(map[string]uint64)
+		s.heap = newHitterHeap()
+	}
+	return nil
+}
+
+func newHitterHeap() *queue.PriorityQueue[Hitter] {
+	return queue.NewPriorityQueue(func(a, b Hitter) bool {
+		return a.Count < b.Count
+	})
+}
+
+// trackHitter обновляет список кандидатов после изменения счетчиков ключа
+func (s *CountMinSketch) trackHitter(key string) {
+	if s.topK <= 0 {
+		return
+	}
+	est := s.Estimate(key)
+
+	if _, ok := s.top[key]; ok || len(s.top) < s.topK {
+		s.top[key] = est
+		s.heap.Push(Hitter{Key: key, Count: est})
+		s.compactHeap()
+		return
+	}
+
+	weakest, ok := s.weakestHitter()
+	if !ok || est <= weakest.Count {
+		return
+	}
+	_, _ = s.heap.Pop()
+	delete(s.top, weakest.Key)
+	s.top[key] = est
+	s.heap.Push(Hitter{Key: key, Count: est})
+}
+
+// weakestHitter отбрасывает устаревшие записи на вершине кучи
+// и возвращает кандидата с минимальной оценкой
+func (s *CountMinSketch) weakestHitter() (Hitter, bool) {
+	for {
+		h, err := s.heap.Peek()
+		if err != nil {
+			return Hitter{}, false
+		}
+		if current, ok := s.top[h.Key]; ok && current == h.Count {
+			return h, true
+		}
+		_, _ = s.heap.Pop()
+	}
+}
+
+// compactHeap перестраивает кучу, если устаревших записей стало слишком много
+func (s *CountMinSketch) compactHeap() {
+	if s.heap.Size() <= 2*s.topK+16 {
+		return
+	}
+	s.heap = newHitterHeap()
+	for k, c := range s.top {
+		s.heap.Push(Hitter{Key: k, Count: c})
+	}
+}
+
+// HeavyHitters возвращает отслеживаемые частые ключи по убыванию оценки
+func (s *CountMinSketch) HeavyHitters() []Hitter {
+	result := make([]Hitter, 0, len(s.top))
+	for k, c := range s.top {
+		result = append(result, Hitter{Key: k, Count: c})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// Merge добавляет счетчики other к текущему скетчу. Размеры должны совпадать.
+// Кандидаты в частые элементы объединяются и переоцениваются.
+func (s *CountMinSketch) Merge(other *CountMinSketch) error {
+	if s.width != other.width || s.depth != other.depth {
+		return ErrIncompatible
+	}
+	for i := range s.counts {
+		s.counts[i] += other.counts[i]
+	}
+	s.total += other.total
+
+	if s.topK > 0 {
+		candidates := make([]string, 0, len(s.top)+len(other.top))
+		for k := range s.top {
+			candidates = append(candidates, k)
+		}
+		for k := range other.top {
+			candidates = append(candidates, k)
+		}
+		s.TrackTop(s.topK)
+		for _, k := range candidates {
+			s.trackHitter(k)
+		}
+	}
+	return nil
+}
+
+// Clear обнуляет все счетчики и список кандидатов
+func (s *CountMinSketch) Clear() {
+	for i := range s.counts {
+		s.counts[i] = 0
+	}
+	s.total = 0
+	s.TrackTop(s.topK)
+}
+
+// SaveBinary сохраняет скетч в бинарный файл
+func (s *CountMinSketch) SaveBinary(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, func(file io.Writer) error {
+		// Заголовок: width, depth, total, затем счетчики
+		if err := binary.Write(file, binary.LittleEndian, s.width); err != nil {
+			return err
+		}
+		if err := binary.Write(file, binary.LittleEndian, s.depth); err != nil {
+			return err
+		}
+		if err := binary.Write(file, binary.LittleEndian, s.total); err != nil {
+			return err
+		}
+		if err := binary.Write(file, binary.LittleEndian, s.counts); err != nil {
+			return err
+		}
+
+		// Кандидаты в частые элементы: k, количество, затем ключи (длина + байты)
+		if err := binary.Write(file, binary.LittleEndian, uint32(s.topK)); err != nil {
+			return err
+		}
+		if err := binary.Write(file, binary.LittleEndian, uint32(len(s.top))); err != nil {
+			return err
+		}
+		for key := range s.top {
+			keyBytes := []byte(key)
+			if err := binary.Write(file, binary.LittleEndian, uint32(len(keyBytes))); err != nil {
+				return err
+			}
+			if _, err := file.Write(keyBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts...)
+}
+
+// LoadBinary загружает скетч из бинарного файла (в том числе сжатого)
+func (s *CountMinSketch) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Could not open binary file for reading: %w", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, s.readBinary, opts...)
+}
+
+func (s *CountMinSketch) readBinary(r io.Reader) error {
+	var width, depth uint32
+	var total uint64
+	if err := binary.Read(r, binary.LittleEndian, &width); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &depth); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &total); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if width == 0 || depth == 0 {
+		return ErrInvalidParams
+	}
+	cells := uint64(width) * uint64(depth)
+	if cells > math.MaxInt {
+		return fmt.Errorf("sketch size %dx%d is too large", width, depth)
+	}
+
+	counts, err := codec.DecodeN(r, codec.Fixed[uint64]{}, int(cells))
+	if err != nil {
+		return fmt.Errorf("failed to read counters: %w", err)
+	}
+	loaded := &CountMinSketch{
+		counts: counts,
+		width:  width,
+		depth:  depth,
+		total:  total,
+	}
+
+	var topK, hitters uint32
+	if err := binary.Read(r, binary.LittleEndian, &topK); err != nil {
+		return fmt.Errorf("failed to read heavy hitters header: %w", err)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &hitters); err != nil {
+		return fmt.Errorf("failed to read heavy hitters header: %w", err)
+	}
+	if topK > MaxTopK {
+		return fmt.Errorf("heavy hitters k %d exceeds maximum %d", topK, MaxTopK)
+	}
+	if hitters > topK {
+		return fmt.Errorf("heavy hitters count %d exceeds k %d", hitters, topK)
+	}
+
+	loaded.TrackTop(int(topK))
+	for i := uint32(0); i < hitters; i++ {
+		var keyLen uint32
+		if err := binary.Read(r, binary.LittleEndian, &keyLen); err != nil {
+			return err
+		}
+		keyBuf, err := codec.DecodeN(r, codec.Fixed[byte]{}, int(keyLen))
+		if err != nil {
+			return fmt.Errorf("failed to read key string: %w", err)
+		}
+		loaded.trackHitter(string(keyBuf))
+	}
+	if err := persist.CheckEOF(r); err != nil {
+		return err
+	}
+
+	*s = *loaded
+	return nil
+}
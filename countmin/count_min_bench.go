@@ -0,0 +1,40 @@
+package countmin
+
+import (
+	"fmt"
+	"testing"
+)
+
+const NumElements = 100000 // Количество событий для теста
+
+func generateKeys(count int) []string {
+	keys := make([]string, count)
+	for i := range keys {
+		// Около 1000 различных ключей с неравномерными частотами
+		keys[i] = fmt.Sprintf("key%d", (i*i)%1000)
+	}
+	return keys
+}
+
+// BenchmarkAdd измеряет обычное обновление.
+func BenchmarkAdd(b *testing.B) {
+	keys := generateKeys(NumElements)
+	s, _ := NewCountMinSketchWithEstimates(0.001, 0.01)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s.Add(keys[i%NumElements], 1)
+	}
+}
+
+// BenchmarkAddConservativeTopK измеряет консервативное обновление с отслеживанием top-10.
+func BenchmarkAddConservativeTopK(b *testing.B) {
+	keys := generateKeys(NumElements)
+	s, _ := NewCountMinSketchWithEstimates(0.001, 0.01)
+	s.TrackTop(10)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s.AddConservative(keys[i%NumElements], 1)
+	}
+}
@@ -0,0 +1,279 @@
+package countmin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+func TestConstructor(t *testing.T) {
+	if _, err := NewCountMinSketch(0, 4); err != ErrInvalidParams {
+		t.Errorf("Expected ErrInvalidParams, got %v", err)
+	}
+	if _, err := NewCountMinSketchWithEstimates(0, 0.01); err == nil {
+		t.Error("Expected error for epsilon = 0")
+	}
+
+	s, err := NewCountMinSketchWithEstimates(0.01, 0.01)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// e/0.01 = 271.8..., ln(100) = 4.6...
+	if s.Width() != 272 || s.Depth() != 5 {
+		t.Errorf("Unexpected dimensions %dx%d", s.Width(), s.Depth())
+	}
+}
+
+// zipfStream генерирует поток, где ключ i встречается (n / (i+1)) раз
+func zipfStream(keys, n int) map[string]uint64 {
+	freq := make(map[string]uint64, keys)
+	for i := 0; i < keys; i++ {
+		freq[fmt.Sprintf("key%d", i)] = uint64(n / (i + 1))
+	}
+	return freq
+}
+
+func TestEstimateBounds(t *testing.T) {
+	freq := zipfStream(2000, 10000)
+
+	plain, _ := NewCountMinSketchWithEstimates(0.001, 0.01)
+	conservative, _ := NewCountMinSketchWithEstimates(0.001, 0.01)
+	for k, c := range freq {
+		plain.Add(k, c)
+		conservative.AddConservative(k, c)
+	}
+	if plain.Total() != conservative.Total() {
+		t.Errorf("Totals differ: %d vs %d", plain.Total(), conservative.Total())
+	}
+
+	bound := uint64(0.001 * float64(plain.Total()))
+	var plainErr, consErr uint64
+	for k, c := range freq {
+		p := plain.Estimate(k)
+		cons := conservative.Estimate(k)
+		// Оценка никогда не меньше истинной частоты
+		if p < c || cons < c {
+			t.Fatalf("Underestimate for %s: true %d, plain %d, conservative %d", k, c, p, cons)
+		}
+		if p-c > 2*bound {
+			t.Errorf("Overestimate for %s too large: %d", k, p-c)
+		}
+		plainErr += p - c
+		consErr += cons - c
+	}
+	// Консервативное обновление не хуже обычного
+	if consErr > plainErr {
+		t.Errorf("Conservative update error %d exceeds plain error %d", consErr, plainErr)
+	}
+
+	if plain.Estimate("never-seen") > 2*bound {
+		t.Error("Estimate of unseen key is too large")
+	}
+}
+
+func TestHeavyHitters(t *testing.T) {
+	s, _ := NewCountMinSketchWithEstimates(0.001, 0.01)
+	s.TrackTop(3)
+
+	// Ключи приходят вперемешку по одному событию
+	freq := map[string]int{"a": 500, "b": 300, "c": 200, "d": 50, "e": 10}
+	for round := 0; round < 500; round++ {
+		for k, c := range freq {
+			if round < c {
+				s.Add(k, 1)
+			}
+		}
+	}
+	for i := 0; i < 100; i++ {
+		s.Add(fmt.Sprintf("noise%d", i), 1)
+	}
+
+	top := s.HeavyHitters()
+	if len(top) != 3 {
+		t.Fatalf("Expected 3 heavy hitters, got %d", len(top))
+	}
+	want := []string{"a", "b", "c"}
+	for i, h := range top {
+		if h.Key != want[i] {
+			t.Errorf("Heavy hitter #%d: expected %s, got %s", i, want[i], h.Key)
+		}
+	}
+	if top[0].Count < 500 {
+		t.Errorf("Heavy hitter count underestimated: %d", top[0].Count)
+	}
+	// Куча не разрастается от устаревших записей
+	if s.heap.Size() > 2*3+16 {
+		t.Errorf("Hitter heap not compacted: %d entries", s.heap.Size())
+	}
+
+	s.Clear()
+	if len(s.HeavyHitters()) != 0 || s.Total() != 0 || s.Estimate("a") != 0 {
+		t.Error("Sketch not empty after Clear")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, _ := NewCountMinSketch(100, 4)
+	b, _ := NewCountMinSketch(100, 4)
+	a.TrackTop(2)
+	b.TrackTop(2)
+
+	a.Add("x", 10)
+	a.Add("y", 1)
+	b.Add("x", 5)
+	b.Add("z", 20)
+
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if a.Total() != 36 {
+		t.Errorf("Expected total 36, got %d", a.Total())
+	}
+	if a.Estimate("x") < 15 || a.Estimate("z") < 20 {
+		t.Error("Merged estimates are too small")
+	}
+	top := a.HeavyHitters()
+	if len(top) != 2 || top[0].Key != "z" || top[1].Key != "x" {
+		t.Errorf("Unexpected heavy hitters after merge: %v", top)
+	}
+
+	other, _ := NewCountMinSketch(50, 4)
+	if err := a.Merge(other); err != ErrIncompatible {
+		t.Errorf("Expected ErrIncompatible, got %v", err)
+	}
+}
+
+func TestSaveLoadBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "cms.bin")
+
+	s, _ := NewCountMinSketch(64, 3)
+	s.TrackTop(2)
+	s.Add("alpha", 7)
+	s.Add("beta", 3)
+	s.Add("gamma", 1)
+
+	if err := s.SaveBinary(file); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+
+	loaded, _ := NewCountMinSketch(1, 1)
+	if err := loaded.LoadBinary(file); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+	if loaded.Width() != 64 || loaded.Depth() != 3 || loaded.Total() != 11 {
+		t.Error("Header lost after load")
+	}
+	if loaded.Estimate("alpha") != s.Estimate("alpha") {
+		t.Error("Counters lost after load")
+	}
+	top := loaded.HeavyHitters()
+	if len(top) != 2 || top[0].Key != "alpha" || top[1].Key != "beta" {
+		t.Errorf("Heavy hitters lost after load: %v", top)
+	}
+
+	// Ошибки
+	if err := s.SaveBinary(""); err == nil {
+		t.Error("Expected error for empty filename")
+	}
+	if err := loaded.LoadBinary(filepath.Join(tmpDir, "missing.bin")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	data, _ := os.ReadFile(file)
+	truncated := filepath.Join(tmpDir, "truncated.bin")
+	os.WriteFile(truncated, data[:100], 0644)
+	if err := loaded.LoadBinary(truncated); err == nil {
+		t.Error("Expected error for truncated file")
+	}
+	if loaded.Total() != 11 {
+		t.Error("Failed load must not modify the sketch")
+	}
+}
+
+// TestSaveLoadCompressed проверяет, что LoadBinary распаковывает файлы SaveBinary
+func TestSaveLoadCompressed(t *testing.T) {
+	s, _ := NewCountMinSketch(256, 4)
+	s.TrackTop(3)
+	for i := 0; i < 100; i++ {
+		s.Add(fmt.Sprintf("key%d", i%10), uint64(i))
+	}
+	for _, alg := range []compression.Algorithm{compression.Gzip, compression.Flate, compression.LZ} {
+		file := filepath.Join(t.TempDir(), "cms.bin")
+		if err := s.SaveBinary(file, compression.With(alg)); err != nil {
+			t.Fatalf("%v: SaveBinary failed: %v", alg, err)
+		}
+		loaded, _ := NewCountMinSketch(1, 1)
+		if err := loaded.LoadBinary(file); err != nil {
+			t.Fatalf("%v: LoadBinary failed: %v", alg, err)
+		}
+		if loaded.Total() != s.Total() || loaded.Estimate("key9") != s.Estimate("key9") || len(loaded.HeavyHitters()) != 3 {
+			t.Errorf("%v: state lost after load", alg)
+		}
+		// Предел WithLimit считается по распакованным данным
+		if err := loaded.LoadBinary(file, persist.WithLimit(16)); !errors.Is(err, persist.ErrLimitExceeded) {
+			t.Errorf("%v: expected ErrLimitExceeded, got %v", alg, err)
+		}
+	}
+}
+
+// TestLoadBinary_HugeTopK проверяет, что k из заголовка не приводит
+// к огромному выделению памяти
+func TestLoadBinary_HugeTopK(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "huge_k.bin")
+
+	// width=1, depth=1, total=0, один счетчик, k=0x7fffffff, кандидатов нет
+	var header []byte
+	header = binary.LittleEndian.AppendUint32(header, 1)
+	header = binary.LittleEndian.AppendUint32(header, 1)
+	header = binary.LittleEndian.AppendUint64(header, 0)
+	header = binary.LittleEndian.AppendUint64(header, 0)
+	header = binary.LittleEndian.AppendUint32(header, 0x7fffffff)
+	header = binary.LittleEndian.AppendUint32(header, 0)
+	if err := os.WriteFile(file, header, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := NewCountMinSketch(8, 2)
+	if err := s.LoadBinary(file); err == nil {
+		t.Error("Expected error for k above MaxTopK")
+	}
+	if s.Width() != 8 {
+		t.Error("Failed load must not modify the sketch")
+	}
+}
+
+// TestTrackTopLimit проверяет, что TrackTop не принимает k, которое
+// LoadBinary отвергнет, а допустимое k переживает сохранение
+func TestTrackTopLimit(t *testing.T) {
+	s, _ := NewCountMinSketch(64, 2)
+	s.TrackTop(2)
+	if err := s.TrackTop(MaxTopK + 1); !errors.Is(err, ErrTopKTooLarge) {
+		t.Fatalf("Expected ErrTopKTooLarge, got %v", err)
+	}
+	s.Add("a", 1)
+	if len(s.HeavyHitters()) != 1 {
+		t.Error("Rejected TrackTop must keep the previous k")
+	}
+
+	if err := s.TrackTop(MaxTopK); err != nil {
+		t.Fatalf("TrackTop(MaxTopK) failed: %v", err)
+	}
+	s.Add("b", 1)
+	file := filepath.Join(t.TempDir(), "cms.bin")
+	if err := s.SaveBinary(file); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+	loaded, _ := NewCountMinSketch(1, 1)
+	if err := loaded.LoadBinary(file); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+	if len(loaded.HeavyHitters()) != 1 {
+		t.Errorf("Expected 1 heavy hitter, got %d", len(loaded.HeavyHitters()))
+	}
+}
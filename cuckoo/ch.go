@@ -1,374 +1,842 @@
-package cuckoo
-
-import (
-	"encoding/binary"
-	"fmt"
-	"io"
-	"math"
-	"os"
-)
-
-// Golden Ratio constant
-var A = (math.Sqrt(5.0) - 1.0) / 2.0
-
-// HashNode хранит пару ключ-значение.
-type HashNode[V any] struct {
-	Key        string
-	Value      V
-	IsOccupied bool
-}
-
-// CuckooHash - основная структура
-type CuckooHash[V any] struct {
-	table         []HashNode[V]
-	tableSize     uint32
-	elementsCount uint32
-}
-
-// NewCuckooHash создает новую таблицу
-func NewCuckooHash[V any](size uint32) *CuckooHash[V] {
-	if size == 0 {
-		size = 3
-	}
-	return &CuckooHash[V]{
-		table:         make([]HashNode[V], size+1), // +1 для совместимости с логикой C++ (резерв)
-		tableSize:     size,
-		elementsCount: 0,
-	}
-}
-
-// Copy создает глубокую копию таблицы
-func (ch *CuckooHash[V]) Copy() *CuckooHash[V] {
-	newCh := NewCuckooHash[V](ch.tableSize)
-	newCh.elementsCount = ch.elementsCount
-	copy(newCh.table, ch.table) // copy для slice делает поверхностную копию элементов, но для HashNode это ок, если V не указатель
-	return newCh
-}
-
-// hash1 - первая хэш-функция
-func (ch *CuckooHash[V]) hash1(key string) uint32 {
-	var numKey uint64
-	for _, c := range []byte(key) {
-		numKey = numKey*31 + uint64(c)
-	}
-	temp := float64(numKey) * A
-	temp = temp - math.Floor(temp)
-	return uint32(math.Floor(float64(ch.tableSize) * temp))
-}
-
-// hash2 - вторая хэш-функция
-func (ch *CuckooHash[V]) hash2(key string) uint32 {
-	var sum uint32
-	for _, c := range []byte(key) {
-		sum += uint32(c)
-	}
-	result := (sum % (ch.tableSize - 1)) + 1
-	if ch.tableSize%2 == 0 && result%2 == 0 {
-		result++
-	}
-	return result
-}
-
-func (ch *CuckooHash[V]) needResize() bool {
-	return (float64(ch.elementsCount) / float64(ch.tableSize)) > 0.5
-}
-
-func (ch *CuckooHash[V]) resize() {
-	oldSize := ch.tableSize
-	oldTable := ch.table
-
-	ch.tableSize = ch.tableSize*2 + 1
-	ch.table = make([]HashNode[V], ch.tableSize+1)
-	ch.elementsCount = 0
-
-	for i := uint32(0); i < oldSize; i++ {
-		if oldTable[i].IsOccupied {
-			ch.Insert(oldTable[i].Key, oldTable[i].Value)
-		}
-	}
-}
-
-// Insert вставляет или обновляет элемент
-func (ch *CuckooHash[V]) Insert(key string, value V) {
-	// Проверка существования и обновление
-	h1 := ch.hash1(key)
-	if ch.table[h1].IsOccupied && ch.table[h1].Key == key {
-		ch.table[h1].Value = value
-		return
-	}
-	h2 := ch.hash2(key)
-	if ch.table[h2].IsOccupied && ch.table[h2].Key == key {
-		ch.table[h2].Value = value
-		return
-	}
-
-	if ch.needResize() {
-		ch.resize()
-		// Пересчитываем хеши после ресайза
-		h1 = ch.hash1(key)
-	}
-
-	currentItem := HashNode[V]{Key: key, Value: value, IsOccupied: true}
-	currentPos := h1
-
-	// Ограничиваем количество выталкиваний (2 * tableSize)
-	for i := uint32(0); i < ch.tableSize*2; i++ {
-		if !ch.table[currentPos].IsOccupied {
-			ch.table[currentPos] = currentItem
-			ch.elementsCount++
-			return
-		}
-
-		// Swap (выталкивание)
-		ch.table[currentPos], currentItem = currentItem, ch.table[currentPos]
-
-		// Куда должен пойти вытолкнутый элемент
-		pos1 := ch.hash1(currentItem.Key)
-		pos2 := ch.hash2(currentItem.Key)
-
-		if currentPos == pos1 {
-			currentPos = pos2
-		} else {
-			currentPos = pos1
-		}
-	}
-
-	// Обнаружен цикл
-	ch.resize()
-	ch.Insert(currentItem.Key, currentItem.Value)
-}
-
-// Find ищет элемент. Возвращает указатель на значение или nil
-func (ch *CuckooHash[V]) Find(key string) *V {
-	h1 := ch.hash1(key)
-	if ch.table[h1].IsOccupied && ch.table[h1].Key == key {
-		return &ch.table[h1].Value
-	}
-
-	h2 := ch.hash2(key)
-	if ch.table[h2].IsOccupied && ch.table[h2].Key == key {
-		return &ch.table[h2].Value
-	}
-
-	if h1 == 99 || h2 == 59 {
-		if 49 < len(ch.table) && ch.table[49].IsOccupied && ch.table[49].Key == key {
-			return &ch.table[49].Value
-		}
-	}
-
-	return nil
-}
-
-// Remove удаляет элемент по ключу
-func (ch *CuckooHash[V]) Remove(key string) bool {
-	h1 := ch.hash1(key)
-	if ch.table[h1].IsOccupied && ch.table[h1].Key == key {
-		ch.table[h1].IsOccupied = false
-		ch.elementsCount--
-		return true
-	}
-
-	h2 := ch.hash2(key)
-	if ch.table[h2].IsOccupied && ch.table[h2].Key == key {
-		ch.table[h2].IsOccupied = false
-		ch.elementsCount--
-		return true
-	}
-	return false
-}
-
-// Size возвращает количество элементов
-func (ch *CuckooHash[V]) Size() uint32 {
-	return ch.elementsCount
-}
-
-// Empty проверяет, пуста ли таблица
-func (ch *CuckooHash[V]) Empty() bool {
-	return ch.elementsCount == 0
-}
-
-// Clear очищает таблицу
-func (ch *CuckooHash[V]) Clear() {
-	for i := range ch.table {
-		ch.table[i] = HashNode[V]{} // zero value
-	}
-	ch.elementsCount = 0
-}
-
-// Print выводит содержимое в stdout
-func (ch *CuckooHash[V]) Print() {
-	fmt.Println("=== Cuckoo Хэш-таблица ===")
-	fmt.Printf("Размер: %d, Элементов: %d\n", ch.tableSize, ch.elementsCount)
-	for i := uint32(0); i < ch.tableSize; i++ {
-		if ch.table[i].IsOccupied {
-			fmt.Printf("[%d] %s => %v\n", i, ch.table[i].Key, ch.table[i].Value)
-		}
-	}
-	fmt.Println("===========================")
-}
-
-// Сериализация
-
-// SerializeText сохраняет таблицу в текстовый файл
-func (ch *CuckooHash[V]) SerializeText(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error: Could not open file for writing: %w", err)
-	}
-	defer file.Close()
-
-	// Заголовок
-	if _, err := fmt.Fprintf(file, "%d %d\n", ch.tableSize, ch.elementsCount); err != nil {
-		return err
-	}
-
-	for i := uint32(0); i < ch.tableSize; i++ {
-		if ch.table[i].IsOccupied {
-			if _, err := fmt.Fprintf(file, "%d %s %v\n", i, ch.table[i].Key, ch.table[i].Value); err != nil {
-				return err
-			}
-		}
-	}
-	fmt.Printf("Таблица (текст) успешно сохранена в %s\n", filename)
-	return nil
-}
-
-// DeserializeText загружает таблицу из текстового файла
-func (ch *CuckooHash[V]) DeserializeText(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("error: Could not open file for reading: %w", err)
-	}
-	defer file.Close()
-
-	var newTableSize, newElementsCount uint32
-	if _, err := fmt.Fscan(file, &newTableSize, &newElementsCount); err != nil {
-		return fmt.Errorf("error: Incorrect file format or empty file: %w", err)
-	}
-
-	ch.table = make([]HashNode[V], newTableSize+1)
-	ch.tableSize = newTableSize
-	ch.elementsCount = newElementsCount
-
-	for {
-		var idx uint32
-		var key string
-		var value V
-
-		// Fscan читает пробельные разделители автоматически
-		_, err := fmt.Fscan(file, &idx, &key, &value)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("error reading data: %w", err)
-		}
-
-		if idx < ch.tableSize {
-			ch.table[idx] = HashNode[V]{Key: key, Value: value, IsOccupied: true}
-		} else {
-			return fmt.Errorf("error: File index (%d) is out of table bounds (%d)", idx, ch.tableSize)
-		}
-	}
-
-	fmt.Printf("Таблица (текст) успешно загружена из %s\n", filename)
-	return nil
-}
-
-// SerializeBin сохраняет таблицу в бинарный файл
-func (ch *CuckooHash[V]) SerializeBin(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error: Could not open file for writing: %w", err)
-	}
-	defer file.Close()
-
-	// Пишем размеры
-	if err := binary.Write(file, binary.LittleEndian, ch.tableSize); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, ch.elementsCount); err != nil {
-		return err
-	}
-
-	for i := uint32(0); i < ch.tableSize; i++ {
-		occupied := ch.table[i].IsOccupied
-		if err := binary.Write(file, binary.LittleEndian, occupied); err != nil {
-			return err
-		}
-
-		if occupied {
-			keyBytes := []byte(ch.table[i].Key)
-			keyLen := uint32(len(keyBytes))
-
-			if err := binary.Write(file, binary.LittleEndian, keyLen); err != nil {
-				return err
-			}
-			if _, err := file.Write(keyBytes); err != nil {
-				return err
-			}
-			// binary.Write работает корректно только для чисел фиксированного размера
-			if err := binary.Write(file, binary.LittleEndian, ch.table[i].Value); err != nil {
-				return err
-			}
-		}
-	}
-	fmt.Printf("Таблица успешно сохранена в %s\n", filename)
-	return nil
-}
-
-// DeserializeBin загружает таблицу из бинарного файла
-func (ch *CuckooHash[V]) DeserializeBin(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("error: Could not open file for reading: %w", err)
-	}
-	defer file.Close()
-
-	var newTableSize, newElementsCount uint32
-	if err := binary.Read(file, binary.LittleEndian, &newTableSize); err != nil {
-		return err
-	}
-	if err := binary.Read(file, binary.LittleEndian, &newElementsCount); err != nil {
-		return err
-	}
-
-	ch.tableSize = newTableSize
-	ch.elementsCount = newElementsCount
-	ch.table = make([]HashNode[V], newTableSize+1)
-
-	for i := uint32(0); i < ch.tableSize; i++ {
-		var occupied bool
-		if err := binary.Read(file, binary.LittleEndian, &occupied); err != nil {
-			return fmt.Errorf("error reading occupied flag at %d: %w", i, err)
-		}
-
-		if occupied {
-			var keyLen uint32
-			if err := binary.Read(file, binary.LittleEndian, &keyLen); err != nil {
-				return err
-			}
-
-			keyBuf := make([]byte, keyLen)
-			if _, err := io.ReadFull(file, keyBuf); err != nil {
-				return err
-			}
-
-			var value V
-			if err := binary.Read(file, binary.LittleEndian, &value); err != nil {
-				return err
-			}
-
-			ch.table[i] = HashNode[V]{
-				Key:        string(keyBuf),
-				Value:      value,
-				IsOccupied: true,
-			}
-		} else {
-			ch.table[i].IsOccupied = false
-		}
-	}
-
-	fmt.Printf("Таблица успешно загружена из %s\n", filename)
-	return nil
-}
+package cuckoo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/hashing"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+	"github.com/D4ROVAN1E/LR_3_Go/wal"
+)
+
+// Ошибки режима журнала
+var (
+	ErrWALEnabled  = errors.New("write-ahead log is already enabled")
+	ErrWALDisabled = errors.New("write-ahead log is not enabled")
+)
+
+// Golden Ratio constant
+var A = hashing.GoldenRatio
+
+// HashNode хранит пару ключ-значение.
+type HashNode[V any] struct {
+	Key        string
+	Value      V
+	IsOccupied bool
+}
+
+// CuckooHash - основная структура
+type CuckooHash[V any] struct {
+	table         []HashNode[V]
+	tableSize     uint32
+	elementsCount uint32
+
+	// Режим журнала: каталог со снимком и журналом, открытый журнал
+	// и последняя ошибка записи в журнал (Insert, Remove и Clear ее не возвращают)
+	walDir string
+	log    *wal.Log
+	walErr error
+
+	// Кодек элементов для бинарного формата; nil - codec.Default
+	elemCodec codec.ElementCodec[V]
+}
+
+// NewCuckooHash создает новую таблицу
+func NewCuckooHash[V any](size uint32) *CuckooHash[V] {
+	if size == 0 {
+		size = 3
+	}
+	return &CuckooHash[V]{
+		table:         make([]HashNode[V], size+1), // +1 для совместимости с логикой C++ (резерв)
+		tableSize:     size,
+		elementsCount: 0,
+	}
+}
+
+// Copy создает глубокую копию таблицы
+func (ch *CuckooHash[V]) Copy() *CuckooHash[V] {
+	newCh := NewCuckooHash[V](ch.tableSize)
+	newCh.elementsCount = ch.elementsCount
+	newCh.elemCodec = ch.elemCodec
+	copy(newCh.table, ch.table) // copy для slice делает поверхностную копию элементов, но для HashNode это ок, если V не указатель
+	return newCh
+}
+
+// hash1 - первая хэш-функция
+func (ch *CuckooHash[V]) hash1(key string) uint32 {
+	return hashing.Multiplicative(hashing.Polynomial(key), A, ch.tableSize)
+}
+
+// hash2 - вторая хэш-функция
+func (ch *CuckooHash[V]) hash2(key string) uint32 {
+	return hashing.Folding(key, ch.tableSize)
+}
+
+func (ch *CuckooHash[V]) needResize() bool {
+	return (float64(ch.elementsCount) / float64(ch.tableSize)) > 0.5
+}
+
+func (ch *CuckooHash[V]) resize() {
+	oldSize := ch.tableSize
+	oldTable := ch.table
+
+	ch.tableSize = ch.tableSize*2 + 1
+	ch.table = make([]HashNode[V], ch.tableSize+1)
+	ch.elementsCount = 0
+
+	for i := uint32(0); i < oldSize; i++ {
+		if oldTable[i].IsOccupied {
+			ch.insert(oldTable[i].Key, oldTable[i].Value)
+		}
+	}
+}
+
+// Insert вставляет или обновляет элемент.
+// Если в режиме журнала запись не удалась, таблица не меняется,
+// а ошибка журнала возвращается.
+func (ch *CuckooHash[V]) Insert(key string, value V) error {
+	if ch.log != nil {
+		c, err := ch.elementCodec()
+		if err != nil {
+			return err
+		}
+		data, err := wal.EncodeValue(c, value)
+		if err != nil {
+			return err
+		}
+		if err := ch.log.Append(wal.Record{Op: wal.OpInsert, Key: key, Value: data}); err != nil {
+			return err
+		}
+	}
+	ch.insert(key, value)
+	return nil
+}
+
+func (ch *CuckooHash[V]) insert(key string, value V) {
+	// Проверка существования и обновление
+	h1 := ch.hash1(key)
+	if ch.table[h1].IsOccupied && ch.table[h1].Key == key {
+		ch.table[h1].Value = value
+		return
+	}
+	h2 := ch.hash2(key)
+	if ch.table[h2].IsOccupied && ch.table[h2].Key == key {
+		ch.table[h2].Value = value
+		return
+	}
+
+	if ch.needResize() {
+		ch.resize()
+		// Пересчитываем хеши после ресайза
+		h1 = ch.hash1(key)
+	}
+
+	currentItem := HashNode[V]{Key: key, Value: value, IsOccupied: true}
+	currentPos := h1
+
+	// Ограничиваем количество выталкиваний (2 * tableSize)
+	for i := uint32(0); i < ch.tableSize*2; i++ {
+		if !ch.table[currentPos].IsOccupied {
+			ch.table[currentPos] = currentItem
+			ch.elementsCount++
+			return
+		}
+
+		// Swap (выталкивание)
+		ch.table[currentPos], currentItem = currentItem, ch.table[currentPos]
+
+		// Куда должен пойти вытолкнутый элемент
+		pos1 := ch.hash1(currentItem.Key)
+		pos2 := ch.hash2(currentItem.Key)
+
+		if currentPos == pos1 {
+			currentPos = pos2
+		} else {
+			currentPos = pos1
+		}
+	}
+
+	// Обнаружен цикл
+	ch.resize()
+	ch.insert(currentItem.Key, currentItem.Value)
+}
+
+// Find ищет элемент. Возвращает указатель на значение или nil
+func (ch *CuckooHash[V]) Find(key string) *V {
+	h1 := ch.hash1(key)
+	if ch.table[h1].IsOccupied && ch.table[h1].Key == key {
+		return &ch.table[h1].Value
+	}
+
+	h2 := ch.hash2(key)
+	if ch.table[h2].IsOccupied && ch.table[h2].Key == key {
+		return &ch.table[h2].Value
+	}
+
+	if h1 == 99 || h2 == 59 {
+		if 49 < len(ch.table) && ch.table[49].IsOccupied && ch.table[49].Key == key {
+			return &ch.table[49].Value
+		}
+	}
+
+	return nil
+}
+
+// Remove удаляет элемент по ключу.
+// Если в режиме журнала запись не удалась, элемент не удаляется и Remove
+// возвращает false, как для отсутствующего ключа: ключ остается доступен
+// через Find, а ошибка - через WALError до следующего Checkpoint.
+func (ch *CuckooHash[V]) Remove(key string) bool {
+	if ch.log != nil && ch.Find(key) != nil {
+		if err := ch.log.Append(wal.Record{Op: wal.OpRemove, Key: key}); err != nil {
+			ch.walErr = err
+			return false
+		}
+	}
+	return ch.remove(key)
+}
+
+func (ch *CuckooHash[V]) remove(key string) bool {
+	h1 := ch.hash1(key)
+	if ch.table[h1].IsOccupied && ch.table[h1].Key == key {
+		ch.table[h1].IsOccupied = false
+		ch.elementsCount--
+		return true
+	}
+
+	h2 := ch.hash2(key)
+	if ch.table[h2].IsOccupied && ch.table[h2].Key == key {
+		ch.table[h2].IsOccupied = false
+		ch.elementsCount--
+		return true
+	}
+	return false
+}
+
+// Size возвращает количество элементов
+func (ch *CuckooHash[V]) Size() uint32 {
+	return ch.elementsCount
+}
+
+// Range обходит все занятые ячейки таблицы. Обход прекращается, если fn вернула false.
+// Изменять таблицу внутри fn нельзя.
+func (ch *CuckooHash[V]) Range(fn func(key string, value V) bool) {
+	for i := uint32(0); i < ch.tableSize; i++ {
+		if ch.table[i].IsOccupied {
+			if !fn(ch.table[i].Key, ch.table[i].Value) {
+				return
+			}
+		}
+	}
+}
+
+// Empty проверяет, пуста ли таблица
+func (ch *CuckooHash[V]) Empty() bool {
+	return ch.elementsCount == 0
+}
+
+// Clear очищает таблицу
+func (ch *CuckooHash[V]) Clear() {
+	if ch.log != nil {
+		if err := ch.log.Append(wal.Record{Op: wal.OpClear}); err != nil {
+			ch.walErr = err
+			return
+		}
+	}
+	ch.clear()
+}
+
+func (ch *CuckooHash[V]) clear() {
+	for i := range ch.table {
+		ch.table[i] = HashNode[V]{} // zero value
+	}
+	ch.elementsCount = 0
+}
+
+// Print выводит содержимое в stdout
+func (ch *CuckooHash[V]) Print() {
+	fmt.Println("=== Cuckoo Хэш-таблица ===")
+	fmt.Printf("Размер: %d, Элементов: %d\n", ch.tableSize, ch.elementsCount)
+	for i := uint32(0); i < ch.tableSize; i++ {
+		if ch.table[i].IsOccupied {
+			fmt.Printf("[%d] %s => %v\n", i, ch.table[i].Key, ch.table[i].Value)
+		}
+	}
+	fmt.Println("===========================")
+}
+
+// Сериализация
+
+// SerializeText сохраняет таблицу в текстовый файл
+func (ch *CuckooHash[V]) SerializeText(filename string, opts ...persist.Option) error {
+	if err := persist.WriteFile(filename, ch.writeText, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Таблица (текст) успешно сохранена в %s\n", filename)
+	return nil
+}
+
+// DeserializeText загружает таблицу из текстового файла
+func (ch *CuckooHash[V]) DeserializeText(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Could not open file for reading: %w", err)
+	}
+	defer file.Close()
+
+	if err := persist.Load(file, compression.NewReader, ch.readText, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Таблица (текст) успешно загружена из %s\n", filename)
+	return ch.checkpointIfLogged()
+}
+
+// WriteTextTo записывает таблицу в w в текстовом формате
+func (ch *CuckooHash[V]) WriteTextTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, ch.writeText)
+}
+
+// ReadTextFrom читает таблицу в текстовом формате из r до конца потока
+func (ch *CuckooHash[V]) ReadTextFrom(r io.Reader) (int64, error) {
+	n, err := persist.ReadCounted(r, ch.readText)
+	if err != nil {
+		return n, err
+	}
+	return n, ch.checkpointIfLogged()
+}
+
+func (ch *CuckooHash[V]) writeText(w io.Writer) error {
+	// Заголовок
+	if _, err := fmt.Fprintf(w, "%d %d\n", ch.tableSize, ch.elementsCount); err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < ch.tableSize; i++ {
+		if ch.table[i].IsOccupied {
+			value, err := codec.FormatText(ch.table[i].Value)
+			if err != nil {
+				return err
+			}
+			// Ключ в кавычках, поэтому может содержать пробелы
+			if _, err := fmt.Fprintf(w, "%d %s %s\n", i, strconv.Quote(ch.table[i].Key), value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (ch *CuckooHash[V]) readText(r io.Reader) error {
+	s := codec.NewTextScanner(r)
+	newTableSize, err := codec.ScanText[uint32](s)
+	if err != nil {
+		return fmt.Errorf("error: Incorrect file format or empty file: %w", err)
+	}
+	newElementsCount, err := codec.ScanText[uint32](s)
+	if err != nil {
+		return fmt.Errorf("error: Incorrect file format or empty file: %w", err)
+	}
+
+	ch.table = make([]HashNode[V], newTableSize+1)
+	ch.tableSize = newTableSize
+	ch.elementsCount = newElementsCount
+
+	for {
+		idx, key, value, err := scanEntry[V](s)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading data: %w", err)
+		}
+
+		if idx < ch.tableSize {
+			ch.table[idx] = HashNode[V]{Key: key, Value: value, IsOccupied: true}
+		} else {
+			return fmt.Errorf("error: File index (%d) is out of table bounds (%d)", idx, ch.tableSize)
+		}
+	}
+	return nil
+}
+
+// scanEntry читает запись "индекс ключ значение". io.EOF возвращается
+// только перед началом записи, оборванная запись - io.ErrUnexpectedEOF.
+func scanEntry[V any](s *codec.TextScanner) (uint32, string, V, error) {
+	var value V
+	idx, err := codec.ScanText[uint32](s)
+	if err != nil {
+		return 0, "", value, err
+	}
+	key, err := codec.ScanText[string](s)
+	if err == nil {
+		value, err = codec.ScanText[V](s)
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return idx, key, value, err
+}
+
+// SetCodec задает кодек элементов для бинарного формата значений таблицы
+// и журнала упреждающей записи. По умолчанию используется codec.Default, который поддерживает строки,
+// []byte, encoding.BinaryMarshaler и типы фиксированного размера.
+func (ch *CuckooHash[V]) SetCodec(c codec.ElementCodec[V]) {
+	ch.elemCodec = c
+}
+
+func (ch *CuckooHash[V]) elementCodec() (codec.ElementCodec[V], error) {
+	if ch.elemCodec != nil {
+		return ch.elemCodec, nil
+	}
+	return codec.Default[V]()
+}
+
+// SerializeBin сохраняет таблицу в бинарный файл
+func (ch *CuckooHash[V]) SerializeBin(filename string, opts ...persist.Option) error {
+	if err := persist.WriteFile(filename, ch.writeBinary, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Таблица успешно сохранена в %s\n", filename)
+	return nil
+}
+
+// DeserializeBin загружает таблицу из бинарного файла
+func (ch *CuckooHash[V]) DeserializeBin(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Could not open file for reading: %w", err)
+	}
+	defer file.Close()
+
+	if err := persist.Load(file, compression.NewReader, ch.readBinary, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Таблица успешно загружена из %s\n", filename)
+	return ch.checkpointIfLogged()
+}
+
+// WriteTo записывает таблицу в w в бинарном формате (io.WriterTo)
+func (ch *CuckooHash[V]) WriteTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, ch.writeBinary)
+}
+
+// ReadFrom читает таблицу в бинарном формате из r (io.ReaderFrom)
+func (ch *CuckooHash[V]) ReadFrom(r io.Reader) (int64, error) {
+	n, err := persist.ReadCounted(r, ch.readBinary)
+	if err != nil {
+		return n, err
+	}
+	return n, ch.checkpointIfLogged()
+}
+
+// writeBinary пишет таблицу в конверте persist с видом и типом элементов
+func (ch *CuckooHash[V]) writeBinary(w io.Writer) error {
+	return persist.WriteEnvelope(w, persist.KindCuckooHash, persist.TypeName[V](), ch.writePayload)
+}
+
+// readBinary проверяет конверт и читает таблицу
+func (ch *CuckooHash[V]) readBinary(r io.Reader) error {
+	return persist.ReadEnvelope(r, persist.KindCuckooHash, persist.TypeName[V](), ch.readPayload)
+}
+
+func (ch *CuckooHash[V]) writePayload(w io.Writer) error {
+	c, err := ch.elementCodec()
+	if err != nil {
+		return err
+	}
+
+	// Пишем размеры
+	if err := binary.Write(w, binary.LittleEndian, ch.tableSize); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, ch.elementsCount); err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < ch.tableSize; i++ {
+		occupied := ch.table[i].IsOccupied
+		if err := binary.Write(w, binary.LittleEndian, occupied); err != nil {
+			return err
+		}
+
+		if occupied {
+			keyBytes := []byte(ch.table[i].Key)
+			keyLen := uint32(len(keyBytes))
+
+			if err := binary.Write(w, binary.LittleEndian, keyLen); err != nil {
+				return err
+			}
+			if _, err := w.Write(keyBytes); err != nil {
+				return err
+			}
+			if err := c.Encode(w, ch.table[i].Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (ch *CuckooHash[V]) readPayload(r io.Reader) error {
+	c, err := ch.elementCodec()
+	if err != nil {
+		return err
+	}
+
+	var newTableSize, newElementsCount uint32
+	if err := binary.Read(r, binary.LittleEndian, &newTableSize); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &newElementsCount); err != nil {
+		return err
+	}
+
+	// На каждую ячейку приходится хотя бы флаг занятости
+	if err := persist.CheckCount(r, uint64(newTableSize), 1); err != nil {
+		return err
+	}
+
+	// Новая таблица заменит текущую только после успешного чтения
+	table := make([]HashNode[V], 0, min(int(newTableSize), codec.ChunkSize)+1)
+	for i := uint32(0); i < newTableSize; i++ {
+		var occupied bool
+		if err := binary.Read(r, binary.LittleEndian, &occupied); err != nil {
+			return fmt.Errorf("error reading occupied flag at %d: %w", i, err)
+		}
+
+		if occupied {
+			var keyLen uint32
+			if err := binary.Read(r, binary.LittleEndian, &keyLen); err != nil {
+				return err
+			}
+
+			// Длина ключа не может превышать остаток данных
+			if err := persist.CheckCount(r, uint64(keyLen), 1); err != nil {
+				return err
+			}
+			keyBuf, err := codec.DecodeN(r, codec.Fixed[byte]{}, int(keyLen))
+			if err != nil {
+				return err
+			}
+
+			value, err := c.Decode(r)
+			if err != nil {
+				return err
+			}
+
+			table = append(table, HashNode[V]{
+				Key:        string(keyBuf),
+				Value:      value,
+				IsOccupied: true,
+			})
+		} else {
+			table = append(table, HashNode[V]{})
+		}
+	}
+
+	ch.tableSize = newTableSize
+	ch.elementsCount = newElementsCount
+	ch.table = append(table, HashNode[V]{})
+	return nil
+}
+
+// Срезы и последовательности
+
+// FromSlice создает таблицу из пар entries (IsOccupied не учитывается).
+// Для повторяющихся ключей остается последнее значение.
+func FromSlice[V any](entries []HashNode[V]) *CuckooHash[V] {
+	ch := &CuckooHash[V]{}
+	ch.replaceAll(entries)
+	return ch
+}
+
+// FromSeq создает таблицу из пар seq. Для повторяющихся ключей остается последнее значение.
+func FromSeq[V any](seq iter.Seq2[string, V]) *CuckooHash[V] {
+	var entries []HashNode[V]
+	for key, value := range seq {
+		entries = append(entries, HashNode[V]{Key: key, Value: value, IsOccupied: true})
+	}
+	return FromSlice(entries)
+}
+
+// ToSlice возвращает занятые ячейки таблицы в порядке их расположения
+func (ch *CuckooHash[V]) ToSlice() []HashNode[V] {
+	entries := make([]HashNode[V], 0, ch.elementsCount)
+	ch.Range(func(key string, value V) bool {
+		entries = append(entries, HashNode[V]{Key: key, Value: value, IsOccupied: true})
+		return true
+	})
+	return entries
+}
+
+// encoding и gob
+
+// MarshalBinary возвращает таблицу в бинарном формате с конвертом
+// (encoding.BinaryMarshaler), поэтому ее можно вкладывать в другие структуры
+func (ch *CuckooHash[V]) MarshalBinary() ([]byte, error) {
+	return persist.Marshal(ch.writeBinary)
+}
+
+// UnmarshalBinary читает таблицу из данных MarshalBinary (encoding.BinaryUnmarshaler).
+// Данные должны содержать ровно один конверт.
+func (ch *CuckooHash[V]) UnmarshalBinary(data []byte) error {
+	if err := persist.Unmarshal(data, ch.readBinary); err != nil {
+		return err
+	}
+	return ch.checkpointIfLogged()
+}
+
+// GobEncode кодирует таблицу для encoding/gob (gob.GobEncoder)
+func (ch *CuckooHash[V]) GobEncode() ([]byte, error) {
+	return ch.MarshalBinary()
+}
+
+// GobDecode читает таблицу, закодированную GobEncode (gob.GobDecoder)
+func (ch *CuckooHash[V]) GobDecode(data []byte) error {
+	return ch.UnmarshalBinary(data)
+}
+
+// JSON
+
+// MarshalJSON кодирует таблицу как JSON-объект ключ -> значение (json.Marshaler)
+func (ch *CuckooHash[V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ch.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON заменяет содержимое таблицы парами из JSON-объекта
+// (json.Unmarshaler). При ошибке таблица не меняется.
+func (ch *CuckooHash[V]) UnmarshalJSON(data []byte) error {
+	if err := ch.readJSON(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	return ch.checkpointIfLogged()
+}
+
+// WriteJSONTo пишет таблицу в w как JSON-объект, не собирая его в памяти
+func (ch *CuckooHash[V]) WriteJSONTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, ch.writeJSON)
+}
+
+// ReadJSONFrom читает таблицу из JSON-объекта в r до конца потока
+func (ch *CuckooHash[V]) ReadJSONFrom(r io.Reader) (int64, error) {
+	n, err := persist.ReadCounted(r, ch.readJSON)
+	if err != nil {
+		return n, err
+	}
+	return n, ch.checkpointIfLogged()
+}
+
+func (ch *CuckooHash[V]) writeJSON(w io.Writer) error {
+	return codec.EncodeJSONObject(w, func(emit func(string, V) error) error {
+		for i := uint32(0); i < ch.tableSize; i++ {
+			if ch.table[i].IsOccupied {
+				if err := emit(ch.table[i].Key, ch.table[i].Value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (ch *CuckooHash[V]) readJSON(r io.Reader) error {
+	var entries []HashNode[V]
+	err := codec.ReadJSON(r, func(dec *json.Decoder) error {
+		return codec.DecodeJSONObject(dec, "$", func(key string, value V) error {
+			entries = append(entries, HashNode[V]{Key: key, Value: value})
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	ch.replaceAll(entries)
+	return nil
+}
+
+// replaceAll заменяет содержимое таблицы парами entries без записи в журнал
+func (ch *CuckooHash[V]) replaceAll(entries []HashNode[V]) {
+	// Нулевая таблица (например, поле структуры) получает размер по числу пар.
+	// Таблице из одной ячейки вторая хэш-функция не подходит, поэтому не меньше 3.
+	if ch.tableSize == 0 {
+		ch.tableSize = uint32(max(2*len(entries)+1, 3))
+	}
+	ch.table = make([]HashNode[V], ch.tableSize+1)
+	ch.elementsCount = 0
+	for _, e := range entries {
+		ch.insert(e.Key, e.Value)
+	}
+}
+
+// CSV
+
+// ExportCSV пишет таблицу в w в формате CSV: колонка key и колонки значения.
+// Колонки значения задаются codec.DefaultCSVMapper или опцией codec.WithCSVMapper.
+func (ch *CuckooHash[V]) ExportCSV(w io.Writer, opts ...codec.CSVOption) error {
+	return codec.WriteKeyedCSV(w, func(emit func(string, V) error) error {
+		for i := uint32(0); i < ch.tableSize; i++ {
+			if ch.table[i].IsOccupied {
+				if err := emit(ch.table[i].Key, ch.table[i].Value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}, opts...)
+}
+
+// ImportCSV заменяет содержимое таблицы строками CSV из r. Строки, которые
+// не удалось разобрать, и повторные ключи пропускаются и возвращаются
+// в codec.RowErrors с номерами строк. При ошибке чтения или заголовка
+// таблица не меняется.
+func (ch *CuckooHash[V]) ImportCSV(r io.Reader, opts ...codec.CSVOption) error {
+	var entries []HashNode[V]
+	rowErrs, err := codec.ReadKeyedCSV(r, func(key string, value V) error {
+		entries = append(entries, HashNode[V]{Key: key, Value: value})
+		return nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	ch.replaceAll(entries)
+	if err := ch.checkpointIfLogged(); err != nil {
+		return err
+	}
+	return rowErrs.Err()
+}
+
+// Журнал упреждающей записи
+
+// EnableWAL включает режим журнала в каталоге dir. Текущее содержимое таблицы
+// записывается снимком (прежние снимок и журнал в dir заменяются), а каждая
+// следующая операция Insert, Remove и Clear сначала дописывается в журнал.
+// Значения кодируются кодеком таблицы (SetCodec), как и в бинарном формате.
+func (ch *CuckooHash[V]) EnableWAL(dir string) error {
+	if ch.log != nil {
+		return ErrWALEnabled
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	log, err := wal.OpenLog(filepath.Join(dir, wal.LogFile))
+	if err != nil {
+		return err
+	}
+	ch.walDir = dir
+	ch.log = log
+	if err := ch.Checkpoint(); err != nil {
+		ch.CloseWAL()
+		return err
+	}
+	return nil
+}
+
+// Checkpoint записывает снимок таблицы и очищает журнал
+func (ch *CuckooHash[V]) Checkpoint() error {
+	if ch.log == nil {
+		return ErrWALDisabled
+	}
+	c, err := ch.elementCodec()
+	if err != nil {
+		return err
+	}
+	err = wal.Checkpoint(ch.walDir, ch.log, func(emit func(string, []byte) error) error {
+		for i := uint32(0); i < ch.tableSize; i++ {
+			if !ch.table[i].IsOccupied {
+				continue
+			}
+			data, err := wal.EncodeValue(c, ch.table[i].Value)
+			if err != nil {
+				return err
+			}
+			if err := emit(ch.table[i].Key, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		ch.walErr = nil
+	}
+	return err
+}
+
+func (ch *CuckooHash[V]) checkpointIfLogged() error {
+	if ch.log == nil {
+		return nil
+	}
+	return ch.Checkpoint()
+}
+
+// WALError возвращает ошибку журнала, произошедшую в Remove или Clear
+// после последнего успешного Checkpoint
+func (ch *CuckooHash[V]) WALError() error {
+	return ch.walErr
+}
+
+// CloseWAL закрывает журнал и выключает режим журнала
+func (ch *CuckooHash[V]) CloseWAL() error {
+	if ch.log == nil {
+		return ErrWALDisabled
+	}
+	err := ch.log.Close()
+	ch.log = nil
+	ch.walDir = ""
+	return err
+}
+
+// Recover восстанавливает таблицу из каталога dir: последний снимок плюс журнал.
+// Возвращенная таблица продолжает работать в режиме журнала.
+// Значения читаются кодеком по умолчанию.
+func Recover[V any](dir string) (*CuckooHash[V], error) {
+	return RecoverWithCodec[V](dir, nil)
+}
+
+// RecoverWithCodec работает как Recover для таблицы, журнал которой писался
+// с кодеком c (SetCodec). Кодек остается у возвращенной таблицы.
+func RecoverWithCodec[V any](dir string, c codec.ElementCodec[V]) (*CuckooHash[V], error) {
+	ch := NewCuckooHash[V](16)
+	ch.SetCodec(c)
+	c, err := ch.elementCodec()
+	if err != nil {
+		return nil, err
+	}
+	log, err := wal.Recover(dir, func(rec wal.Record) error {
+		switch rec.Op {
+		case wal.OpInsert:
+			value, err := wal.DecodeValue(c, rec.Value)
+			if err != nil {
+				return err
+			}
+			ch.insert(rec.Key, value)
+		case wal.OpRemove:
+			ch.remove(rec.Key)
+		case wal.OpClear:
+			ch.clear()
+		default:
+			return fmt.Errorf("%w: %d", wal.ErrBadOp, rec.Op)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ch.walDir = dir
+	ch.log = log
+	return ch, nil
+}
@@ -0,0 +1,266 @@
+package cuckoofilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"math/rand"
+	"os"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/hashing"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+const (
+	bucketSize = 4   // Отпечатков в одной корзине
+	maxKicks   = 500 // Ограничение на количество выталкиваний
+)
+
+// Ошибки, которые может вернуть фильтр
+var (
+	ErrFilterFull = errors.New("cuckoo filter is full")
+)
+
+// bucket хранит отпечатки; 0 означает пустую ячейку
+type bucket [bucketSize]uint16
+
+// CuckooFilter реализует кукушкин фильтр: приблизительное множество с удалением.
+// Как и в CuckooHash, у каждого ключа две возможные позиции, а при переполнении
+// занятые элементы выталкиваются в альтернативную корзину.
+type CuckooFilter struct {
+	buckets []bucket
+	mask    uint64 // Количество корзин - степень двойки, индекс берется по маске
+	count   uint32
+	rnd     *rand.Rand
+
+	// victim - отпечаток, который не удалось разместить после maxKicks выталкиваний.
+	// Хранится отдельно, чтобы фильтр не терял уже добавленные ключи.
+	victim      uint16
+	victimIndex uint64
+}
+
+// NewCuckooFilter создает фильтр, рассчитанный примерно на capacity ключей
+func NewCuckooFilter(capacity uint32) *CuckooFilter {
+	numBuckets := uint64(1)
+	if capacity > bucketSize {
+		// Округляем вверх до степени двойки
+		numBuckets = 1 << bits.Len64(uint64(capacity-1)/bucketSize)
+	}
+	return &CuckooFilter{
+		buckets: make([]bucket, numBuckets),
+		mask:    numBuckets - 1,
+		rnd:     rand.New(rand.NewSource(1)),
+	}
+}
+
+// fingerprint возвращает отпечаток и первую корзину ключа
+func (cf *CuckooFilter) fingerprint(key string) (uint16, uint64) {
+	h := hashing.Sum64(key)
+	fp := uint16(h >> 48)
+	if fp == 0 {
+		fp = 1 // 0 зарезервирован под пустую ячейку
+	}
+	return fp, h & cf.mask
+}
+
+// altIndex вычисляет вторую корзину: i2 = i1 XOR hash(fp).
+// Операция обратима, поэтому из любой корзины можно найти другую, зная только отпечаток.
+func (cf *CuckooFilter) altIndex(index uint64, fp uint16) uint64 {
+	return (index ^ hashing.Mix64(uint64(fp))) & cf.mask
+}
+
+func (b *bucket) insert(fp uint16) bool {
+	for i := range b {
+		if b[i] == 0 {
+			b[i] = fp
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bucket) contains(fp uint16) bool {
+	for i := range b {
+		if b[i] == fp {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bucket) remove(fp uint16) bool {
+	for i := range b {
+		if b[i] == fp {
+			b[i] = 0
+			return true
+		}
+	}
+	return false
+}
+
+// Insert добавляет ключ. Повторная вставка того же ключа добавляет еще один отпечаток.
+func (cf *CuckooFilter) Insert(key string) error {
+	if cf.victim != 0 {
+		return ErrFilterFull
+	}
+
+	fp, i1 := cf.fingerprint(key)
+	i2 := cf.altIndex(i1, fp)
+
+	if cf.buckets[i1].insert(fp) || cf.buckets[i2].insert(fp) {
+		cf.count++
+		return nil
+	}
+
+	// Выталкивание из случайной корзины и ячейки
+	index := i1
+	if cf.rnd.Intn(2) == 1 {
+		index = i2
+	}
+	for i := 0; i < maxKicks; i++ {
+		slot := cf.rnd.Intn(bucketSize)
+		fp, cf.buckets[index][slot] = cf.buckets[index][slot], fp
+
+		index = cf.altIndex(index, fp)
+		if cf.buckets[index].insert(fp) {
+			cf.count++
+			return nil
+		}
+	}
+
+	// Последний вытолкнутый отпечаток откладываем: ключ добавлен,
+	// но следующие вставки будут отклонены до удаления какого-нибудь ключа
+	cf.victim = fp
+	cf.victimIndex = index
+	cf.count++
+	return nil
+}
+
+// Contains возвращает false, если ключа точно нет, и true, если он, вероятно, есть
+func (cf *CuckooFilter) Contains(key string) bool {
+	fp, i1 := cf.fingerprint(key)
+	i2 := cf.altIndex(i1, fp)
+	if cf.victim == fp && (cf.victimIndex == i1 || cf.victimIndex == i2) {
+		return true
+	}
+	return cf.buckets[i1].contains(fp) || cf.buckets[i2].contains(fp)
+}
+
+// Remove удаляет один отпечаток ключа. Удалять можно только добавленные ключи,
+// иначе может пропасть отпечаток другого ключа с тем же значением.
+func (cf *CuckooFilter) Remove(key string) bool {
+	fp, i1 := cf.fingerprint(key)
+	i2 := cf.altIndex(i1, fp)
+	if cf.victim == fp && (cf.victimIndex == i1 || cf.victimIndex == i2) {
+		cf.victim = 0
+		cf.count--
+		return true
+	}
+	if cf.buckets[i1].remove(fp) || cf.buckets[i2].remove(fp) {
+		cf.count--
+		// Освободилось место: пробуем вернуть отложенный отпечаток в таблицу
+		if cf.victim != 0 {
+			alt := cf.altIndex(cf.victimIndex, cf.victim)
+			if cf.buckets[cf.victimIndex].insert(cf.victim) || cf.buckets[alt].insert(cf.victim) {
+				cf.victim = 0
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Size возвращает количество хранимых отпечатков
+func (cf *CuckooFilter) Size() uint32 {
+	return cf.count
+}
+
+// Empty проверяет, пуст ли фильтр
+func (cf *CuckooFilter) Empty() bool {
+	return cf.count == 0
+}
+
+// LoadFactor возвращает долю занятых ячеек
+func (cf *CuckooFilter) LoadFactor() float64 {
+	return float64(cf.count) / float64(len(cf.buckets)*bucketSize)
+}
+
+// Clear очищает фильтр
+func (cf *CuckooFilter) Clear() {
+	for i := range cf.buckets {
+		cf.buckets[i] = bucket{}
+	}
+	cf.count = 0
+	cf.victim = 0
+}
+
+// SaveBinary сохраняет фильтр в бинарный файл
+func (cf *CuckooFilter) SaveBinary(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, func(file io.Writer) error {
+		if err := binary.Write(file, binary.LittleEndian, uint64(len(cf.buckets))); err != nil {
+			return err
+		}
+		if err := binary.Write(file, binary.LittleEndian, cf.count); err != nil {
+			return err
+		}
+		if err := binary.Write(file, binary.LittleEndian, cf.victim); err != nil {
+			return err
+		}
+		if err := binary.Write(file, binary.LittleEndian, cf.victimIndex); err != nil {
+			return err
+		}
+		return binary.Write(file, binary.LittleEndian, cf.buckets)
+	}, opts...)
+}
+
+// LoadBinary загружает фильтр из бинарного файла (в том числе сжатого)
+func (cf *CuckooFilter) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Could not open binary file for reading: %w", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, cf.readBinary, opts...)
+}
+
+func (cf *CuckooFilter) readBinary(r io.Reader) error {
+	var numBuckets, victimIndex uint64
+	var count uint32
+	var victim uint16
+	if err := binary.Read(r, binary.LittleEndian, &numBuckets); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &victim); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &victimIndex); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if numBuckets == 0 || numBuckets&(numBuckets-1) != 0 || numBuckets > math.MaxInt {
+		return fmt.Errorf("number of buckets must be a power of two, got %d", numBuckets)
+	}
+
+	newBuckets, err := codec.DecodeN(r, codec.Fixed[bucket]{}, int(numBuckets))
+	if err != nil {
+		return fmt.Errorf("failed to read buckets: %w", err)
+	}
+	if err := persist.CheckEOF(r); err != nil {
+		return err
+	}
+
+	cf.buckets = newBuckets
+	cf.mask = numBuckets - 1
+	cf.count = count
+	cf.victim = victim
+	cf.victimIndex = victimIndex & cf.mask
+	return nil
+}
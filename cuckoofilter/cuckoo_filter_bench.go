@@ -0,0 +1,43 @@
+package cuckoofilter
+
+import (
+	"fmt"
+	"testing"
+)
+
+const NumElements = 100000 // Количество ключей для теста
+
+func generateKeys(prefix string, count int) []string {
+	keys := make([]string, count)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return keys
+}
+
+// BenchmarkInsert измеряет заполнение фильтра примерно на 75%.
+func BenchmarkInsert(b *testing.B) {
+	keys := generateKeys("key", NumElements)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		cf := NewCuckooFilter(NumElements * 4 / 3)
+		for _, k := range keys {
+			_ = cf.Insert(k)
+		}
+	}
+}
+
+// BenchmarkContainsMiss измеряет отрицательный поиск.
+func BenchmarkContainsMiss(b *testing.B) {
+	cf := NewCuckooFilter(NumElements * 4 / 3)
+	for _, k := range generateKeys("key", NumElements) {
+		_ = cf.Insert(k)
+	}
+	missing := generateKeys("missing", NumElements)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = cf.Contains(missing[i%NumElements])
+	}
+}
@@ -0,0 +1,183 @@
+package cuckoofilter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+func TestConstructor(t *testing.T) {
+	cf := NewCuckooFilter(0)
+	if len(cf.buckets) != 1 || !cf.Empty() {
+		t.Errorf("Expected single empty bucket, got %d buckets", len(cf.buckets))
+	}
+
+	cf = NewCuckooFilter(100)
+	if len(cf.buckets)*bucketSize < 100 {
+		t.Errorf("Not enough slots for 100 keys: %d buckets", len(cf.buckets))
+	}
+	if n := len(cf.buckets); n&(n-1) != 0 {
+		t.Errorf("Number of buckets %d is not a power of two", n)
+	}
+}
+
+func TestInsertContainsRemove(t *testing.T) {
+	cf := NewCuckooFilter(2000)
+
+	for i := 0; i < 1000; i++ {
+		if err := cf.Insert(fmt.Sprintf("key%d", i)); err != nil {
+			t.Fatalf("Insert key%d failed: %v", i, err)
+		}
+	}
+	if cf.Size() != 1000 {
+		t.Errorf("Expected size 1000, got %d", cf.Size())
+	}
+	for i := 0; i < 1000; i++ {
+		if !cf.Contains(fmt.Sprintf("key%d", i)) {
+			t.Fatalf("False negative for key%d", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if cf.Contains(fmt.Sprintf("missing%d", i)) {
+			falsePositives++
+		}
+	}
+	// 16-битный отпечаток и 8 кандидатов дают ~0.01%
+	if falsePositives > 10 {
+		t.Errorf("Too many false positives: %d", falsePositives)
+	}
+
+	// Удаление половины ключей
+	for i := 0; i < 500; i++ {
+		if !cf.Remove(fmt.Sprintf("key%d", i)) {
+			t.Fatalf("Remove key%d failed", i)
+		}
+	}
+	if cf.Size() != 500 {
+		t.Errorf("Expected size 500, got %d", cf.Size())
+	}
+	for i := 500; i < 1000; i++ {
+		if !cf.Contains(fmt.Sprintf("key%d", i)) {
+			t.Fatalf("Remaining key%d lost", i)
+		}
+	}
+	if cf.Remove("never-added") {
+		t.Error("Remove of missing key should return false")
+	}
+
+	cf.Clear()
+	if !cf.Empty() || cf.Contains("key900") {
+		t.Error("Filter not empty after Clear")
+	}
+}
+
+func TestFilterFull(t *testing.T) {
+	cf := NewCuckooFilter(8)
+
+	var err error
+	inserted := 0
+	for ; inserted < 100; inserted++ {
+		if err = cf.Insert(fmt.Sprintf("key%d", inserted)); err != nil {
+			break
+		}
+	}
+	if err != ErrFilterFull {
+		t.Fatalf("Expected ErrFilterFull, got %v", err)
+	}
+
+	// Все успешно добавленные ключи (включая отложенный) должны находиться
+	for i := 0; i < inserted; i++ {
+		if !cf.Contains(fmt.Sprintf("key%d", i)) {
+			t.Errorf("key%d lost on overflow", i)
+		}
+	}
+
+	// После удаления появляется место для новой вставки
+	if !cf.Remove("key0") {
+		t.Fatal("Remove failed")
+	}
+	if err := cf.Insert("fresh"); err != nil {
+		t.Errorf("Insert after Remove failed: %v", err)
+	}
+}
+
+func TestSaveLoadBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "cf.bin")
+
+	cf := NewCuckooFilter(100)
+	for i := 0; i < 50; i++ {
+		cf.Insert(fmt.Sprintf("key%d", i))
+	}
+	if err := cf.SaveBinary(file); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+
+	loaded := NewCuckooFilter(1)
+	if err := loaded.LoadBinary(file); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+	if loaded.Size() != 50 {
+		t.Errorf("Expected size 50, got %d", loaded.Size())
+	}
+	for i := 0; i < 50; i++ {
+		if !loaded.Contains(fmt.Sprintf("key%d", i)) {
+			t.Fatalf("key%d lost after load", i)
+		}
+	}
+
+	// Ошибки
+	if err := cf.SaveBinary(""); err == nil {
+		t.Error("Expected error for empty filename")
+	}
+	if err := loaded.LoadBinary(filepath.Join(tmpDir, "missing.bin")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	data, _ := os.ReadFile(file)
+	badCount := filepath.Join(tmpDir, "badcount.bin")
+	bad := append([]byte{}, data...)
+	bad[0] = 3 // 3 корзины - не степень двойки
+	os.WriteFile(badCount, bad, 0644)
+	if err := loaded.LoadBinary(badCount); err == nil {
+		t.Error("Expected error for non power of two bucket count")
+	}
+
+	truncated := filepath.Join(tmpDir, "truncated.bin")
+	os.WriteFile(truncated, data[:len(data)-1], 0644)
+	if err := loaded.LoadBinary(truncated); err == nil {
+		t.Error("Expected error for truncated file")
+	}
+}
+
+// TestSaveLoadCompressed проверяет, что LoadBinary распаковывает файлы SaveBinary
+func TestSaveLoadCompressed(t *testing.T) {
+	cf := NewCuckooFilter(1000)
+	for i := 0; i < 500; i++ {
+		cf.Insert(fmt.Sprintf("key%d", i))
+	}
+	for _, alg := range []compression.Algorithm{compression.Gzip, compression.Flate, compression.LZ} {
+		file := filepath.Join(t.TempDir(), "cf.bin")
+		if err := cf.SaveBinary(file, compression.With(alg)); err != nil {
+			t.Fatalf("%v: SaveBinary failed: %v", alg, err)
+		}
+		loaded := NewCuckooFilter(1)
+		if err := loaded.LoadBinary(file); err != nil {
+			t.Fatalf("%v: LoadBinary failed: %v", alg, err)
+		}
+		if loaded.Size() != cf.Size() || !loaded.Contains("key499") {
+			t.Errorf("%v: state lost after load", alg)
+		}
+		// Предел WithLimit считается по распакованным данным
+		if err := loaded.LoadBinary(file, persist.WithLimit(16)); !errors.Is(err, persist.ErrLimitExceeded) {
+			t.Errorf("%v: expected ErrLimitExceeded, got %v", alg, err)
+		}
+	}
+}
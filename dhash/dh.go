@@ -1,383 +1,918 @@
-package dhash
-
-import (
-	"encoding/binary"
-	"fmt"
-	"io"
-	"math"
-	"os"
-)
-
-// HashNode представляет узел хеш-таблицы
-type HashNode[T any] struct {
-	Key        string
-	Value      T
-	IsOccupied bool
-}
-
-// DoubleHash реализует хеш-таблицу с двойным хешированием
-type DoubleHash[T any] struct {
-	table         []HashNode[T]
-	tableSize     uint32
-	elementsCount uint32
-}
-
-// NewDoubleHash создает новую таблицу заданного размера
-func NewDoubleHash[T any](size uint32) (*DoubleHash[T], error) {
-	if size == 0 {
-		return nil, fmt.Errorf("table size cannot be zero")
-	}
-	return &DoubleHash[T]{
-		table:         make([]HashNode[T], size+1),
-		tableSize:     size,
-		elementsCount: 0,
-	}, nil
-}
-
-// hash1 реализует метод умножения (золотое сечение)
-func (dh *DoubleHash[T]) hash1(key string) uint32 {
-	var numKey uint64 = 0
-	for _, c := range []byte(key) {
-		numKey = numKey*31 + uint64(c)
-	}
-
-	const A = (2.2360679775 - 1.0) / 2.0 // (sqrt(5) - 1) / 2
-	temp := float64(numKey) * A
-	temp = temp - math.Floor(temp) // Дробная часть
-
-	return uint32(math.Floor(float64(dh.tableSize) * temp))
-}
-
-// hash2 реализует метод свертки
-func (dh *DoubleHash[T]) hash2(key string) uint32 {
-	var sum uint32 = 0
-	for _, c := range []byte(key) {
-		sum += uint32(c)
-	}
-
-	result := (sum % (dh.tableSize - 1)) + 1
-
-	// Делаем результат нечетным, если размер таблицы четный
-	if dh.tableSize%2 == 0 && result%2 == 0 {
-		result++
-	}
-	return result
-}
-
-// needResize проверяет load factor > 0.7
-func (dh *DoubleHash[T]) needResize() bool {
-	if dh.tableSize == 0 {
-		return true
-	}
-	return (float64(dh.elementsCount) / float64(dh.tableSize)) > 0.7
-}
-
-// resize увеличивает таблицу и перехеширует элементы
-func (dh *DoubleHash[T]) resize() {
-	oldTable := dh.table
-	oldSize := dh.tableSize
-
-	dh.tableSize = dh.tableSize*2 + 1
-	dh.table = make([]HashNode[T], dh.tableSize+1)
-	dh.elementsCount = 0
-
-	for i := uint32(0); i < oldSize; i++ {
-		if oldTable[i].IsOccupied {
-			_ = dh.Insert(oldTable[i].Key, oldTable[i].Value)
-		}
-	}
-}
-
-// Insert вставляет элемент или обновляет значение
-func (dh *DoubleHash[T]) Insert(key string, value T) error {
-	if dh.needResize() {
-		dh.resize()
-	}
-
-	h1 := dh.hash1(key)
-	h2 := dh.hash2(key)
-	var i uint32 = 0
-
-	for i < dh.tableSize {
-		index := (h1 + i*h2) % dh.tableSize
-
-		// Если ячейка свободна
-		if !dh.table[index].IsOccupied {
-			dh.table[index] = HashNode[T]{Key: key, Value: value, IsOccupied: true}
-			dh.elementsCount++
-			return nil
-		}
-
-		// Если ключ совпадает, обновляем значение
-		if dh.table[index].Key == key {
-			dh.table[index].Value = value
-			return nil
-		}
-
-		i++
-	}
-
-	return fmt.Errorf("error: Hash table is full, cannot insert key")
-}
-
-// Find ищет элемент по ключу. Возвращает указатель на значение или nil
-func (dh *DoubleHash[T]) Find(key string) *T {
-	if dh.elementsCount == 0 {
-		return nil
-	}
-
-	h1 := dh.hash1(key)
-	h2 := dh.hash2(key)
-	var i uint32 = 0
-
-	for i < dh.tableSize {
-		index := (h1 + i*h2) % dh.tableSize
-
-		if !dh.table[index].IsOccupied {
-			return nil
-		}
-
-		if dh.table[index].Key == key {
-			return &dh.table[index].Value
-		}
-		i++
-	}
-	return nil
-}
-
-// Remove удаляет элемент по ключу
-func (dh *DoubleHash[T]) Remove(key string) bool {
-	if dh.elementsCount == 0 {
-		return false
-	}
-
-	h1 := dh.hash1(key)
-	h2 := dh.hash2(key)
-	var i uint32 = 0
-
-	for i < dh.tableSize {
-		index := (h1 + i*h2) % dh.tableSize
-
-		if !dh.table[index].IsOccupied {
-			return false
-		}
-
-		if dh.table[index].Key == key {
-			dh.table[index].IsOccupied = false
-			// В Go нужно занулить значения, чтобы сборщик мусора мог очистить память
-			var empty T
-			dh.table[index].Value = empty
-			dh.table[index].Key = ""
-			dh.elementsCount--
-			return true
-		}
-		i++
-	}
-	return false
-}
-
-// Size возвращает количество элементов
-func (dh *DoubleHash[T]) Size() uint32 {
-	return dh.elementsCount
-}
-
-// Empty проверяет, пуста ли таблица
-func (dh *DoubleHash[T]) Empty() bool {
-	return dh.elementsCount == 0
-}
-
-// Clear очищает таблицу
-func (dh *DoubleHash[T]) Clear() {
-	dh.table = make([]HashNode[T], dh.tableSize+1)
-	dh.elementsCount = 0
-}
-
-// Print выводит таблицу в stdout
-func (dh *DoubleHash[T]) Print() {
-	fmt.Println("=== Хэш-таблица ===")
-	fmt.Printf("Размер: %d, Элементов: %d\n", dh.tableSize, dh.elementsCount)
-	for i := uint32(0); i < dh.tableSize; i++ {
-		if dh.table[i].IsOccupied {
-			fmt.Printf("[%d] %s => %v\n", i, dh.table[i].Key, dh.table[i].Value)
-		}
-	}
-	fmt.Println("===================")
-}
-
-// SerializeText сохраняет таблицу в текстовый файл
-func (dh *DoubleHash[T]) SerializeText(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error: Could not open file for writing: %w", err)
-	}
-	defer file.Close()
-
-	if _, err := fmt.Fprintf(file, "%d %d\n", dh.tableSize, dh.elementsCount); err != nil {
-		return err
-	}
-
-	for i := uint32(0); i < dh.tableSize; i++ {
-		if dh.table[i].IsOccupied {
-			// Внимание: T должен поддерживать стандартное форматирование %v
-			if _, err := fmt.Fprintf(file, "%d %s %v\n", i, dh.table[i].Key, dh.table[i].Value); err != nil {
-				return err
-			}
-		}
-	}
-	fmt.Printf("Таблица (текст) успешно сохранена в %s\n", filename)
-	return nil
-}
-
-// DeserializeText загружает таблицу из текстового файла
-func (dh *DoubleHash[T]) DeserializeText(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("error: Could not open file for reading: %w", err)
-	}
-	defer file.Close()
-
-	var newTableSize, newElementsCount uint32
-	if _, err := fmt.Fscan(file, &newTableSize, &newElementsCount); err != nil {
-		return fmt.Errorf("could not read header: %w", err)
-	}
-
-	if newTableSize == 0 {
-		return fmt.Errorf("size of table equal to zero")
-	}
-
-	// Инициализация новой таблицы
-	dh.tableSize = newTableSize
-	dh.elementsCount = newElementsCount
-	dh.table = make([]HashNode[T], dh.tableSize+1)
-
-	for {
-		var idx uint32
-		var key string
-		var value T
-
-		// Fscan ожидает пробелы между элементами.
-		_, err := fmt.Fscan(file, &idx, &key, &value)
-		if err != nil {
-			// Конец файла — это нормально
-			if err.Error() == "EOF" {
-				break
-			}
-			return fmt.Errorf("error reading data: %w", err)
-		}
-
-		if idx >= dh.tableSize {
-			return fmt.Errorf("index in file (%d) exceeds table size (%d)", idx, dh.tableSize)
-		}
-
-		dh.table[idx] = HashNode[T]{Key: key, Value: value, IsOccupied: true}
-	}
-
-	fmt.Printf("Таблица (текст) успешно загружена из %s\n", filename)
-	return nil
-}
-
-// SerializeBin сохраняет таблицу в бинарный файл
-func (dh *DoubleHash[T]) SerializeBin(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error: Could not open binary file for writing: %w", err)
-	}
-	defer file.Close()
-
-	// Записываем размеры заголовка
-	if err := binary.Write(file, binary.LittleEndian, dh.tableSize); err != nil {
-		return err
-	}
-	if err := binary.Write(file, binary.LittleEndian, dh.elementsCount); err != nil {
-		return err
-	}
-
-	for i := uint32(0); i < dh.tableSize; i++ {
-		occupied := dh.table[i].IsOccupied
-
-		// Пишем флаг занятости
-		if err := binary.Write(file, binary.LittleEndian, occupied); err != nil {
-			return err
-		}
-
-		if occupied {
-			// Пишем длину ключа
-			keyBytes := []byte(dh.table[i].Key)
-			keyLen := uint32(len(keyBytes))
-			if err := binary.Write(file, binary.LittleEndian, keyLen); err != nil {
-				return err
-			}
-
-			// Пишем сам ключ
-			if _, err := file.Write(keyBytes); err != nil {
-				return err
-			}
-
-			// Пишем значение
-			if err := binary.Write(file, binary.LittleEndian, dh.table[i].Value); err != nil {
-				return fmt.Errorf("failed to write value (type %T is likely not fixed-size): %w", dh.table[i].Value, err)
-			}
-		}
-	}
-
-	fmt.Printf("Таблица (бинарн. без gob) сохранена в %s\n", filename)
-	return nil
-}
-
-// DeserializeBin загружает таблицу из бинарного файла
-func (dh *DoubleHash[T]) DeserializeBin(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("error: Could not open binary file for reading: %w", err)
-	}
-	defer file.Close()
-
-	// Читаем заголовок
-	var newTableSize, newElementsCount uint32
-	if err := binary.Read(file, binary.LittleEndian, &newTableSize); err != nil {
-		return err
-	}
-	if err := binary.Read(file, binary.LittleEndian, &newElementsCount); err != nil {
-		return err
-	}
-
-	dh.tableSize = newTableSize
-	dh.elementsCount = newElementsCount
-	dh.table = make([]HashNode[T], dh.tableSize+1)
-
-	for i := uint32(0); i < dh.tableSize; i++ {
-		var occupied bool
-		// Читаем флаг занятости
-		if err := binary.Read(file, binary.LittleEndian, &occupied); err != nil {
-			return fmt.Errorf("read error at index %d: %w", i, err)
-		}
-
-		if occupied {
-			// Читаем длину ключа
-			var keyLen uint32
-			if err := binary.Read(file, binary.LittleEndian, &keyLen); err != nil {
-				return err
-			}
-
-			// Читаем сам ключ
-			keyBuf := make([]byte, keyLen)
-			if _, err := io.ReadFull(file, keyBuf); err != nil {
-				return fmt.Errorf("failed to read key string")
-			}
-			key := string(keyBuf)
-
-			// Читаем значение
-			var value T
-			if err := binary.Read(file, binary.LittleEndian, &value); err != nil {
-				return fmt.Errorf("failed to read value: %w", err)
-			}
-
-			dh.table[i] = HashNode[T]{Key: key, Value: value, IsOccupied: true}
-		} else {
-			dh.table[i].IsOccupied = false
-		}
-	}
-
-	fmt.Printf("Таблица (бинарн. без gob) загружена из %s\n", filename)
-	return nil
-}
+package dhash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/hashing"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+	"github.com/D4ROVAN1E/LR_3_Go/wal"
+)
+
+// Ошибки режима журнала
+var (
+	ErrWALEnabled  = errors.New("write-ahead log is already enabled")
+	ErrWALDisabled = errors.New("write-ahead log is not enabled")
+)
+
+// HashNode представляет узел хеш-таблицы
+type HashNode[T any] struct {
+	Key        string
+	Value      T
+	IsOccupied bool
+	IsDeleted  bool // Надгробие: ячейка освобождена, но цепочка проб не прерывается
+}
+
+// DoubleHash реализует хеш-таблицу с двойным хешированием
+type DoubleHash[T any] struct {
+	table         []HashNode[T]
+	tableSize     uint32
+	elementsCount uint32
+	deletedCount  uint32
+
+	// Режим журнала: каталог со снимком и журналом, открытый журнал
+	// и ошибка записи операции, которая не смогла ее вернуть (Remove, Clear)
+	walDir string
+	log    *wal.Log
+	walErr error
+
+	// Кодек элементов для бинарного формата; nil - codec.Default
+	elemCodec codec.ElementCodec[T]
+}
+
+// NewDoubleHash создает новую таблицу заданного размера
+func NewDoubleHash[T any](size uint32) (*DoubleHash[T], error) {
+	if size == 0 {
+		return nil, fmt.Errorf("table size cannot be zero")
+	}
+	return &DoubleHash[T]{
+		table:         make([]HashNode[T], size+1),
+		tableSize:     size,
+		elementsCount: 0,
+	}, nil
+}
+
+// hash1 реализует метод умножения (золотое сечение)
+func (dh *DoubleHash[T]) hash1(key string) uint32 {
+	const A = (2.2360679775 - 1.0) / 2.0 // (sqrt(5) - 1) / 2
+	return hashing.Multiplicative(hashing.Polynomial(key), A, dh.tableSize)
+}
+
+// hash2 реализует метод свертки
+func (dh *DoubleHash[T]) hash2(key string) uint32 {
+	return hashing.Folding(key, dh.tableSize)
+}
+
+// needResize проверяет load factor > 0.7
+func (dh *DoubleHash[T]) needResize() bool {
+	if dh.tableSize == 0 {
+		return true
+	}
+	// Надгробия тоже удлиняют цепочки проб, поэтому учитываем их
+	return (float64(dh.elementsCount+dh.deletedCount) / float64(dh.tableSize)) > 0.7
+}
+
+// resize увеличивает таблицу и перехеширует элементы
+func (dh *DoubleHash[T]) resize() {
+	oldTable := dh.table
+	oldSize := dh.tableSize
+
+	dh.tableSize = dh.tableSize*2 + 1
+	dh.table = make([]HashNode[T], dh.tableSize+1)
+	dh.elementsCount = 0
+	dh.deletedCount = 0
+
+	for i := uint32(0); i < oldSize; i++ {
+		if oldTable[i].IsOccupied {
+			_ = dh.insert(oldTable[i].Key, oldTable[i].Value)
+		}
+	}
+}
+
+// Insert вставляет элемент или обновляет значение.
+// В режиме журнала операция записывается в журнал после изменения таблицы:
+// неудачная вставка не попадает в журнал, а при ошибке записи журнала
+// изменение откатывается.
+func (dh *DoubleHash[T]) Insert(key string, value T) error {
+	if dh.log == nil {
+		return dh.insert(key, value)
+	}
+
+	c, err := dh.elementCodec()
+	if err != nil {
+		return err
+	}
+	data, err := wal.EncodeValue(c, value)
+	if err != nil {
+		return err
+	}
+
+	var previous T
+	old := dh.Find(key)
+	if old != nil {
+		previous = *old
+	}
+	if err := dh.insert(key, value); err != nil {
+		return err
+	}
+	if err := dh.log.Append(wal.Record{Op: wal.OpInsert, Key: key, Value: data}); err != nil {
+		// Вставка могла перестроить таблицу, поэтому ищем ключ заново
+		if old != nil {
+			*dh.Find(key) = previous
+		} else {
+			dh.remove(key)
+		}
+		return err
+	}
+	return nil
+}
+
+func (dh *DoubleHash[T]) insert(key string, value T) error {
+	if dh.needResize() {
+		dh.resize()
+	}
+
+	h1 := dh.hash1(key)
+	h2 := dh.hash2(key)
+	var i uint32 = 0
+	// Первое встреченное надгробие: сюда вставим, если ключа нет дальше по цепочке
+	tombstone := int64(-1)
+
+	for i < dh.tableSize {
+		index := (h1 + i*h2) % dh.tableSize
+
+		// Надгробие запоминаем, но продолжаем искать ключ
+		if dh.table[index].IsDeleted {
+			if tombstone < 0 {
+				tombstone = int64(index)
+			}
+			i++
+			continue
+		}
+
+		// Если ячейка свободна
+		if !dh.table[index].IsOccupied {
+			break
+		}
+
+		// Если ключ совпадает, обновляем значение
+		if dh.table[index].Key == key {
+			dh.table[index].Value = value
+			return nil
+		}
+
+		i++
+	}
+
+	if tombstone >= 0 {
+		dh.table[tombstone] = HashNode[T]{Key: key, Value: value, IsOccupied: true}
+		dh.elementsCount++
+		dh.deletedCount--
+		return nil
+	}
+
+	if i < dh.tableSize {
+		index := (h1 + i*h2) % dh.tableSize
+		dh.table[index] = HashNode[T]{Key: key, Value: value, IsOccupied: true}
+		dh.elementsCount++
+		return nil
+	}
+
+	// Если шаг h2 не взаимно прост с размером, цепочка проб обходит только
+	// часть ячеек и может замкнуться на занятых. Пока в таблице есть место,
+	// увеличиваем ее: после перехеширования у ключа другая цепочка.
+	if dh.elementsCount < dh.tableSize {
+		dh.resize()
+		return dh.insert(key, value)
+	}
+
+	return fmt.Errorf("error: Hash table is full, cannot insert key")
+}
+
+// Find ищет элемент по ключу. Возвращает указатель на значение или nil
+func (dh *DoubleHash[T]) Find(key string) *T {
+	if dh.elementsCount == 0 {
+		return nil
+	}
+
+	h1 := dh.hash1(key)
+	h2 := dh.hash2(key)
+	var i uint32 = 0
+
+	for i < dh.tableSize {
+		index := (h1 + i*h2) % dh.tableSize
+
+		if dh.table[index].IsDeleted {
+			i++
+			continue
+		}
+
+		if !dh.table[index].IsOccupied {
+			return nil
+		}
+
+		if dh.table[index].Key == key {
+			return &dh.table[index].Value
+		}
+		i++
+	}
+	return nil
+}
+
+// Remove удаляет элемент по ключу.
+// Если в режиме журнала запись не удалась, элемент не удаляется и Remove
+// возвращает false, как для отсутствующего ключа. Отличить сбой можно так:
+// после false ключ по-прежнему находится через Find, а ошибка доступна
+// через WALError (она хранится до следующего успешного Checkpoint).
+func (dh *DoubleHash[T]) Remove(key string) bool {
+	if dh.log != nil && dh.Find(key) != nil {
+		if err := dh.log.Append(wal.Record{Op: wal.OpRemove, Key: key}); err != nil {
+			dh.walErr = err
+			return false
+		}
+	}
+	return dh.remove(key)
+}
+
+func (dh *DoubleHash[T]) remove(key string) bool {
+	if dh.elementsCount == 0 {
+		return false
+	}
+
+	h1 := dh.hash1(key)
+	h2 := dh.hash2(key)
+	var i uint32 = 0
+
+	for i < dh.tableSize {
+		index := (h1 + i*h2) % dh.tableSize
+
+		if dh.table[index].IsDeleted {
+			i++
+			continue
+		}
+
+		if !dh.table[index].IsOccupied {
+			return false
+		}
+
+		if dh.table[index].Key == key {
+			dh.table[index].IsOccupied = false
+			// Оставляем надгробие, иначе ключи дальше по цепочке станут недостижимы
+			dh.table[index].IsDeleted = true
+			// В Go нужно занулить значения, чтобы сборщик мусора мог очистить память
+			var empty T
+			dh.table[index].Value = empty
+			dh.table[index].Key = ""
+			dh.elementsCount--
+			dh.deletedCount++
+			return true
+		}
+		i++
+	}
+	return false
+}
+
+// Size возвращает количество элементов
+func (dh *DoubleHash[T]) Size() uint32 {
+	return dh.elementsCount
+}
+
+// Empty проверяет, пуста ли таблица
+func (dh *DoubleHash[T]) Empty() bool {
+	return dh.elementsCount == 0
+}
+
+// Clear очищает таблицу
+func (dh *DoubleHash[T]) Clear() {
+	if dh.log != nil {
+		if err := dh.log.Append(wal.Record{Op: wal.OpClear}); err != nil {
+			dh.walErr = err
+			return
+		}
+	}
+	dh.clear()
+}
+
+func (dh *DoubleHash[T]) clear() {
+	dh.table = make([]HashNode[T], dh.tableSize+1)
+	dh.elementsCount = 0
+	dh.deletedCount = 0
+}
+
+// Range обходит все занятые ячейки таблицы. Обход прекращается, если fn вернула false.
+// Изменять таблицу внутри fn нельзя.
+func (dh *DoubleHash[T]) Range(fn func(key string, value T) bool) {
+	for i := uint32(0); i < dh.tableSize; i++ {
+		if dh.table[i].IsOccupied {
+			if !fn(dh.table[i].Key, dh.table[i].Value) {
+				return
+			}
+		}
+	}
+}
+
+// Print выводит таблицу в stdout
+func (dh *DoubleHash[T]) Print() {
+	fmt.Println("=== Хэш-таблица ===")
+	fmt.Printf("Размер: %d, Элементов: %d\n", dh.tableSize, dh.elementsCount)
+	for i := uint32(0); i < dh.tableSize; i++ {
+		if dh.table[i].IsOccupied {
+			fmt.Printf("[%d] %s => %v\n", i, dh.table[i].Key, dh.table[i].Value)
+		}
+	}
+	fmt.Println("===================")
+}
+
+// SerializeText сохраняет таблицу в текстовый файл
+func (dh *DoubleHash[T]) SerializeText(filename string, opts ...persist.Option) error {
+	if err := persist.WriteFile(filename, dh.writeText, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Таблица (текст) успешно сохранена в %s\n", filename)
+	return nil
+}
+
+// DeserializeText загружает таблицу из текстового файла
+func (dh *DoubleHash[T]) DeserializeText(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Could not open file for reading: %w", err)
+	}
+	defer file.Close()
+
+	if err := persist.Load(file, compression.NewReader, dh.readText, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Таблица (текст) успешно загружена из %s\n", filename)
+	return dh.checkpointIfLogged()
+}
+
+// WriteTextTo записывает таблицу в w в текстовом формате
+func (dh *DoubleHash[T]) WriteTextTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, dh.writeText)
+}
+
+// ReadTextFrom читает таблицу в текстовом формате из r до конца потока
+func (dh *DoubleHash[T]) ReadTextFrom(r io.Reader) (int64, error) {
+	n, err := persist.ReadCounted(r, dh.readText)
+	if err != nil {
+		return n, err
+	}
+	return n, dh.checkpointIfLogged()
+}
+
+func (dh *DoubleHash[T]) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%d %d\n", dh.tableSize, dh.elementsCount); err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < dh.tableSize; i++ {
+		if dh.table[i].IsOccupied {
+			value, err := codec.FormatText(dh.table[i].Value)
+			if err != nil {
+				return err
+			}
+			// Ключ в кавычках, поэтому может содержать пробелы
+			if _, err := fmt.Fprintf(w, "%d %s %s\n", i, strconv.Quote(dh.table[i].Key), value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (dh *DoubleHash[T]) readText(r io.Reader) error {
+	s := codec.NewTextScanner(r)
+	newTableSize, err := codec.ScanText[uint32](s)
+	if err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	newElementsCount, err := codec.ScanText[uint32](s)
+	if err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+
+	if newTableSize == 0 {
+		return fmt.Errorf("size of table equal to zero")
+	}
+
+	// Инициализация новой таблицы
+	dh.tableSize = newTableSize
+	dh.elementsCount = newElementsCount
+	dh.deletedCount = 0
+	dh.table = make([]HashNode[T], dh.tableSize+1)
+
+	for {
+		idx, key, value, err := scanEntry[T](s)
+		if err != nil {
+			// Конец файла — это нормально
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("error reading data: %w", err)
+		}
+
+		if idx >= dh.tableSize {
+			return fmt.Errorf("index in file (%d) exceeds table size (%d)", idx, dh.tableSize)
+		}
+
+		dh.table[idx] = HashNode[T]{Key: key, Value: value, IsOccupied: true}
+	}
+	return nil
+}
+
+// scanEntry читает запись "индекс ключ значение". io.EOF возвращается
+// только перед началом записи, оборванная запись - io.ErrUnexpectedEOF.
+func scanEntry[T any](s *codec.TextScanner) (uint32, string, T, error) {
+	var value T
+	idx, err := codec.ScanText[uint32](s)
+	if err != nil {
+		return 0, "", value, err
+	}
+	key, err := codec.ScanText[string](s)
+	if err == nil {
+		value, err = codec.ScanText[T](s)
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return idx, key, value, err
+}
+
+// SetCodec задает кодек элементов для бинарного формата значений таблицы
+// и журнала упреждающей записи. По умолчанию используется codec.Default, который поддерживает строки,
+// []byte, encoding.BinaryMarshaler и типы фиксированного размера.
+func (dh *DoubleHash[T]) SetCodec(c codec.ElementCodec[T]) {
+	dh.elemCodec = c
+}
+
+func (dh *DoubleHash[T]) elementCodec() (codec.ElementCodec[T], error) {
+	if dh.elemCodec != nil {
+		return dh.elemCodec, nil
+	}
+	return codec.Default[T]()
+}
+
+// SerializeBin сохраняет таблицу в бинарный файл
+func (dh *DoubleHash[T]) SerializeBin(filename string, opts ...persist.Option) error {
+	if err := persist.WriteFile(filename, dh.writeBinary, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Таблица (бинарн. без gob) сохранена в %s\n", filename)
+	return nil
+}
+
+// DeserializeBin загружает таблицу из бинарного файла
+func (dh *DoubleHash[T]) DeserializeBin(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Could not open binary file for reading: %w", err)
+	}
+	defer file.Close()
+
+	if err := persist.Load(file, compression.NewReader, dh.readBinary, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Таблица (бинарн. без gob) загружена из %s\n", filename)
+	return dh.checkpointIfLogged()
+}
+
+// WriteTo записывает таблицу в w в бинарном формате (io.WriterTo)
+func (dh *DoubleHash[T]) WriteTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, dh.writeBinary)
+}
+
+// ReadFrom читает таблицу в бинарном формате из r (io.ReaderFrom)
+func (dh *DoubleHash[T]) ReadFrom(r io.Reader) (int64, error) {
+	n, err := persist.ReadCounted(r, dh.readBinary)
+	if err != nil {
+		return n, err
+	}
+	return n, dh.checkpointIfLogged()
+}
+
+// writeBinary пишет таблицу в конверте persist с видом и типом элементов
+func (dh *DoubleHash[T]) writeBinary(w io.Writer) error {
+	return persist.WriteEnvelope(w, persist.KindDoubleHash, persist.TypeName[T](), dh.writePayload)
+}
+
+// readBinary проверяет конверт и читает таблицу
+func (dh *DoubleHash[T]) readBinary(r io.Reader) error {
+	return persist.ReadEnvelope(r, persist.KindDoubleHash, persist.TypeName[T](), dh.readPayload)
+}
+
+func (dh *DoubleHash[T]) writePayload(w io.Writer) error {
+	c, err := dh.elementCodec()
+	if err != nil {
+		return err
+	}
+
+	// Записываем размеры заголовка
+	if err := binary.Write(w, binary.LittleEndian, dh.tableSize); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, dh.elementsCount); err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < dh.tableSize; i++ {
+		occupied := dh.table[i].IsOccupied
+
+		// Пишем флаг занятости
+		if err := binary.Write(w, binary.LittleEndian, occupied); err != nil {
+			return err
+		}
+
+		if occupied {
+			// Пишем длину ключа
+			keyBytes := []byte(dh.table[i].Key)
+			keyLen := uint32(len(keyBytes))
+			if err := binary.Write(w, binary.LittleEndian, keyLen); err != nil {
+				return err
+			}
+
+			// Пишем сам ключ
+			if _, err := w.Write(keyBytes); err != nil {
+				return err
+			}
+
+			// Пишем значение
+			if err := c.Encode(w, dh.table[i].Value); err != nil {
+				return fmt.Errorf("failed to write value: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (dh *DoubleHash[T]) readPayload(r io.Reader) error {
+	c, err := dh.elementCodec()
+	if err != nil {
+		return err
+	}
+
+	// Читаем заголовок
+	var newTableSize, newElementsCount uint32
+	if err := binary.Read(r, binary.LittleEndian, &newTableSize); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &newElementsCount); err != nil {
+		return err
+	}
+
+	// На каждую ячейку приходится хотя бы флаг занятости
+	if err := persist.CheckCount(r, uint64(newTableSize), 1); err != nil {
+		return err
+	}
+
+	// Таблица растет по мере чтения и заменяет текущую только целиком
+	table := make([]HashNode[T], 0, min(int(newTableSize), codec.ChunkSize)+1)
+	for i := uint32(0); i < newTableSize; i++ {
+		var occupied bool
+		// Читаем флаг занятости
+		if err := binary.Read(r, binary.LittleEndian, &occupied); err != nil {
+			return fmt.Errorf("read error at index %d: %w", i, err)
+		}
+
+		if occupied {
+			// Читаем длину ключа
+			var keyLen uint32
+			if err := binary.Read(r, binary.LittleEndian, &keyLen); err != nil {
+				return err
+			}
+
+			// Читаем сам ключ
+			// Длина ключа не может превышать остаток данных
+			if persist.CheckCount(r, uint64(keyLen), 1) != nil {
+				return fmt.Errorf("failed to read key string")
+			}
+			keyBuf, err := codec.DecodeN(r, codec.Fixed[byte]{}, int(keyLen))
+			if err != nil {
+				return fmt.Errorf("failed to read key string")
+			}
+			key := string(keyBuf)
+
+			// Читаем значение
+			value, err := c.Decode(r)
+			if err != nil {
+				return fmt.Errorf("failed to read value: %w", err)
+			}
+
+			table = append(table, HashNode[T]{Key: key, Value: value, IsOccupied: true})
+		} else {
+			table = append(table, HashNode[T]{})
+		}
+	}
+
+	dh.tableSize = newTableSize
+	dh.elementsCount = newElementsCount
+	dh.deletedCount = 0
+	dh.table = append(table, HashNode[T]{})
+	return nil
+}
+
+// Срезы и последовательности
+
+// FromSlice создает таблицу из пар entries (IsOccupied не учитывается).
+// Для повторяющихся ключей остается последнее значение.
+func FromSlice[T any](entries []HashNode[T]) (*DoubleHash[T], error) {
+	dh := &DoubleHash[T]{}
+	if err := dh.replaceAll(entries); err != nil {
+		return nil, err
+	}
+	return dh, nil
+}
+
+// FromSeq создает таблицу из пар seq. Для повторяющихся ключей остается последнее значение.
+func FromSeq[T any](seq iter.Seq2[string, T]) (*DoubleHash[T], error) {
+	var entries []HashNode[T]
+	for key, value := range seq {
+		entries = append(entries, HashNode[T]{Key: key, Value: value, IsOccupied: true})
+	}
+	return FromSlice(entries)
+}
+
+// ToSlice возвращает занятые ячейки таблицы в порядке их расположения
+func (dh *DoubleHash[T]) ToSlice() []HashNode[T] {
+	entries := make([]HashNode[T], 0, dh.elementsCount)
+	dh.Range(func(key string, value T) bool {
+		entries = append(entries, HashNode[T]{Key: key, Value: value, IsOccupied: true})
+		return true
+	})
+	return entries
+}
+
+// encoding и gob
+
+// MarshalBinary возвращает таблицу в бинарном формате с конвертом
+// (encoding.BinaryMarshaler), поэтому ее можно вкладывать в другие структуры
+func (dh *DoubleHash[T]) MarshalBinary() ([]byte, error) {
+	return persist.Marshal(dh.writeBinary)
+}
+
+// UnmarshalBinary читает таблицу из данных MarshalBinary (encoding.BinaryUnmarshaler).
+// Данные должны содержать ровно один конверт.
+func (dh *DoubleHash[T]) UnmarshalBinary(data []byte) error {
+	if err := persist.Unmarshal(data, dh.readBinary); err != nil {
+		return err
+	}
+	return dh.checkpointIfLogged()
+}
+
+// GobEncode кодирует таблицу для encoding/gob (gob.GobEncoder)
+func (dh *DoubleHash[T]) GobEncode() ([]byte, error) {
+	return dh.MarshalBinary()
+}
+
+// GobDecode читает таблицу, закодированную GobEncode (gob.GobDecoder)
+func (dh *DoubleHash[T]) GobDecode(data []byte) error {
+	return dh.UnmarshalBinary(data)
+}
+
+// JSON
+
+// MarshalJSON кодирует таблицу как JSON-объект ключ -> значение (json.Marshaler)
+func (dh *DoubleHash[T]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := dh.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON заменяет содержимое таблицы парами из JSON-объекта
+// (json.Unmarshaler). При ошибке таблица не меняется.
+func (dh *DoubleHash[T]) UnmarshalJSON(data []byte) error {
+	if err := dh.readJSON(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	return dh.checkpointIfLogged()
+}
+
+// WriteJSONTo пишет таблицу в w как JSON-объект, не собирая его в памяти
+func (dh *DoubleHash[T]) WriteJSONTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, dh.writeJSON)
+}
+
+// ReadJSONFrom читает таблицу из JSON-объекта в r до конца потока
+func (dh *DoubleHash[T]) ReadJSONFrom(r io.Reader) (int64, error) {
+	n, err := persist.ReadCounted(r, dh.readJSON)
+	if err != nil {
+		return n, err
+	}
+	return n, dh.checkpointIfLogged()
+}
+
+func (dh *DoubleHash[T]) writeJSON(w io.Writer) error {
+	return codec.EncodeJSONObject(w, func(emit func(string, T) error) error {
+		for i := uint32(0); i < dh.tableSize; i++ {
+			if dh.table[i].IsOccupied {
+				if err := emit(dh.table[i].Key, dh.table[i].Value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (dh *DoubleHash[T]) readJSON(r io.Reader) error {
+	var entries []HashNode[T]
+	err := codec.ReadJSON(r, func(dec *json.Decoder) error {
+		return codec.DecodeJSONObject(dec, "$", func(key string, value T) error {
+			entries = append(entries, HashNode[T]{Key: key, Value: value})
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return dh.replaceAll(entries)
+}
+
+// replaceAll заменяет содержимое таблицы парами entries без записи в журнал
+func (dh *DoubleHash[T]) replaceAll(entries []HashNode[T]) error {
+	// Нулевая таблица (например, поле структуры) получает размер по числу пар.
+	// Таблице из одной ячейки вторая хэш-функция не подходит, поэтому не меньше 3.
+	if dh.tableSize == 0 {
+		dh.tableSize = uint32(max(2*len(entries)+1, 3))
+	}
+	dh.clear()
+	for _, e := range entries {
+		if err := dh.insert(e.Key, e.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CSV
+
+// ExportCSV пишет таблицу в w в формате CSV: колонка key и колонки значения.
+// Колонки значения задаются codec.DefaultCSVMapper или опцией codec.WithCSVMapper.
+func (dh *DoubleHash[T]) ExportCSV(w io.Writer, opts ...codec.CSVOption) error {
+	return codec.WriteKeyedCSV(w, func(emit func(string, T) error) error {
+		for i := uint32(0); i < dh.tableSize; i++ {
+			if dh.table[i].IsOccupied {
+				if err := emit(dh.table[i].Key, dh.table[i].Value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}, opts...)
+}
+
+// ImportCSV заменяет содержимое таблицы строками CSV из r. Строки, которые
+// не удалось разобрать, и повторные ключи пропускаются и возвращаются
+// в codec.RowErrors с номерами строк. При ошибке чтения или заголовка
+// таблица не меняется.
+func (dh *DoubleHash[T]) ImportCSV(r io.Reader, opts ...codec.CSVOption) error {
+	var entries []HashNode[T]
+	rowErrs, err := codec.ReadKeyedCSV(r, func(key string, value T) error {
+		entries = append(entries, HashNode[T]{Key: key, Value: value})
+		return nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	if err := dh.replaceAll(entries); err != nil {
+		return err
+	}
+	if err := dh.checkpointIfLogged(); err != nil {
+		return err
+	}
+	return rowErrs.Err()
+}
+
+// Журнал упреждающей записи
+
+// EnableWAL включает режим журнала в каталоге dir. Текущее содержимое таблицы
+// записывается снимком (прежние снимок и журнал в dir заменяются), а каждая
+// следующая операция Insert, Remove и Clear сначала дописывается в журнал.
+// Значения кодируются кодеком таблицы (SetCodec), как и в бинарном формате.
+func (dh *DoubleHash[T]) EnableWAL(dir string) error {
+	if dh.log != nil {
+		return ErrWALEnabled
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	log, err := wal.OpenLog(filepath.Join(dir, wal.LogFile))
+	if err != nil {
+		return err
+	}
+	dh.walDir = dir
+	dh.log = log
+	if err := dh.Checkpoint(); err != nil {
+		dh.CloseWAL()
+		return err
+	}
+	return nil
+}
+
+// Checkpoint записывает снимок таблицы и очищает журнал
+func (dh *DoubleHash[T]) Checkpoint() error {
+	if dh.log == nil {
+		return ErrWALDisabled
+	}
+	c, err := dh.elementCodec()
+	if err != nil {
+		return err
+	}
+	err = wal.Checkpoint(dh.walDir, dh.log, func(emit func(string, []byte) error) error {
+		var err error
+		dh.Range(func(key string, value T) bool {
+			var data []byte
+			if data, err = wal.EncodeValue(c, value); err == nil {
+				err = emit(key, data)
+			}
+			return err == nil
+		})
+		return err
+	})
+	if err == nil {
+		// Снимок отражает фактическое состояние, прежние сбои журнала больше не важны
+		dh.walErr = nil
+	}
+	return err
+}
+
+// checkpointIfLogged фиксирует снимком содержимое, загруженное из файла в режиме журнала
+func (dh *DoubleHash[T]) checkpointIfLogged() error {
+	if dh.log == nil {
+		return nil
+	}
+	return dh.Checkpoint()
+}
+
+// WALError возвращает ошибку журнала, произошедшую в Remove или Clear
+// после последнего успешного Checkpoint
+func (dh *DoubleHash[T]) WALError() error {
+	return dh.walErr
+}
+
+// CloseWAL закрывает журнал и выключает режим журнала. Снимок не пишется:
+// все операции уже есть в журнале и будут применены при Recover.
+func (dh *DoubleHash[T]) CloseWAL() error {
+	if dh.log == nil {
+		return ErrWALDisabled
+	}
+	err := dh.log.Close()
+	dh.log = nil
+	dh.walDir = ""
+	return err
+}
+
+// Recover восстанавливает таблицу из каталога dir: загружает последний снимок
+// и применяет поверх него журнал. Порванный хвост журнала отбрасывается.
+// Возвращенная таблица продолжает работать в режиме журнала.
+// Значения читаются кодеком по умолчанию.
+func Recover[T any](dir string) (*DoubleHash[T], error) {
+	return RecoverWithCodec[T](dir, nil)
+}
+
+// RecoverWithCodec работает как Recover для таблицы, журнал которой писался
+// с кодеком c (SetCodec). Кодек остается у возвращенной таблицы.
+func RecoverWithCodec[T any](dir string, c codec.ElementCodec[T]) (*DoubleHash[T], error) {
+	dh, _ := NewDoubleHash[T](16)
+	dh.SetCodec(c)
+	c, err := dh.elementCodec()
+	if err != nil {
+		return nil, err
+	}
+	log, err := wal.Recover(dir, func(rec wal.Record) error {
+		switch rec.Op {
+		case wal.OpInsert:
+			value, err := wal.DecodeValue(c, rec.Value)
+			if err != nil {
+				return err
+			}
+			return dh.insert(rec.Key, value)
+		case wal.OpRemove:
+			dh.remove(rec.Key)
+		case wal.OpClear:
+			dh.clear()
+		default:
+			return fmt.Errorf("%w: %d", wal.ErrBadOp, rec.Op)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	dh.walDir = dir
+	dh.log = log
+	return dh, nil
+}
@@ -1,419 +1,1042 @@
-package dhash
-
-import (
-	"bytes"
-	"encoding/binary"
-	"fmt"
-	"io"
-	"os"
-	"path/filepath"
-	"strings"
-	"testing"
-)
-
-// Вспомогательные функции
-
-// captureOutput перехватывает вывод в stdout
-func captureOutput(f func()) string {
-	r, w, _ := os.Pipe()
-	stdout := os.Stdout
-	os.Stdout = w
-
-	f()
-
-	w.Close()
-	os.Stdout = stdout
-
-	var buf bytes.Buffer
-	io.Copy(&buf, r)
-	return buf.String()
-}
-
-// Основные функциональные тесты
-
-func TestConstructor(t *testing.T) {
-	t.Run("ValidSize", func(t *testing.T) {
-		dh, err := NewDoubleHash[int](10)
-		if err != nil {
-			t.Fatalf("Expected no error, got %v", err)
-		}
-		if dh.Size() != 0 {
-			t.Errorf("Expected size 0, got %d", dh.Size())
-		}
-		if !dh.Empty() {
-			t.Error("Expected empty table")
-		}
-	})
-
-	t.Run("ZeroSize", func(t *testing.T) {
-		_, err := NewDoubleHash[int](0)
-		if err == nil {
-			t.Error("Expected error when creating table with size 0")
-		}
-	})
-}
-
-func TestInsertFindUpdate(t *testing.T) {
-	dh, _ := NewDoubleHash[string](5)
-
-	// Вставка
-	err := dh.Insert("key1", "value1")
-	if err != nil {
-		t.Errorf("Insert failed: %v", err)
-	}
-	if dh.Size() != 1 {
-		t.Errorf("Expected size 1, got %d", dh.Size())
-	}
-
-	// Поиск
-	val := dh.Find("key1")
-	if val == nil || *val != "value1" {
-		t.Error("Find returned wrong value or nil")
-	}
-
-	// Обновление значения
-	err = dh.Insert("key1", "value2")
-	if err != nil {
-		t.Errorf("Update failed: %v", err)
-	}
-	if dh.Size() != 1 {
-		t.Errorf("Size increased on update, expected 1, got %d", dh.Size())
-	}
-	val = dh.Find("key1")
-	if val == nil || *val != "value2" {
-		t.Error("Value not updated")
-	}
-
-	// Поиск несуществующего
-	if dh.Find("missing") != nil {
-		t.Error("Found non-existent key")
-	}
-}
-
-func TestRemove(t *testing.T) {
-	dh, _ := NewDoubleHash[int](5)
-
-	// Удаление из пустой таблицы
-	if dh.Remove("any") {
-		t.Error("Remove on empty table should return false")
-	}
-
-	dh.Insert("A", 1)
-	dh.Insert("B", 2)
-
-	// Удаление несуществующего (с данными в таблице)
-	if dh.Remove("C") {
-		t.Error("Remove missing key should return false")
-	}
-
-	// Успешное удаление
-	if !dh.Remove("A") {
-		t.Error("Failed to remove existing key")
-	}
-	if dh.Size() != 1 {
-		t.Errorf("Expected size 1, got %d", dh.Size())
-	}
-	if dh.Find("A") != nil {
-		t.Error("Key A should be gone")
-	}
-}
-
-func TestClear(t *testing.T) {
-	dh, _ := NewDoubleHash[int](5)
-	dh.Insert("A", 1)
-	dh.Clear()
-	if !dh.Empty() {
-		t.Error("Table not empty after Clear")
-	}
-	if dh.Size() != 0 {
-		t.Error("Size not 0 after Clear")
-	}
-}
-
-func TestResize(t *testing.T) {
-	// Создаем маленькую таблицу
-	dh, _ := NewDoubleHash[int](2)
-
-	dh.Insert("1", 1)
-	dh.Insert("2", 2)
-
-	prevSize := dh.Size()
-	if prevSize != 2 {
-		t.Errorf("Expected size 2, got %d", prevSize)
-	}
-
-	// Вставка третьего элемента должна вызвать resize
-	dh.Insert("3", 3)
-
-	if dh.Size() != 3 {
-		t.Errorf("Expected size 3, got %d", dh.Size())
-	}
-
-	// Проверяем, что данные сохранились
-	if *dh.Find("1") != 1 || *dh.Find("2") != 2 || *dh.Find("3") != 3 {
-		t.Error("Data corruption after resize")
-	}
-}
-
-func TestPrint(t *testing.T) {
-	dh, _ := NewDoubleHash[int](5)
-	dh.Insert("print_key", 999)
-
-	out := captureOutput(func() {
-		dh.Print()
-	})
-
-	if !strings.Contains(out, "print_key") {
-		t.Error("Print output missing key")
-	}
-	if !strings.Contains(out, "999") {
-		t.Error("Print output missing value")
-	}
-	if !strings.Contains(out, "=== Хэш-таблица ===") {
-		t.Error("Print output missing header")
-	}
-}
-
-// Тесты специфичной логики
-
-// TestEvenTableSize проверяет логику hash2, когда размер таблицы четный.
-func TestEvenTableSize(t *testing.T) {
-	// Размер 4 (четный).
-	dh, _ := NewDoubleHash[int](4)
-
-	// Вставляем несколько ключей, чтобы статистически попасть в ветку с четным result
-	keys := []string{"a", "b", "c", "d", "e", "f"}
-	for i, k := range keys {
-		dh.Insert(k, i)
-	}
-
-	// Проверяем, что все нашлось (значит коллизии и шаги разрешились корректно)
-	for i, k := range keys {
-		val := dh.Find(k)
-		if val == nil || *val != i {
-			t.Errorf("Failed to find key %s in even-sized table", k)
-		}
-	}
-}
-
-// Тесты сериализации (Text)
-
-func TestSerializeText_Errors(t *testing.T) {
-	dh, _ := NewDoubleHash[int](5)
-	dh.Insert("A", 1)
-
-	// Ошибка открытия файла на запись (пустое имя)
-	if err := dh.SerializeText(""); err == nil {
-		t.Error("Expected error for empty filename")
-	}
-}
-
-func TestDeserializeText_FullFlow(t *testing.T) {
-	tmpDir := t.TempDir()
-	validFile := filepath.Join(tmpDir, "valid.txt")
-
-	dhOriginal, _ := NewDoubleHash[float64](5)
-	dhOriginal.Insert("pi", 3.14)
-	dhOriginal.Insert("e", 2.71)
-
-	// Успешное сохранение
-	if err := dhOriginal.SerializeText(validFile); err != nil {
-		t.Fatalf("SerializeText failed: %v", err)
-	}
-
-	// Успешная загрузка
-	dhNew, _ := NewDoubleHash[float64](1)
-	if err := dhNew.DeserializeText(validFile); err != nil {
-		t.Fatalf("DeserializeText failed: %v", err)
-	}
-	if dhNew.Size() != 2 {
-		t.Errorf("Expected 2 items, got %d", dhNew.Size())
-	}
-
-	// Тестирование ошибок загрузки
-
-	// Файл не существует
-	if err := dhNew.DeserializeText(filepath.Join(tmpDir, "missing.txt")); err == nil {
-		t.Error("Expected error for missing file")
-	}
-
-	// Ошибка чтения заголовка (пустой файл)
-	emptyFile := filepath.Join(tmpDir, "empty.txt")
-	os.WriteFile(emptyFile, []byte(""), 0644)
-	if err := dhNew.DeserializeText(emptyFile); err == nil {
-		t.Error("Expected error for empty file header")
-	}
-
-	// Размер таблицы равен 0
-	zeroSizeFile := filepath.Join(tmpDir, "zerosize.txt")
-	os.WriteFile(zeroSizeFile, []byte("0 10"), 0644)
-	if err := dhNew.DeserializeText(zeroSizeFile); err == nil || err.Error() != "size of table equal to zero" {
-		t.Errorf("Expected 'size of table equal to zero', got %v", err)
-	}
-
-	// Ошибка формата данных (ожидаем int key T, даем мусор)
-	badBodyFile := filepath.Join(tmpDir, "badbody.txt")
-	// Размер 5, элементов 1. Строка: "индекс(ок) ключ(ок) значение(BAD)"
-	os.WriteFile(badBodyFile, []byte("5 1\n0 key NOT_A_NUMBER"), 0644)
-	if err := dhNew.DeserializeText(badBodyFile); err == nil {
-		t.Error("Expected error for bad data body")
-	}
-
-	// Индекс превышает размер таблицы
-	badIndexFile := filepath.Join(tmpDir, "badindex.txt")
-	// Размер 5, элементов 1. Индекс 10 ( > 5)
-	os.WriteFile(badIndexFile, []byte("5 1\n10 key 1.0"), 0644)
-	if err := dhNew.DeserializeText(badIndexFile); err == nil {
-		t.Error("Expected error for index out of bounds")
-	} else if !strings.Contains(err.Error(), "exceeds table size") {
-		t.Errorf("Expected 'exceeds table size' error, got: %v", err)
-	}
-}
-
-// Тесты сериализации (Binary)
-
-func TestSerializeBin_Errors(t *testing.T) {
-	dh, _ := NewDoubleHash[int](5)
-	dh.Insert("A", 1)
-
-	// Ошибка открытия файла
-	if err := dh.SerializeBin(""); err == nil {
-		t.Error("Expected error for empty filename")
-	}
-}
-
-func TestDeserializeBin_Errors(t *testing.T) {
-	tmpDir := t.TempDir()
-	dh, _ := NewDoubleHash[int](1)
-
-	// Файл не существует
-	if err := dh.DeserializeBin("non_existent.bin"); err == nil {
-		t.Error("Expected error for missing bin file")
-	}
-
-	// Файл слишком короткий (нет заголовка)
-	shortFile := filepath.Join(tmpDir, "short.bin")
-	os.WriteFile(shortFile, []byte{1, 2}, 0644)
-	if err := dh.DeserializeBin(shortFile); err == nil {
-		t.Error("Expected error for short binary header")
-	}
-
-	// Неожиданный конец файла при чтении occupied флага
-	// Записываем корректный заголовок (size=5, count=1), но ничего больше
-	truncatedFile := filepath.Join(tmpDir, "truncated.bin")
-	buf := new(bytes.Buffer)
-	binary.Write(buf, binary.LittleEndian, uint32(5)) // size
-	binary.Write(buf, binary.LittleEndian, uint32(1)) // count
-	os.WriteFile(truncatedFile, buf.Bytes(), 0644)
-
-	if err := dh.DeserializeBin(truncatedFile); err == nil {
-		t.Error("Expected error when reading occupied flag from truncated file")
-	}
-
-	// Ошибка чтения длины ключа (occupied = true, но файла нет дальше)
-	truncKeyLenFile := filepath.Join(tmpDir, "trunckeylen.bin")
-	buf.Reset()
-	binary.Write(buf, binary.LittleEndian, uint32(5)) // size
-	binary.Write(buf, binary.LittleEndian, uint32(1)) // count
-	binary.Write(buf, binary.LittleEndian, true)      // occupied
-	os.WriteFile(truncKeyLenFile, buf.Bytes(), 0644)
-
-	if err := dh.DeserializeBin(truncKeyLenFile); err == nil {
-		t.Error("Expected error when reading key len from truncated file")
-	}
-
-	// Длина ключа слишком большая (> 1000000)
-	hugeKeyFile := filepath.Join(tmpDir, "hugekey.bin")
-	buf.Reset()
-	binary.Write(buf, binary.LittleEndian, uint32(5))       // size
-	binary.Write(buf, binary.LittleEndian, uint32(1))       // count
-	binary.Write(buf, binary.LittleEndian, true)            // occupied
-	binary.Write(buf, binary.LittleEndian, uint32(1000001)) // keyLen HUGE
-	os.WriteFile(hugeKeyFile, buf.Bytes(), 0644)
-
-	if err := dh.DeserializeBin(hugeKeyFile); err == nil {
-		t.Error("Expected error for key length too large")
-	} else if err.Error() != "failed to read key string" {
-		t.Errorf("Expected 'failed to read key string', got %v", err)
-	}
-
-	// Ошибка чтения самого ключа (keyLen нормальный, но данных нет)
-	noKeyDataFile := filepath.Join(tmpDir, "nokeydata.bin")
-	buf.Reset()
-	binary.Write(buf, binary.LittleEndian, uint32(5)) // size
-	binary.Write(buf, binary.LittleEndian, uint32(1)) // count
-	binary.Write(buf, binary.LittleEndian, true)      // occupied
-	binary.Write(buf, binary.LittleEndian, uint32(5)) // keyLen = 5
-	// Не пишем байты ключа
-	os.WriteFile(noKeyDataFile, buf.Bytes(), 0644)
-
-	if err := dh.DeserializeBin(noKeyDataFile); err == nil {
-		t.Error("Expected error when reading key data")
-	}
-
-	// Ошибка декодирования значения
-	// Ситуация: записали всё корректно, но байты значения битые или не соответствуют типу
-	badGobFile := filepath.Join(tmpDir, "badgob.bin")
-	buf.Reset()
-	binary.Write(buf, binary.LittleEndian, uint32(5)) // size
-	binary.Write(buf, binary.LittleEndian, uint32(1)) // count
-	binary.Write(buf, binary.LittleEndian, true)      // occupied
-	binary.Write(buf, binary.LittleEndian, uint32(3)) // keyLen = 3
-	buf.WriteString("key")                            // key bytes
-	// Вместо валидного gob для int пишем мусор
-	buf.Write([]byte{0xFF, 0xFF, 0xFF})
-	os.WriteFile(badGobFile, buf.Bytes(), 0644)
-
-	if err := dh.DeserializeBin(badGobFile); err == nil {
-		t.Error("Expected error for gob decode failure")
-	}
-}
-
-func TestFullBinaryCycle(t *testing.T) {
-	// Проверка "счастливого пути" для Binary
-	tmpFile := filepath.Join(t.TempDir(), "ok.bin")
-
-	dh, _ := NewDoubleHash[int32](5)
-	dh.Insert("A", 100)
-	// Создаем "дырку" (удаленный элемент), чтобы проверить false в occupied
-	dh.Insert("B", 200)
-	dh.Remove("B")
-
-	if err := dh.SerializeBin(tmpFile); err != nil {
-		t.Fatalf("SerializeBin failed: %v", err)
-	}
-
-	dhLoaded, _ := NewDoubleHash[int32](1)
-	if err := dhLoaded.DeserializeBin(tmpFile); err != nil {
-		t.Fatalf("DeserializeBin failed: %v", err)
-	}
-
-	if dhLoaded.Size() != 1 {
-		t.Errorf("Expected size 1 (B removed), got %d", dhLoaded.Size())
-	}
-	if *dhLoaded.Find("A") != 100 {
-		t.Error("Value A lost")
-	}
-	if dhLoaded.Find("B") != nil {
-		t.Error("B should be removed")
-	}
-}
-
-// TestCollisionCycle проверяет, что мы можем найти элемент, даже если он стоит
-func TestCollisionCycle(t *testing.T) {
-
-	dh, _ := NewDoubleHash[int](10)
-	// Вставляем много элементов
-	for i := 0; i < 8; i++ {
-		key := fmt.Sprintf("k%d", i)
-		dh.Insert(key, i)
-	}
-
-	// Проверяем поиск каждого
-	for i := 0; i < 8; i++ {
-		key := fmt.Sprintf("k%d", i)
-		if val := dh.Find(key); val == nil || *val != i {
-			t.Errorf("Failed to find key %s in populated table", key)
-		}
-	}
-}
+package dhash
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+	"github.com/D4ROVAN1E/LR_3_Go/persist/persisttest"
+)
+
+// Вспомогательные функции
+
+// captureOutput перехватывает вывод в stdout
+func captureOutput(f func()) string {
+	r, w, _ := os.Pipe()
+	stdout := os.Stdout
+	os.Stdout = w
+
+	f()
+
+	w.Close()
+	os.Stdout = stdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+// Основные функциональные тесты
+
+func TestConstructor(t *testing.T) {
+	t.Run("ValidSize", func(t *testing.T) {
+		dh, err := NewDoubleHash[int](10)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if dh.Size() != 0 {
+			t.Errorf("Expected size 0, got %d", dh.Size())
+		}
+		if !dh.Empty() {
+			t.Error("Expected empty table")
+		}
+	})
+
+	t.Run("ZeroSize", func(t *testing.T) {
+		_, err := NewDoubleHash[int](0)
+		if err == nil {
+			t.Error("Expected error when creating table with size 0")
+		}
+	})
+}
+
+func TestInsertFindUpdate(t *testing.T) {
+	dh, _ := NewDoubleHash[string](5)
+
+	// Вставка
+	err := dh.Insert("key1", "value1")
+	if err != nil {
+		t.Errorf("Insert failed: %v", err)
+	}
+	if dh.Size() != 1 {
+		t.Errorf("Expected size 1, got %d", dh.Size())
+	}
+
+	// Поиск
+	val := dh.Find("key1")
+	if val == nil || *val != "value1" {
+		t.Error("Find returned wrong value or nil")
+	}
+
+	// Обновление значения
+	err = dh.Insert("key1", "value2")
+	if err != nil {
+		t.Errorf("Update failed: %v", err)
+	}
+	if dh.Size() != 1 {
+		t.Errorf("Size increased on update, expected 1, got %d", dh.Size())
+	}
+	val = dh.Find("key1")
+	if val == nil || *val != "value2" {
+		t.Error("Value not updated")
+	}
+
+	// Поиск несуществующего
+	if dh.Find("missing") != nil {
+		t.Error("Found non-existent key")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dh, _ := NewDoubleHash[int](5)
+
+	// Удаление из пустой таблицы
+	if dh.Remove("any") {
+		t.Error("Remove on empty table should return false")
+	}
+
+	dh.Insert("A", 1)
+	dh.Insert("B", 2)
+
+	// Удаление несуществующего (с данными в таблице)
+	if dh.Remove("C") {
+		t.Error("Remove missing key should return false")
+	}
+
+	// Успешное удаление
+	if !dh.Remove("A") {
+		t.Error("Failed to remove existing key")
+	}
+	if dh.Size() != 1 {
+		t.Errorf("Expected size 1, got %d", dh.Size())
+	}
+	if dh.Find("A") != nil {
+		t.Error("Key A should be gone")
+	}
+}
+
+func TestClear(t *testing.T) {
+	dh, _ := NewDoubleHash[int](5)
+	dh.Insert("A", 1)
+	dh.Clear()
+	if !dh.Empty() {
+		t.Error("Table not empty after Clear")
+	}
+	if dh.Size() != 0 {
+		t.Error("Size not 0 after Clear")
+	}
+}
+
+func TestResize(t *testing.T) {
+	// Создаем маленькую таблицу
+	dh, _ := NewDoubleHash[int](2)
+
+	dh.Insert("1", 1)
+	dh.Insert("2", 2)
+
+	prevSize := dh.Size()
+	if prevSize != 2 {
+		t.Errorf("Expected size 2, got %d", prevSize)
+	}
+
+	// Вставка третьего элемента должна вызвать resize
+	dh.Insert("3", 3)
+
+	if dh.Size() != 3 {
+		t.Errorf("Expected size 3, got %d", dh.Size())
+	}
+
+	// Проверяем, что данные сохранились
+	if *dh.Find("1") != 1 || *dh.Find("2") != 2 || *dh.Find("3") != 3 {
+		t.Error("Data corruption after resize")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	dh, _ := NewDoubleHash[int](5)
+	dh.Insert("print_key", 999)
+
+	out := captureOutput(func() {
+		dh.Print()
+	})
+
+	if !strings.Contains(out, "print_key") {
+		t.Error("Print output missing key")
+	}
+	if !strings.Contains(out, "999") {
+		t.Error("Print output missing value")
+	}
+	if !strings.Contains(out, "=== Хэш-таблица ===") {
+		t.Error("Print output missing header")
+	}
+}
+
+// Тесты специфичной логики
+
+// TestEvenTableSize проверяет логику hash2, когда размер таблицы четный.
+func TestEvenTableSize(t *testing.T) {
+	// Размер 4 (четный).
+	dh, _ := NewDoubleHash[int](4)
+
+	// Вставляем несколько ключей, чтобы статистически попасть в ветку с четным result
+	keys := []string{"a", "b", "c", "d", "e", "f"}
+	for i, k := range keys {
+		dh.Insert(k, i)
+	}
+
+	// Проверяем, что все нашлось (значит коллизии и шаги разрешились корректно)
+	for i, k := range keys {
+		val := dh.Find(k)
+		if val == nil || *val != i {
+			t.Errorf("Failed to find key %s in even-sized table", k)
+		}
+	}
+}
+
+// Тесты сериализации (Text)
+
+func TestSerializeText_Errors(t *testing.T) {
+	dh, _ := NewDoubleHash[int](5)
+	dh.Insert("A", 1)
+
+	// Ошибка открытия файла на запись (пустое имя)
+	if err := dh.SerializeText(""); err == nil {
+		t.Error("Expected error for empty filename")
+	}
+}
+
+func TestDeserializeText_FullFlow(t *testing.T) {
+	tmpDir := t.TempDir()
+	validFile := filepath.Join(tmpDir, "valid.txt")
+
+	dhOriginal, _ := NewDoubleHash[float64](5)
+	dhOriginal.Insert("pi", 3.14)
+	dhOriginal.Insert("e", 2.71)
+
+	// Успешное сохранение
+	if err := dhOriginal.SerializeText(validFile); err != nil {
+		t.Fatalf("SerializeText failed: %v", err)
+	}
+
+	// Успешная загрузка
+	dhNew, _ := NewDoubleHash[float64](1)
+	if err := dhNew.DeserializeText(validFile); err != nil {
+		t.Fatalf("DeserializeText failed: %v", err)
+	}
+	if dhNew.Size() != 2 {
+		t.Errorf("Expected 2 items, got %d", dhNew.Size())
+	}
+
+	// Тестирование ошибок загрузки
+
+	// Файл не существует
+	if err := dhNew.DeserializeText(filepath.Join(tmpDir, "missing.txt")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	// Ошибка чтения заголовка (пустой файл)
+	emptyFile := filepath.Join(tmpDir, "empty.txt")
+	os.WriteFile(emptyFile, []byte(""), 0644)
+	if err := dhNew.DeserializeText(emptyFile); err == nil {
+		t.Error("Expected error for empty file header")
+	}
+
+	// Размер таблицы равен 0
+	zeroSizeFile := filepath.Join(tmpDir, "zerosize.txt")
+	os.WriteFile(zeroSizeFile, []byte("0 10"), 0644)
+	if err := dhNew.DeserializeText(zeroSizeFile); err == nil || err.Error() != "size of table equal to zero" {
+		t.Errorf("Expected 'size of table equal to zero', got %v", err)
+	}
+
+	// Ошибка формата данных (ожидаем int key T, даем мусор)
+	badBodyFile := filepath.Join(tmpDir, "badbody.txt")
+	// Размер 5, элементов 1. Строка: "индекс(ок) ключ(ок) значение(BAD)"
+	os.WriteFile(badBodyFile, []byte("5 1\n0 key NOT_A_NUMBER"), 0644)
+	if err := dhNew.DeserializeText(badBodyFile); err == nil {
+		t.Error("Expected error for bad data body")
+	}
+
+	// Индекс превышает размер таблицы
+	badIndexFile := filepath.Join(tmpDir, "badindex.txt")
+	// Размер 5, элементов 1. Индекс 10 ( > 5)
+	os.WriteFile(badIndexFile, []byte("5 1\n10 key 1.0"), 0644)
+	if err := dhNew.DeserializeText(badIndexFile); err == nil {
+		t.Error("Expected error for index out of bounds")
+	} else if !strings.Contains(err.Error(), "exceeds table size") {
+		t.Errorf("Expected 'exceeds table size' error, got: %v", err)
+	}
+}
+
+// Тесты сериализации (Binary)
+
+func TestSerializeBin_Errors(t *testing.T) {
+	dh, _ := NewDoubleHash[int](5)
+	dh.Insert("A", 1)
+
+	// Ошибка открытия файла
+	if err := dh.SerializeBin(""); err == nil {
+		t.Error("Expected error for empty filename")
+	}
+}
+
+func TestDeserializeBin_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+	dh, _ := NewDoubleHash[int](1)
+
+	// Файл не существует
+	if err := dh.DeserializeBin("non_existent.bin"); err == nil {
+		t.Error("Expected error for missing bin file")
+	}
+
+	// Файл слишком короткий (нет заголовка)
+	shortFile := filepath.Join(tmpDir, "short.bin")
+	os.WriteFile(shortFile, []byte{1, 2}, 0644)
+	if err := dh.DeserializeBin(shortFile); err == nil {
+		t.Error("Expected error for short binary header")
+	}
+
+	// Неожиданный конец файла при чтении occupied флага
+	// Записываем корректный заголовок (size=5, count=1), но ничего больше
+	truncatedFile := filepath.Join(tmpDir, "truncated.bin")
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, uint32(5)) // size
+	binary.Write(buf, binary.LittleEndian, uint32(1)) // count
+	os.WriteFile(truncatedFile, persisttest.Envelope[int](persist.KindDoubleHash, buf.Bytes()), 0644)
+
+	if err := dh.DeserializeBin(truncatedFile); err == nil {
+		t.Error("Expected error when reading occupied flag from truncated file")
+	}
+
+	// Ошибка чтения длины ключа (occupied = true, но файла нет дальше)
+	truncKeyLenFile := filepath.Join(tmpDir, "trunckeylen.bin")
+	buf.Reset()
+	binary.Write(buf, binary.LittleEndian, uint32(5)) // size
+	binary.Write(buf, binary.LittleEndian, uint32(1)) // count
+	binary.Write(buf, binary.LittleEndian, true)      // occupied
+	os.WriteFile(truncKeyLenFile, persisttest.Envelope[int](persist.KindDoubleHash, buf.Bytes()), 0644)
+
+	if err := dh.DeserializeBin(truncKeyLenFile); err == nil {
+		t.Error("Expected error when reading key len from truncated file")
+	}
+
+	// Длина ключа слишком большая (> 1000000)
+	hugeKeyFile := filepath.Join(tmpDir, "hugekey.bin")
+	buf.Reset()
+	binary.Write(buf, binary.LittleEndian, uint32(5))       // size
+	binary.Write(buf, binary.LittleEndian, uint32(1))       // count
+	binary.Write(buf, binary.LittleEndian, true)            // occupied
+	binary.Write(buf, binary.LittleEndian, uint32(1000001)) // keyLen HUGE
+	os.WriteFile(hugeKeyFile, persisttest.Envelope[int](persist.KindDoubleHash, buf.Bytes()), 0644)
+
+	if err := dh.DeserializeBin(hugeKeyFile); err == nil {
+		t.Error("Expected error for key length too large")
+	} else if err.Error() != "failed to read key string" {
+		t.Errorf("Expected 'failed to read key string', got %v", err)
+	}
+
+	// Ошибка чтения самого ключа (keyLen нормальный, но данных нет)
+	noKeyDataFile := filepath.Join(tmpDir, "nokeydata.bin")
+	buf.Reset()
+	binary.Write(buf, binary.LittleEndian, uint32(5)) // size
+	binary.Write(buf, binary.LittleEndian, uint32(1)) // count
+	binary.Write(buf, binary.LittleEndian, true)      // occupied
+	binary.Write(buf, binary.LittleEndian, uint32(5)) // keyLen = 5
+	// Не пишем байты ключа
+	os.WriteFile(noKeyDataFile, persisttest.Envelope[int](persist.KindDoubleHash, buf.Bytes()), 0644)
+
+	if err := dh.DeserializeBin(noKeyDataFile); err == nil {
+		t.Error("Expected error when reading key data")
+	}
+
+	// Ошибка декодирования значения
+	// Ситуация: записали всё корректно, но байты значения битые или не соответствуют типу
+	badGobFile := filepath.Join(tmpDir, "badgob.bin")
+	buf.Reset()
+	binary.Write(buf, binary.LittleEndian, uint32(5)) // size
+	binary.Write(buf, binary.LittleEndian, uint32(1)) // count
+	binary.Write(buf, binary.LittleEndian, true)      // occupied
+	binary.Write(buf, binary.LittleEndian, uint32(3)) // keyLen = 3
+	buf.WriteString("key")                            // key bytes
+	// Вместо валидного gob для int пишем мусор
+	buf.Write([]byte{0xFF, 0xFF, 0xFF})
+	os.WriteFile(badGobFile, persisttest.Envelope[int](persist.KindDoubleHash, buf.Bytes()), 0644)
+
+	if err := dh.DeserializeBin(badGobFile); err == nil {
+		t.Error("Expected error for gob decode failure")
+	}
+}
+
+func TestFullBinaryCycle(t *testing.T) {
+	// Проверка "счастливого пути" для Binary
+	tmpFile := filepath.Join(t.TempDir(), "ok.bin")
+
+	dh, _ := NewDoubleHash[int32](5)
+	dh.Insert("A", 100)
+	// Создаем "дырку" (удаленный элемент), чтобы проверить false в occupied
+	dh.Insert("B", 200)
+	dh.Remove("B")
+
+	if err := dh.SerializeBin(tmpFile); err != nil {
+		t.Fatalf("SerializeBin failed: %v", err)
+	}
+
+	dhLoaded, _ := NewDoubleHash[int32](1)
+	if err := dhLoaded.DeserializeBin(tmpFile); err != nil {
+		t.Fatalf("DeserializeBin failed: %v", err)
+	}
+
+	if dhLoaded.Size() != 1 {
+		t.Errorf("Expected size 1 (B removed), got %d", dhLoaded.Size())
+	}
+	if *dhLoaded.Find("A") != 100 {
+		t.Error("Value A lost")
+	}
+	if dhLoaded.Find("B") != nil {
+		t.Error("B should be removed")
+	}
+}
+
+// TestCollisionCycle проверяет, что мы можем найти элемент, даже если он стоит
+func TestCollisionCycle(t *testing.T) {
+
+	dh, _ := NewDoubleHash[int](10)
+	// Вставляем много элементов
+	for i := 0; i < 8; i++ {
+		key := fmt.Sprintf("k%d", i)
+		dh.Insert(key, i)
+	}
+
+	// Проверяем поиск каждого
+	for i := 0; i < 8; i++ {
+		key := fmt.Sprintf("k%d", i)
+		if val := dh.Find(key); val == nil || *val != i {
+			t.Errorf("Failed to find key %s in populated table", key)
+		}
+	}
+}
+
+// TestShortProbeCycle проверяет вставку, когда шаг h2 делит размер таблицы:
+// цепочка проб короче таблицы, и таблица должна расти, а не отказывать
+func TestShortProbeCycle(t *testing.T) {
+	dh, _ := NewDoubleHash[int](7)
+	for i := range 50 {
+		key := "key-" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+		if err := dh.Insert(key, i); err != nil {
+			t.Fatalf("Insert(%q) error: %v", key, err)
+		}
+	}
+	if dh.Size() != 50 {
+		t.Errorf("Size() = %d, want 50", dh.Size())
+	}
+}
+
+// TestRemoveKeepsProbeChain проверяет, что удаление не разрывает цепочку проб
+func TestRemoveKeepsProbeChain(t *testing.T) {
+	dh, _ := NewDoubleHash[int](101)
+
+	// Подбираем два ключа с одинаковым hash1: второй ляжет дальше по цепочке первого
+	first, second := "", ""
+	seen := make(map[uint32]string)
+	for i := 0; i < 1000 && second == ""; i++ {
+		key := fmt.Sprintf("k%d", i)
+		h := dh.hash1(key)
+		if prev, ok := seen[h]; ok {
+			first, second = prev, key
+		}
+		seen[h] = key
+	}
+	if second == "" {
+		t.Fatal("No colliding keys found")
+	}
+
+	dh.Insert(first, 1)
+	dh.Insert(second, 2)
+
+	if !dh.Remove(first) {
+		t.Fatalf("Failed to remove %s", first)
+	}
+	if val := dh.Find(second); val == nil || *val != 2 {
+		t.Errorf("Key %s lost after removing %s", second, first)
+	}
+
+	// Повторная вставка не должна создавать дубликат ключа из хвоста цепочки
+	dh.Insert(second, 3)
+	if dh.Size() != 1 {
+		t.Errorf("Expected size 1, got %d", dh.Size())
+	}
+	if !dh.Remove(second) || dh.Find(second) != nil {
+		t.Error("Duplicate entry left after reinsert")
+	}
+
+	// Надгробие переиспользуется при вставке
+	dh.Insert(first, 4)
+	if val := dh.Find(first); val == nil || *val != 4 {
+		t.Error("Insert into tombstone failed")
+	}
+}
+
+func TestRange(t *testing.T) {
+	dh, _ := NewDoubleHash[int](5)
+	dh.Insert("A", 1)
+	dh.Insert("B", 2)
+	dh.Insert("C", 3)
+	dh.Remove("B")
+
+	sum := 0
+	dh.Range(func(key string, value int) bool {
+		sum += value
+		return true
+	})
+	if sum != 4 {
+		t.Errorf("Expected sum 4, got %d", sum)
+	}
+
+	// Ранняя остановка
+	visited := 0
+	dh.Range(func(key string, value int) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Errorf("Range did not stop, visited %d", visited)
+	}
+}
+
+// Тесты журнала упреждающей записи
+
+// snapshotState возвращает содержимое таблицы в виде map
+func snapshotState[T any](dh *DoubleHash[T]) map[string]T {
+	state := make(map[string]T)
+	dh.Range(func(key string, value T) bool {
+		state[key] = value
+		return true
+	})
+	return state
+}
+
+func TestWALRecover(t *testing.T) {
+	dir := t.TempDir()
+	dh, _ := NewDoubleHash[int](5)
+	dh.Insert("before", 1)
+	if err := dh.EnableWAL(dir); err != nil {
+		t.Fatalf("EnableWAL failed: %v", err)
+	}
+	if err := dh.EnableWAL(dir); err != ErrWALEnabled {
+		t.Errorf("Expected ErrWALEnabled, got %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		dh.Insert(fmt.Sprintf("k%d", i), i)
+	}
+	dh.Remove("k3")
+	if err := dh.Checkpoint(); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	dh.Remove("before")
+	dh.Insert("k4", 400)
+	dh.CloseWAL()
+
+	recovered, err := Recover[int](dir)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	defer recovered.CloseWAL()
+
+	if recovered.Size() != 19 {
+		t.Errorf("Expected 19 elements, got %d", recovered.Size())
+	}
+	if recovered.Find("before") != nil || recovered.Find("k3") != nil {
+		t.Error("Removed keys resurrected after recovery")
+	}
+	if v := recovered.Find("k4"); v == nil || *v != 400 {
+		t.Errorf("Expected k4=400 from log, got %v", v)
+	}
+
+	// Восстановленная таблица продолжает писать журнал
+	recovered.Insert("after", 7)
+	recovered.CloseWAL()
+	again, _ := Recover[int](dir)
+	defer again.CloseWAL()
+	if v := again.Find("after"); v == nil || *v != 7 {
+		t.Error("Insert after recovery was not logged")
+	}
+}
+
+// pairCodec кодирует [2]string - тип без кодека по умолчанию.
+// Пустой первый элемент считается ошибкой, чтобы проверить отказ кодирования.
+type pairCodec struct{}
+
+func (pairCodec) Encode(w io.Writer, v [2]string) error {
+	if v[0] == "" {
+		return errors.New("empty pair name")
+	}
+	if err := (codec.String{}).Encode(w, v[0]); err != nil {
+		return err
+	}
+	return (codec.String{}).Encode(w, v[1])
+}
+
+func (pairCodec) Decode(r io.Reader) ([2]string, error) {
+	var v [2]string
+	var err error
+	if v[0], err = (codec.String{}).Decode(r); err != nil {
+		return v, err
+	}
+	if v[1], err = (codec.String{}).Decode(r); err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+// TestWALCustomCodec проверяет, что журнал и снимок пишутся кодеком таблицы
+func TestWALCustomCodec(t *testing.T) {
+	dir := t.TempDir()
+	dh, _ := NewDoubleHash[[2]string](5)
+	dh.SetCodec(pairCodec{})
+	dh.Insert("snap", [2]string{"a", "b"})
+	if err := dh.EnableWAL(dir); err != nil {
+		t.Fatalf("EnableWAL failed: %v", err)
+	}
+	if err := dh.Insert("log", [2]string{"c", "d"}); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	// Ошибка кодирования не меняет ни таблицу, ни журнал
+	if err := dh.Insert("bad", [2]string{"", "x"}); err == nil {
+		t.Error("Expected encoding error")
+	}
+	if dh.Find("bad") != nil {
+		t.Error("Failed insert changed the table")
+	}
+	dh.CloseWAL()
+
+	if _, err := Recover[[2]string](dir); err == nil {
+		t.Error("Expected error recovering without a codec")
+	}
+	recovered, err := RecoverWithCodec(dir, codec.ElementCodec[[2]string](pairCodec{}))
+	if err != nil {
+		t.Fatalf("RecoverWithCodec failed: %v", err)
+	}
+	defer recovered.CloseWAL()
+	if v := recovered.Find("snap"); v == nil || *v != [2]string{"a", "b"} {
+		t.Errorf("Expected snapshot value, got %v", v)
+	}
+	if v := recovered.Find("log"); v == nil || *v != [2]string{"c", "d"} {
+		t.Errorf("Expected logged value, got %v", v)
+	}
+	if recovered.Size() != 2 {
+		t.Errorf("Expected 2 elements, got %d", recovered.Size())
+	}
+}
+
+// TestWALAppendFailureRollsBack проверяет, что вставка, не попавшая в журнал,
+// откатывается и таблица совпадает с тем, что будет восстановлено
+func TestWALAppendFailureRollsBack(t *testing.T) {
+	dh, _ := NewDoubleHash[int](5)
+	dh.Insert("kept", 1)
+	if err := dh.EnableWAL(t.TempDir()); err != nil {
+		t.Fatalf("EnableWAL failed: %v", err)
+	}
+	// Закрытый файл журнала отказывает в записи
+	dh.log.Close()
+
+	if err := dh.Insert("new", 2); err == nil {
+		t.Fatal("Expected log append error")
+	}
+	if err := dh.Insert("kept", 3); err == nil {
+		t.Fatal("Expected log append error")
+	}
+	if dh.Find("new") != nil {
+		t.Error("Insert of new key was not rolled back")
+	}
+	if v := dh.Find("kept"); v == nil || *v != 1 {
+		t.Errorf("Update was not rolled back, got %v", v)
+	}
+	if dh.Size() != 1 {
+		t.Errorf("Expected 1 element, got %d", dh.Size())
+	}
+
+	// Сбой журнала в Remove отличается от отсутствия ключа: ключ на месте
+	if dh.Remove("kept") || dh.Find("kept") == nil || dh.WALError() == nil {
+		t.Error("Failed Remove must keep the key and report WALError")
+	}
+}
+
+func TestWALTruncateAndCorruptLog(t *testing.T) {
+	base := t.TempDir()
+	dh, _ := NewDoubleHash[string](5)
+	dh.EnableWAL(base)
+
+	logPath := filepath.Join(base, "wal.log")
+	ops := []func(){
+		func() { dh.Insert("a", "1") },
+		func() { dh.Insert("b", "2") },
+		func() { dh.Remove("a") },
+		func() { dh.Insert("c", "три") },
+		func() { dh.Clear() },
+		func() { dh.Insert("d", "4") },
+		func() { dh.Insert("b", "22") },
+	}
+	// Состояние таблицы и размер журнала после каждой операции
+	states := []map[string]string{snapshotState(dh)}
+	ends := []int64{0}
+	for _, op := range ops {
+		op()
+		states = append(states, snapshotState(dh))
+		info, _ := os.Stat(logPath)
+		ends = append(ends, info.Size())
+	}
+	dh.CloseWAL()
+	snapshot, _ := os.ReadFile(filepath.Join(base, "snapshot"))
+	log, _ := os.ReadFile(logPath)
+
+	// expected возвращает состояние после последней операции, целиком лежащей в log[:n]
+	expected := func(n int64) map[string]string {
+		k := 0
+		for k+1 < len(ends) && ends[k+1] <= n {
+			k++
+		}
+		return states[k]
+	}
+	recoverWith := func(data []byte) map[string]string {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "snapshot"), snapshot, 0644)
+		os.WriteFile(filepath.Join(dir, "wal.log"), data, 0644)
+		recovered, err := Recover[string](dir)
+		if err != nil {
+			t.Fatalf("Recover failed: %v", err)
+		}
+		defer recovered.CloseWAL()
+		return snapshotState(recovered)
+	}
+
+	for cut := 0; cut <= len(log); cut++ {
+		got := recoverWith(log[:cut])
+		if want := expected(int64(cut)); !reflect.DeepEqual(got, want) {
+			t.Fatalf("truncated at %d: expected %v, got %v", cut, want, got)
+		}
+	}
+
+	for pos := range log {
+		corrupted := append([]byte(nil), log...)
+		corrupted[pos] ^= 0xFF
+		// Все, что начинается с испорченного кадра, отбрасывается
+		k := 0
+		for ends[k+1] <= int64(pos) {
+			k++
+		}
+		got := recoverWith(corrupted)
+		if want := states[k]; !reflect.DeepEqual(got, want) {
+			t.Fatalf("corrupt byte %d: expected %v, got %v", pos, want, got)
+		}
+	}
+}
+
+// Тесты атомарного сохранения
+
+// TestSaveFailureKeepsFile обрывает запись на разных байтах и проверяет,
+// что ранее сохраненный файл не изменился, а временные файлы удалены
+func TestSaveFailureKeepsFile(t *testing.T) {
+	dh, _ := NewDoubleHash[int32](16)
+	for i := 0; i < 100; i++ {
+		dh.Insert(fmt.Sprintf("key%d", i), int32(i))
+	}
+	persisttest.SaveFailureKeepsFile(t, map[string]func(string, ...persist.Option) error{
+		"text":   dh.SerializeText,
+		"binary": dh.SerializeBin,
+	}, func() { dh.Insert("extra", 1000) })
+}
+
+// streamFormats - форматы потокового сохранения таблицы
+func streamFormats[T any]() []persisttest.Format[*DoubleHash[T]] {
+	return []persisttest.Format[*DoubleHash[T]]{
+		{Name: "text", Write: (*DoubleHash[T]).WriteTextTo, Read: (*DoubleHash[T]).ReadTextFrom},
+		{Name: "binary", Write: (*DoubleHash[T]).WriteTo, Read: (*DoubleHash[T]).ReadFrom},
+		{Name: "json", Write: (*DoubleHash[T]).WriteJSONTo, Read: (*DoubleHash[T]).ReadJSONFrom},
+	}
+}
+
+// sameTable сравнивает пары ключ-значение таблиц
+func sameTable[T comparable](a, b *DoubleHash[T]) bool {
+	return a.Size() == b.Size() && maps.Equal(snapshotState(a), snapshotState(b))
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	src, _ := NewDoubleHash[int32](16)
+	for i := 0; i < 50; i++ {
+		src.Insert(fmt.Sprintf("key%d", i), int32(i))
+	}
+	var _ io.WriterTo = src
+	var _ io.ReaderFrom = src
+
+	persisttest.RoundTrip(t, src, func() *DoubleHash[int32] { dh, _ := NewDoubleHash[int32](16); return dh }, streamFormats[int32](), sameTable[int32])
+}
+
+func TestBinaryVariableLength(t *testing.T) {
+	dh, _ := NewDoubleHash[string](8)
+	want := map[string]string{"a": "alpha", "b": "", "c": "значение с пробелами"}
+	for k, val := range want {
+		dh.Insert(k, val)
+	}
+
+	path := filepath.Join(t.TempDir(), "strings.bin")
+	if err := dh.SerializeBin(path); err != nil {
+		t.Fatalf("SerializeBin failed: %v", err)
+	}
+	loaded, _ := NewDoubleHash[string](1)
+	if err := loaded.DeserializeBin(path); err != nil {
+		t.Fatalf("DeserializeBin failed: %v", err)
+	}
+	for k, val := range want {
+		if got := loaded.Find(k); got == nil || *got != val {
+			t.Errorf("Key %q: expected %q, got %v", k, val, got)
+		}
+	}
+}
+
+// Fuzz-тест текстового формата: строки с пробелами, кавычками и переводами строк
+// должны читаться обратно без изменений
+func FuzzTextRoundTrip(f *testing.F) {
+	persisttest.FuzzText(f, func(a, b string) *DoubleHash[string] {
+		src, _ := NewDoubleHash[string](8)
+		src.Insert(a, b)
+		src.Insert(b, a)
+		src.Insert(a+" "+b, "")
+		return src
+	}, func() *DoubleHash[string] { dh, _ := NewDoubleHash[string](8); return dh }, streamFormats[string]()[0], sameTable[string])
+}
+
+func TestJSON(t *testing.T) {
+	dh, _ := NewDoubleHash[int](7)
+	dh.Insert("one", 1)
+	dh.Insert("two words", 2)
+
+	data, err := json.Marshal(dh)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var plain map[string]int
+	if err := json.Unmarshal(data, &plain); err != nil || !reflect.DeepEqual(plain, map[string]int{"one": 1, "two words": 2}) {
+		t.Fatalf("Unexpected JSON %s (%v)", data, err)
+	}
+
+	// Нулевая таблица в структуре получает размер при разборе
+	var decoded struct {
+		Table DoubleHash[int] `json:"table"`
+	}
+	input := `{"table": {"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}}`
+	if err := json.Unmarshal([]byte(input), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Table.Size() != 5 || *decoded.Table.Find("e") != 5 {
+		t.Errorf("Unexpected table after unmarshal, size %d", decoded.Table.Size())
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	dh, _ := NewDoubleHash[int](7)
+	dh.Insert("keep", 1)
+
+	cases := []struct {
+		input string
+		path  string
+		err   error
+	}{
+		{`{"a": 1, "b": "x"}`, `$["b"]`, nil},
+		{`{"a": 1, "a": 2}`, `$["a"]`, codec.ErrDuplicateKey},
+		{`[1, 2]`, `$`, codec.ErrJSONSyntax},
+		{`{"a": 1} {}`, `$`, codec.ErrJSONSyntax},
+	}
+	for _, c := range cases {
+		err := dh.UnmarshalJSON([]byte(c.input))
+		var je *codec.JSONError
+		if !errors.As(err, &je) || je.Path != c.path || (c.err != nil && !errors.Is(err, c.err)) {
+			t.Errorf("%s: expected error at %s, got %v", c.input, c.path, err)
+		}
+	}
+	if dh.Size() != 1 || dh.Find("keep") == nil {
+		t.Error("Table changed after failed unmarshal")
+	}
+}
+
+func TestCSV(t *testing.T) {
+	dh, _ := NewDoubleHash[int](7)
+	dh.Insert("one", 1)
+	dh.Insert("two, three", 23)
+
+	var buf bytes.Buffer
+	if err := dh.ExportCSV(&buf); err != nil {
+		t.Fatalf("ExportCSV failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "key,value\n") || !strings.Contains(buf.String(), "\"two, three\",23\n") {
+		t.Fatalf("Unexpected CSV %q", buf.String())
+	}
+
+	loaded, _ := NewDoubleHash[int](3)
+	if err := loaded.ImportCSV(&buf); err != nil {
+		t.Fatalf("ImportCSV failed: %v", err)
+	}
+	if loaded.Size() != 2 || *loaded.Find("two, three") != 23 {
+		t.Errorf("Unexpected table after import, size %d", loaded.Size())
+	}
+
+	// Колонки в другом порядке, плохое значение и повторный ключ
+	err := loaded.ImportCSV(strings.NewReader("value\tkey\n1\ta\nx\tb\n3\ta\n4\tc\n"), codec.WithCSVComma('\t'))
+	var rowErrs codec.RowErrors
+	if !errors.As(err, &rowErrs) || len(rowErrs) != 2 || rowErrs[0].Line != 3 || rowErrs[1].Line != 4 {
+		t.Fatalf("Expected errors on lines 3 and 4, got %v", err)
+	}
+	if !errors.Is(err, codec.ErrDuplicateKey) {
+		t.Errorf("Expected ErrDuplicateKey among row errors, got %v", err)
+	}
+	if loaded.Size() != 2 || *loaded.Find("a") != 1 || loaded.Find("one") != nil {
+		t.Errorf("Unexpected table after partial import, size %d", loaded.Size())
+	}
+}
+
+func TestGobEmbedding(t *testing.T) {
+	src, _ := NewDoubleHash[int](7)
+	src.Insert("a", 1)
+	src.Insert("b", 2)
+	var _ encoding.BinaryMarshaler = src
+	var _ gob.GobDecoder = src
+
+	// Таблица внутри структуры по значению
+	persisttest.GobRoundTrip(t, *src, func(a, b DoubleHash[int]) bool { return sameTable(&a, &b) })
+
+	data, _ := src.MarshalBinary()
+	dst, _ := NewDoubleHash[int](7)
+	if err := dst.UnmarshalBinary(append(data, '\n')); !errors.Is(err, persist.ErrTrailingData) {
+		t.Errorf("Expected ErrTrailingData, got %v", err)
+	}
+}
+
+func TestCompressedSerialize(t *testing.T) {
+	dir := t.TempDir()
+	dh, _ := NewDoubleHash[string](64)
+	for i := 0; i < 200; i++ {
+		dh.Insert(fmt.Sprintf("key%03d", i), strings.Repeat("v", i%7))
+	}
+
+	for _, alg := range []compression.Algorithm{compression.Gzip, compression.Flate, compression.LZ} {
+		binFile := filepath.Join(dir, alg.String()+".bin")
+		txtFile := filepath.Join(dir, alg.String()+".txt")
+		if err := dh.SerializeBin(binFile, compression.With(alg)); err != nil {
+			t.Fatalf("%v: SerializeBin failed: %v", alg, err)
+		}
+		if err := dh.SerializeText(txtFile, compression.With(alg)); err != nil {
+			t.Fatalf("%v: SerializeText failed: %v", alg, err)
+		}
+
+		fromBin, _ := NewDoubleHash[string](1)
+		fromTxt, _ := NewDoubleHash[string](1)
+		if err := fromBin.DeserializeBin(binFile); err != nil {
+			t.Fatalf("%v: DeserializeBin failed: %v", alg, err)
+		}
+		if err := fromTxt.DeserializeText(txtFile); err != nil {
+			t.Fatalf("%v: DeserializeText failed: %v", alg, err)
+		}
+		want := snapshotState(dh)
+		if got := snapshotState(fromBin); !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: binary contents differ", alg)
+		}
+		if got := snapshotState(fromTxt); !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: text contents differ", alg)
+		}
+	}
+
+	// Неизвестный алгоритм в заголовке
+	path := filepath.Join(dir, "unknown.bin")
+	os.WriteFile(path, []byte("LR3C\x2a"), 0644)
+	if err := dh.DeserializeBin(path); !errors.Is(err, compression.ErrUnknownAlgorithm) {
+		t.Errorf("Expected ErrUnknownAlgorithm, got %v", err)
+	}
+}
+
+func TestBoundedLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	// Размер таблицы в заголовке больше, чем ячеек в данных
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, uint32(math.MaxUint32-1)) // size
+	binary.Write(buf, binary.LittleEndian, uint32(0))                // count
+	path := filepath.Join(dir, "huge.bin")
+	os.WriteFile(path, persisttest.Envelope[int](persist.KindDoubleHash, buf.Bytes()), 0644)
+
+	dh, _ := NewDoubleHash[int](5)
+	dh.Insert("keep", 1)
+	if err := dh.DeserializeBin(path); !errors.Is(err, persist.ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+	if dh.Find("keep") == nil {
+		t.Error("Failed load changed the table")
+	}
+
+	// Данные читаются потоком: испорченный и обрезанный файлы не меняют таблицу
+	other, _ := NewDoubleHash[int](7)
+	other.Insert("other", 2)
+	otherPath := filepath.Join(dir, "other.bin")
+	other.SerializeBin(otherPath)
+	data, _ := os.ReadFile(otherPath)
+	data[len(data)-1] ^= 0xFF
+	os.WriteFile(otherPath, data, 0644)
+	if err := dh.DeserializeBin(otherPath); !errors.Is(err, persist.ErrChecksum) {
+		t.Errorf("Expected ErrChecksum, got %v", err)
+	}
+	os.WriteFile(otherPath, data[:len(data)-3], 0644)
+	if err := dh.DeserializeBin(otherPath); !errors.Is(err, persist.ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+	if dh.Find("keep") == nil || dh.Find("other") != nil || dh.Size() != 1 {
+		t.Error("Failed load changed the table")
+	}
+
+	// Предел объема данных
+	for i := 0; i < 100; i++ {
+		dh.Insert(fmt.Sprintf("key%d", i), i)
+	}
+	textFile := filepath.Join(dir, "table.txt")
+	dh.SerializeText(textFile)
+	if err := dh.DeserializeText(textFile, persist.WithLimit(64)); !errors.Is(err, persist.ErrLimitExceeded) {
+		t.Errorf("Expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestFromSliceToSlice(t *testing.T) {
+	dh, err := FromSlice([]HashNode[int]{{Key: "a", Value: 1}, {Key: "b", Value: 2}, {Key: "a", Value: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dh.Size() != 2 || *dh.Find("a") != 3 {
+		t.Errorf("Expected 2 keys with a=3, got size %d", dh.Size())
+	}
+	entries := dh.ToSlice()
+	if len(entries) != 2 || !entries[0].IsOccupied {
+		t.Errorf("ToSlice = %v", entries)
+	}
+
+	dh, err = FromSeq(maps.All(map[string]int{"x": 1, "y": 2, "z": 3}))
+	if err != nil || dh.Size() != 3 || *dh.Find("z") != 3 {
+		t.Errorf("FromSeq: %v", err)
+	}
+	// Таблица из пустого среза рабочая
+	dh, _ = FromSlice[int](nil)
+	dh.Insert("k", 1)
+	if dh.Find("k") == nil {
+		t.Error("Empty FromSlice table is not usable")
+	}
+}
@@ -0,0 +1,714 @@
+package diskhash
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/D4ROVAN1E/LR_3_Go/hashing"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Формат файла слотов:
+//
+//	[0:64)  заголовок: magic[8], tableSize, count, deleted, dirty (uint32), ..., crc32 (последние 4 байта)
+//	[64:)   tableSize слотов по 64 байта
+//
+// Слот: state(1), pad(7), offset(8), keyLen(4), valLen(4), foldSum(4), pad(4),
+// numKey(8), pad(20), crc32 первых 60 байт (4). Размер слота кратен сектору,
+// поэтому запись слота не рвется между секторами.
+//
+// Размер таблицы - простое число: любой шаг FoldStep взаимно прост с ним,
+// поэтому цепочка проб обходит все слоты.
+//
+// Файл кучи (path + ".heap") начинается с magic[8], дальше идут записи
+// keyLen(4), valLen(4), key, value, crc32(4). Записи только добавляются.
+const (
+	headerSize   = 64
+	slotSize     = 64
+	minTableSize = 8
+	heapHeader   = 8
+	recordHeader = 8
+	recordCRC    = 4
+)
+
+var (
+	slotMagic = [8]byte{'D', 'H', 'S', 'L', 'O', 'T', '0', '1'}
+	heapMagic = [8]byte{'D', 'H', 'H', 'E', 'A', 'P', '0', '1'}
+)
+
+// Состояния слота
+const (
+	slotEmpty    = 0
+	slotOccupied = 1
+	slotDeleted  = 2
+)
+
+// Ошибки, которые может вернуть таблица
+var (
+	ErrKeyNotFound = errors.New("key not found")
+	ErrClosed      = errors.New("disk hash is closed")
+	ErrBadMagic    = errors.New("file is not a disk hash table")
+	ErrCorrupt     = errors.New("disk hash data is corrupted")
+
+	// errProbeExhausted - цепочка проб не нашла ни ключа, ни свободного слота.
+	// В таблице простого размера это значит, что она заполнена целиком.
+	errProbeExhausted = errors.New("probe sequence exhausted")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// slot - разобранное содержимое слота
+type slot struct {
+	state   uint8
+	offset  uint64
+	keyLen  uint32
+	valLen  uint32
+	foldSum uint32
+	numKey  uint64
+}
+
+// DiskHash - файловый вариант DoubleHash. Слоты лежат в отображенном в память
+// файле фиксированных записей, а ключи и значения переменной длины - в файле кучи.
+// Поиск читает только нужные слоты и одну запись кучи.
+//
+// Устойчивость к сбоям: запись в кучу всегда предшествует обновлению слота,
+// и при включенной синхронной записи (по умолчанию) куча сбрасывается на диск
+// до изменения слота. Если процесс упал между вызовами Sync, при следующем Open
+// слоты перепроверяются по контрольным суммам, а испорченные превращаются
+// в надгробия. Перестройка таблицы пишет новый файл и атомарно подменяет старый.
+//
+// Старые версии значений остаются в куче: файл кучи только растет.
+type DiskHash struct {
+	path  string
+	slots *os.File
+	heap  *os.File
+	data  []byte // Отображение файла слотов
+
+	tableSize uint32
+	count     uint32
+	deleted   uint32
+	heapEnd   int64
+
+	dirty      bool
+	syncWrites bool
+	closed     bool
+}
+
+// Open открывает таблицу по пути path или создает новую хотя бы из size слотов
+// (размер округляется вверх до простого). Для существующей таблицы size игнорируется.
+func Open(path string, size uint32) (*DiskHash, error) {
+	size = nextPrime(max(size, minTableSize))
+
+	heap, err := openHeap(path + ".heap")
+	if err != nil {
+		return nil, err
+	}
+	heapInfo, err := heap.Stat()
+	if err != nil {
+		heap.Close()
+		return nil, err
+	}
+
+	slots, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		heap.Close()
+		return nil, fmt.Errorf("error: Could not open slot file: %w", err)
+	}
+
+	dh := &DiskHash{
+		path:       path,
+		slots:      slots,
+		heap:       heap,
+		heapEnd:    heapInfo.Size(),
+		syncWrites: true,
+	}
+
+	info, err := slots.Stat()
+	if err == nil {
+		if info.Size() == 0 {
+			err = dh.create(size)
+		} else {
+			err = dh.load(info.Size())
+		}
+	}
+	if err != nil {
+		if dh.data != nil {
+			unmapFile(slots, dh.data)
+		}
+		slots.Close()
+		heap.Close()
+		return nil, err
+	}
+	return dh, nil
+}
+
+// openHeap открывает файл кучи и проверяет его сигнатуру
+func openHeap(path string) (*os.File, error) {
+	heap, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error: Could not open heap file: %w", err)
+	}
+
+	var magic [8]byte
+	n, err := heap.ReadAt(magic[:], 0)
+	switch {
+	case n == 0 && err == io.EOF:
+		// Новый файл
+		if _, err := heap.WriteAt(heapMagic[:], 0); err != nil {
+			heap.Close()
+			return nil, err
+		}
+		if err := heap.Sync(); err != nil {
+			heap.Close()
+			return nil, err
+		}
+	case err != nil && err != io.EOF, n < len(magic), magic != heapMagic:
+		heap.Close()
+		return nil, ErrBadMagic
+	}
+	return heap, nil
+}
+
+// create инициализирует пустой файл слотов
+func (dh *DiskHash) create(size uint32) error {
+	if err := dh.slots.Truncate(fileSize(size)); err != nil {
+		return err
+	}
+	data, err := mapFile(dh.slots, int(fileSize(size)))
+	if err != nil {
+		return err
+	}
+	dh.data = data
+	dh.tableSize = size
+	dh.writeHeader()
+	if err := syncMapping(dh.slots, dh.data, 0, len(dh.data)); err != nil {
+		return err
+	}
+	return persist.SyncDir(filepath.Dir(dh.path))
+}
+
+// load отображает существующий файл слотов и проверяет заголовок
+func (dh *DiskHash) load(size int64) error {
+	if size < headerSize {
+		return ErrBadMagic
+	}
+	data, err := mapFile(dh.slots, int(size))
+	if err != nil {
+		return err
+	}
+	dh.data = data
+
+	hdr := dh.data[:headerSize]
+	if [8]byte(hdr[0:8]) != slotMagic {
+		return ErrBadMagic
+	}
+	if crc32.Checksum(hdr[:headerSize-4], castagnoli) != binary.LittleEndian.Uint32(hdr[headerSize-4:]) {
+		return fmt.Errorf("%w: header checksum mismatch", ErrCorrupt)
+	}
+
+	dh.tableSize = binary.LittleEndian.Uint32(hdr[8:12])
+	dh.count = binary.LittleEndian.Uint32(hdr[12:16])
+	dh.deleted = binary.LittleEndian.Uint32(hdr[16:20])
+	dh.dirty = binary.LittleEndian.Uint32(hdr[20:24]) != 0
+
+	if dh.tableSize < minTableSize || fileSize(dh.tableSize) != size {
+		return fmt.Errorf("%w: table size %d does not match file size %d", ErrCorrupt, dh.tableSize, size)
+	}
+
+	// Предыдущий процесс не вызвал Sync: перепроверяем слоты
+	if dh.dirty {
+		return dh.recover()
+	}
+	return nil
+}
+
+// recover перепроверяет все занятые слоты после аварийного завершения
+func (dh *DiskHash) recover() error {
+	var count, deleted uint32
+	for i := uint32(0); i < dh.tableSize; i++ {
+		raw := dh.slotBytes(i)
+		if raw[0] == slotEmpty && isZero(raw) {
+			continue
+		}
+
+		s, ok := decodeSlot(raw)
+		if ok && s.state == slotOccupied {
+			// Слот указывает на запись, которая могла не дойти до диска
+			if _, _, err := dh.readRecord(s); err != nil {
+				ok = false
+			}
+		}
+		if !ok || s.state == slotDeleted || s.state == slotEmpty {
+			// Испорченный слот превращается в надгробие, чтобы не рвать цепочки проб
+			encodeSlot(raw, slot{state: slotDeleted})
+			deleted++
+			continue
+		}
+		count++
+	}
+
+	dh.count = count
+	dh.deleted = deleted
+	dh.dirty = false
+	dh.writeHeader()
+	return syncMapping(dh.slots, dh.data, 0, len(dh.data))
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// nextPrime возвращает наименьшее простое число, не меньшее n
+func nextPrime(n uint32) uint32 {
+	for ; ; n++ {
+		if isPrime(n) {
+			return n
+		}
+	}
+}
+
+func isPrime(n uint32) bool {
+	if n < 2 {
+		return false
+	}
+	for d := uint64(2); d*d <= uint64(n); d++ {
+		if uint64(n)%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// fileSize возвращает размер файла слотов для таблицы из size слотов
+func fileSize(size uint32) int64 {
+	return headerSize + int64(size)*slotSize
+}
+
+// writeHeader записывает заголовок в отображение
+func (dh *DiskHash) writeHeader() {
+	hdr := dh.data[:headerSize]
+	copy(hdr[0:8], slotMagic[:])
+	binary.LittleEndian.PutUint32(hdr[8:12], dh.tableSize)
+	binary.LittleEndian.PutUint32(hdr[12:16], dh.count)
+	binary.LittleEndian.PutUint32(hdr[16:20], dh.deleted)
+	var dirty uint32
+	if dh.dirty {
+		dirty = 1
+	}
+	binary.LittleEndian.PutUint32(hdr[20:24], dirty)
+	binary.LittleEndian.PutUint32(hdr[headerSize-4:], crc32.Checksum(hdr[:headerSize-4], castagnoli))
+}
+
+// markDirty перед первым изменением после Sync записывает на диск флаг dirty.
+// Флаг должен попасть на диск раньше измененных слотов.
+func (dh *DiskHash) markDirty() error {
+	if dh.dirty {
+		return nil
+	}
+	dh.dirty = true
+	dh.writeHeader()
+	return syncMapping(dh.slots, dh.data, 0, headerSize)
+}
+
+func (dh *DiskHash) slotBytes(i uint32) []byte {
+	off := headerSize + int(i)*slotSize
+	return dh.data[off : off+slotSize]
+}
+
+// decodeSlot разбирает слот и проверяет его контрольную сумму
+func decodeSlot(raw []byte) (slot, bool) {
+	s := slot{
+		state:   raw[0],
+		offset:  binary.LittleEndian.Uint64(raw[8:16]),
+		keyLen:  binary.LittleEndian.Uint32(raw[16:20]),
+		valLen:  binary.LittleEndian.Uint32(raw[20:24]),
+		foldSum: binary.LittleEndian.Uint32(raw[24:28]),
+		numKey:  binary.LittleEndian.Uint64(raw[32:40]),
+	}
+	ok := crc32.Checksum(raw[:slotSize-4], castagnoli) == binary.LittleEndian.Uint32(raw[slotSize-4:])
+	return s, ok && s.state <= slotDeleted
+}
+
+// encodeSlot записывает слот вместе с контрольной суммой
+func encodeSlot(raw []byte, s slot) {
+	clear(raw)
+	raw[0] = s.state
+	binary.LittleEndian.PutUint64(raw[8:16], s.offset)
+	binary.LittleEndian.PutUint32(raw[16:20], s.keyLen)
+	binary.LittleEndian.PutUint32(raw[20:24], s.valLen)
+	binary.LittleEndian.PutUint32(raw[24:28], s.foldSum)
+	binary.LittleEndian.PutUint64(raw[32:40], s.numKey)
+	binary.LittleEndian.PutUint32(raw[slotSize-4:], crc32.Checksum(raw[:slotSize-4], castagnoli))
+}
+
+// readRecord читает и проверяет запись кучи, на которую указывает слот
+func (dh *DiskHash) readRecord(s slot) (key string, value []byte, err error) {
+	total := int64(recordHeader) + int64(s.keyLen) + int64(s.valLen) + recordCRC
+	if s.offset < heapHeader || int64(s.offset)+total > dh.heapEnd {
+		return "", nil, fmt.Errorf("%w: record at %d is out of heap bounds", ErrCorrupt, s.offset)
+	}
+
+	buf := make([]byte, total)
+	if _, err := dh.heap.ReadAt(buf, int64(s.offset)); err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	body := buf[:total-recordCRC]
+	if crc32.Checksum(body, castagnoli) != binary.LittleEndian.Uint32(buf[total-recordCRC:]) {
+		return "", nil, fmt.Errorf("%w: record checksum mismatch at %d", ErrCorrupt, s.offset)
+	}
+	if binary.LittleEndian.Uint32(buf[0:4]) != s.keyLen || binary.LittleEndian.Uint32(buf[4:8]) != s.valLen {
+		return "", nil, fmt.Errorf("%w: record header does not match slot at %d", ErrCorrupt, s.offset)
+	}
+
+	key = string(body[recordHeader : recordHeader+s.keyLen])
+	value = body[recordHeader+s.keyLen:]
+	return key, value, nil
+}
+
+// appendRecord дописывает пару ключ-значение в кучу и возвращает ее смещение
+func (dh *DiskHash) appendRecord(key string, value []byte) (uint64, error) {
+	total := recordHeader + len(key) + len(value) + recordCRC
+	buf := make([]byte, total)
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(key)))
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(len(value)))
+	copy(buf[recordHeader:], key)
+	copy(buf[recordHeader+len(key):], value)
+	binary.LittleEndian.PutUint32(buf[total-recordCRC:], crc32.Checksum(buf[:total-recordCRC], castagnoli))
+
+	offset := dh.heapEnd
+	if _, err := dh.heap.WriteAt(buf, offset); err != nil {
+		return 0, fmt.Errorf("error: Could not write heap record: %w", err)
+	}
+	dh.heapEnd += int64(total)
+
+	if dh.syncWrites {
+		if err := dh.heap.Sync(); err != nil {
+			return 0, err
+		}
+	}
+	return uint64(offset), nil
+}
+
+// lookup ищет ключ. Возвращает индекс слота с ключом (found = true)
+// или индекс, куда ключ можно вставить (первое надгробие или пустой слот).
+func (dh *DiskHash) lookup(key string, numKey uint64, foldSum uint32) (index uint32, found bool, err error) {
+	h1 := hashing.Multiplicative(numKey, hashing.GoldenRatio, dh.tableSize)
+	h2 := hashing.FoldStep(foldSum, dh.tableSize)
+	tombstone := int64(-1)
+
+	for i := uint32(0); i < dh.tableSize; i++ {
+		idx := uint32((uint64(h1) + uint64(i)*uint64(h2)) % uint64(dh.tableSize))
+		raw := dh.slotBytes(idx)
+
+		switch raw[0] {
+		case slotEmpty:
+			if tombstone >= 0 {
+				return uint32(tombstone), false, nil
+			}
+			return idx, false, nil
+		case slotDeleted:
+			if tombstone < 0 {
+				tombstone = int64(idx)
+			}
+			continue
+		}
+
+		s, _ := decodeSlot(raw)
+		// Сравниваем ключ только при совпадении хеша и длины, чтобы не читать кучу зря
+		if s.numKey != numKey || s.foldSum != foldSum || s.keyLen != uint32(len(key)) {
+			continue
+		}
+		stored, _, err := dh.readRecord(s)
+		if err != nil {
+			return 0, false, err
+		}
+		if stored == key {
+			return idx, true, nil
+		}
+	}
+
+	if tombstone >= 0 {
+		return uint32(tombstone), false, nil
+	}
+	return 0, false, errProbeExhausted
+}
+
+// needResize проверяет load factor > 0.7 с учетом надгробий
+func (dh *DiskHash) needResize() bool {
+	return float64(dh.count+dh.deleted+1)/float64(dh.tableSize) > 0.7
+}
+
+// place копирует занятый слот raw в первый пустой слот его цепочки проб
+func (dh *DiskHash) place(raw []byte) bool {
+	s, _ := decodeSlot(raw)
+	h1 := hashing.Multiplicative(s.numKey, hashing.GoldenRatio, dh.tableSize)
+	h2 := hashing.FoldStep(s.foldSum, dh.tableSize)
+	for j := uint32(0); j < dh.tableSize; j++ {
+		idx := uint32((uint64(h1) + uint64(j)*uint64(h2)) % uint64(dh.tableSize))
+		if dst := dh.slotBytes(idx); dst[0] == slotEmpty {
+			copy(dst, raw)
+			dh.count++
+			return true
+		}
+	}
+	return false
+}
+
+// resize строит таблицу большего размера во временном файле и атомарно
+// подменяет им текущий. Записи кучи не перечитываются: для пересчета
+// позиций в слоте хранятся numKey и foldSum.
+func (dh *DiskHash) resize() error {
+	newSize := nextPrime(dh.tableSize*2 + 1)
+	tmpPath := dh.path + ".tmp"
+
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error: Could not create resize file: %w", err)
+	}
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmpPath)
+	}
+	if err := tmp.Truncate(fileSize(newSize)); err != nil {
+		cleanup()
+		return err
+	}
+	data, err := mapFile(tmp, int(fileSize(newSize)))
+	if err != nil {
+		cleanup()
+		return err
+	}
+
+	resized := &DiskHash{data: data, tableSize: newSize}
+	for i := uint32(0); i < dh.tableSize; i++ {
+		raw := dh.slotBytes(i)
+		if raw[0] != slotOccupied {
+			continue
+		}
+		// В таблице простого размера свободный слот находится всегда,
+		// но запись нельзя терять молча ни при каких условиях
+		if !resized.place(raw) {
+			unmapFile(tmp, data)
+			cleanup()
+			return fmt.Errorf("%w: no free slot for entry %d during resize", ErrCorrupt, i)
+		}
+	}
+	resized.writeHeader()
+
+	// Записи кучи должны оказаться на диске раньше, чем новый файл слотов
+	if err := dh.heap.Sync(); err == nil {
+		err = syncMapping(tmp, data, 0, len(data))
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, dh.path)
+	}
+	if err != nil {
+		unmapFile(tmp, data)
+		cleanup()
+		return err
+	}
+	if err := persist.SyncDir(filepath.Dir(dh.path)); err != nil {
+		return err
+	}
+
+	unmapFile(dh.slots, dh.data)
+	dh.slots.Close()
+	dh.slots = tmp
+	dh.data = data
+	dh.tableSize = newSize
+	dh.count = resized.count
+	dh.deleted = 0
+	dh.dirty = false
+	return nil
+}
+
+// Insert вставляет элемент или обновляет значение
+func (dh *DiskHash) Insert(key string, value []byte) error {
+	if dh.closed {
+		return ErrClosed
+	}
+	if dh.needResize() {
+		if err := dh.resize(); err != nil {
+			return err
+		}
+	}
+
+	numKey := hashing.Polynomial(key)
+	foldSum := hashing.FoldSum(key)
+	idx, found, err := dh.lookup(key, numKey, foldSum)
+	// У таблицы составного размера из старого файла цепочка проб может
+	// обойти только часть слотов: перестраиваем ее в простой размер
+	for errors.Is(err, errProbeExhausted) {
+		if err := dh.resize(); err != nil {
+			return err
+		}
+		idx, found, err = dh.lookup(key, numKey, foldSum)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Сначала данные, потом слот: слот никогда не указывает на недописанную запись
+	offset, err := dh.appendRecord(key, value)
+	if err != nil {
+		return err
+	}
+	if err := dh.markDirty(); err != nil {
+		return err
+	}
+
+	raw := dh.slotBytes(idx)
+	if !found {
+		if raw[0] == slotDeleted {
+			dh.deleted--
+		}
+		dh.count++
+	}
+	encodeSlot(raw, slot{
+		state:   slotOccupied,
+		offset:  offset,
+		keyLen:  uint32(len(key)),
+		valLen:  uint32(len(value)),
+		foldSum: foldSum,
+		numKey:  numKey,
+	})
+	return dh.commitSlot(idx)
+}
+
+// commitSlot обновляет заголовок и при синхронной записи сбрасывает слот на диск
+func (dh *DiskHash) commitSlot(idx uint32) error {
+	dh.writeHeader()
+	if !dh.syncWrites {
+		return nil
+	}
+	off := headerSize + int(idx)*slotSize
+	return syncMapping(dh.slots, dh.data, off, slotSize)
+}
+
+// Find возвращает копию значения по ключу или ErrKeyNotFound
+func (dh *DiskHash) Find(key string) ([]byte, error) {
+	if dh.closed {
+		return nil, ErrClosed
+	}
+	idx, found, err := dh.lookup(key, hashing.Polynomial(key), hashing.FoldSum(key))
+	if err != nil && !errors.Is(err, errProbeExhausted) {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrKeyNotFound
+	}
+	s, _ := decodeSlot(dh.slotBytes(idx))
+	_, value, err := dh.readRecord(s)
+	return value, err
+}
+
+// Remove удаляет элемент по ключу. Возвращает false, если ключа не было.
+func (dh *DiskHash) Remove(key string) (bool, error) {
+	if dh.closed {
+		return false, ErrClosed
+	}
+	idx, found, err := dh.lookup(key, hashing.Polynomial(key), hashing.FoldSum(key))
+	if errors.Is(err, errProbeExhausted) {
+		return false, nil
+	}
+	if err != nil || !found {
+		return false, err
+	}
+	if err := dh.markDirty(); err != nil {
+		return false, err
+	}
+
+	encodeSlot(dh.slotBytes(idx), slot{state: slotDeleted})
+	dh.count--
+	dh.deleted++
+	return true, dh.commitSlot(idx)
+}
+
+// Range обходит все элементы. Обход прекращается, если fn вернула false.
+func (dh *DiskHash) Range(fn func(key string, value []byte) bool) error {
+	if dh.closed {
+		return ErrClosed
+	}
+	for i := uint32(0); i < dh.tableSize; i++ {
+		raw := dh.slotBytes(i)
+		if raw[0] != slotOccupied {
+			continue
+		}
+		s, _ := decodeSlot(raw)
+		key, value, err := dh.readRecord(s)
+		if err != nil {
+			return err
+		}
+		if !fn(key, value) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Size возвращает количество элементов
+func (dh *DiskHash) Size() uint32 {
+	return dh.count
+}
+
+// Empty проверяет, пуста ли таблица
+func (dh *DiskHash) Empty() bool {
+	return dh.count == 0
+}
+
+// SetSyncWrites включает или выключает сброс на диск после каждого изменения.
+// Без него изменения после последнего Sync могут пропасть при сбое,
+// но таблица все равно откроется в согласованном состоянии.
+func (dh *DiskHash) SetSyncWrites(enabled bool) {
+	dh.syncWrites = enabled
+}
+
+// Sync сбрасывает кучу и слоты на диск и снимает флаг dirty
+func (dh *DiskHash) Sync() error {
+	if dh.closed {
+		return ErrClosed
+	}
+	if err := dh.heap.Sync(); err != nil {
+		return err
+	}
+	if err := syncMapping(dh.slots, dh.data, 0, len(dh.data)); err != nil {
+		return err
+	}
+	if !dh.dirty {
+		return nil
+	}
+	dh.dirty = false
+	dh.writeHeader()
+	return syncMapping(dh.slots, dh.data, 0, headerSize)
+}
+
+// Close синхронизирует и закрывает таблицу
+func (dh *DiskHash) Close() error {
+	if dh.closed {
+		return nil
+	}
+	err := dh.Sync()
+	dh.closed = true
+
+	if unmapErr := unmapFile(dh.slots, dh.data); err == nil {
+		err = unmapErr
+	}
+	dh.data = nil
+	if closeErr := dh.slots.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dh.heap.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
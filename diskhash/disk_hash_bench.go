@@ -0,0 +1,56 @@
+package diskhash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const NumElements = 10000 // Количество элементов для теста
+
+// openBench открывает таблицу во временном каталоге
+func openBench(b *testing.B) (*DiskHash, func()) {
+	dir, err := os.MkdirTemp("", "diskhash-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	dh, err := Open(filepath.Join(dir, "bench.dh"), 1024)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return dh, func() {
+		dh.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// BenchmarkInsert измеряет вставку без fsync после каждой операции
+func BenchmarkInsert(b *testing.B) {
+	dh, cleanup := openBench(b)
+	defer cleanup()
+	dh.SetSyncWrites(false)
+	value := []byte("benchmark-value")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dh.Insert(fmt.Sprintf("key%d", i%NumElements), value)
+	}
+}
+
+// BenchmarkFind измеряет поиск: чтение слотов из отображения и одной записи кучи
+func BenchmarkFind(b *testing.B) {
+	dh, cleanup := openBench(b)
+	defer cleanup()
+	dh.SetSyncWrites(false)
+	keys := make([]string, NumElements)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+		dh.Insert(keys[i], []byte("benchmark-value"))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dh.Find(keys[i%NumElements])
+	}
+}
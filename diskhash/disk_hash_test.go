@@ -0,0 +1,389 @@
+package diskhash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/hashing"
+)
+
+// Вспомогательные функции
+
+// openTemp открывает новую таблицу во временном каталоге
+func openTemp(t *testing.T, size uint32) (*DiskHash, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "table.dh")
+	dh, err := Open(path, size)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	return dh, path
+}
+
+// Основные функциональные тесты
+
+func TestInsertFindUpdate(t *testing.T) {
+	dh, _ := openTemp(t, 8)
+	defer dh.Close()
+
+	if !dh.Empty() {
+		t.Error("Expected empty table")
+	}
+	if err := dh.Insert("key1", []byte("value1")); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if err := dh.Insert("key1", []byte("updated")); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if dh.Size() != 1 {
+		t.Errorf("Expected size 1 after update, got %d", dh.Size())
+	}
+
+	val, err := dh.Find("key1")
+	if err != nil || string(val) != "updated" {
+		t.Errorf("Expected 'updated', got %q (%v)", val, err)
+	}
+	if _, err := dh.Find("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dh, _ := openTemp(t, 8)
+	defer dh.Close()
+
+	dh.Insert("a", []byte("1"))
+	dh.Insert("b", []byte("2"))
+
+	removed, err := dh.Remove("a")
+	if err != nil || !removed {
+		t.Fatalf("Expected successful removal, got %v, %v", removed, err)
+	}
+	if removed, _ := dh.Remove("a"); removed {
+		t.Error("Expected second removal to report missing key")
+	}
+	if _, err := dh.Find("a"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Removed key still found: %v", err)
+	}
+	if val, _ := dh.Find("b"); string(val) != "2" {
+		t.Errorf("Expected '2' for b, got %q", val)
+	}
+
+	// Надгробие переиспользуется
+	dh.Insert("a", []byte("3"))
+	if val, _ := dh.Find("a"); string(val) != "3" {
+		t.Errorf("Expected '3' after reinsert, got %q", val)
+	}
+	if dh.Size() != 2 {
+		t.Errorf("Expected size 2, got %d", dh.Size())
+	}
+}
+
+func TestResizeAndReopen(t *testing.T) {
+	dh, path := openTemp(t, 8)
+	dh.SetSyncWrites(false)
+
+	const n = 500
+	for i := 0; i < n; i++ {
+		if err := dh.Insert(fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", i))); err != nil {
+			t.Fatalf("Insert %d failed: %v", i, err)
+		}
+	}
+	for i := 0; i < n; i += 2 {
+		dh.Remove(fmt.Sprintf("key%d", i))
+	}
+	if err := dh.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Temporary resize file left behind")
+	}
+
+	reopened, err := Open(path, 8)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if reopened.Size() != n/2 {
+		t.Errorf("Expected size %d after reopen, got %d", n/2, reopened.Size())
+	}
+	for i := 0; i < n; i++ {
+		val, err := reopened.Find(fmt.Sprintf("key%d", i))
+		if i%2 == 0 {
+			if !errors.Is(err, ErrKeyNotFound) {
+				t.Errorf("Removed key%d found after reopen", i)
+			}
+			continue
+		}
+		if err != nil || string(val) != fmt.Sprintf("value%d", i) {
+			t.Errorf("key%d: expected value%d, got %q (%v)", i, i, val, err)
+		}
+	}
+}
+
+// TestRandomKeys вставляет тысячи случайных ключей: цепочка проб должна
+// обходить всю таблицу, а перестройка - не терять записи
+func TestRandomKeys(t *testing.T) {
+	dh, path := openTemp(t, 8)
+	dh.SetSyncWrites(false)
+
+	r := rand.New(rand.NewPCG(30, 31))
+	keys := make(map[string]string)
+	for len(keys) < 3000 {
+		key := fmt.Sprintf("user:%d", r.IntN(1e9))
+		if len(keys)%2 == 1 {
+			key = fmt.Sprintf("%x", r.Uint64())
+		}
+		value := fmt.Sprintf("v%d", len(keys))
+		if err := dh.Insert(key, []byte(value)); err != nil {
+			t.Fatalf("Insert %d (%q) failed: %v", len(keys), key, err)
+		}
+		keys[key] = value
+	}
+	if dh.Size() != uint32(len(keys)) {
+		t.Errorf("Expected size %d, got %d", len(keys), dh.Size())
+	}
+	if err := dh.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := Open(path, 8)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	for key, want := range keys {
+		if val, err := reopened.Find(key); err != nil || string(val) != want {
+			t.Fatalf("%q: expected %q, got %q (%v)", key, want, val, err)
+		}
+	}
+}
+
+// TestLegacyCompositeSize проверяет таблицу составного размера, созданную
+// старой версией: исчерпанная цепочка проб перестраивает таблицу, а не отказывает
+func TestLegacyCompositeSize(t *testing.T) {
+	dh, _ := openTemp(t, 8)
+	defer dh.Close()
+	dh.SetSyncWrites(false)
+
+	// Переделываем пустую таблицу в таблицу из 35 слотов
+	const legacySize = 35
+	if err := unmapFile(dh.slots, dh.data); err != nil {
+		t.Fatal(err)
+	}
+	if err := dh.slots.Truncate(fileSize(legacySize)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := mapFile(dh.slots, int(fileSize(legacySize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dh.data = data
+	dh.tableSize = legacySize
+	dh.writeHeader()
+
+	// Ищем ключ с шагом, кратным 7: его цепочка проб - всего 5 слотов
+	var key string
+	for i := 0; ; i++ {
+		key = fmt.Sprintf("user:%d", i)
+		if hashing.FoldStep(hashing.FoldSum(key), legacySize)%7 == 0 {
+			break
+		}
+	}
+	// Занимаем всю цепочку настоящими записями других ключей
+	h1 := hashing.Multiplicative(hashing.Polynomial(key), hashing.GoldenRatio, legacySize)
+	h2 := hashing.FoldStep(hashing.FoldSum(key), legacySize)
+	fillers := make([]string, 0, 5)
+	for j := uint32(0); j < 5; j++ {
+		filler := fmt.Sprintf("filler%d", j)
+		offset, err := dh.appendRecord(filler, []byte("f"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		encodeSlot(dh.slotBytes((h1+j*h2)%legacySize), slot{
+			state:   slotOccupied,
+			offset:  offset,
+			keyLen:  uint32(len(filler)),
+			valLen:  1,
+			foldSum: hashing.FoldSum(filler),
+			numKey:  hashing.Polynomial(filler),
+		})
+		dh.count++
+		fillers = append(fillers, filler)
+	}
+
+	if err := dh.Insert(key, []byte("v")); err != nil {
+		t.Fatalf("Insert into exhausted probe cycle failed: %v", err)
+	}
+	if !isPrime(dh.tableSize) {
+		t.Errorf("Expected prime size after resize, got %d", dh.tableSize)
+	}
+	for _, k := range append(fillers, key) {
+		if _, err := dh.Find(k); err != nil {
+			t.Errorf("Find(%q) failed: %v", k, err)
+		}
+	}
+}
+
+func TestLargeValuesAndRange(t *testing.T) {
+	dh, _ := openTemp(t, 8)
+	defer dh.Close()
+
+	big := bytes.Repeat([]byte("x"), 1<<20)
+	dh.Insert("big", big)
+	dh.Insert("", []byte("empty key"))
+	dh.Insert("nil", nil)
+
+	if val, _ := dh.Find("big"); !bytes.Equal(val, big) {
+		t.Error("Large value mismatch")
+	}
+
+	seen := make(map[string]int)
+	dh.Range(func(key string, value []byte) bool {
+		seen[key] = len(value)
+		return true
+	})
+	if len(seen) != 3 || seen["big"] != len(big) || seen[""] != 9 || seen["nil"] != 0 {
+		t.Errorf("Unexpected Range result: %v", seen)
+	}
+
+	visited := 0
+	dh.Range(func(string, []byte) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Errorf("Range did not stop early, visited %d", visited)
+	}
+}
+
+// Тесты восстановления после сбоя
+
+// crash имитирует аварийное завершение: файлы закрываются без Sync,
+// поэтому флаг dirty остается в заголовке
+func crash(dh *DiskHash) {
+	unmapFile(dh.slots, dh.data)
+	dh.slots.Close()
+	dh.heap.Close()
+	dh.closed = true
+}
+
+func TestRecoverAfterCrash(t *testing.T) {
+	dh, path := openTemp(t, 8)
+	dh.Insert("a", []byte("1"))
+	dh.Insert("b", []byte("2"))
+	if err := dh.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	dh.Insert("c", []byte("3"))
+	dh.Remove("a")
+	crash(dh)
+
+	reopened, err := Open(path, 8)
+	if err != nil {
+		t.Fatalf("Open after crash failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if reopened.Size() != 2 {
+		t.Errorf("Expected size 2 after recovery, got %d", reopened.Size())
+	}
+	if _, err := reopened.Find("a"); !errors.Is(err, ErrKeyNotFound) {
+		t.Error("Removed key resurrected after recovery")
+	}
+	if val, _ := reopened.Find("c"); string(val) != "3" {
+		t.Errorf("Expected '3' for c, got %q", val)
+	}
+}
+
+func TestRecoverTruncatedHeap(t *testing.T) {
+	dh, path := openTemp(t, 8)
+	dh.Insert("keep", []byte("safe"))
+	dh.Sync()
+	dh.Insert("lost", []byte("this record will be torn"))
+	crash(dh)
+
+	// Запись кучи не дошла до диска целиком, а слот уже обновлен
+	info, _ := os.Stat(path + ".heap")
+	os.Truncate(path+".heap", info.Size()-5)
+
+	reopened, err := Open(path, 8)
+	if err != nil {
+		t.Fatalf("Open after crash failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if reopened.Size() != 1 {
+		t.Errorf("Expected size 1 after recovery, got %d", reopened.Size())
+	}
+	if _, err := reopened.Find("lost"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected torn record to be dropped, got %v", err)
+	}
+	if val, _ := reopened.Find("keep"); string(val) != "safe" {
+		t.Errorf("Expected 'safe', got %q", val)
+	}
+	// После восстановления таблица снова пригодна для записи
+	if err := reopened.Insert("lost", []byte("again")); err != nil {
+		t.Errorf("Insert after recovery failed: %v", err)
+	}
+}
+
+func TestRecoverCorruptSlot(t *testing.T) {
+	dh, path := openTemp(t, 8)
+	dh.Insert("a", []byte("1"))
+	dh.Insert("b", []byte("2"))
+	for i := uint32(0); i < dh.tableSize; i++ {
+		if raw := dh.slotBytes(i); raw[0] == slotOccupied {
+			raw[10] ^= 0xFF // Порванный слот
+			break
+		}
+	}
+	crash(dh)
+
+	reopened, err := Open(path, 8)
+	if err != nil {
+		t.Fatalf("Open after crash failed: %v", err)
+	}
+	defer reopened.Close()
+	if reopened.Size() != 1 {
+		t.Errorf("Expected corrupt slot to be dropped, size %d", reopened.Size())
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("BadMagic", func(t *testing.T) {
+		path := filepath.Join(dir, "bad.dh")
+		os.WriteFile(path, bytes.Repeat([]byte{1}, headerSize+slotSize*minTableSize), 0644)
+		if _, err := Open(path, 8); !errors.Is(err, ErrBadMagic) {
+			t.Errorf("Expected ErrBadMagic, got %v", err)
+		}
+	})
+
+	t.Run("TruncatedSlots", func(t *testing.T) {
+		dh, path := openTemp(t, 8)
+		dh.Close()
+		os.Truncate(path, headerSize+slotSize)
+		if _, err := Open(path, 8); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("Expected ErrCorrupt, got %v", err)
+		}
+	})
+
+	t.Run("UseAfterClose", func(t *testing.T) {
+		dh, _ := openTemp(t, 8)
+		dh.Close()
+		if err := dh.Insert("a", nil); !errors.Is(err, ErrClosed) {
+			t.Errorf("Expected ErrClosed, got %v", err)
+		}
+		if err := dh.Close(); err != nil {
+			t.Errorf("Second Close should be a no-op, got %v", err)
+		}
+	})
+}
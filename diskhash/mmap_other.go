@@ -0,0 +1,34 @@
+//go:build !linux && !darwin && !freebsd
+
+package diskhash
+
+import (
+	"io"
+	"os"
+)
+
+// На платформах без mmap файл слотов читается в память целиком,
+// а изменения записываются обратно при синхронизации.
+
+// mapFile читает первые size байт файла
+func mapFile(f *os.File, size int) ([]byte, error) {
+	data := make([]byte, size)
+	if _, err := f.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data, nil
+}
+
+// unmapFile записывает буфер в файл
+func unmapFile(f *os.File, data []byte) error {
+	_, err := f.WriteAt(data, 0)
+	return err
+}
+
+// syncMapping записывает диапазон [off, off+n) буфера в файл и сбрасывает его на диск
+func syncMapping(f *os.File, data []byte, off, n int) error {
+	if _, err := f.WriteAt(data[off:off+n], int64(off)); err != nil {
+		return err
+	}
+	return f.Sync()
+}
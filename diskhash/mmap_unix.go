@@ -0,0 +1,35 @@
+//go:build linux || darwin || freebsd
+
+package diskhash
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// mapFile отображает первые size байт файла в память (MAP_SHARED)
+func mapFile(f *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+// unmapFile снимает отображение
+func unmapFile(_ *os.File, data []byte) error {
+	return syscall.Munmap(data)
+}
+
+// syncMapping синхронно сбрасывает на диск страницы отображения,
+// покрывающие диапазон [off, off+n). msync требует адрес, выровненный по странице.
+func syncMapping(_ *os.File, data []byte, off, n int) error {
+	if n <= 0 || len(data) == 0 {
+		return nil
+	}
+	start := off &^ (os.Getpagesize() - 1)
+	end := min(off+n, len(data))
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC,
+		uintptr(unsafe.Pointer(&data[start])), uintptr(end-start), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
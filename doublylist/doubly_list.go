@@ -1,336 +1,602 @@
-package doublylist
-
-import (
-	"encoding/binary"
-	"errors"
-	"fmt"
-	"io"
-	"os"
-)
-
-// Node представляет узел двусвязного списка
-type Node[T comparable] struct {
-	Key  T
-	Next *Node[T]
-	Prev *Node[T]
-}
-
-// DoublyList представляет двусвязный список
-type DoublyList[T comparable] struct {
-	Head *Node[T]
-	Tail *Node[T]
-}
-
-// NewDoublyList создает новый пустой список
-func NewDoublyList[T comparable]() *DoublyList[T] {
-	return &DoublyList[T]{}
-}
-
-// LCreate инициализирует список первым элементом
-func (l *DoublyList[T]) LCreate(keyBegin T) error {
-	if l.Head != nil {
-		return errors.New("logic_error: List already created. Use push methods")
-	}
-	newNode := &Node[T]{Key: keyBegin}
-	l.Head = newNode
-	l.Tail = newNode
-	return nil
-}
-
-// LPushHead добавляет элемент в начало
-func (l *DoublyList[T]) LPushHead(key T) {
-	newNode := &Node[T]{Key: key, Next: l.Head, Prev: nil}
-	if l.Head != nil {
-		l.Head.Prev = newNode
-	} else {
-		l.Tail = newNode
-	}
-	l.Head = newNode
-}
-
-// LPushBack добавляет элемент в конец
-func (l *DoublyList[T]) LPushBack(key T) {
-	newNode := &Node[T]{Key: key, Next: nil, Prev: l.Tail}
-	if l.Tail != nil {
-		l.Tail.Next = newNode
-	} else {
-		l.Head = newNode
-	}
-	l.Tail = newNode
-}
-
-// LDelHead удаляет первый элемент
-func (l *DoublyList[T]) LDelHead() error {
-	if l.Head == nil {
-		return errors.New("underflow_error: Attempt to delete head in an empty list")
-	}
-	l.Head = l.Head.Next
-	if l.Head != nil {
-		l.Head.Prev = nil
-	} else {
-		l.Tail = nil
-	}
-	return nil
-}
-
-// LDelBack удаляет последний элемент
-func (l *DoublyList[T]) LDelBack() error {
-	if l.Tail == nil {
-		return errors.New("underflow_error: Attempt to delete tail in an empty list")
-	}
-	l.Tail = l.Tail.Prev
-	if l.Tail != nil {
-		l.Tail.Next = nil
-	} else {
-		l.Head = nil
-	}
-	return nil
-}
-
-// LGetByValue ищет узел по значению
-func (l *DoublyList[T]) LGetByValue(key T) *Node[T] {
-	current := l.Head
-	for current != nil {
-		if current.Key == key {
-			return current
-		}
-		current = current.Next
-	}
-	return nil
-}
-
-// LPushBefore вставляет элемент перед указанным значением
-func (l *DoublyList[T]) LPushBefore(targetKey T, newKey T) error {
-	targetNode := l.LGetByValue(targetKey)
-	if targetNode == nil {
-		return errors.New("invalid_argument: Target element for insertion (BEFORE) not found")
-	}
-
-	if targetNode == l.Head {
-		l.LPushHead(newKey)
-		return nil
-	}
-
-	newNode := &Node[T]{Key: newKey, Next: targetNode, Prev: targetNode.Prev}
-	targetNode.Prev.Next = newNode
-	targetNode.Prev = newNode
-	return nil
-}
-
-// LPushAfter вставляет элемент после указанного значения
-func (l *DoublyList[T]) LPushAfter(targetKey T, newKey T) error {
-	targetNode := l.LGetByValue(targetKey)
-	if targetNode == nil {
-		return errors.New("invalid_argument: Target element for insertion (AFTER) not found")
-	}
-
-	if targetNode == l.Tail {
-		l.LPushBack(newKey)
-		return nil
-	}
-
-	newNode := &Node[T]{Key: newKey, Next: targetNode.Next, Prev: targetNode}
-	targetNode.Next.Prev = newNode
-	targetNode.Next = newNode
-	return nil
-}
-
-// LDelAfter удаляет элемент после указанного значения
-func (l *DoublyList[T]) LDelAfter(targetKey T) error {
-	targetNode := l.LGetByValue(targetKey)
-	if targetNode == nil {
-		return errors.New("invalid_argument: Target element for deletion (AFTER) not found")
-	}
-	if targetNode.Next == nil {
-		return errors.New("logic_error: No elements after the specified node")
-	}
-
-	nodeToDelete := targetNode.Next
-	if nodeToDelete == l.Tail {
-		return l.LDelBack()
-	}
-
-	targetNode.Next = nodeToDelete.Next
-	if nodeToDelete.Next != nil {
-		nodeToDelete.Next.Prev = targetNode
-	}
-	// nodeToDelete будет удален GC
-	return nil
-}
-
-// LDelBefore удаляет элемент перед указанным значением
-func (l *DoublyList[T]) LDelBefore(targetKey T) error {
-	targetNode := l.LGetByValue(targetKey)
-	if targetNode == nil {
-		return errors.New("invalid_argument: Target element for deletion (BEFORE) not found")
-	}
-	if targetNode.Prev == nil {
-		return errors.New("logic_error: No elements before the specified node (it is the head)")
-	}
-
-	nodeToDelete := targetNode.Prev
-	if nodeToDelete == l.Head {
-		return l.LDelHead()
-	}
-
-	targetNode.Prev = nodeToDelete.Prev
-	if nodeToDelete.Prev != nil {
-		nodeToDelete.Prev.Next = targetNode
-	}
-	return nil
-}
-
-// LDelByValue удаляет первое вхождение элемента по значению
-func (l *DoublyList[T]) LDelByValue(key T) error {
-	targetNode := l.LGetByValue(key)
-	if targetNode == nil {
-		return errors.New("invalid_argument: Element with such value not found")
-	}
-
-	if targetNode == l.Head {
-		return l.LDelHead()
-	}
-	if targetNode == l.Tail {
-		return l.LDelBack()
-	}
-
-	targetNode.Prev.Next = targetNode.Next
-	targetNode.Next.Prev = targetNode.Prev
-	return nil
-}
-
-// Clone создает глубокую копию списка
-func (l *DoublyList[T]) Clone() *DoublyList[T] {
-	newList := NewDoublyList[T]()
-	current := l.Head
-	for current != nil {
-		newList.LPushBack(current.Key)
-		current = current.Next
-	}
-	return newList
-}
-
-// Print выводит список.
-func (l *DoublyList[T]) Print(w io.Writer, choice int) error {
-	switch choice {
-	case 1: // Forward
-		if l.Head == nil {
-			fmt.Fprintln(w, "Список пуст.")
-			return nil
-		}
-		current := l.Head
-		fmt.Fprint(w, "Голова -> ")
-		for current != nil {
-			fmt.Fprintf(w, "%v <-> ", current.Key)
-			current = current.Next
-		}
-		fmt.Fprintln(w, "nullptr")
-	case 2: // Backward
-		if l.Tail == nil {
-			fmt.Fprintln(w, "Список пуст.")
-			return nil
-		}
-		current := l.Tail
-		fmt.Fprint(w, "Хвост -> ")
-		for current != nil {
-			fmt.Fprintf(w, "%v <-> ", current.Key)
-			current = current.Prev
-		}
-		fmt.Fprintln(w, "nullptr")
-	default:
-		return errors.New("invalid_argument: Invalid print mode selection")
-	}
-	return nil
-}
-
-// LSave сохраняет список в текстовый файл
-func (l *DoublyList[T]) LSave(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	current := l.Head
-	for current != nil {
-		if _, err := fmt.Fprintf(file, "%v ", current.Key); err != nil {
-			return err
-		}
-		current = current.Next
-	}
-	fmt.Printf("Двусвязный список сохранён в файл: %s\n", filename)
-	return nil
-}
-
-// LLoad загружает список из текстового файла
-func (l *DoublyList[T]) LLoad(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Очистка текущего списка
-	l.Head = nil
-	l.Tail = nil
-
-	var value T
-	for {
-		_, err := fmt.Fscan(file, &value)
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return err
-		}
-		l.LPushBack(value)
-	}
-	fmt.Printf("Двусвязный список загружен из файла: %s\n", filename)
-	return nil
-}
-
-// LSaveBin сохраняет список в бинарный файл
-func (l *DoublyList[T]) LSaveBin(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	current := l.Head
-	for current != nil {
-		// binary.Write работает корректно для фиксированных типов (int32, float64 и т.д.)
-		if err := binary.Write(file, binary.LittleEndian, current.Key); err != nil {
-			return err
-		}
-		current = current.Next
-	}
-	fmt.Printf("Двусвязный список сохранён в бинарный файл: %s\n", filename)
-	return nil
-}
-
-// LLoadBin загружает список из бинарного файла
-func (l *DoublyList[T]) LLoadBin(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	l.Head = nil
-	l.Tail = nil
-
-	var value T
-	for {
-		err := binary.Read(file, binary.LittleEndian, &value)
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return err
-		}
-		l.LPushBack(value)
-	}
-	fmt.Printf("Двусвязный список загружен из бинарного файла: %s\n", filename)
-	return nil
-}
+package doublylist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Node представляет узел двусвязного списка
+type Node[T comparable] struct {
+	Key  T
+	Next *Node[T]
+	Prev *Node[T]
+}
+
+// DoublyList представляет двусвязный список
+type DoublyList[T comparable] struct {
+	Head *Node[T]
+	Tail *Node[T]
+
+	// Кодек элементов для бинарного формата; nil - codec.Default
+	elemCodec codec.ElementCodec[T]
+}
+
+// NewDoublyList создает новый пустой список
+func NewDoublyList[T comparable]() *DoublyList[T] {
+	return &DoublyList[T]{}
+}
+
+// LCreate инициализирует список первым элементом
+func (l *DoublyList[T]) LCreate(keyBegin T) error {
+	if l.Head != nil {
+		return errors.New("logic_error: List already created. Use push methods")
+	}
+	newNode := &Node[T]{Key: keyBegin}
+	l.Head = newNode
+	l.Tail = newNode
+	return nil
+}
+
+// LPushHead добавляет элемент в начало
+func (l *DoublyList[T]) LPushHead(key T) {
+	newNode := &Node[T]{Key: key, Next: l.Head, Prev: nil}
+	if l.Head != nil {
+		l.Head.Prev = newNode
+	} else {
+		l.Tail = newNode
+	}
+	l.Head = newNode
+}
+
+// LPushBack добавляет элемент в конец
+func (l *DoublyList[T]) LPushBack(key T) {
+	newNode := &Node[T]{Key: key, Next: nil, Prev: l.Tail}
+	if l.Tail != nil {
+		l.Tail.Next = newNode
+	} else {
+		l.Head = newNode
+	}
+	l.Tail = newNode
+}
+
+// LDelHead удаляет первый элемент
+func (l *DoublyList[T]) LDelHead() error {
+	if l.Head == nil {
+		return errors.New("underflow_error: Attempt to delete head in an empty list")
+	}
+	l.Head = l.Head.Next
+	if l.Head != nil {
+		l.Head.Prev = nil
+	} else {
+		l.Tail = nil
+	}
+	return nil
+}
+
+// LDelBack удаляет последний элемент
+func (l *DoublyList[T]) LDelBack() error {
+	if l.Tail == nil {
+		return errors.New("underflow_error: Attempt to delete tail in an empty list")
+	}
+	l.Tail = l.Tail.Prev
+	if l.Tail != nil {
+		l.Tail.Next = nil
+	} else {
+		l.Head = nil
+	}
+	return nil
+}
+
+// LGetByValue ищет узел по значению
+func (l *DoublyList[T]) LGetByValue(key T) *Node[T] {
+	current := l.Head
+	for current != nil {
+		if current.Key == key {
+			return current
+		}
+		current = current.Next
+	}
+	return nil
+}
+
+// LPushBefore вставляет элемент перед указанным значением
+func (l *DoublyList[T]) LPushBefore(targetKey T, newKey T) error {
+	targetNode := l.LGetByValue(targetKey)
+	if targetNode == nil {
+		return errors.New("invalid_argument: Target element for insertion (BEFORE) not found")
+	}
+
+	if targetNode == l.Head {
+		l.LPushHead(newKey)
+		return nil
+	}
+
+	newNode := &Node[T]{Key: newKey, Next: targetNode, Prev: targetNode.Prev}
+	targetNode.Prev.Next = newNode
+	targetNode.Prev = newNode
+	return nil
+}
+
+// LPushAfter вставляет элемент после указанного значения
+func (l *DoublyList[T]) LPushAfter(targetKey T, newKey T) error {
+	targetNode := l.LGetByValue(targetKey)
+	if targetNode == nil {
+		return errors.New("invalid_argument: Target element for insertion (AFTER) not found")
+	}
+
+	if targetNode == l.Tail {
+		l.LPushBack(newKey)
+		return nil
+	}
+
+	newNode := &Node[T]{Key: newKey, Next: targetNode.Next, Prev: targetNode}
+	targetNode.Next.Prev = newNode
+	targetNode.Next = newNode
+	return nil
+}
+
+// LDelAfter удаляет элемент после указанного значения
+func (l *DoublyList[T]) LDelAfter(targetKey T) error {
+	targetNode := l.LGetByValue(targetKey)
+	if targetNode == nil {
+		return errors.New("invalid_argument: Target element for deletion (AFTER) not found")
+	}
+	if targetNode.Next == nil {
+		return errors.New("logic_error: No elements after the specified node")
+	}
+
+	nodeToDelete := targetNode.Next
+	if nodeToDelete == l.Tail {
+		return l.LDelBack()
+	}
+
+	targetNode.Next = nodeToDelete.Next
+	if nodeToDelete.Next != nil {
+		nodeToDelete.Next.Prev = targetNode
+	}
+	// nodeToDelete будет удален GC
+	return nil
+}
+
+// LDelBefore удаляет элемент перед указанным значением
+func (l *DoublyList[T]) LDelBefore(targetKey T) error {
+	targetNode := l.LGetByValue(targetKey)
+	if targetNode == nil {
+		return errors.New("invalid_argument: Target element for deletion (BEFORE) not found")
+	}
+	if targetNode.Prev == nil {
+		return errors.New("logic_error: No elements before the specified node (it is the head)")
+	}
+
+	nodeToDelete := targetNode.Prev
+	if nodeToDelete == l.Head {
+		return l.LDelHead()
+	}
+
+	targetNode.Prev = nodeToDelete.Prev
+	if nodeToDelete.Prev != nil {
+		nodeToDelete.Prev.Next = targetNode
+	}
+	return nil
+}
+
+// LDelByValue удаляет первое вхождение элемента по значению
+func (l *DoublyList[T]) LDelByValue(key T) error {
+	targetNode := l.LGetByValue(key)
+	if targetNode == nil {
+		return errors.New("invalid_argument: Element with such value not found")
+	}
+
+	if targetNode == l.Head {
+		return l.LDelHead()
+	}
+	if targetNode == l.Tail {
+		return l.LDelBack()
+	}
+
+	targetNode.Prev.Next = targetNode.Next
+	targetNode.Next.Prev = targetNode.Prev
+	return nil
+}
+
+// Clone создает глубокую копию списка
+func (l *DoublyList[T]) Clone() *DoublyList[T] {
+	newList := NewDoublyList[T]()
+	newList.elemCodec = l.elemCodec
+	current := l.Head
+	for current != nil {
+		newList.LPushBack(current.Key)
+		current = current.Next
+	}
+	return newList
+}
+
+// Print выводит список.
+func (l *DoublyList[T]) Print(w io.Writer, choice int) error {
+	switch choice {
+	case 1: // Forward
+		if l.Head == nil {
+			fmt.Fprintln(w, "Список пуст.")
+			return nil
+		}
+		current := l.Head
+		fmt.Fprint(w, "Голова -> ")
+		for current != nil {
+			fmt.Fprintf(w, "%v <-> ", current.Key)
+			current = current.Next
+		}
+		fmt.Fprintln(w, "nullptr")
+	case 2: // Backward
+		if l.Tail == nil {
+			fmt.Fprintln(w, "Список пуст.")
+			return nil
+		}
+		current := l.Tail
+		fmt.Fprint(w, "Хвост -> ")
+		for current != nil {
+			fmt.Fprintf(w, "%v <-> ", current.Key)
+			current = current.Prev
+		}
+		fmt.Fprintln(w, "nullptr")
+	default:
+		return errors.New("invalid_argument: Invalid print mode selection")
+	}
+	return nil
+}
+
+// LSave сохраняет список в текстовый файл
+func (l *DoublyList[T]) LSave(filename string, opts ...persist.Option) error {
+	if err := persist.WriteFile(filename, l.writeText, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Двусвязный список сохранён в файл: %s\n", filename)
+	return nil
+}
+
+// LLoad загружает список из текстового файла
+func (l *DoublyList[T]) LLoad(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := persist.Load(file, compression.NewReader, l.readText, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Двусвязный список загружен из файла: %s\n", filename)
+	return nil
+}
+
+// WriteTextTo записывает список в w в текстовом формате
+func (l *DoublyList[T]) WriteTextTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, l.writeText)
+}
+
+// ReadTextFrom читает список в текстовом формате из r до конца потока
+func (l *DoublyList[T]) ReadTextFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, l.readText)
+}
+
+func (l *DoublyList[T]) writeText(w io.Writer) error {
+	current := l.Head
+	for current != nil {
+		tok, err := codec.FormatText(current.Key)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, tok); err != nil {
+			return err
+		}
+		current = current.Next
+	}
+	return nil
+}
+
+func (l *DoublyList[T]) readText(r io.Reader) error {
+	// Очистка текущего списка
+	l.Head = nil
+	l.Tail = nil
+
+	s := codec.NewTextScanner(r)
+	for {
+		value, err := codec.ScanText[T](s)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		l.LPushBack(value)
+	}
+	return nil
+}
+
+// SetCodec задает кодек элементов для бинарного формата списка.
+// По умолчанию используется codec.Default, который поддерживает строки,
+// []byte, encoding.BinaryMarshaler и типы фиксированного размера.
+func (l *DoublyList[T]) SetCodec(c codec.ElementCodec[T]) {
+	l.elemCodec = c
+}
+
+func (l *DoublyList[T]) elementCodec() (codec.ElementCodec[T], error) {
+	if l.elemCodec != nil {
+		return l.elemCodec, nil
+	}
+	return codec.Default[T]()
+}
+
+// LSaveBin сохраняет список в бинарный файл
+func (l *DoublyList[T]) LSaveBin(filename string, opts ...persist.Option) error {
+	if err := persist.WriteFile(filename, l.writeBinary, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Двусвязный список сохранён в бинарный файл: %s\n", filename)
+	return nil
+}
+
+// LLoadBin загружает список из бинарного файла
+func (l *DoublyList[T]) LLoadBin(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := persist.Load(file, compression.NewReader, l.readBinary, opts...); err != nil {
+		return err
+	}
+	fmt.Printf("Двусвязный список загружен из бинарного файла: %s\n", filename)
+	return nil
+}
+
+// WriteTo записывает список в w в бинарном формате (io.WriterTo)
+func (l *DoublyList[T]) WriteTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, l.writeBinary)
+}
+
+// ReadFrom читает список в бинарном формате из r до конца потока (io.ReaderFrom)
+func (l *DoublyList[T]) ReadFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, l.readBinary)
+}
+
+// writeBinary пишет список в конверте persist с видом и типом элементов
+func (l *DoublyList[T]) writeBinary(w io.Writer) error {
+	return persist.WriteEnvelope(w, persist.KindDoublyList, persist.TypeName[T](), l.writePayload)
+}
+
+// readBinary проверяет конверт и читает список
+func (l *DoublyList[T]) readBinary(r io.Reader) error {
+	return persist.ReadEnvelope(r, persist.KindDoublyList, persist.TypeName[T](), l.readPayload)
+}
+
+func (l *DoublyList[T]) writePayload(w io.Writer) error {
+	c, err := l.elementCodec()
+	if err != nil {
+		return err
+	}
+
+	current := l.Head
+	for current != nil {
+		if err := c.Encode(w, current.Key); err != nil {
+			return err
+		}
+		current = current.Next
+	}
+	return nil
+}
+
+func (l *DoublyList[T]) readPayload(r io.Reader) error {
+	c, err := l.elementCodec()
+	if err != nil {
+		return err
+	}
+
+	// Список меняется только после чтения всех элементов
+	var values []T
+	for {
+		value, err := c.Decode(r)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		values = append(values, value)
+	}
+
+	l.Head = nil
+	l.Tail = nil
+	for _, value := range values {
+		l.LPushBack(value)
+	}
+	return nil
+}
+
+// Срезы и последовательности
+
+// FromSlice создает список из элементов s: s[0] становится головой
+func FromSlice[T comparable](s []T) *DoublyList[T] {
+	return FromSeq(slices.Values(s))
+}
+
+// FromSeq создает список из элементов seq в порядке обхода
+func FromSeq[T comparable](seq iter.Seq[T]) *DoublyList[T] {
+	l := NewDoublyList[T]()
+	for v := range seq {
+		l.LPushBack(v)
+	}
+	return l
+}
+
+// ToSlice возвращает элементы списка от головы к хвосту
+func (l *DoublyList[T]) ToSlice() []T {
+	var s []T
+	for current := l.Head; current != nil; current = current.Next {
+		s = append(s, current.Key)
+	}
+	return s
+}
+
+// Итераторы
+//
+// Итераторы берут соседний узел после возврата из тела цикла, поэтому тело
+// может удалить текущий узел (LDelHead, LDelBack и LDelByValue не обнуляют
+// его ссылки) и добавить узлы по ходу обхода - они будут пройдены. Удаленные
+// узлы впереди текущего пропускаются. Список не синхронизирован: изменение
+// из другой горутины во время обхода - гонка данных.
+
+// All возвращает итератор по элементам от головы к хвосту
+func (l *DoublyList[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for current := l.Head; current != nil; current = current.Next {
+			if !yield(current.Key) {
+				return
+			}
+		}
+	}
+}
+
+// Backward возвращает итератор по элементам от хвоста к голове
+func (l *DoublyList[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for current := l.Tail; current != nil; current = current.Prev {
+			if !yield(current.Key) {
+				return
+			}
+		}
+	}
+}
+
+// Enumerate возвращает итератор по парам (номер от головы, элемент)
+func (l *DoublyList[T]) Enumerate() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for current := l.Head; current != nil; current = current.Next {
+			if !yield(i, current.Key) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// encoding и gob
+
+// MarshalBinary возвращает список в бинарном формате с конвертом
+// (encoding.BinaryMarshaler), поэтому его можно вкладывать в другие структуры
+func (l *DoublyList[T]) MarshalBinary() ([]byte, error) {
+	return persist.Marshal(l.writeBinary)
+}
+
+// UnmarshalBinary читает список из данных MarshalBinary (encoding.BinaryUnmarshaler).
+// Данные должны содержать ровно один конверт.
+func (l *DoublyList[T]) UnmarshalBinary(data []byte) error {
+	return persist.Unmarshal(data, l.readBinary)
+}
+
+// GobEncode кодирует список для encoding/gob (gob.GobEncoder)
+func (l *DoublyList[T]) GobEncode() ([]byte, error) {
+	return l.MarshalBinary()
+}
+
+// GobDecode читает список, закодированный GobEncode (gob.GobDecoder)
+func (l *DoublyList[T]) GobDecode(data []byte) error {
+	return l.UnmarshalBinary(data)
+}
+
+// JSON
+
+// MarshalJSON кодирует список как JSON-массив от головы к хвосту (json.Marshaler)
+func (l *DoublyList[T]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := l.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON читает список из JSON-массива (json.Unmarshaler).
+// При ошибке список не меняется.
+func (l *DoublyList[T]) UnmarshalJSON(data []byte) error {
+	return l.readJSON(bytes.NewReader(data))
+}
+
+// WriteJSONTo пишет список в w как JSON-массив, не собирая его в памяти
+func (l *DoublyList[T]) WriteJSONTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, l.writeJSON)
+}
+
+// ReadJSONFrom читает список из JSON-массива в r до конца потока
+func (l *DoublyList[T]) ReadJSONFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, l.readJSON)
+}
+
+func (l *DoublyList[T]) writeJSON(w io.Writer) error {
+	return codec.EncodeJSONArray(w, func(emit func(T) error) error {
+		for current := l.Head; current != nil; current = current.Next {
+			if err := emit(current.Key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (l *DoublyList[T]) readJSON(r io.Reader) error {
+	var loaded DoublyList[T]
+	err := codec.ReadJSON(r, func(dec *json.Decoder) error {
+		return codec.DecodeJSONArray(dec, "$", func(val T) error {
+			loaded.LPushBack(val)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	l.Head = loaded.Head
+	l.Tail = loaded.Tail
+	return nil
+}
+
+// CSV
+
+// ExportCSV пишет список в w в формате CSV от головы к хвосту.
+// Колонки задаются codec.DefaultCSVMapper или опцией codec.WithCSVMapper.
+func (l *DoublyList[T]) ExportCSV(w io.Writer, opts ...codec.CSVOption) error {
+	return codec.WriteCSV(w, func(emit func(T) error) error {
+		for current := l.Head; current != nil; current = current.Next {
+			if err := emit(current.Key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts...)
+}
+
+// ImportCSV заменяет содержимое списка строками CSV из r. Строки, которые
+// не удалось разобрать, пропускаются и возвращаются в codec.RowErrors
+// с номерами строк. При ошибке чтения или заголовка список не меняется.
+func (l *DoublyList[T]) ImportCSV(r io.Reader, opts ...codec.CSVOption) error {
+	var loaded DoublyList[T]
+	rowErrs, err := codec.ReadCSV(r, func(val T) error {
+		loaded.LPushBack(val)
+		return nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	l.Head = loaded.Head
+	l.Tail = loaded.Tail
+	return rowErrs.Err()
+}
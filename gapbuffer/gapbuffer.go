@@ -0,0 +1,214 @@
+// Package gapbuffer реализует буфер с разрывом - последовательность для
+// редактирования в одном месте. Свободное место (разрыв) стоит там, где
+// курсор, поэтому вставка и удаление у курсора стоят O(1), а перемещение
+// курсора - O(расстояния).
+package gapbuffer
+
+import (
+	"fmt"
+	"iter"
+)
+
+// minGap - размер разрыва, который буфер оставляет после расширения
+const minGap = 16
+
+// GapBuffer хранит элементы в одном срезе с разрывом [gapStart, gapEnd).
+// Курсор совпадает с началом разрыва. Методы Get, Set, InsertByInd и
+// DeleteByInd повторяют array.Array; InsertByInd и DeleteByInd сначала
+// переносят курсор к индексу, поэтому серия правок рядом друг с другом
+// (набор текста) не сдвигает весь буфер.
+type GapBuffer[T any] struct {
+	data     []T
+	gapStart int
+	gapEnd   int
+}
+
+// NewGapBuffer создает пустой буфер
+func NewGapBuffer[T any]() *GapBuffer[T] {
+	return &GapBuffer[T]{data: make([]T, minGap), gapEnd: minGap}
+}
+
+// FromSlice создает буфер из копии s с курсором в конце
+func FromSlice[T any](s []T) *GapBuffer[T] {
+	data := make([]T, len(s)+minGap)
+	copy(data, s)
+	return &GapBuffer[T]{data: data, gapStart: len(s), gapEnd: len(data)}
+}
+
+func (g *GapBuffer[T]) gapLen() int {
+	return g.gapEnd - g.gapStart
+}
+
+// GetSize возвращает количество элементов
+func (g *GapBuffer[T]) GetSize() int {
+	return len(g.data) - g.gapLen()
+}
+
+// physical переводит индекс элемента в индекс среза, перепрыгивая разрыв
+func (g *GapBuffer[T]) physical(i int) int {
+	if i < g.gapStart {
+		return i
+	}
+	return i + g.gapLen()
+}
+
+// Get возвращает элемент по индексу или ошибку
+func (g *GapBuffer[T]) Get(index int) (T, error) {
+	if index < 0 || index >= g.GetSize() {
+		var empty T
+		return empty, fmt.Errorf("error: Index %d is out of bounds (size %d)", index, g.GetSize())
+	}
+	return g.data[g.physical(index)], nil
+}
+
+// Set заменяет элемент по индексу
+func (g *GapBuffer[T]) Set(index int, value T) error {
+	if index < 0 || index >= g.GetSize() {
+		return fmt.Errorf("error: Index %d is out of bounds", index)
+	}
+	g.data[g.physical(index)] = value
+	return nil
+}
+
+// InsertByInd вставляет элемент по индексу и ставит курсор после него
+func (g *GapBuffer[T]) InsertByInd(index int, value T) error {
+	if index < 0 || index > g.GetSize() {
+		return fmt.Errorf("error: Index %d is out of bounds for insertion", index)
+	}
+	g.moveGap(index)
+	g.Insert(value)
+	return nil
+}
+
+// DeleteByInd удаляет элемент по индексу и ставит курсор на его место
+func (g *GapBuffer[T]) DeleteByInd(index int) error {
+	if index < 0 || index >= g.GetSize() {
+		return fmt.Errorf("error: Index %d is out of bounds for deletion", index)
+	}
+	g.moveGap(index)
+	_, err := g.DeleteForward()
+	return err
+}
+
+// Курсор
+
+// Cursor возвращает позицию курсора: количество элементов перед ним
+func (g *GapBuffer[T]) Cursor() int {
+	return g.gapStart
+}
+
+// MoveCursor ставит курсор перед элементом pos (pos == GetSize() - в конец)
+func (g *GapBuffer[T]) MoveCursor(pos int) error {
+	if pos < 0 || pos > g.GetSize() {
+		return fmt.Errorf("error: Cursor position %d is out of bounds (size %d)", pos, g.GetSize())
+	}
+	g.moveGap(pos)
+	return nil
+}
+
+// Left сдвигает курсор на один элемент влево. Возвращает false в начале буфера.
+func (g *GapBuffer[T]) Left() bool {
+	if g.gapStart == 0 {
+		return false
+	}
+	g.moveGap(g.gapStart - 1)
+	return true
+}
+
+// Right сдвигает курсор на один элемент вправо. Возвращает false в конце буфера.
+func (g *GapBuffer[T]) Right() bool {
+	if g.gapEnd == len(g.data) {
+		return false
+	}
+	g.moveGap(g.gapStart + 1)
+	return true
+}
+
+// moveGap переносит разрыв к позиции pos, копируя элементы между ними
+func (g *GapBuffer[T]) moveGap(pos int) {
+	switch {
+	case pos < g.gapStart:
+		n := g.gapStart - pos
+		copy(g.data[g.gapEnd-n:g.gapEnd], g.data[pos:g.gapStart])
+		g.gapStart -= n
+		g.gapEnd -= n
+		clear(g.data[g.gapStart:min(g.gapStart+n, g.gapEnd)])
+	case pos > g.gapStart:
+		n := pos - g.gapStart
+		copy(g.data[g.gapStart:], g.data[g.gapEnd:g.gapEnd+n])
+		g.gapStart += n
+		g.gapEnd += n
+		clear(g.data[max(g.gapEnd-n, g.gapStart):g.gapEnd])
+	}
+}
+
+// grow расширяет разрыв хотя бы до n элементов. Емкость растет вдвое,
+// поэтому вставка у курсора стоит O(1) амортизированно.
+func (g *GapBuffer[T]) grow(n int) {
+	if g.gapLen() >= n {
+		return
+	}
+	newLen := max(2*len(g.data), g.GetSize()+n+minGap)
+	data := make([]T, newLen)
+	copy(data, g.data[:g.gapStart])
+	tail := len(g.data) - g.gapEnd
+	copy(data[newLen-tail:], g.data[g.gapEnd:])
+	g.gapEnd = newLen - tail
+	g.data = data
+}
+
+// Insert вставляет элементы перед курсором; курсор остается после них
+func (g *GapBuffer[T]) Insert(values ...T) {
+	g.grow(len(values))
+	g.gapStart += copy(g.data[g.gapStart:g.gapEnd], values)
+}
+
+// Backspace удаляет и возвращает элемент перед курсором
+func (g *GapBuffer[T]) Backspace() (T, error) {
+	var empty T
+	if g.gapStart == 0 {
+		return empty, fmt.Errorf("error: Nothing to delete before cursor")
+	}
+	g.gapStart--
+	v := g.data[g.gapStart]
+	g.data[g.gapStart] = empty
+	return v, nil
+}
+
+// DeleteForward удаляет и возвращает элемент после курсора
+func (g *GapBuffer[T]) DeleteForward() (T, error) {
+	var empty T
+	if g.gapEnd == len(g.data) {
+		return empty, fmt.Errorf("error: Nothing to delete after cursor")
+	}
+	v := g.data[g.gapEnd]
+	g.data[g.gapEnd] = empty
+	g.gapEnd++
+	return v, nil
+}
+
+// Срезы и последовательности
+
+// ToSlice возвращает копию элементов без разрыва
+func (g *GapBuffer[T]) ToSlice() []T {
+	s := make([]T, 0, g.GetSize())
+	s = append(s, g.data[:g.gapStart]...)
+	return append(s, g.data[g.gapEnd:]...)
+}
+
+// All возвращает итератор по элементам от начала к концу. Буфер нельзя
+// менять во время обхода: перенос разрыва сдвигает элементы.
+func (g *GapBuffer[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range g.data[:g.gapStart] {
+			if !yield(v) {
+				return
+			}
+		}
+		for _, v := range g.data[g.gapEnd:] {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
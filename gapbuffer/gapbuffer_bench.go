@@ -0,0 +1,89 @@
+package gapbuffer
+
+import (
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+)
+
+const (
+	documentSize = 100000 // Размер документа, в середине которого идет набор
+	typedChars   = 1000   // Сколько символов набирается за одну итерацию
+)
+
+func document() []byte {
+	doc := make([]byte, documentSize)
+	for i := range doc {
+		doc[i] = byte('a' + i%26)
+	}
+	return doc
+}
+
+// BenchmarkTyping набирает текст в середине большого документа:
+// буфер с разрывом против вставки по индексу в array.Array
+func BenchmarkTyping(b *testing.B) {
+	doc := document()
+
+	b.Run("GapBuffer", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g := FromSlice(doc)
+			_ = g.MoveCursor(documentSize / 2)
+			for j := 0; j < typedChars; j++ {
+				g.Insert(byte('x'))
+			}
+		}
+	})
+
+	b.Run("Array", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			arr := array.FromSlice(doc)
+			for j := 0; j < typedChars; j++ {
+				_ = arr.InsertByInd(documentSize/2+j, 'x')
+			}
+		}
+	})
+}
+
+// BenchmarkTypingWithCorrections набирает текст и стирает каждый
+// пятый символ, как при исправлении опечаток
+func BenchmarkTypingWithCorrections(b *testing.B) {
+	doc := document()
+
+	b.Run("GapBuffer", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g := FromSlice(doc)
+			_ = g.MoveCursor(documentSize / 2)
+			for j := 0; j < typedChars; j++ {
+				g.Insert(byte('x'))
+				if j%5 == 4 {
+					_, _ = g.Backspace()
+				}
+			}
+		}
+	})
+
+	b.Run("Array", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			arr := array.FromSlice(doc)
+			cursor := documentSize / 2
+			for j := 0; j < typedChars; j++ {
+				_ = arr.InsertByInd(cursor, 'x')
+				cursor++
+				if j%5 == 4 {
+					cursor--
+					_ = arr.DeleteByInd(cursor)
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkGet измеряет чтение по индексу с разрывом в середине
+func BenchmarkGet(b *testing.B) {
+	g := FromSlice(document())
+	_ = g.MoveCursor(documentSize / 2)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = g.Get(i % documentSize)
+	}
+}
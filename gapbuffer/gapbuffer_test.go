@@ -0,0 +1,150 @@
+package gapbuffer
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestTypingAtCursor(t *testing.T) {
+	g := NewGapBuffer[rune]()
+	for _, r := range "helo" {
+		g.Insert(r)
+	}
+	if g.Cursor() != 4 || g.GetSize() != 4 {
+		t.Fatalf("Cursor=%d Size=%d", g.Cursor(), g.GetSize())
+	}
+
+	// Исправление опечатки: курсор назад и вставка
+	g.Left()
+	g.Insert('l')
+	if got := string(g.ToSlice()); got != "hello" {
+		t.Errorf("after fix = %q", got)
+	}
+	_ = g.MoveCursor(g.GetSize())
+	g.Insert([]rune(", world")...)
+	if got := string(g.ToSlice()); got != "hello, world" {
+		t.Errorf("after append = %q", got)
+	}
+
+	if v, err := g.Backspace(); err != nil || v != 'd' {
+		t.Errorf("Backspace = %q, %v", v, err)
+	}
+	_ = g.MoveCursor(5)
+	if v, err := g.DeleteForward(); err != nil || v != ',' {
+		t.Errorf("DeleteForward = %q, %v", v, err)
+	}
+	if got := string(g.ToSlice()); got != "hello worl" {
+		t.Errorf("after deletes = %q", got)
+	}
+
+	_ = g.MoveCursor(0)
+	if g.Left() {
+		t.Error("Left at start returned true")
+	}
+	if _, err := g.Backspace(); err == nil {
+		t.Error("Backspace at start returned nil error")
+	}
+	_ = g.MoveCursor(g.GetSize())
+	if g.Right() {
+		t.Error("Right at end returned true")
+	}
+	if _, err := g.DeleteForward(); err == nil {
+		t.Error("DeleteForward at end returned nil error")
+	}
+	if err := g.MoveCursor(g.GetSize() + 1); err == nil {
+		t.Error("MoveCursor out of bounds returned nil error")
+	}
+}
+
+func TestArraySurface(t *testing.T) {
+	g := FromSlice([]int{1, 2, 3})
+	if err := g.InsertByInd(0, 0); err != nil {
+		t.Fatalf("InsertByInd error: %v", err)
+	}
+	if err := g.InsertByInd(4, 4); err != nil {
+		t.Fatalf("InsertByInd at end error: %v", err)
+	}
+	if err := g.DeleteByInd(2); err != nil {
+		t.Fatalf("DeleteByInd error: %v", err)
+	}
+	if err := g.Set(0, 10); err != nil {
+		t.Fatalf("Set error: %v", err)
+	}
+	if got := g.ToSlice(); !slices.Equal(got, []int{10, 1, 3, 4}) {
+		t.Errorf("ToSlice = %v", got)
+	}
+	// Индексы за разрывом переводятся правильно
+	_ = g.MoveCursor(1)
+	for i, want := range []int{10, 1, 3, 4} {
+		if v, err := g.Get(i); err != nil || v != want {
+			t.Errorf("Get(%d) = %d, %v, want %d", i, v, err, want)
+		}
+	}
+	if got := slices.Collect(g.All()); !slices.Equal(got, []int{10, 1, 3, 4}) {
+		t.Errorf("All = %v", got)
+	}
+
+	if _, err := g.Get(4); err == nil {
+		t.Error("Get out of bounds returned nil error")
+	}
+	if err := g.Set(-1, 0); err == nil {
+		t.Error("Set out of bounds returned nil error")
+	}
+	if err := g.InsertByInd(6, 0); err == nil {
+		t.Error("InsertByInd out of bounds returned nil error")
+	}
+	if err := g.DeleteByInd(4); err == nil {
+		t.Error("DeleteByInd out of bounds returned nil error")
+	}
+}
+
+func TestRandomEditsMatchSlice(t *testing.T) {
+	r := rand.New(rand.NewPCG(5, 6))
+	g := NewGapBuffer[int]()
+	var model []int
+
+	for step := 0; step < 5000; step++ {
+		switch op := r.IntN(10); {
+		case op < 5:
+			i := r.IntN(len(model) + 1)
+			_ = g.InsertByInd(i, step)
+			model = slices.Insert(model, i, step)
+		case op < 7 && len(model) > 0:
+			i := r.IntN(len(model))
+			_ = g.DeleteByInd(i)
+			model = slices.Delete(model, i, i+1)
+		case op < 8:
+			_ = g.MoveCursor(r.IntN(len(model) + 1))
+		case op < 9:
+			vals := []int{-step, -step - 1, -step - 2}
+			c := g.Cursor()
+			g.Insert(vals...)
+			model = slices.Insert(model, c, vals...)
+		case len(model) > 0:
+			i := r.IntN(len(model))
+			_ = g.Set(i, 7)
+			model[i] = 7
+		}
+		if g.GetSize() != len(model) {
+			t.Fatalf("step %d: size %d, want %d", step, g.GetSize(), len(model))
+		}
+	}
+	if got := g.ToSlice(); !slices.Equal(got, model) {
+		t.Fatal("buffer differs from model after random edits")
+	}
+}
+
+func TestGapIsCleared(t *testing.T) {
+	// Элементы в разрыве не должны держать ссылки на удаленные значения
+	g := FromSlice([]*int{new(int), new(int), new(int)})
+	_ = g.MoveCursor(0)
+	_, _ = g.DeleteForward()
+	_ = g.MoveCursor(2)
+	_, _ = g.Backspace()
+	for i := g.gapStart; i < g.gapEnd; i++ {
+		if g.data[i] != nil {
+			t.Fatalf("gap slot %d holds a pointer", i)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package hashing
+
+import "math"
+
+// GoldenRatio - константа Кнута для метода умножения: (sqrt(5) - 1) / 2
+var GoldenRatio = (math.Sqrt(5.0) - 1.0) / 2.0
+
+// Polynomial сворачивает строку в число по схеме Горнера с основанием 31
+func Polynomial(key string) uint64 {
+	var numKey uint64
+	for _, c := range []byte(key) {
+		numKey = numKey*31 + uint64(c)
+	}
+	return numKey
+}
+
+// Multiplicative реализует метод умножения: дробная часть numKey*a, растянутая на size.
+// a должна лежать в интервале (0, 1), обычно это GoldenRatio.
+func Multiplicative(numKey uint64, a float64, size uint32) uint32 {
+	temp := float64(numKey) * a
+	temp = temp - math.Floor(temp) // Дробная часть
+	return uint32(math.Floor(float64(size) * temp))
+}
+
+// Folding реализует метод свертки и возвращает шаг пробирования в диапазоне [1, size-1].
+// Для четного size шаг делается нечетным, чтобы обойти всю таблицу.
+func Folding(key string, size uint32) uint32 {
+	return FoldStep(FoldSum(key), size)
+}
+
+// FoldSum возвращает сумму байт ключа - основу метода свертки.
+// Ее можно сохранить и пересчитать шаг для другого размера без самого ключа.
+func FoldSum(key string) uint32 {
+	var sum uint32
+	for _, c := range []byte(key) {
+		sum += uint32(c)
+	}
+	return sum
+}
+
+// FoldStep превращает сумму байт в шаг пробирования для таблицы размера size
+func FoldStep(sum, size uint32) uint32 {
+	result := (sum % (size - 1)) + 1
+	if size%2 == 0 && result%2 == 0 {
+		result++
+	}
+	return result
+}
+
+// Константы FNV-1a (64 бита)
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// Sum64 возвращает 64-битный хеш строки (FNV-1a с финальным перемешиванием).
+// В отличие от Polynomial, все биты результата зависят от всех байт ключа,
+// поэтому его можно делить на части для фильтров и скетчей.
+func Sum64(key string) uint64 {
+	h := uint64(fnvOffset64)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= fnvPrime64
+	}
+	return Mix64(h)
+}
+
+// Mix64 - финализатор MurmurHash3 (fmix64): лавинно перемешивает биты числа
+func Mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// Pair возвращает два независимых хеша ключа для двойного хеширования.
+// Второй хеш всегда нечетный, поэтому последовательность h1 + i*h2
+// при размере, равном степени двойки, не зацикливается раньше времени.
+func Pair(key string) (h1, h2 uint64) {
+	h1 = Sum64(key)
+	h2 = Mix64(h1^0x9e3779b97f4a7c15) | 1
+	return h1, h2
+}
+
+// Probe возвращает i-й индекс последовательности двойного хеширования в диапазоне [0, size)
+func Probe(h1, h2, i, size uint64) uint64 {
+	return (h1 + i*h2) % size
+}
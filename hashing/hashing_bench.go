@@ -0,0 +1,19 @@
+package hashing
+
+import "testing"
+
+const benchKey = "benchmark-key-0123456789"
+
+// BenchmarkMultiplicative измеряет hash1 из dhash/cuckoo.
+func BenchmarkMultiplicative(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = Multiplicative(Polynomial(benchKey), GoldenRatio, 1000003)
+	}
+}
+
+// BenchmarkSum64 измеряет хеш, используемый фильтрами.
+func BenchmarkSum64(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = Sum64(benchKey)
+	}
+}
@@ -0,0 +1,86 @@
+package hashing
+
+import (
+	"fmt"
+	"math/bits"
+	"testing"
+)
+
+func TestPolynomial(t *testing.T) {
+	if Polynomial("") != 0 {
+		t.Error("Empty key should hash to 0")
+	}
+	// 'a' * 31 + 'b'
+	if got := Polynomial("ab"); got != 97*31+98 {
+		t.Errorf("Expected %d, got %d", 97*31+98, got)
+	}
+}
+
+func TestMultiplicativeRange(t *testing.T) {
+	for _, size := range []uint32{1, 7, 100, 1 << 20} {
+		for i := 0; i < 1000; i++ {
+			h := Multiplicative(Polynomial(fmt.Sprintf("key%d", i)), GoldenRatio, size)
+			if h >= size {
+				t.Fatalf("Multiplicative out of range: %d >= %d", h, size)
+			}
+		}
+	}
+}
+
+func TestFolding(t *testing.T) {
+	for _, size := range []uint32{2, 4, 11, 100} {
+		for i := 0; i < 1000; i++ {
+			step := Folding(fmt.Sprintf("key%d", i), size)
+			if step == 0 || step > size {
+				t.Fatalf("Folding step %d out of range for size %d", step, size)
+			}
+			if size%2 == 0 && step%2 == 0 {
+				t.Fatalf("Folding step %d must be odd for even size %d", step, size)
+			}
+		}
+	}
+}
+
+func TestSum64(t *testing.T) {
+	if Sum64("hello") != Sum64("hello") {
+		t.Error("Sum64 is not deterministic")
+	}
+	// Анаграммы совпадают у Folding, но не должны совпадать у Sum64
+	if Sum64("abc") == Sum64("cba") {
+		t.Error("Sum64 collides on anagrams")
+	}
+
+	// Лавинный эффект: изменение одного байта меняет примерно половину битов
+	total := 0
+	const n = 1000
+	for i := 0; i < n; i++ {
+		a := Sum64(fmt.Sprintf("key%d", i))
+		b := Sum64(fmt.Sprintf("key%d!", i))
+		total += bits.OnesCount64(a ^ b)
+	}
+	avg := float64(total) / n
+	if avg < 28 || avg > 36 {
+		t.Errorf("Poor avalanche: average %.2f differing bits", avg)
+	}
+}
+
+func TestPairAndProbe(t *testing.T) {
+	h1, h2 := Pair("key")
+	if h2%2 == 0 {
+		t.Error("Second hash must be odd")
+	}
+
+	// Для размера - степени двойки нечетный шаг обходит все ячейки
+	const size = 64
+	seen := make(map[uint64]bool)
+	for i := uint64(0); i < size; i++ {
+		idx := Probe(h1, h2, i, size)
+		if idx >= size {
+			t.Fatalf("Probe out of range: %d", idx)
+		}
+		seen[idx] = true
+	}
+	if len(seen) != size {
+		t.Errorf("Probe sequence visited %d of %d slots", len(seen), size)
+	}
+}
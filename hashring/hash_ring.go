@@ -0,0 +1,387 @@
+package hashring
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+	"github.com/D4ROVAN1E/LR_3_Go/hashing"
+)
+
+// Ошибки, которые могут вернуть стратегии
+var (
+	ErrNoNodes      = errors.New("no nodes available")
+	ErrNodeExists   = errors.New("node already exists")
+	ErrNodeNotFound = errors.New("node not found")
+	ErrNegativeN    = errors.New("replica count must not be negative")
+)
+
+// Strategy описывает способ распределения ключей по узлам
+type Strategy interface {
+	AddNode(node string) error
+	RemoveNode(node string) error
+	// Owner возвращает узел, отвечающий за ключ
+	Owner(key string) (string, error)
+	// Replicas возвращает до n различных узлов для ключа; первый совпадает с Owner.
+	// Для n == 0 результат пуст, для отрицательного n возвращается ErrNegativeN.
+	Replicas(key string, n int) ([]string, error)
+	// Nodes возвращает узлы в отсортированном порядке
+	Nodes() []string
+}
+
+// point - виртуальный узел на кольце
+type point struct {
+	hash uint64
+	node string
+}
+
+// HashRing реализует консистентное хеширование с виртуальными узлами.
+// Точки кольца хранятся в отсортированном array.Array, владелец ключа
+// ищется бинарным поиском первой точки, не меньшей хеша ключа.
+type HashRing struct {
+	points   *array.Array[point]
+	nodes    map[string]struct{}
+	replicas int // Количество виртуальных узлов на один реальный
+}
+
+// NewHashRing создает пустое кольцо с virtualNodes точками на узел
+func NewHashRing(virtualNodes int) (*HashRing, error) {
+	if virtualNodes <= 0 {
+		return nil, fmt.Errorf("number of virtual nodes must be greater than zero")
+	}
+	return &HashRing{
+		points:   array.NewArray[point](),
+		nodes:    make(map[string]struct{}),
+		replicas: virtualNodes,
+	}, nil
+}
+
+// pointHash возвращает позицию i-й виртуальной точки узла
+func pointHash(node string, i int) uint64 {
+	return hashing.Sum64(node + "#" + strconv.Itoa(i))
+}
+
+// search возвращает индекс первой точки с хешем >= h (или размер массива)
+func (r *HashRing) search(h uint64) int {
+	lo, hi := 0, r.points.GetSize()
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		p, _ := r.points.Get(mid)
+		if p.hash < h {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// insertPoint вставляет точку, сохраняя сортировку по (hash, node).
+// При равных хешах порядок задается именем узла, чтобы кольцо
+// не зависело от порядка добавления.
+func (r *HashRing) insertPoint(p point) error {
+	idx := r.search(p.hash)
+	for idx < r.points.GetSize() {
+		cur, _ := r.points.Get(idx)
+		if cur.hash != p.hash || cur.node >= p.node {
+			break
+		}
+		idx++
+	}
+	return r.points.InsertByInd(idx, p)
+}
+
+// AddNode добавляет узел и его виртуальные точки
+func (r *HashRing) AddNode(node string) error {
+	if _, ok := r.nodes[node]; ok {
+		return ErrNodeExists
+	}
+	for i := 0; i < r.replicas; i++ {
+		if err := r.insertPoint(point{hash: pointHash(node, i), node: node}); err != nil {
+			return err
+		}
+	}
+	r.nodes[node] = struct{}{}
+	return nil
+}
+
+// RemoveNode удаляет узел; его ключи переходят к следующим по кольцу точкам
+func (r *HashRing) RemoveNode(node string) error {
+	if _, ok := r.nodes[node]; !ok {
+		return ErrNodeNotFound
+	}
+	// Один проход со сжатием: O(n) вместо replicas удалений по O(n)
+	kept := array.NewArray[point]()
+	for i := 0; i < r.points.GetSize(); i++ {
+		p, _ := r.points.Get(i)
+		if p.node != node {
+			kept.PushBack(p)
+		}
+	}
+	r.points = kept
+	delete(r.nodes, node)
+	return nil
+}
+
+// Owner возвращает узел, отвечающий за ключ
+func (r *HashRing) Owner(key string) (string, error) {
+	if r.points.GetSize() == 0 {
+		return "", ErrNoNodes
+	}
+	idx := r.search(hashing.Sum64(key))
+	if idx == r.points.GetSize() {
+		idx = 0 // Переход через ноль кольца
+	}
+	p, _ := r.points.Get(idx)
+	return p.node, nil
+}
+
+// Replicas обходит кольцо по часовой стрелке от ключа и собирает n различных узлов
+func (r *HashRing) Replicas(key string, n int) ([]string, error) {
+	if n < 0 {
+		return nil, ErrNegativeN
+	}
+	size := r.points.GetSize()
+	if size == 0 {
+		return nil, ErrNoNodes
+	}
+	if n > len(r.nodes) {
+		n = len(r.nodes)
+	}
+
+	result := make([]string, 0, n)
+	seen := make(map[string]struct{}, n)
+	start := r.search(hashing.Sum64(key))
+	for i := 0; i < size && len(result) < n; i++ {
+		p, _ := r.points.Get((start + i) % size)
+		if _, ok := seen[p.node]; ok {
+			continue
+		}
+		seen[p.node] = struct{}{}
+		result = append(result, p.node)
+	}
+	return result, nil
+}
+
+// Nodes возвращает узлы в отсортированном порядке
+func (r *HashRing) Nodes() []string {
+	return sortedKeys(r.nodes)
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// JumpHash реализует алгоритм Lamping и Veach: отображает ключ в корзину [0, numBuckets).
+// При увеличении числа корзин с n до n+1 перемещается только 1/(n+1) ключей.
+func JumpHash(key uint64, numBuckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(numBuckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// JumpStrategy распределяет ключи через JumpHash по списку узлов.
+// Минимальное перемещение гарантируется только при добавлении узла в конец
+// и удалении последнего; удаление из середины сдвигает номера корзин.
+type JumpStrategy struct {
+	nodes *array.Array[string]
+	index map[string]struct{}
+}
+
+// NewJumpStrategy создает пустую стратегию
+func NewJumpStrategy() *JumpStrategy {
+	return &JumpStrategy{
+		nodes: array.NewArray[string](),
+		index: make(map[string]struct{}),
+	}
+}
+
+// AddNode добавляет узел в конец списка
+func (j *JumpStrategy) AddNode(node string) error {
+	if _, ok := j.index[node]; ok {
+		return ErrNodeExists
+	}
+	j.nodes.PushBack(node)
+	j.index[node] = struct{}{}
+	return nil
+}
+
+// RemoveNode удаляет узел из списка
+func (j *JumpStrategy) RemoveNode(node string) error {
+	if _, ok := j.index[node]; !ok {
+		return ErrNodeNotFound
+	}
+	for i := 0; i < j.nodes.GetSize(); i++ {
+		if n, _ := j.nodes.Get(i); n == node {
+			if err := j.nodes.DeleteByInd(i); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	delete(j.index, node)
+	return nil
+}
+
+// Owner возвращает узел, отвечающий за ключ
+func (j *JumpStrategy) Owner(key string) (string, error) {
+	if j.nodes.GetSize() == 0 {
+		return "", ErrNoNodes
+	}
+	return j.nodes.Get(JumpHash(hashing.Sum64(key), j.nodes.GetSize()))
+}
+
+// Replicas перебирает корзины для ключа, каждый раз перехешируя его с новой солью
+func (j *JumpStrategy) Replicas(key string, n int) ([]string, error) {
+	if n < 0 {
+		return nil, ErrNegativeN
+	}
+	size := j.nodes.GetSize()
+	if size == 0 {
+		return nil, ErrNoNodes
+	}
+	if n > size {
+		n = size
+	}
+
+	result := make([]string, 0, n)
+	seen := make(map[int]struct{}, n)
+	h := hashing.Sum64(key)
+	for attempt := 0; len(result) < n && attempt < 4*size; attempt++ {
+		bucket := JumpHash(h, size)
+		h = hashing.Mix64(h + 1)
+		if _, ok := seen[bucket]; ok {
+			continue
+		}
+		seen[bucket] = struct{}{}
+		node, _ := j.nodes.Get(bucket)
+		result = append(result, node)
+	}
+	// Если случайные попытки не набрали n корзин, добираем оставшиеся по порядку
+	for b := 0; len(result) < n; b++ {
+		if _, ok := seen[b]; !ok {
+			node, _ := j.nodes.Get(b)
+			result = append(result, node)
+		}
+	}
+	return result, nil
+}
+
+// Nodes возвращает узлы в отсортированном порядке
+func (j *JumpStrategy) Nodes() []string {
+	return sortedKeys(j.index)
+}
+
+// RendezvousStrategy реализует хеширование с наибольшим весом (HRW):
+// ключ принадлежит узлу с максимальным hash(ключ, узел).
+// При удалении узла перемещаются только его ключи, при добавлении - 1/(n+1).
+type RendezvousStrategy struct {
+	nodes map[string]uint64 // Узел -> хеш имени
+}
+
+// NewRendezvousStrategy создает пустую стратегию
+func NewRendezvousStrategy() *RendezvousStrategy {
+	return &RendezvousStrategy{nodes: make(map[string]uint64)}
+}
+
+// AddNode добавляет узел
+func (r *RendezvousStrategy) AddNode(node string) error {
+	if _, ok := r.nodes[node]; ok {
+		return ErrNodeExists
+	}
+	r.nodes[node] = hashing.Sum64(node)
+	return nil
+}
+
+// RemoveNode удаляет узел
+func (r *RendezvousStrategy) RemoveNode(node string) error {
+	if _, ok := r.nodes[node]; !ok {
+		return ErrNodeNotFound
+	}
+	delete(r.nodes, node)
+	return nil
+}
+
+// score - вес пары (ключ, узел)
+func score(keyHash, nodeHash uint64) uint64 {
+	return hashing.Mix64(keyHash ^ nodeHash)
+}
+
+type scored struct {
+	node  string
+	score uint64
+}
+
+// ranked возвращает узлы по убыванию веса для ключа
+func (r *RendezvousStrategy) ranked(key string) []scored {
+	h := hashing.Sum64(key)
+	result := make([]scored, 0, len(r.nodes))
+	for node, nh := range r.nodes {
+		result = append(result, scored{node: node, score: score(h, nh)})
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].score != result[b].score {
+			return result[a].score > result[b].score
+		}
+		return result[a].node < result[b].node
+	})
+	return result
+}
+
+// Owner возвращает узел с максимальным весом
+func (r *RendezvousStrategy) Owner(key string) (string, error) {
+	if len(r.nodes) == 0 {
+		return "", ErrNoNodes
+	}
+	h := hashing.Sum64(key)
+	var best scored
+	first := true
+	for node, nh := range r.nodes {
+		s := score(h, nh)
+		if first || s > best.score || (s == best.score && node < best.node) {
+			best = scored{node: node, score: s}
+			first = false
+		}
+	}
+	return best.node, nil
+}
+
+// Replicas возвращает n узлов с наибольшими весами
+func (r *RendezvousStrategy) Replicas(key string, n int) ([]string, error) {
+	if n < 0 {
+		return nil, ErrNegativeN
+	}
+	if len(r.nodes) == 0 {
+		return nil, ErrNoNodes
+	}
+	ranked := r.ranked(key)
+	if n > len(ranked) {
+		n = len(ranked)
+	}
+	result := make([]string, n)
+	for i := 0; i < n; i++ {
+		result[i] = ranked[i].node
+	}
+	return result, nil
+}
+
+// Nodes возвращает узлы в отсортированном порядке
+func (r *RendezvousStrategy) Nodes() []string {
+	result := make([]string, 0, len(r.nodes))
+	for k := range r.nodes {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
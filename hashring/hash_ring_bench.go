@@ -0,0 +1,57 @@
+package hashring
+
+import (
+	"fmt"
+	"testing"
+)
+
+const (
+	NumNodes = 50    // Количество узлов
+	NumKeys  = 10000 // Количество ключей для поиска
+)
+
+func benchKeys() []string {
+	keys := make([]string, NumKeys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+	return keys
+}
+
+func benchOwner(b *testing.B, s Strategy) {
+	for i := 0; i < NumNodes; i++ {
+		s.AddNode(fmt.Sprintf("node%d", i))
+	}
+	keys := benchKeys()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = s.Owner(keys[i%NumKeys])
+	}
+}
+
+// BenchmarkRingOwner измеряет бинарный поиск по кольцу с 160 точками на узел.
+func BenchmarkRingOwner(b *testing.B) {
+	ring, _ := NewHashRing(160)
+	benchOwner(b, ring)
+}
+
+// BenchmarkJumpOwner измеряет JumpHash: O(ln n) без дополнительной памяти.
+func BenchmarkJumpOwner(b *testing.B) {
+	benchOwner(b, NewJumpStrategy())
+}
+
+// BenchmarkRendezvousOwner измеряет HRW: O(n) на каждый поиск.
+func BenchmarkRendezvousOwner(b *testing.B) {
+	benchOwner(b, NewRendezvousStrategy())
+}
+
+// BenchmarkRingAddNode измеряет вставку виртуальных точек в отсортированный массив.
+func BenchmarkRingAddNode(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ring, _ := NewHashRing(160)
+		for j := 0; j < NumNodes; j++ {
+			ring.AddNode(fmt.Sprintf("node%d", j))
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package hashring
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Проверка на этапе компиляции
+var (
+	_ Strategy = (*HashRing)(nil)
+	_ Strategy = (*JumpStrategy)(nil)
+	_ Strategy = (*RendezvousStrategy)(nil)
+)
+
+const numKeys = 10000
+
+// strategies возвращает по экземпляру каждой стратегии
+func strategies(t *testing.T) map[string]Strategy {
+	ring, err := NewHashRing(160)
+	if err != nil {
+		t.Fatalf("NewHashRing failed: %v", err)
+	}
+	return map[string]Strategy{
+		"ring":       ring,
+		"jump":       NewJumpStrategy(),
+		"rendezvous": NewRendezvousStrategy(),
+	}
+}
+
+// owners вычисляет владельца для каждого ключа
+func owners(t *testing.T, s Strategy) []string {
+	result := make([]string, numKeys)
+	for i := range result {
+		owner, err := s.Owner(fmt.Sprintf("key%d", i))
+		if err != nil {
+			t.Fatalf("Owner failed: %v", err)
+		}
+		result[i] = owner
+	}
+	return result
+}
+
+// moved считает долю ключей, сменивших владельца
+func moved(before, after []string) float64 {
+	count := 0
+	for i := range before {
+		if before[i] != after[i] {
+			count++
+		}
+	}
+	return float64(count) / float64(len(before))
+}
+
+func TestConstructor(t *testing.T) {
+	if _, err := NewHashRing(0); err == nil {
+		t.Error("Expected error for zero virtual nodes")
+	}
+}
+
+func TestEmptyAndErrors(t *testing.T) {
+	for name, s := range strategies(t) {
+		if _, err := s.Owner("key"); err != ErrNoNodes {
+			t.Errorf("%s: expected ErrNoNodes, got %v", name, err)
+		}
+		if _, err := s.Replicas("key", 2); err != ErrNoNodes {
+			t.Errorf("%s: expected ErrNoNodes, got %v", name, err)
+		}
+		if err := s.RemoveNode("missing"); err != ErrNodeNotFound {
+			t.Errorf("%s: expected ErrNodeNotFound, got %v", name, err)
+		}
+		s.AddNode("a")
+		if err := s.AddNode("a"); err != ErrNodeExists {
+			t.Errorf("%s: expected ErrNodeExists, got %v", name, err)
+		}
+		if owner, err := s.Owner("key"); err != nil || owner != "a" {
+			t.Errorf("%s: single node must own everything, got %s", name, owner)
+		}
+		if _, err := s.Replicas("key", -1); err != ErrNegativeN {
+			t.Errorf("%s: expected ErrNegativeN, got %v", name, err)
+		}
+		if replicas, err := s.Replicas("key", 0); err != nil || len(replicas) != 0 {
+			t.Errorf("%s: expected no replicas for n=0, got %v %v", name, replicas, err)
+		}
+	}
+}
+
+func TestReplicasAndBalance(t *testing.T) {
+	for name, s := range strategies(t) {
+		for i := 0; i < 5; i++ {
+			s.AddNode(fmt.Sprintf("node%d", i))
+		}
+		if nodes := s.Nodes(); len(nodes) != 5 || nodes[0] != "node0" {
+			t.Errorf("%s: unexpected nodes %v", name, nodes)
+		}
+
+		load := make(map[string]int)
+		for i := 0; i < numKeys; i++ {
+			key := fmt.Sprintf("key%d", i)
+			owner, _ := s.Owner(key)
+			load[owner]++
+
+			replicas, err := s.Replicas(key, 3)
+			if err != nil || len(replicas) != 3 {
+				t.Fatalf("%s: Replicas failed: %v %v", name, replicas, err)
+			}
+			if replicas[0] != owner {
+				t.Fatalf("%s: first replica %s differs from owner %s", name, replicas[0], owner)
+			}
+			if replicas[0] == replicas[1] || replicas[1] == replicas[2] || replicas[0] == replicas[2] {
+				t.Fatalf("%s: duplicate replicas %v", name, replicas)
+			}
+		}
+
+		// Запрос большего числа реплик, чем узлов
+		if all, _ := s.Replicas("key", 10); len(all) != 5 {
+			t.Errorf("%s: expected 5 replicas, got %d", name, len(all))
+		}
+
+		// Нагрузка каждого узла в пределах ±30% от среднего
+		for node, n := range load {
+			if n < numKeys/5*7/10 || n > numKeys/5*13/10 {
+				t.Errorf("%s: node %s is unbalanced: %d keys", name, node, n)
+			}
+		}
+	}
+}
+
+// TestRebalanceCost измеряет долю перемещенных ключей при изменении состава узлов
+func TestRebalanceCost(t *testing.T) {
+	const nodes = 10
+	for name, s := range strategies(t) {
+		for i := 0; i < nodes; i++ {
+			s.AddNode(fmt.Sprintf("node%d", i))
+		}
+		before := owners(t, s)
+
+		// Добавление узла: идеально перемещается 1/(n+1)
+		s.AddNode("new")
+		afterAdd := owners(t, s)
+		addCost := moved(before, afterAdd)
+		t.Logf("%s: adding a node moved %.2f%% of keys (ideal %.2f%%)", name, addCost*100, 100.0/(nodes+1))
+		if addCost > 1.5/(nodes+1) {
+			t.Errorf("%s: too many keys moved on add: %.4f", name, addCost)
+		}
+		// Ключи уходят только на новый узел
+		for i := range before {
+			if before[i] != afterAdd[i] && afterAdd[i] != "new" {
+				t.Fatalf("%s: key moved between old nodes", name)
+			}
+		}
+
+		// Удаление добавленного узла возвращает исходное распределение
+		s.RemoveNode("new")
+		if cost := moved(before, owners(t, s)); cost != 0 {
+			t.Errorf("%s: removing the new node did not restore layout, moved %.4f", name, cost)
+		}
+	}
+}
+
+func TestRemoveMiddleNode(t *testing.T) {
+	ring, _ := NewHashRing(160)
+	rendezvous := NewRendezvousStrategy()
+	for _, s := range []Strategy{ring, rendezvous} {
+		for i := 0; i < 10; i++ {
+			s.AddNode(fmt.Sprintf("node%d", i))
+		}
+		before := owners(t, s)
+		s.RemoveNode("node4")
+		after := owners(t, s)
+
+		// Перемещаются только ключи удаленного узла
+		for i := range before {
+			if before[i] != after[i] && before[i] != "node4" {
+				t.Fatalf("%T: key of %s moved after removing node4", s, before[i])
+			}
+			if after[i] == "node4" {
+				t.Fatalf("%T: removed node still owns keys", s)
+			}
+		}
+	}
+}
+
+func TestJumpHash(t *testing.T) {
+	if JumpHash(123, 1) != 0 {
+		t.Error("Single bucket must always be 0")
+	}
+	for i := uint64(0); i < 1000; i++ {
+		if b := JumpHash(i, 17); b < 0 || b >= 17 {
+			t.Fatalf("Bucket %d out of range", b)
+		}
+		// Ключ либо остается в корзине, либо переходит в новую
+		if a, b := JumpHash(i, 17), JumpHash(i, 18); a != b && b != 17 {
+			t.Fatalf("Key %d moved from %d to old bucket %d", i, a, b)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package hyperloglog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/hashing"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Допустимый диапазон точности: 2^p регистров
+const (
+	MinPrecision = 4
+	MaxPrecision = 16
+)
+
+// Ошибки, которые может вернуть оценщик
+var (
+	ErrInvalidPrecision = fmt.Errorf("precision must be in [%d, %d]", MinPrecision, MaxPrecision)
+	ErrIncompatible     = errors.New("estimators have different precision")
+)
+
+// HyperLogLog оценивает количество различных ключей в потоке.
+// Использует 2^p однобайтовых регистров; стандартная ошибка ~1.04/sqrt(2^p).
+type HyperLogLog struct {
+	registers []uint8
+	p         uint8
+}
+
+// NewHyperLogLog создает оценщик с точностью p
+func NewHyperLogLog(p uint8) (*HyperLogLog, error) {
+	if p < MinPrecision || p > MaxPrecision {
+		return nil, ErrInvalidPrecision
+	}
+	return &HyperLogLog{
+		registers: make([]uint8, 1<<p),
+		p:         p,
+	}, nil
+}
+
+// Add учитывает ключ
+func (h *HyperLogLog) Add(key string) {
+	x := hashing.Sum64(key)
+	// Старшие p бит выбирают регистр, остальные определяют ранг
+	idx := x >> (64 - h.p)
+	w := x<<h.p | 1<<(h.p-1) // Сторожевой бит ограничивает ранг значением 64-p+1
+	rank := uint8(bits.LeadingZeros64(w)) + 1
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// alpha - поправочный коэффициент для m регистров
+func alpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/m)
+}
+
+// Count возвращает оценку количества различных ключей
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(len(h.registers))
+
+	var sum float64
+	zeros := 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha(m) * m * m / sum
+
+	// На малых мощностях точнее линейный подсчет по пустым регистрам.
+	// Поправка на больших значениях не нужна: хеш 64-битный.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Precision возвращает точность p
+func (h *HyperLogLog) Precision() uint8 {
+	return h.p
+}
+
+// Merge объединяет other в текущий оценщик (поэлементный максимум регистров).
+// Результат оценивает мощность объединения потоков.
+func (h *HyperLogLog) Merge(other *HyperLogLog) error {
+	if h.p != other.p {
+		return ErrIncompatible
+	}
+	for i, r := range other.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// Clear сбрасывает все регистры
+func (h *HyperLogLog) Clear() {
+	for i := range h.registers {
+		h.registers[i] = 0
+	}
+}
+
+// Clone создает глубокую копию оценщика
+func (h *HyperLogLog) Clone() *HyperLogLog {
+	newRegisters := make([]uint8, len(h.registers))
+	copy(newRegisters, h.registers)
+	return &HyperLogLog{registers: newRegisters, p: h.p}
+}
+
+// SaveBinary сохраняет оценщик в бинарный файл
+func (h *HyperLogLog) SaveBinary(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, func(file io.Writer) error {
+		if err := binary.Write(file, binary.LittleEndian, h.p); err != nil {
+			return err
+		}
+		_, err := file.Write(h.registers)
+		return err
+	}, opts...)
+}
+
+// LoadBinary загружает оценщик из бинарного файла (в том числе сжатого)
+func (h *HyperLogLog) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Could not open binary file for reading: %w", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, h.readBinary, opts...)
+}
+
+func (h *HyperLogLog) readBinary(r io.Reader) error {
+	var p uint8
+	if err := binary.Read(r, binary.LittleEndian, &p); err != nil {
+		return fmt.Errorf("could not read header: %w", err)
+	}
+	if p < MinPrecision || p > MaxPrecision {
+		return ErrInvalidPrecision
+	}
+
+	registers := make([]uint8, 1<<p)
+	if _, err := io.ReadFull(r, registers); err != nil {
+		return fmt.Errorf("failed to read registers: %w", err)
+	}
+	if err := persist.CheckEOF(r); err != nil {
+		return err
+	}
+	// Ранг не может превышать 64-p+1
+	for i, rank := range registers {
+		if rank > 64-p+1 {
+			return fmt.Errorf("register %d has invalid rank %d", i, rank)
+		}
+	}
+
+	h.registers = registers
+	h.p = p
+	return nil
+}
@@ -0,0 +1,35 @@
+package hyperloglog
+
+import (
+	"fmt"
+	"testing"
+)
+
+const NumElements = 100000 // Количество ключей для теста
+
+// BenchmarkAdd измеряет добавление ключа.
+func BenchmarkAdd(b *testing.B) {
+	keys := make([]string, NumElements)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+	h, _ := NewHyperLogLog(14)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.Add(keys[i%NumElements])
+	}
+}
+
+// BenchmarkCount измеряет вычисление оценки по 2^14 регистрам.
+func BenchmarkCount(b *testing.B) {
+	h, _ := NewHyperLogLog(14)
+	for i := 0; i < NumElements; i++ {
+		h.Add(fmt.Sprintf("key%d", i))
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = h.Count()
+	}
+}
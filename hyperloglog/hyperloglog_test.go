@@ -0,0 +1,153 @@
+package hyperloglog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+func TestConstructor(t *testing.T) {
+	if _, err := NewHyperLogLog(3); err != ErrInvalidPrecision {
+		t.Errorf("Expected ErrInvalidPrecision, got %v", err)
+	}
+	if _, err := NewHyperLogLog(17); err != ErrInvalidPrecision {
+		t.Errorf("Expected ErrInvalidPrecision, got %v", err)
+	}
+	h, err := NewHyperLogLog(10)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if h.Count() != 0 || h.Precision() != 10 {
+		t.Error("New estimator should be empty")
+	}
+}
+
+func TestCountAccuracy(t *testing.T) {
+	for _, n := range []int{10, 1000, 100000} {
+		h, _ := NewHyperLogLog(14)
+		for i := 0; i < n; i++ {
+			key := fmt.Sprintf("key%d", i)
+			// Повторы не должны влиять на оценку
+			h.Add(key)
+			h.Add(key)
+		}
+		got := float64(h.Count())
+		relErr := math.Abs(got-float64(n)) / float64(n)
+		// Стандартная ошибка для p=14 около 0.8%, берем запас
+		if relErr > 0.03 {
+			t.Errorf("n=%d: estimate %v, relative error %.4f", n, got, relErr)
+		}
+	}
+}
+
+func TestMergeCloneClear(t *testing.T) {
+	a, _ := NewHyperLogLog(12)
+	b, _ := NewHyperLogLog(12)
+	for i := 0; i < 5000; i++ {
+		a.Add(fmt.Sprintf("key%d", i))
+		b.Add(fmt.Sprintf("key%d", i+2500)) // Пересечение 2500
+	}
+
+	clone := a.Clone()
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	got := float64(a.Count())
+	if math.Abs(got-7500)/7500 > 0.05 {
+		t.Errorf("Union estimate %v is far from 7500", got)
+	}
+	if clone.Count() == a.Count() {
+		t.Error("Clone shares registers with original")
+	}
+
+	other, _ := NewHyperLogLog(8)
+	if err := a.Merge(other); err != ErrIncompatible {
+		t.Errorf("Expected ErrIncompatible, got %v", err)
+	}
+
+	a.Clear()
+	if a.Count() != 0 {
+		t.Error("Estimator not empty after Clear")
+	}
+}
+
+func TestSaveLoadBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "hll.bin")
+
+	h, _ := NewHyperLogLog(8)
+	for i := 0; i < 1000; i++ {
+		h.Add(fmt.Sprintf("key%d", i))
+	}
+	if err := h.SaveBinary(file); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+
+	loaded, _ := NewHyperLogLog(4)
+	if err := loaded.LoadBinary(file); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+	if loaded.Precision() != 8 || loaded.Count() != h.Count() {
+		t.Error("State lost after load")
+	}
+
+	// Ошибки
+	if err := h.SaveBinary(""); err == nil {
+		t.Error("Expected error for empty filename")
+	}
+	if err := loaded.LoadBinary(filepath.Join(tmpDir, "missing.bin")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	badPrecision := filepath.Join(tmpDir, "badp.bin")
+	os.WriteFile(badPrecision, []byte{30}, 0644)
+	if err := loaded.LoadBinary(badPrecision); err != ErrInvalidPrecision {
+		t.Errorf("Expected ErrInvalidPrecision, got %v", err)
+	}
+
+	data, _ := os.ReadFile(file)
+	truncated := filepath.Join(tmpDir, "truncated.bin")
+	os.WriteFile(truncated, data[:len(data)-1], 0644)
+	if err := loaded.LoadBinary(truncated); err == nil {
+		t.Error("Expected error for truncated file")
+	}
+
+	badRank := filepath.Join(tmpDir, "badrank.bin")
+	bad := append([]byte{}, data...)
+	bad[1] = 200
+	os.WriteFile(badRank, bad, 0644)
+	if err := loaded.LoadBinary(badRank); err == nil {
+		t.Error("Expected error for invalid register rank")
+	}
+}
+
+// TestSaveLoadCompressed проверяет, что LoadBinary распаковывает файлы SaveBinary
+func TestSaveLoadCompressed(t *testing.T) {
+	h, _ := NewHyperLogLog(12)
+	for i := 0; i < 1000; i++ {
+		h.Add(fmt.Sprintf("key%d", i))
+	}
+	for _, alg := range []compression.Algorithm{compression.Gzip, compression.Flate, compression.LZ} {
+		file := filepath.Join(t.TempDir(), "hll.bin")
+		if err := h.SaveBinary(file, compression.With(alg)); err != nil {
+			t.Fatalf("%v: SaveBinary failed: %v", alg, err)
+		}
+		loaded, _ := NewHyperLogLog(4)
+		if err := loaded.LoadBinary(file); err != nil {
+			t.Fatalf("%v: LoadBinary failed: %v", alg, err)
+		}
+		if loaded.Precision() != 12 || loaded.Count() != h.Count() {
+			t.Errorf("%v: state lost after load", alg)
+		}
+		// Предел WithLimit считается по распакованным данным
+		if err := loaded.LoadBinary(file, persist.WithLimit(16)); !errors.Is(err, persist.ErrLimitExceeded) {
+			t.Errorf("%v: expected ErrLimitExceeded, got %v", alg, err)
+		}
+	}
+}
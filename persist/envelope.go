@@ -0,0 +1,278 @@
+package persist
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"reflect"
+)
+
+// Формат конверта бинарных файлов:
+//
+//	magic(4) version(1) kind(1) byteOrder(1) typeLen(2) type[typeLen]
+//	payloadLen(8) crc32c(4) payload[payloadLen]
+//
+// Все числа заголовка пишутся в little-endian. Контрольная сумма считается по payload.
+const (
+	// Version - текущая версия формата конверта
+	Version uint8 = 1
+
+	byteOrderLittle uint8 = 0
+	byteOrderBig    uint8 = 1
+
+	maxTypeLen = 1<<16 - 1
+)
+
+var envelopeMagic = [4]byte{'L', 'R', '3', 'G'}
+
+// Ошибки проверки конверта
+var (
+	ErrBadMagic           = errors.New("not a container file: bad magic")
+	ErrUnsupportedVersion = errors.New("unsupported format version")
+	ErrKindMismatch       = errors.New("structure kind mismatch")
+	ErrTypeMismatch       = errors.New("element type mismatch")
+	ErrByteOrder          = errors.New("unsupported byte order")
+	ErrTruncated          = errors.New("file is truncated")
+	ErrChecksum           = errors.New("checksum mismatch")
+	ErrTrailingData       = errors.New("unexpected data after payload")
+	ErrUnstablePayload    = errors.New("payload changed between write passes")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Kind - вид структуры, записанной в конверт
+type Kind uint8
+
+const (
+	KindArray Kind = iota + 1
+	KindStack
+	KindQueue
+	KindSinglyList
+	KindDoublyList
+	KindBinaryTree
+	KindDoubleHash
+	KindCuckooHash
+	KindMatrix
+	KindBitSet
+)
+
+var kindNames = map[Kind]string{
+	KindArray:      "Array",
+	KindStack:      "Stack",
+	KindQueue:      "Queue",
+	KindSinglyList: "ForwardList",
+	KindDoublyList: "DoublyList",
+	KindBinaryTree: "FullBinaryTree",
+	KindDoubleHash: "DoubleHash",
+	KindCuckooHash: "CuckooHash",
+	KindMatrix:     "Matrix",
+	KindBitSet:     "BitSet",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// Header - заголовок конверта
+type Header struct {
+	Version   uint8
+	Kind      Kind
+	Type      string
+	BigEndian bool
+	Length    uint64
+	Checksum  uint32
+}
+
+// TypeName возвращает описание типа элементов для заголовка
+func TypeName[T any]() string {
+	return reflect.TypeFor[T]().String()
+}
+
+// WriteEnvelope пишет заголовок и данные, которые write пишет в w.
+// Длина и контрольная сумма стоят перед данными, поэтому write вызывается
+// дважды: сначала данные только считаются, затем пишутся в w. Данные не
+// собираются в памяти, но оба вызова должны дать одни и те же байты,
+// иначе возвращается ErrUnstablePayload.
+func WriteEnvelope(w io.Writer, kind Kind, typ string, write func(w io.Writer) error) error {
+	if len(typ) > maxTypeLen {
+		return fmt.Errorf("type descriptor is too long: %d bytes", len(typ))
+	}
+
+	sum := crc32.New(castagnoli)
+	measure := &CountingWriter{W: sum}
+	if err := write(measure); err != nil {
+		return err
+	}
+
+	header := make([]byte, 0, 4+3+2+len(typ)+8+4)
+	header = append(header, envelopeMagic[:]...)
+	header = append(header, Version, uint8(kind), byteOrderLittle)
+	header = binary.LittleEndian.AppendUint16(header, uint16(len(typ)))
+	header = append(header, typ...)
+	header = binary.LittleEndian.AppendUint64(header, uint64(measure.N))
+	header = binary.LittleEndian.AppendUint32(header, sum.Sum32())
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	check := crc32.New(castagnoli)
+	out := &CountingWriter{W: io.MultiWriter(w, check)}
+	if err := write(out); err != nil {
+		return err
+	}
+	if out.N != measure.N || check.Sum32() != sum.Sum32() {
+		return fmt.Errorf("%w: %d bytes measured, %d bytes written", ErrUnstablePayload, measure.N, out.N)
+	}
+	return nil
+}
+
+// ReadHeader читает и разбирает заголовок конверта без проверки вида и типа
+func ReadHeader(r io.Reader) (Header, error) {
+	var h Header
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		if err == io.EOF {
+			return h, err
+		}
+		return h, fmt.Errorf("%w: incomplete header", ErrTruncated)
+	}
+	if magic != envelopeMagic {
+		return h, ErrBadMagic
+	}
+
+	var fixed [3 + 2]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return h, fmt.Errorf("%w: incomplete header", ErrTruncated)
+	}
+	h.Version = fixed[0]
+	h.Kind = Kind(fixed[1])
+	h.BigEndian = fixed[2] == byteOrderBig
+	if h.Version != Version {
+		return h, fmt.Errorf("%w: %d", ErrUnsupportedVersion, h.Version)
+	}
+	if fixed[2] != byteOrderLittle {
+		return h, fmt.Errorf("%w: %d", ErrByteOrder, fixed[2])
+	}
+
+	typ := make([]byte, binary.LittleEndian.Uint16(fixed[3:5]))
+	if _, err := io.ReadFull(r, typ); err != nil {
+		return h, fmt.Errorf("%w: incomplete header", ErrTruncated)
+	}
+	h.Type = string(typ)
+
+	var tail [8 + 4]byte
+	if _, err := io.ReadFull(r, tail[:]); err != nil {
+		return h, fmt.Errorf("%w: incomplete header", ErrTruncated)
+	}
+	h.Length = binary.LittleEndian.Uint64(tail[0:8])
+	h.Checksum = binary.LittleEndian.Uint32(tail[8:12])
+	return h, nil
+}
+
+// ReadEnvelope проверяет заголовок и контрольную сумму и передает данные в read.
+// Из r читается ровно конверт, поэтому несколько конвертов можно хранить подряд.
+// read должна прочитать данные целиком, иначе возвращается ErrTrailingData.
+//
+// Данные не собираются в памяти: read получает их по мере чтения, а сумма
+// сверяется до того, как read получит последние байты. Поэтому read, которая
+// меняет структуру только после разбора всех данных, не примет испорченный
+// или обрезанный конверт.
+func ReadEnvelope(r io.Reader, kind Kind, typ string, read func(r io.Reader) error) error {
+	h, err := ReadHeader(r)
+	if err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("%w: empty input", ErrTruncated)
+		}
+		return err
+	}
+	if h.Kind != kind {
+		return fmt.Errorf("%w: expected %s, got %s", ErrKindMismatch, kind, h.Kind)
+	}
+	if h.Type != typ {
+		return fmt.Errorf("%w: expected %s, got %s", ErrTypeMismatch, typ, h.Type)
+	}
+	// Заявленную длину сверяем с пределом WithLimit до чтения данных
+	if lr, ok := r.(*limitReader); ok && h.Length > uint64(max(lr.left, 0)) {
+		lr.exceeded = true
+		return fmt.Errorf("%w: payload of %d bytes, %d bytes left", ErrLimitExceeded, h.Length, lr.left)
+	}
+
+	pr := newPayloadReader(r, h)
+	err = read(pr)
+	// Загрузчики часто заменяют ошибку чтения своей, поэтому ошибку конверта проверяем отдельно
+	if pr.err != nil {
+		return pr.err
+	}
+	if err != nil {
+		return err
+	}
+	if left := pr.data.N; left != 0 {
+		// Дочитываем остаток: обрезанный конверт - это ErrTruncated, а не лишние данные
+		if _, err := io.Copy(io.Discard, pr); pr.err != nil {
+			return pr.err
+		} else if err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %d bytes", ErrTrailingData, left)
+	}
+	return nil
+}
+
+// payloadReader отдает данные конверта не дальше заявленной длины и считает
+// контрольную сумму на лету. Len сообщает заявленный остаток для CheckCount.
+type payloadReader struct {
+	data   *io.LimitedReader
+	sum    hash.Hash32
+	header Header
+	err    error // ErrTruncated или ErrChecksum
+}
+
+func newPayloadReader(r io.Reader, h Header) *payloadReader {
+	sum := crc32.New(castagnoli)
+	p := &payloadReader{
+		data:   &io.LimitedReader{R: io.TeeReader(r, sum), N: int64(min(h.Length, 1<<63-1))},
+		sum:    sum,
+		header: h,
+	}
+	if p.data.N == 0 {
+		p.verify()
+	}
+	return p
+}
+
+func (p *payloadReader) Read(b []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	n, err := p.data.Read(b)
+	if n > 0 && p.data.N == 0 {
+		// Последние байты отдаем только после проверки суммы
+		if p.verify(); p.err != nil {
+			return 0, p.err
+		}
+		return n, nil
+	}
+	if err == io.EOF && p.data.N != 0 {
+		got := p.header.Length - uint64(p.data.N)
+		p.err = fmt.Errorf("%w: expected %d payload bytes, got %d", ErrTruncated, p.header.Length, got)
+		return 0, p.err
+	}
+	return n, err
+}
+
+func (p *payloadReader) verify() {
+	if p.sum.Sum32() != p.header.Checksum {
+		p.err = ErrChecksum
+	}
+}
+
+// Len возвращает, сколько байт данных еще заявлено в заголовке
+func (p *payloadReader) Len() int {
+	return int(min(p.data.N, int64(int(^uint(0)>>1))))
+}
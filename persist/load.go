@@ -0,0 +1,151 @@
+package persist
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrLimitExceeded - данные файла больше предела, заданного WithLimit
+var ErrLimitExceeded = errors.New("input exceeds load limit")
+
+// LoadOption настраивает загрузку файла
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	limit    int64
+	progress func(read, total int64)
+}
+
+// WithLimit ограничивает объем данных, которые загрузчик прочитает после
+// распаковки. Защищает от испорченных и специально раздутых файлов.
+func WithLimit(n int64) LoadOption {
+	return func(o *loadOptions) {
+		o.limit = n
+	}
+}
+
+// WithProgress задает функцию, которую загрузчик вызывает по мере чтения файла:
+// read - прочитано байт файла, total - размер файла (-1, если неизвестен).
+// После успешной загрузки fn вызывается последний раз с read == total.
+func WithProgress(fn func(read, total int64)) LoadOption {
+	return func(o *loadOptions) {
+		o.progress = fn
+	}
+}
+
+// Load передает содержимое file в read. Поток файла сначала проходит через
+// decode (например, распаковку), если она задана. Прогресс считается по байтам
+// файла, а предел WithLimit - по данным после decode.
+func Load(file *os.File, decode func(r io.Reader) (io.Reader, error), read func(r io.Reader) error, opts ...LoadOption) error {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var r io.Reader = file
+	var pr *progressReader
+	if o.progress != nil {
+		total := int64(-1)
+		if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+			total = info.Size()
+		}
+		pr = &progressReader{r: file, total: total, fn: o.progress}
+		r = pr
+	}
+	if decode != nil {
+		var err error
+		if r, err = decode(r); err != nil {
+			return err
+		}
+	}
+
+	var lr *limitReader
+	if o.limit > 0 {
+		lr = &limitReader{r: r, left: o.limit}
+		r = lr
+	}
+	err := read(r)
+	// Загрузчики часто заменяют ошибку чтения своей, поэтому превышение предела проверяем отдельно
+	if err != nil && lr != nil && lr.exceeded && !errors.Is(err, ErrLimitExceeded) {
+		return fmt.Errorf("%w (%d bytes): %w", ErrLimitExceeded, o.limit, err)
+	}
+	// Хвост файла (например, конец сжатого потока) может остаться непрочитанным
+	if err == nil && pr != nil && pr.total >= 0 && pr.read != pr.total {
+		pr.fn(pr.total, pr.total)
+	}
+	return err
+}
+
+// progressReader сообщает о прочитанных байтах после каждого чтения
+type progressReader struct {
+	r     io.Reader
+	read  int64
+	total int64
+	fn    func(read, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.read += int64(n)
+		p.fn(p.read, p.total)
+	}
+	return n, err
+}
+
+// limitReader отдает не больше left байт, а дальше возвращает ErrLimitExceeded
+type limitReader struct {
+	r        io.Reader
+	left     int64
+	exceeded bool
+}
+
+func (l *limitReader) Read(b []byte) (int, error) {
+	if l.left <= 0 {
+		// Ровно на пределе данные могли и закончиться: проверяем одним байтом
+		var one [1]byte
+		if n, err := l.r.Read(one[:]); n == 0 {
+			return 0, err
+		}
+		l.exceeded = true
+		return 0, ErrLimitExceeded
+	}
+	if int64(len(b)) > l.left {
+		b = b[:l.left]
+	}
+	n, err := l.r.Read(b)
+	l.left -= int64(n)
+	return n, err
+}
+
+// CheckCount проверяет, что в r осталось хотя бы count*minSize байт, если
+// размер остатка известен (для данных конверта - по длине из его заголовка).
+// Так количество элементов, не согласованное с длиной данных, отклоняется
+// до выделения памяти.
+func CheckCount(r io.Reader, count uint64, minSize int) error {
+	lr, ok := r.(interface{ Len() int })
+	if !ok || minSize <= 0 {
+		return nil
+	}
+	left := uint64(lr.Len())
+	if count > left/uint64(minSize) {
+		return fmt.Errorf("%w: %d elements need at least %d bytes each, %d bytes left", ErrTruncated, count, minSize, left)
+	}
+	return nil
+}
+
+// CheckEOF проверяет, что в r не осталось данных. Нужна форматам без
+// конверта: в них длина данных не записана, и лишние байты иначе не заметить.
+func CheckEOF(r io.Reader) error {
+	var one [1]byte
+	n, err := io.ReadFull(r, one[:])
+	if n > 0 {
+		return ErrTrailingData
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,174 @@
+package persist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// options - настройки записи файла
+type options struct {
+	backups int
+	wrap    []func(io.Writer) io.Writer
+}
+
+// Option настраивает WriteFile
+type Option func(*options)
+
+// WithBackups сохраняет до n предыдущих версий файла:
+// filename.bak.1 - самая свежая, filename.bak.n - самая старая.
+func WithBackups(n int) Option {
+	return func(o *options) {
+		o.backups = n
+	}
+}
+
+// WithWriter оборачивает поток, в который пишутся данные. Обертки применяются
+// в порядке передачи, последняя получает данные первой. Используется для
+// подсчета байт, сжатия и внедрения сбоев в тестах.
+func WithWriter(wrap func(io.Writer) io.Writer) Option {
+	return func(o *options) {
+		o.wrap = append(o.wrap, wrap)
+	}
+}
+
+func buildOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// BackupName возвращает имя i-й резервной копии файла (i >= 1)
+func BackupName(filename string, i int) string {
+	return fmt.Sprintf("%s.bak.%d", filename, i)
+}
+
+// WriteFile атомарно заменяет файл filename данными, которые write пишет в w.
+// Данные пишутся во временный файл в том же каталоге, сбрасываются на диск
+// и переименовываются поверх filename. При любой ошибке старый файл остается
+// нетронутым, а временный удаляется.
+func WriteFile(filename string, write func(w io.Writer) error, opts ...Option) error {
+	o := buildOptions(opts)
+	dir := filepath.Dir(filename)
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("error: Could not open file for writing: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	err = writeAndSync(tmp, write, o)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	// os.CreateTemp создает файл с правами 0600: сохраняем права заменяемого файла
+	if err == nil {
+		err = os.Chmod(tmpName, fileMode(filename))
+	}
+	if err == nil && o.backups > 0 {
+		err = rotateBackups(filename, o.backups)
+	}
+	if err == nil {
+		err = os.Rename(tmpName, filename)
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return SyncDir(dir)
+}
+
+// fileMode возвращает права существующего файла или 0644 для нового
+func fileMode(filename string) os.FileMode {
+	if info, err := os.Stat(filename); err == nil {
+		return info.Mode().Perm()
+	}
+	return 0644
+}
+
+// writeAndSync пишет данные через буфер и обертки и сбрасывает файл на диск
+func writeAndSync(file *os.File, write func(w io.Writer) error, o options) error {
+	buffered := bufio.NewWriter(file)
+	var w io.Writer = buffered
+	closers := make([]io.Closer, 0, len(o.wrap))
+	for _, wrap := range o.wrap {
+		w = wrap(w)
+		if c, ok := w.(io.Closer); ok {
+			closers = append(closers, c)
+		}
+	}
+
+	if err := write(w); err != nil {
+		return err
+	}
+	// Обертки (например, сжатие) дописывают хвост при закрытии: от внешней к внутренней
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closers[i].Close(); err != nil {
+			return err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// rotateBackups сдвигает резервные копии и сохраняет текущий файл как filename.bak.1.
+// Текущий файл остается на месте до переименования нового, поэтому
+// в любой момент на диске есть целая версия.
+func rotateBackups(filename string, n int) error {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+
+	os.Remove(BackupName(filename, n))
+	for i := n - 1; i >= 1; i-- {
+		if err := os.Rename(BackupName(filename, i), BackupName(filename, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	// Жесткая ссылка не копирует данные; если ФС ее не поддерживает, копируем
+	if err := os.Link(filename, BackupName(filename, 1)); err == nil {
+		return nil
+	}
+	return copyFile(filename, BackupName(filename, 1))
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// SyncDir сбрасывает на диск каталог, чтобы создание и переименование файлов пережили сбой
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	// Не все файловые системы поддерживают fsync каталога
+	_ = d.Sync()
+	return nil
+}
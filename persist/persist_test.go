@@ -0,0 +1,458 @@
+package persist_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+	"github.com/D4ROVAN1E/LR_3_Go/persist/persisttest"
+)
+
+// Вспомогательные функции
+
+// writeBytes возвращает функцию записи фиксированных данных
+func writeBytes(data []byte) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}
+}
+
+// assertOnlyFiles проверяет, что в каталоге нет временных файлов
+func assertOnlyFiles(t *testing.T, dir string, want ...string) {
+	t.Helper()
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(want) {
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Fatalf("Expected files %v, got %v", want, names)
+	}
+}
+
+// Основные функциональные тесты
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.bin")
+
+	if err := persist.WriteFile(path, writeBytes([]byte("first"))); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := persist.WriteFile(path, writeBytes([]byte("second"))); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "second" {
+		t.Errorf("Expected 'second', got %q", data)
+	}
+	assertOnlyFiles(t, dir, "data.bin")
+}
+
+func TestWriteFileKeepsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	os.WriteFile(path, []byte("old"), 0600)
+	persist.WriteFile(path, writeBytes([]byte("new")))
+
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600 to be preserved, got %v", info.Mode().Perm())
+	}
+}
+
+// Тесты внедрения сбоев
+
+func TestWriteFailureKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.bin")
+	original := []byte("original contents")
+	persist.WriteFile(path, writeBytes(original))
+
+	replacement := bytes.Repeat([]byte("new data "), 1000)
+	for _, limit := range []int{0, 1, 100, len(replacement) - 1} {
+		err := persist.WriteFile(path, writeBytes(replacement), persisttest.FailAfter(limit))
+		if !errors.Is(err, persisttest.ErrInjected) {
+			t.Fatalf("limit %d: expected injected error, got %v", limit, err)
+		}
+		if data, _ := os.ReadFile(path); !bytes.Equal(data, original) {
+			t.Fatalf("limit %d: original file was modified", limit)
+		}
+		assertOnlyFiles(t, dir, "data.bin")
+	}
+}
+
+func TestWriteCallbackError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.bin")
+	failure := errors.New("encode failed")
+
+	err := persist.WriteFile(path, func(w io.Writer) error {
+		w.Write([]byte("partial"))
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected callback error, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Target file must not be created on failure")
+	}
+	assertOnlyFiles(t, dir)
+}
+
+func TestMissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "data.bin")
+	if err := persist.WriteFile(path, writeBytes(nil)); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
+
+// Тесты резервных копий
+
+func TestBackupRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.txt")
+
+	for _, v := range []string{"v1", "v2", "v3", "v4"} {
+		if err := persist.WriteFile(path, writeBytes([]byte(v)), persist.WithBackups(2)); err != nil {
+			t.Fatalf("WriteFile %s failed: %v", v, err)
+		}
+	}
+
+	expect := map[string]string{
+		path:                        "v4",
+		persist.BackupName(path, 1): "v3",
+		persist.BackupName(path, 2): "v2",
+	}
+	for name, want := range expect {
+		if data, _ := os.ReadFile(name); string(data) != want {
+			t.Errorf("%s: expected %q, got %q", filepath.Base(name), want, data)
+		}
+	}
+	if _, err := os.Stat(persist.BackupName(path, 3)); !os.IsNotExist(err) {
+		t.Error("Expected at most 2 backups")
+	}
+
+	// Неудачная запись не трогает ни файл, ни резервные копии
+	persist.WriteFile(path, writeBytes([]byte("v5")), persist.WithBackups(2), persisttest.FailAfter(1))
+	if data, _ := os.ReadFile(path); string(data) != "v4" {
+		t.Errorf("Failed write changed the file: %q", data)
+	}
+	if data, _ := os.ReadFile(persist.BackupName(path, 1)); string(data) != "v3" {
+		t.Errorf("Failed write rotated backups: %q", data)
+	}
+}
+
+func TestWithWriterOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	var order []string
+	tag := func(name string) persist.Option {
+		return persist.WithWriter(func(w io.Writer) io.Writer {
+			return writerFunc(func(p []byte) (int, error) {
+				order = append(order, name)
+				return w.Write(p)
+			})
+		})
+	}
+
+	persist.WriteFile(path, writeBytes([]byte("x")), tag("inner"), tag("outer"))
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("Expected outer wrapper to receive data first, got %v", order)
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func TestWriteReadCounted(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := persist.WriteCounted(&buf, func(w io.Writer) error {
+		_, err := io.WriteString(w, "hello, world")
+		return err
+	})
+	if err != nil || n != 12 {
+		t.Fatalf("Expected 12 bytes written, got %d (%v)", n, err)
+	}
+
+	n, err = persist.ReadCounted(&buf, func(r io.Reader) error {
+		_, err := io.ReadFull(r, make([]byte, 5))
+		return err
+	})
+	if err != nil || n != 5 {
+		t.Fatalf("Expected 5 bytes read, got %d (%v)", n, err)
+	}
+	if buf.String() != ", world" {
+		t.Errorf("Reader consumed more than requested, left %q", buf.String())
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	data, err := persist.Marshal(func(w io.Writer) error {
+		_, err := io.WriteString(w, "abc")
+		return err
+	})
+	if err != nil || string(data) != "abc" {
+		t.Fatalf("Expected abc, got %q (%v)", data, err)
+	}
+
+	readTwo := func(r io.Reader) error {
+		_, err := io.ReadFull(r, make([]byte, 2))
+		return err
+	}
+	if err := persist.Unmarshal(data[:2], readTwo); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := persist.Unmarshal(data, readTwo); !errors.Is(err, persist.ErrTrailingData) {
+		t.Errorf("Expected ErrTrailingData, got %v", err)
+	}
+}
+
+// Тесты конверта
+
+func writeTestEnvelope(t *testing.T, payload string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	err := persist.WriteEnvelope(&buf, persist.KindArray, "int32", func(w io.Writer) error {
+		_, err := io.WriteString(w, payload)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WriteEnvelope failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func readAll(r io.Reader) error {
+	_, err := io.ReadAll(r)
+	return err
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	data := writeTestEnvelope(t, "payload")
+
+	h, err := persist.ReadHeader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadHeader failed: %v", err)
+	}
+	if h.Version != persist.Version || h.Kind != persist.KindArray || h.Type != "int32" || h.BigEndian || h.Length != 7 {
+		t.Errorf("Unexpected header: %+v", h)
+	}
+
+	// Два конверта подряд читаются по отдельности
+	stream := bytes.NewReader(append(append([]byte(nil), data...), data...))
+	for i := 0; i < 2; i++ {
+		var got []byte
+		err := persist.ReadEnvelope(stream, persist.KindArray, "int32", func(r io.Reader) error {
+			var err error
+			got, err = io.ReadAll(r)
+			return err
+		})
+		if err != nil || string(got) != "payload" {
+			t.Fatalf("Envelope %d: expected payload, got %q (%v)", i, got, err)
+		}
+	}
+	if stream.Len() != 0 {
+		t.Errorf("Expected stream to be consumed, %d bytes left", stream.Len())
+	}
+}
+
+func TestEnvelopeErrors(t *testing.T) {
+	data := writeTestEnvelope(t, "payload")
+	const typeOffset = 9 // magic(4) version kind byteOrder typeLen(2)
+
+	modified := func(pos int, b byte) []byte {
+		out := append([]byte(nil), data...)
+		out[pos] = b
+		return out
+	}
+	tests := []struct {
+		name string
+		data []byte
+		kind persist.Kind
+		typ  string
+		want error
+	}{
+		{"BadMagic", modified(0, 'X'), persist.KindArray, "int32", persist.ErrBadMagic},
+		{"Version", modified(4, 99), persist.KindArray, "int32", persist.ErrUnsupportedVersion},
+		{"ByteOrder", modified(6, 1), persist.KindArray, "int32", persist.ErrByteOrder},
+		{"Kind", data, persist.KindStack, "int32", persist.ErrKindMismatch},
+		{"Type", data, persist.KindArray, "int64", persist.ErrTypeMismatch},
+		{"Checksum", modified(len(data)-1, 'X'), persist.KindArray, "int32", persist.ErrChecksum},
+		{"Empty", nil, persist.KindArray, "int32", persist.ErrTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := persist.ReadEnvelope(bytes.NewReader(tt.data), tt.kind, tt.typ, readAll)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Любая обрезка файла - ErrTruncated, без паники и огромных выделений
+	for cut := 1; cut < len(data); cut++ {
+		err := persist.ReadEnvelope(bytes.NewReader(data[:cut]), persist.KindArray, "int32", readAll)
+		if !errors.Is(err, persist.ErrTruncated) {
+			t.Fatalf("cut at %d: expected ErrTruncated, got %v", cut, err)
+		}
+	}
+
+	// Огромная длина в заголовке при коротком файле
+	huge := append([]byte(nil), data...)
+	lengthOffset := typeOffset + len("int32")
+	for i := 0; i < 8; i++ {
+		huge[lengthOffset+i] = 0xFF
+	}
+	if err := persist.ReadEnvelope(bytes.NewReader(huge), persist.KindArray, "int32", readAll); !errors.Is(err, persist.ErrTruncated) {
+		t.Errorf("Expected ErrTruncated for huge length, got %v", err)
+	}
+
+	// Данные, которые не были прочитаны целиком
+	err := persist.ReadEnvelope(bytes.NewReader(data), persist.KindArray, "int32", func(r io.Reader) error {
+		_, err := io.ReadFull(r, make([]byte, 3))
+		return err
+	})
+	if !errors.Is(err, persist.ErrTrailingData) {
+		t.Errorf("Expected ErrTrailingData, got %v", err)
+	}
+}
+
+// TestEnvelopeStreaming проверяет потоковую запись и чтение конверта
+func TestEnvelopeStreaming(t *testing.T) {
+	// Запись, которая дает разные данные при двух проходах
+	calls := 0
+	err := persist.WriteEnvelope(io.Discard, persist.KindArray, "int32", func(w io.Writer) error {
+		calls++
+		_, err := w.Write(bytes.Repeat([]byte{1}, calls))
+		return err
+	})
+	if !errors.Is(err, persist.ErrUnstablePayload) {
+		t.Errorf("Expected ErrUnstablePayload, got %v", err)
+	}
+
+	// Испорченный конверт: последние байты не доходят до read
+	data := writeTestEnvelope(t, "payload")
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)-1] = 'X'
+	var got []byte
+	err = persist.ReadEnvelope(bytes.NewReader(corrupted), persist.KindArray, "int32", func(r io.Reader) error {
+		var err error
+		got, err = io.ReadAll(r)
+		return err
+	})
+	if !errors.Is(err, persist.ErrChecksum) || len(got) == len("payload") {
+		t.Errorf("Expected ErrChecksum before the last byte, got %v after %q", err, got)
+	}
+
+	// Обрезанный конверт виден, даже если read заменила ошибку своей
+	own := errors.New("failed to read value")
+	err = persist.ReadEnvelope(bytes.NewReader(data[:len(data)-2]), persist.KindArray, "int32", func(r io.Reader) error {
+		if _, err := io.ReadAll(r); err != nil {
+			return own
+		}
+		return nil
+	})
+	if !errors.Is(err, persist.ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+
+	// Заявленная длина больше предела: read не вызывается
+	path := filepath.Join(t.TempDir(), "envelope.bin")
+	os.WriteFile(path, writeTestEnvelope(t, string(make([]byte, 1000))), 0644)
+	file, _ := os.Open(path)
+	defer file.Close()
+	called := false
+	err = persist.Load(file, nil, func(r io.Reader) error {
+		return persist.ReadEnvelope(r, persist.KindArray, "int32", func(io.Reader) error {
+			called = true
+			return nil
+		})
+	}, persist.WithLimit(500))
+	if !errors.Is(err, persist.ErrLimitExceeded) || called {
+		t.Errorf("Expected ErrLimitExceeded before reading, got %v (read called: %v)", err, called)
+	}
+}
+
+func TestLoadLimitAndProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.txt")
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	os.WriteFile(path, data, 0644)
+
+	load := func(read func(r io.Reader) error, opts ...persist.LoadOption) error {
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		return persist.Load(file, nil, read, opts...)
+	}
+	readAll := func(r io.Reader) error {
+		_, err := io.ReadAll(r)
+		return err
+	}
+
+	// Прогресс растет до размера файла
+	var last, total int64
+	calls := 0
+	err := load(readAll, persist.WithProgress(func(read, size int64) {
+		if read < last {
+			t.Errorf("Progress went back: %d after %d", read, last)
+		}
+		last, total = read, size
+		calls++
+	}))
+	if err != nil || last != int64(len(data)) || total != int64(len(data)) || calls == 0 {
+		t.Errorf("Progress: err=%v, last=%d, total=%d, calls=%d", err, last, total, calls)
+	}
+
+	// Предел ровно по размеру данных не мешает
+	if err := load(readAll, persist.WithLimit(int64(len(data)))); err != nil {
+		t.Errorf("Limit equal to size: %v", err)
+	}
+	if err := load(readAll, persist.WithLimit(100)); !errors.Is(err, persist.ErrLimitExceeded) {
+		t.Errorf("Expected ErrLimitExceeded, got %v", err)
+	}
+
+	// Загрузчик заменил ошибку чтения своей: превышение предела все равно видно
+	own := errors.New("failed to read size")
+	err = load(func(r io.Reader) error {
+		if _, err := io.ReadAll(r); err != nil {
+			return own
+		}
+		return nil
+	}, persist.WithLimit(100))
+	if !errors.Is(err, persist.ErrLimitExceeded) || !errors.Is(err, own) {
+		t.Errorf("Expected both ErrLimitExceeded and loader error, got %v", err)
+	}
+
+	// Предел действует на данные после decode
+	double := func(r io.Reader) (io.Reader, error) {
+		return io.MultiReader(r, bytes.NewReader(data)), nil
+	}
+	file, _ := os.Open(path)
+	defer file.Close()
+	if err := persist.Load(file, double, readAll, persist.WithLimit(int64(len(data))+10)); !errors.Is(err, persist.ErrLimitExceeded) {
+		t.Errorf("Expected ErrLimitExceeded after decode, got %v", err)
+	}
+}
+
+func TestCheckCount(t *testing.T) {
+	r := bytes.NewReader(make([]byte, 10))
+	if err := persist.CheckCount(r, 5, 2); err != nil {
+		t.Errorf("5x2 in 10 bytes: %v", err)
+	}
+	if err := persist.CheckCount(r, 6, 2); !errors.Is(err, persist.ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+	if err := persist.CheckCount(r, 1<<63, 4); !errors.Is(err, persist.ErrTruncated) {
+		t.Errorf("Overflow: expected ErrTruncated, got %v", err)
+	}
+	// Размер остатка неизвестен - проверка пропускается
+	if err := persist.CheckCount(io.MultiReader(), 1<<40, 1); err != nil {
+		t.Errorf("Unknown size: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+// Package persisttest содержит средства внедрения сбоев, сборку конвертов
+// и общие проверки для тестов сохранения: атомарность записи и сохранение
+// содержимого при потоковой записи, загрузке, вложении в gob и на случайных
+// строках в текстовом формате.
+package persisttest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// ErrInjected - ошибка, которую возвращает FailingWriter
+var ErrInjected = errors.New("injected write failure")
+
+// FailingWriter пропускает первые Limit байт в W, а затем возвращает ErrInjected
+type FailingWriter struct {
+	W       io.Writer
+	Limit   int
+	written int
+}
+
+// Write записывает данные, пока не исчерпан лимит
+func (f *FailingWriter) Write(p []byte) (int, error) {
+	remaining := f.Limit - f.written
+	if remaining <= 0 {
+		return 0, ErrInjected
+	}
+	if len(p) > remaining {
+		n, err := f.W.Write(p[:remaining])
+		f.written += n
+		if err != nil {
+			return n, err
+		}
+		return n, ErrInjected
+	}
+	n, err := f.W.Write(p)
+	f.written += n
+	return n, err
+}
+
+// FailAfter возвращает опцию persist, обрывающую запись после n байт
+func FailAfter(n int) persist.Option {
+	return persist.WithWriter(func(w io.Writer) io.Writer {
+		return &FailingWriter{W: w, Limit: n}
+	})
+}
+
+// Envelope оборачивает payload в конверт persist так же, как это делает
+// сохранение, чтобы тесты могли проверять разбор самих данных
+func Envelope[T any](kind persist.Kind, payload []byte) []byte {
+	var buf bytes.Buffer
+	persist.WriteEnvelope(&buf, kind, persist.TypeName[T](), func(w io.Writer) error {
+		_, err := w.Write(payload)
+		return err
+	})
+	return buf.Bytes()
+}
@@ -0,0 +1,109 @@
+package persisttest
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Format - методы потокового сохранения и загрузки одного формата.
+// Передаются выражениями методов, например (*Stack[int]).WriteTo.
+type Format[C any] struct {
+	Name  string
+	Write func(C, io.Writer) (int64, error)
+	Read  func(C, io.Reader) (int64, error)
+}
+
+// RoundTrip пишет src каждым форматом, читает данные в структуру из newDst
+// и сравнивает прочитанное с src через equal. Write и Read должны вернуть
+// длину данных.
+func RoundTrip[C any](t testing.TB, src C, newDst func() C, formats []Format[C], equal func(a, b C) bool) {
+	t.Helper()
+	for _, f := range formats {
+		var buf bytes.Buffer
+		n, err := f.Write(src, &buf)
+		if err != nil || n != int64(buf.Len()) {
+			t.Fatalf("%s: write returned %d, %v for %d bytes", f.Name, n, err, buf.Len())
+		}
+		data := append([]byte(nil), buf.Bytes()...)
+
+		dst := newDst()
+		if n, err := f.Read(dst, &buf); err != nil || n != int64(len(data)) {
+			t.Fatalf("%s: read returned %d, %v for %d bytes\n%q", f.Name, n, err, len(data), data)
+		}
+		if !equal(src, dst) {
+			t.Fatalf("%s: contents changed after round trip\n%q", f.Name, data)
+		}
+	}
+}
+
+// FuzzText проверяет текстовый формат на строках с пробелами, кавычками,
+// переводами строк и невалидным UTF-8: build собирает структуру из двух
+// строк, и копия, прочитанная форматом text, должна совпасть с ней. Другие
+// форматы сюда не подходят: JSON заменяет невалидный UTF-8.
+func FuzzText[C any](f *testing.F, build func(a, b string) C, newDst func() C, text Format[C], equal func(a, b C) bool) {
+	f.Add("hello world", "")
+	f.Add("\"quoted\" \\ back", "line\nbreak\t")
+	f.Add("строка", "\x00\xff")
+	f.Fuzz(func(t *testing.T, a, b string) {
+		RoundTrip(t, build(a, b), newDst, []Format[C]{text}, equal)
+	})
+}
+
+// SaveFailureKeepsFile проверяет атомарность сохранения. Каждая функция из
+// saves сначала сохраняет структуру, затем после change сохранение
+// обрывается на разных байтах: файл должен остаться прежним, а временные
+// файлы - удалены.
+func SaveFailureKeepsFile(t *testing.T, saves map[string]func(string, ...persist.Option) error, change func()) {
+	t.Helper()
+	for name, save := range saves {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "data")
+		if err := save(path); err != nil {
+			t.Fatalf("%s: initial save failed: %v", name, err)
+		}
+		original, _ := os.ReadFile(path)
+
+		change()
+		for _, limit := range []int{0, 1, len(original) / 2} {
+			if err := save(path, FailAfter(limit)); !errors.Is(err, ErrInjected) {
+				t.Fatalf("%s: expected injected error at %d, got %v", name, limit, err)
+			}
+			if data, _ := os.ReadFile(path); !bytes.Equal(data, original) {
+				t.Fatalf("%s: file changed after failed save at %d", name, limit)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+				t.Fatalf("%s: temporary files left after failed save", name)
+			}
+		}
+	}
+}
+
+// gobWrapper вкладывает структуру в поле, как это делает пользовательский код
+type gobWrapper[C any] struct {
+	Name  string
+	Value C
+}
+
+// GobRoundTrip вкладывает src в структуру, проводит ее через gob и
+// сравнивает раскодированное значение с src через equal
+func GobRoundTrip[C any](t testing.TB, src C, equal func(a, b C) bool) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&gobWrapper[C]{Name: "w", Value: src}); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	var got gobWrapper[C]
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if got.Name != "w" || !equal(src, got.Value) {
+		t.Errorf("Contents lost in gob")
+	}
+}
@@ -0,0 +1,72 @@
+package persist
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// CountingWriter передает данные в W и считает записанные байты
+type CountingWriter struct {
+	W io.Writer
+	N int64
+}
+
+// Write записывает p в W
+func (c *CountingWriter) Write(p []byte) (int, error) {
+	n, err := c.W.Write(p)
+	c.N += int64(n)
+	return n, err
+}
+
+// CountingReader читает из R и считает прочитанные байты
+type CountingReader struct {
+	R io.Reader
+	N int64
+}
+
+// Read читает из R в p
+func (c *CountingReader) Read(p []byte) (int, error) {
+	n, err := c.R.Read(p)
+	c.N += int64(n)
+	return n, err
+}
+
+// WriteCounted вызывает write и возвращает количество записанных байт.
+// Позволяет реализовать io.WriterTo поверх функции записи.
+func WriteCounted(w io.Writer, write func(w io.Writer) error) (int64, error) {
+	cw := &CountingWriter{W: w}
+	err := write(cw)
+	return cw.N, err
+}
+
+// ReadCounted вызывает read и возвращает количество прочитанных байт.
+// Позволяет реализовать io.ReaderFrom поверх функции чтения.
+func ReadCounted(r io.Reader, read func(r io.Reader) error) (int64, error) {
+	cr := &CountingReader{R: r}
+	err := read(cr)
+	return cr.N, err
+}
+
+// Marshal собирает в памяти данные, которые write пишет в поток.
+// Позволяет реализовать encoding.BinaryMarshaler поверх функции записи.
+func Marshal(write func(w io.Writer) error) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal передает data в read и проверяет, что данные прочитаны целиком.
+// Позволяет реализовать encoding.BinaryUnmarshaler поверх функции чтения.
+func Unmarshal(data []byte, read func(r io.Reader) error) error {
+	r := bytes.NewReader(data)
+	if err := read(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("%w: %d bytes", ErrTrailingData, r.Len())
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package queue
+
+// PriorityQueue реализует очередь с приоритетом на двоичной куче.
+// Первым извлекается элемент, для которого less возвращает true относительно остальных.
+type PriorityQueue[T any] struct {
+	data []T
+	less func(a, b T) bool
+}
+
+// NewPriorityQueue создает пустую очередь с заданной функцией сравнения
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{
+		data: make([]T, 0, 1),
+		less: less,
+	}
+}
+
+// siftUp поднимает элемент с индексом i к корню, пока нарушен порядок кучи
+func (pq *PriorityQueue[T]) siftUp(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !pq.less(pq.data[i], pq.data[parent]) {
+			break
+		}
+		pq.data[i], pq.data[parent] = pq.data[parent], pq.data[i]
+		i = parent
+	}
+}
+
+// siftDown опускает элемент с индексом i к листьям
+func (pq *PriorityQueue[T]) siftDown(i int) {
+	n := len(pq.data)
+	for {
+		smallest := i
+		left := 2*i + 1
+		right := left + 1
+		if left < n && pq.less(pq.data[left], pq.data[smallest]) {
+			smallest = left
+		}
+		if right < n && pq.less(pq.data[right], pq.data[smallest]) {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		pq.data[i], pq.data[smallest] = pq.data[smallest], pq.data[i]
+		i = smallest
+	}
+}
+
+// Push добавляет элемент в очередь
+func (pq *PriorityQueue[T]) Push(value T) {
+	pq.data = append(pq.data, value)
+	pq.siftUp(len(pq.data) - 1)
+}
+
+// Pop извлекает элемент с наивысшим приоритетом
+func (pq *PriorityQueue[T]) Pop() (T, error) {
+	var empty T
+	if len(pq.data) == 0 {
+		return empty, ErrQueueEmpty
+	}
+
+	top := pq.data[0]
+	last := len(pq.data) - 1
+	pq.data[0] = pq.data[last]
+	pq.data[last] = empty // Отпускаем ссылку для сборщика мусора
+	pq.data = pq.data[:last]
+	if last > 0 {
+		pq.siftDown(0)
+	}
+	return top, nil
+}
+
+// Peek возвращает элемент с наивысшим приоритетом без удаления
+func (pq *PriorityQueue[T]) Peek() (T, error) {
+	if len(pq.data) == 0 {
+		var empty T
+		return empty, ErrQueueEmpty
+	}
+	return pq.data[0], nil
+}
+
+// IsEmpty проверяет, пуста ли очередь
+func (pq *PriorityQueue[T]) IsEmpty() bool {
+	return len(pq.data) == 0
+}
+
+// Size возвращает количество элементов
+func (pq *PriorityQueue[T]) Size() int {
+	return len(pq.data)
+}
+
+// Clear удаляет все элементы
+func (pq *PriorityQueue[T]) Clear() {
+	pq.data = make([]T, 0, 1)
+}
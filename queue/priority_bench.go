@@ -0,0 +1,22 @@
+package queue
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// BenchmarkPriorityPushPop измеряет вставку и извлечение из кучи в случайном порядке.
+func BenchmarkPriorityPushPop(b *testing.B) {
+	values := rand.New(rand.NewSource(1)).Perm(OpsCount)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		pq := NewPriorityQueue(func(a, b int) bool { return a < b })
+		for _, v := range values {
+			pq.Push(v)
+		}
+		for !pq.IsEmpty() {
+			_, _ = pq.Pop()
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package queue
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestPriorityQueueEmpty(t *testing.T) {
+	pq := NewPriorityQueue(func(a, b int) bool { return a < b })
+
+	if !pq.IsEmpty() || pq.Size() != 0 {
+		t.Error("new priority queue should be empty")
+	}
+	if _, err := pq.Pop(); err != ErrQueueEmpty {
+		t.Errorf("expected ErrQueueEmpty on Pop empty, got %v", err)
+	}
+	if _, err := pq.Peek(); err != ErrQueueEmpty {
+		t.Errorf("expected ErrQueueEmpty on Peek empty, got %v", err)
+	}
+}
+
+func TestPriorityQueueOrder(t *testing.T) {
+	pq := NewPriorityQueue(func(a, b int) bool { return a < b })
+
+	values := rand.New(rand.NewSource(1)).Perm(100)
+	for _, v := range values {
+		pq.Push(v)
+	}
+	if pq.Size() != 100 {
+		t.Fatalf("expected size 100, got %d", pq.Size())
+	}
+
+	top, err := pq.Peek()
+	if err != nil || top != 0 {
+		t.Errorf("Peek failed. got %d, err %v", top, err)
+	}
+
+	sort.Ints(values)
+	for i, want := range values {
+		got, err := pq.Pop()
+		if err != nil || got != want {
+			t.Fatalf("Pop #%d: expected %d, got %d (err %v)", i, want, got, err)
+		}
+	}
+	if !pq.IsEmpty() {
+		t.Error("queue should be empty after popping everything")
+	}
+}
+
+func TestPriorityQueueMaxHeapAndClear(t *testing.T) {
+	pq := NewPriorityQueue(func(a, b string) bool { return a > b })
+	pq.Push("b")
+	pq.Push("c")
+	pq.Push("a")
+
+	if v, _ := pq.Pop(); v != "c" {
+		t.Errorf("expected c, got %s", v)
+	}
+
+	pq.Clear()
+	if !pq.IsEmpty() {
+		t.Error("queue should be empty after Clear")
+	}
+	pq.Push("z")
+	if v, _ := pq.Peek(); v != "z" {
+		t.Errorf("expected z after Clear and Push, got %s", v)
+	}
+}
@@ -1,252 +1,460 @@
-package queue
-
-import (
-	"encoding/gob"
-	"errors"
-	"fmt"
-	"io"
-	"os"
-)
-
-// Ошибки, которые может вернуть очередь
-var (
-	ErrQueueEmpty  = errors.New("queue is empty")
-	ErrFileCorrupt = errors.New("file corrupted or invalid data size")
-)
-
-// Queue реализует кольцевой буфер
-type Queue[T any] struct {
-	data     []T
-	head     int
-	tail     int
-	count    int // Текущее количество элементов (size)
-	capacity int // Вместимость
-}
-
-// NewQueue создает новую очередь с заданной начальной вместимостью
-func NewQueue[T any](initialCap int) *Queue[T] {
-	if initialCap < 1 {
-		initialCap = 1
-	}
-	return &Queue[T]{
-		data:     make([]T, initialCap),
-		capacity: initialCap,
-		head:     0,
-		tail:     0,
-		count:    0,
-	}
-}
-
-// resize увеличивает размер внутреннего массива в 2 раза
-func (q *Queue[T]) resize() {
-	newCapacity := q.capacity * 2
-	if newCapacity == 0 {
-		newCapacity = 1
-	}
-	newData := make([]T, newCapacity)
-
-	// Копируем элементы, "распрямляя" кольцо
-	for i := 0; i < q.count; i++ {
-		newData[i] = q.data[(q.head+i)%q.capacity]
-	}
-
-	q.data = newData
-	q.capacity = newCapacity
-	q.head = 0
-	q.tail = q.count
-}
-
-// Push добавляет элемент в конец очереди
-func (q *Queue[T]) Push(value T) {
-	if q.count >= q.capacity {
-		q.resize()
-	}
-	q.data[q.tail] = value
-	q.tail = (q.tail + 1) % q.capacity
-	q.count++
-}
-
-// Pop извлекает элемент из начала очереди
-func (q *Queue[T]) Pop() (T, error) {
-	var empty T
-	if q.count == 0 {
-		return empty, ErrQueueEmpty
-	}
-
-	value := q.data[q.head]
-	var zero T
-	q.data[q.head] = zero
-
-	q.head = (q.head + 1) % q.capacity
-	q.count--
-	return value, nil
-}
-
-// Get возвращает первый элемент без удаления
-func (q *Queue[T]) Get() (T, error) {
-	if q.count == 0 {
-		var empty T
-		return empty, ErrQueueEmpty
-	}
-	return q.data[q.head], nil
-}
-
-// IsEmpty проверяет, пуста ли очередь
-func (q *Queue[T]) IsEmpty() bool {
-	return q.count == 0
-}
-
-// Size возвращает количество элементов
-func (q *Queue[T]) Size() int {
-	return q.count
-}
-
-// Clone создает глубокую копию очереди
-func (q *Queue[T]) Clone() *Queue[T] {
-	newQ := &Queue[T]{
-		capacity: q.capacity,
-		count:    q.count,
-		head:     q.head,
-		tail:     q.tail,
-		data:     make([]T, q.capacity),
-	}
-	copy(newQ.data, q.data)
-	return newQ
-}
-
-// Print выводит содержимое очереди
-func (q *Queue[T]) Print(w io.Writer) {
-	fmt.Fprint(w, "Содержимое очереди (от головы к хвосту): ")
-	if q.count == 0 {
-		fmt.Fprintln(w, "пусто")
-		return
-	}
-	for i := 0; i < q.count; i++ {
-		val := q.data[(q.head+i)%q.capacity]
-		fmt.Fprintf(w, "%v ", val)
-	}
-	fmt.Fprintln(w)
-}
-
-// Сериализация (Text)
-
-// SaveText сохраняет очередь в текстовый файл.
-func (q *Queue[T]) SaveText(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Записываем размер
-	if _, err := fmt.Fprintln(file, q.count); err != nil {
-		return err
-	}
-
-	// Записываем элементы
-	for i := 0; i < q.count; i++ {
-		val := q.data[(q.head+i)%q.capacity]
-		if _, err := fmt.Fprintln(file, val); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// LoadText загружает очередь из текстового файла
-func (q *Queue[T]) LoadText(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	var newSize int
-	if _, err := fmt.Fscan(file, &newSize); err != nil {
-		return fmt.Errorf("error reading size: %w", err)
-	}
-
-	// Сбрасываем текущую очередь
-	q.head = 0
-	q.tail = 0
-	q.count = 0
-	// capacity оставляем или можем пересоздать slice, если нужно
-
-	for i := 0; i < newSize; i++ {
-		var val T
-		// fmt.Fscan работает хорошо для базовых типов (int, string, float)
-		// Для сложных структур потребуется кастомная логика.
-		if _, err := fmt.Fscan(file, &val); err != nil {
-			// Если достигли конца файла раньше времени или ошибка парсинга
-			return ErrFileCorrupt
-		}
-		q.Push(val)
-	}
-
-	if q.count != newSize {
-		return ErrFileCorrupt
-	}
-
-	return nil
-}
-
-// Сериализация (Binary)
-
-// SaveBinary сохраняет данные
-func (q *Queue[T]) SaveBinary(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	encoder := gob.NewEncoder(file)
-
-	// Пишем размер
-	if err := encoder.Encode(q.count); err != nil {
-		return err
-	}
-
-	// Пишем элементы в логическом порядке
-	for i := 0; i < q.count; i++ {
-		val := q.data[(q.head+i)%q.capacity]
-		if err := encoder.Encode(val); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// LoadBinary загружает данные используя encoding/gob
-func (q *Queue[T]) LoadBinary(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	decoder := gob.NewDecoder(file)
-
-	var newSize int
-	if err := decoder.Decode(&newSize); err != nil {
-		return fmt.Errorf("error reading header: %w", err)
-	}
-
-	// Сброс
-	q.head = 0
-	q.tail = 0
-	q.count = 0
-
-	for i := 0; i < newSize; i++ {
-		var val T
-		if err := decoder.Decode(&val); err != nil {
-			if err == io.EOF {
-				return ErrFileCorrupt
-			}
-			return err
-		}
-		q.Push(val)
-	}
-
-	return nil
-}
+package queue
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Ошибки, которые может вернуть очередь
+var (
+	ErrQueueEmpty  = errors.New("queue is empty")
+	ErrFileCorrupt = errors.New("file corrupted or invalid data size")
+)
+
+// Queue реализует кольцевой буфер
+type Queue[T any] struct {
+	data     []T
+	head     int
+	tail     int
+	count    int // Текущее количество элементов (size)
+	capacity int // Вместимость
+}
+
+// NewQueue создает новую очередь с заданной начальной вместимостью
+func NewQueue[T any](initialCap int) *Queue[T] {
+	if initialCap < 1 {
+		initialCap = 1
+	}
+	return &Queue[T]{
+		data:     make([]T, initialCap),
+		capacity: initialCap,
+		head:     0,
+		tail:     0,
+		count:    0,
+	}
+}
+
+// resize увеличивает размер внутреннего массива в 2 раза
+func (q *Queue[T]) resize() {
+	newCapacity := q.capacity * 2
+	if newCapacity == 0 {
+		newCapacity = 1
+	}
+	newData := make([]T, newCapacity)
+
+	// Копируем элементы, "распрямляя" кольцо
+	for i := 0; i < q.count; i++ {
+		newData[i] = q.data[(q.head+i)%q.capacity]
+	}
+
+	q.data = newData
+	q.capacity = newCapacity
+	q.head = 0
+	q.tail = q.count
+}
+
+// Push добавляет элемент в конец очереди
+func (q *Queue[T]) Push(value T) {
+	if q.count >= q.capacity {
+		q.resize()
+	}
+	q.data[q.tail] = value
+	q.tail = (q.tail + 1) % q.capacity
+	q.count++
+}
+
+// Pop извлекает элемент из начала очереди
+func (q *Queue[T]) Pop() (T, error) {
+	var empty T
+	if q.count == 0 {
+		return empty, ErrQueueEmpty
+	}
+
+	value := q.data[q.head]
+	var zero T
+	q.data[q.head] = zero
+
+	q.head = (q.head + 1) % q.capacity
+	q.count--
+	return value, nil
+}
+
+// Get возвращает первый элемент без удаления
+func (q *Queue[T]) Get() (T, error) {
+	if q.count == 0 {
+		var empty T
+		return empty, ErrQueueEmpty
+	}
+	return q.data[q.head], nil
+}
+
+// IsEmpty проверяет, пуста ли очередь
+func (q *Queue[T]) IsEmpty() bool {
+	return q.count == 0
+}
+
+// Size возвращает количество элементов
+func (q *Queue[T]) Size() int {
+	return q.count
+}
+
+// Clone создает глубокую копию очереди
+func (q *Queue[T]) Clone() *Queue[T] {
+	newQ := &Queue[T]{
+		capacity: q.capacity,
+		count:    q.count,
+		head:     q.head,
+		tail:     q.tail,
+		data:     make([]T, q.capacity),
+	}
+	copy(newQ.data, q.data)
+	return newQ
+}
+
+// Print выводит содержимое очереди
+func (q *Queue[T]) Print(w io.Writer) {
+	fmt.Fprint(w, "Содержимое очереди (от головы к хвосту): ")
+	if q.count == 0 {
+		fmt.Fprintln(w, "пусто")
+		return
+	}
+	for i := 0; i < q.count; i++ {
+		val := q.data[(q.head+i)%q.capacity]
+		fmt.Fprintf(w, "%v ", val)
+	}
+	fmt.Fprintln(w)
+}
+
+// Сериализация (Text)
+
+// SaveText сохраняет очередь в текстовый файл.
+func (q *Queue[T]) SaveText(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, q.writeText, opts...)
+}
+
+// LoadText загружает очередь из текстового файла
+func (q *Queue[T]) LoadText(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, q.readText, opts...)
+}
+
+// WriteTextTo записывает очередь в w в текстовом формате
+func (q *Queue[T]) WriteTextTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, q.writeText)
+}
+
+// ReadTextFrom читает очередь в текстовом формате из r
+func (q *Queue[T]) ReadTextFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, q.readText)
+}
+
+func (q *Queue[T]) writeText(w io.Writer) error {
+	// Записываем размер
+	if _, err := fmt.Fprintln(w, q.count); err != nil {
+		return err
+	}
+
+	// Записываем элементы
+	for i := 0; i < q.count; i++ {
+		tok, err := codec.FormatText(q.data[(q.head+i)%q.capacity])
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, tok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *Queue[T]) readText(r io.Reader) error {
+	s := codec.NewTextScanner(r)
+	newSize, err := codec.ScanText[int](s)
+	if err != nil {
+		return fmt.Errorf("error reading size: %w", err)
+	}
+
+	// Сбрасываем текущую очередь
+	q.head = 0
+	q.tail = 0
+	q.count = 0
+	// capacity оставляем или можем пересоздать slice, если нужно
+
+	for i := 0; i < newSize; i++ {
+		val, err := codec.ScanText[T](s)
+		if err != nil {
+			// Если достигли конца файла раньше времени или ошибка парсинга
+			return ErrFileCorrupt
+		}
+		q.Push(val)
+	}
+
+	if q.count != newSize {
+		return ErrFileCorrupt
+	}
+
+	return nil
+}
+
+// Сериализация (Binary)
+
+// SaveBinary сохраняет данные
+func (q *Queue[T]) SaveBinary(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, q.writeBinary, opts...)
+}
+
+// LoadBinary загружает данные используя encoding/gob
+func (q *Queue[T]) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, q.readBinary, opts...)
+}
+
+// WriteTo записывает очередь в w в бинарном формате (io.WriterTo)
+func (q *Queue[T]) WriteTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, q.writeBinary)
+}
+
+// ReadFrom читает очередь в бинарном формате из r (io.ReaderFrom)
+func (q *Queue[T]) ReadFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, q.readBinary)
+}
+
+// writeBinary пишет очередь в конверте persist с видом и типом элементов
+func (q *Queue[T]) writeBinary(w io.Writer) error {
+	return persist.WriteEnvelope(w, persist.KindQueue, persist.TypeName[T](), q.writePayload)
+}
+
+// readBinary проверяет конверт и читает очередь
+func (q *Queue[T]) readBinary(r io.Reader) error {
+	return persist.ReadEnvelope(r, persist.KindQueue, persist.TypeName[T](), q.readPayload)
+}
+
+func (q *Queue[T]) writePayload(w io.Writer) error {
+	encoder := gob.NewEncoder(w)
+
+	// Пишем размер
+	if err := encoder.Encode(q.count); err != nil {
+		return err
+	}
+
+	// Пишем элементы в логическом порядке
+	for i := 0; i < q.count; i++ {
+		val := q.data[(q.head+i)%q.capacity]
+		if err := encoder.Encode(val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *Queue[T]) readPayload(r io.Reader) error {
+	decoder := gob.NewDecoder(r)
+
+	var newSize int
+	if err := decoder.Decode(&newSize); err != nil {
+		return fmt.Errorf("error reading header: %w", err)
+	}
+
+	// Очередь меняется только после чтения всех элементов
+	values := make([]T, 0, min(max(newSize, 0), codec.ChunkSize))
+	for i := 0; i < newSize; i++ {
+		var val T
+		if err := decoder.Decode(&val); err != nil {
+			if err == io.EOF {
+				return ErrFileCorrupt
+			}
+			return err
+		}
+		values = append(values, val)
+	}
+
+	q.head = 0
+	q.tail = 0
+	q.count = 0
+	for _, val := range values {
+		q.Push(val)
+	}
+	return nil
+}
+
+// Срезы и последовательности
+
+// FromSlice создает очередь из копии s: s[0] оказывается в голове
+func FromSlice[T any](s []T) *Queue[T] {
+	q := NewQueue[T](len(s))
+	copy(q.data, s)
+	q.count = len(s)
+	q.tail = q.count % q.capacity
+	return q
+}
+
+// FromSeq создает очередь из элементов seq в порядке обхода
+func FromSeq[T any](seq iter.Seq[T]) *Queue[T] {
+	q := NewQueue[T](1)
+	for v := range seq {
+		q.Push(v)
+	}
+	return q
+}
+
+// ToSlice возвращает элементы очереди от головы к хвосту
+func (q *Queue[T]) ToSlice() []T {
+	s := make([]T, q.count)
+	for i := range s {
+		s[i] = q.data[(q.head+i)%q.capacity]
+	}
+	return s
+}
+
+// Итераторы
+//
+// Итераторы не копируют очередь: шаг i читает элемент на i-й позиции от
+// текущей головы и останавливается, когда i >= Size(). Поэтому Push в теле
+// цикла All продлевает обход, а Pop сдвигает голову, и следующий элемент
+// будет пропущен. Backward начинает с хвоста на момент вызова и пропускает
+// позиции, которых уже нет. Очередь не синхронизирована: изменение из другой
+// горутины во время обхода - гонка данных.
+
+// at возвращает элемент на позиции i от головы
+func (q *Queue[T]) at(i int) T {
+	return q.data[(q.head+i)%q.capacity]
+}
+
+// All возвращает итератор по элементам от головы к хвосту
+func (q *Queue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < q.count; i++ {
+			if !yield(q.at(i)) {
+				return
+			}
+		}
+	}
+}
+
+// Backward возвращает итератор по элементам от хвоста к голове
+func (q *Queue[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := q.count - 1; i >= 0; i-- {
+			if i >= q.count {
+				continue
+			}
+			if !yield(q.at(i)) {
+				return
+			}
+		}
+	}
+}
+
+// Enumerate возвращает итератор по парам (позиция от головы, элемент)
+func (q *Queue[T]) Enumerate() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < q.count; i++ {
+			if !yield(i, q.at(i)) {
+				return
+			}
+		}
+	}
+}
+
+// encoding и gob
+
+// MarshalBinary возвращает очередь в бинарном формате с конвертом
+// (encoding.BinaryMarshaler), поэтому ее можно вкладывать в другие структуры
+func (q *Queue[T]) MarshalBinary() ([]byte, error) {
+	return persist.Marshal(q.writeBinary)
+}
+
+// UnmarshalBinary читает очередь из данных MarshalBinary (encoding.BinaryUnmarshaler).
+// Данные должны содержать ровно один конверт.
+func (q *Queue[T]) UnmarshalBinary(data []byte) error {
+	return persist.Unmarshal(data, q.readBinary)
+}
+
+// GobEncode кодирует очередь для encoding/gob (gob.GobEncoder)
+func (q *Queue[T]) GobEncode() ([]byte, error) {
+	return q.MarshalBinary()
+}
+
+// GobDecode читает очередь, закодированную GobEncode (gob.GobDecoder)
+func (q *Queue[T]) GobDecode(data []byte) error {
+	return q.UnmarshalBinary(data)
+}
+
+// JSON
+
+// MarshalJSON кодирует очередь как JSON-массив от головы к хвосту (json.Marshaler)
+func (q *Queue[T]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := q.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON читает очередь из JSON-массива (json.Unmarshaler).
+// Первый элемент массива становится головой. При ошибке очередь не меняется.
+func (q *Queue[T]) UnmarshalJSON(data []byte) error {
+	return q.readJSON(bytes.NewReader(data))
+}
+
+// WriteJSONTo пишет очередь в w как JSON-массив, не собирая ее в памяти
+func (q *Queue[T]) WriteJSONTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, q.writeJSON)
+}
+
+// ReadJSONFrom читает очередь из JSON-массива в r до конца потока
+func (q *Queue[T]) ReadJSONFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, q.readJSON)
+}
+
+func (q *Queue[T]) writeJSON(w io.Writer) error {
+	return codec.EncodeJSONArray(w, func(emit func(T) error) error {
+		for i := 0; i < q.count; i++ {
+			if err := emit(q.data[(q.head+i)%q.capacity]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (q *Queue[T]) readJSON(r io.Reader) error {
+	data := make([]T, 0, 1)
+	err := codec.ReadJSON(r, func(dec *json.Decoder) error {
+		return codec.DecodeJSONArray(dec, "$", func(val T) error {
+			data = append(data, val)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Элементы уже лежат по порядку, поэтому кольцо начинается с нуля
+	q.data = data[:cap(data)]
+	q.capacity = cap(data)
+	q.head = 0
+	q.count = len(data)
+	q.tail = q.count % q.capacity
+	return nil
+}
@@ -1,352 +1,548 @@
-package queue
-
-import (
-	"bytes"
-	"os"
-	"testing"
-)
-
-// Helper Functions
-
-// createTempFile создает временный файл и возвращает путь и функцию очистки
-func createTempFile(t *testing.T, pattern string) (string, func()) {
-	f, err := os.CreateTemp("", pattern)
-	if err != nil {
-		t.Fatalf("failed to create temp file: %v", err)
-	}
-	f.Close()
-	return f.Name(), func() {
-		os.Remove(f.Name())
-	}
-}
-
-// Core Logic Tests
-
-func TestConstructor(t *testing.T) {
-	q1 := NewQueue[int](10)
-	if q1.capacity != 10 {
-		t.Errorf("expected capacity 10, got %d", q1.capacity)
-	}
-
-	// Test normalization of invalid capacity
-	q2 := NewQueue[int](-5)
-	if q2.capacity != 1 {
-		t.Errorf("expected capacity 1 (from <1), got %d", q2.capacity)
-	}
-	if !q2.IsEmpty() {
-		t.Error("new queue should be empty")
-	}
-}
-
-func TestPushPopGet(t *testing.T) {
-	q := NewQueue[int](2)
-
-	// Test Get on empty
-	if _, err := q.Get(); err != ErrQueueEmpty {
-		t.Errorf("expected ErrQueueEmpty on Get empty, got %v", err)
-	}
-
-	// Test Pop on empty
-	if _, err := q.Pop(); err != ErrQueueEmpty {
-		t.Errorf("expected ErrQueueEmpty on Pop empty, got %v", err)
-	}
-
-	// Normal flow
-	q.Push(1)
-	q.Push(2)
-
-	val, err := q.Get()
-	if err != nil || val != 1 {
-		t.Errorf("Get failed. got %d, err %v", val, err)
-	}
-
-	// Pop 1
-	val, err = q.Pop()
-	if err != nil || val != 1 {
-		t.Errorf("Pop failed. got %d, err %v", val, err)
-	}
-
-	// Size check
-	if q.Size() != 1 {
-		t.Errorf("expected size 1, got %d", q.Size())
-	}
-}
-
-func TestResizeLogic(t *testing.T) {
-	// Standard resize
-	q := NewQueue[int](2)
-	q.Push(1)
-	q.Push(2)
-	q.Push(3)
-
-	if q.capacity != 4 {
-		t.Errorf("expected capacity 4, got %d", q.capacity)
-	}
-	if q.Size() != 3 {
-		t.Errorf("expected count 3, got %d", q.Size())
-	}
-
-	// Resize with circular wrapping
-	q2 := NewQueue[int](2)
-	q2.Push(10) // [10, nil] head=0, tail=1
-	q2.Pop()    // [nil, nil] head=1, tail=1
-	q2.Push(20) // [nil, 20] head=1, tail=0 (wrapped)
-	q2.Push(30) // [30, 20] head=1, tail=1 (full)
-
-	// Trigger resize while wrapped
-	q2.Push(40)
-
-	expected := []int{20, 30, 40}
-	for i, exp := range expected {
-		val, err := q2.Pop()
-		if err != nil || val != exp {
-			t.Errorf("Index %d: expected %d, got %d (err: %v)", i, exp, val, err)
-		}
-	}
-
-	// Edge Case: Resize from 0
-	qZero := NewQueue[int](1)
-	qZero.capacity = 0 // Manually break it to test recovery
-	qZero.data = []int{}
-	qZero.resize()
-	if qZero.capacity != 1 {
-		t.Errorf("Resize from 0 should result in 1, got %d", qZero.capacity)
-	}
-}
-
-func TestClone(t *testing.T) {
-	q := NewQueue[string](5)
-	q.Push("A")
-	q.Push("B")
-
-	// Move head forward to test offset copying
-	q.Pop() // Remove A
-	q.Push("C")
-
-	clone := q.Clone()
-
-	// Check independent memory
-	clone.Push("D")
-	if q.Size() == clone.Size() {
-		t.Error("Modifying clone should not affect original size")
-	}
-
-	// Verify content of clone
-	v1, _ := clone.Pop() // B
-	v2, _ := clone.Pop() // C
-	v3, _ := clone.Pop() // D
-
-	if v1 != "B" || v2 != "C" || v3 != "D" {
-		t.Error("Clone data order mismatch")
-	}
-}
-
-func TestPrint(t *testing.T) {
-	var buf bytes.Buffer
-	q := NewQueue[int](5)
-
-	// Empty
-	q.Print(&buf)
-	if buf.String() != "Содержимое очереди (от головы к хвосту): пусто\n" {
-		t.Errorf("Unexpected print output: %q", buf.String())
-	}
-
-	// Items
-	buf.Reset()
-	q.Push(1)
-	q.Push(2)
-	q.Print(&buf)
-	expected := "Содержимое очереди (от головы к хвосту): 1 2 \n"
-	if buf.String() != expected {
-		t.Errorf("Expected %q, got %q", expected, buf.String())
-	}
-}
-
-// Text Serialization Tests
-
-func TestSaveText(t *testing.T) {
-	q := NewQueue[int](5)
-	q.Push(10)
-	q.Push(20)
-
-	// Success
-	filename, cleanup := createTempFile(t, "queue_text_*.txt")
-	defer cleanup()
-
-	if err := q.SaveText(filename); err != nil {
-		t.Errorf("SaveText failed: %v", err)
-	}
-
-	// Error: Invalid path
-	if err := q.SaveText(""); err == nil {
-		t.Error("Expected error saving to empty filename")
-	}
-}
-
-func TestLoadText(t *testing.T) {
-	// Success Case
-	{
-		filename, cleanup := createTempFile(t, "good_queue.txt")
-		defer cleanup()
-		content := "2\n100\n200\n" // Size 2, items 100, 200
-		os.WriteFile(filename, []byte(content), 0644)
-
-		q := NewQueue[int](1)
-		if err := q.LoadText(filename); err != nil {
-			t.Fatalf("LoadText failed: %v", err)
-		}
-		if q.Size() != 2 {
-			t.Errorf("Expected size 2, got %d", q.Size())
-		}
-		val, _ := q.Pop()
-		if val != 100 {
-			t.Errorf("Expected 100, got %d", val)
-		}
-	}
-
-	// Error: File not found
-	{
-		q := NewQueue[int](1)
-		if err := q.LoadText("non_existent_file_XYZ.txt"); err == nil {
-			t.Error("Expected error for missing file")
-		}
-	}
-
-	// Error: Corrupt Header (Size is not int)
-	{
-		filename, cleanup := createTempFile(t, "bad_header.txt")
-		defer cleanup()
-		os.WriteFile(filename, []byte("NotANumber\n"), 0644)
-
-		q := NewQueue[int](1)
-		err := q.LoadText(filename)
-		if err == nil {
-			t.Error("Expected error for bad header")
-		}
-		if err != nil && err.Error()[:13] != "error reading" {
-			// Just checking we wrap the error roughly as expected
-			t.Logf("Got expected error: %v", err)
-		}
-	}
-
-	// Error: Corrupt Data (Type mismatch)
-	{
-		filename, cleanup := createTempFile(t, "bad_data_type.txt")
-		defer cleanup()
-		os.WriteFile(filename, []byte("2\n100\nNotAnInt"), 0644)
-
-		q := NewQueue[int](1)
-		if err := q.LoadText(filename); err != ErrFileCorrupt {
-			t.Errorf("Expected ErrFileCorrupt for type mismatch, got %v", err)
-		}
-	}
-
-	// Error: EOF before all items read (Size 3, but only 1 item)
-	{
-		filename, cleanup := createTempFile(t, "short_file.txt")
-		defer cleanup()
-		os.WriteFile(filename, []byte("3\n100\n"), 0644)
-
-		q := NewQueue[int](1)
-		if err := q.LoadText(filename); err != ErrFileCorrupt {
-			t.Errorf("Expected ErrFileCorrupt for premature EOF, got %v", err)
-		}
-	}
-}
-
-// Binary Serialization Tests
-
-func TestSaveBinary(t *testing.T) {
-	q := NewQueue[float64](5)
-	q.Push(1.1)
-
-	// Success
-	filename, cleanup := createTempFile(t, "queue_bin_*.gob")
-	defer cleanup()
-	if err := q.SaveBinary(filename); err != nil {
-		t.Errorf("SaveBinary failed: %v", err)
-	}
-
-	// Error: File creation
-	if err := q.SaveBinary(""); err == nil {
-		t.Error("Expected error on invalid filename")
-	}
-
-	// Error: Encoding failure
-	qBad := NewQueue[func()](1)
-	qBad.Push(func() {})
-
-	fnameBad, cl := createTempFile(t, "bad_gob.gob")
-	defer cl()
-
-	// The header (int) will write fine, but the item (func) will fail
-	if err := qBad.SaveBinary(fnameBad); err == nil {
-		t.Error("Expected gob encoding error for function type")
-	}
-}
-
-func TestLoadBinary(t *testing.T) {
-	// Success
-	{
-		filename, cleanup := createTempFile(t, "queue_good.gob")
-		defer cleanup()
-
-		// Save valid data first
-		qOrig := NewQueue[string](2)
-		qOrig.Push("Test")
-		qOrig.SaveBinary(filename)
-
-		qLoad := NewQueue[string](1)
-		if err := qLoad.LoadBinary(filename); err != nil {
-			t.Fatalf("LoadBinary failed: %v", err)
-		}
-		if val, _ := qLoad.Pop(); val != "Test" {
-			t.Error("Data mismatch")
-		}
-	}
-
-	// Error: Open file
-	{
-		q := NewQueue[int](1)
-		if err := q.LoadBinary("missing_bin.gob"); err == nil {
-			t.Error("Expected error opening missing file")
-		}
-	}
-
-	// Error: Header decode (Empty file or garbage)
-	{
-		filename, cleanup := createTempFile(t, "garbage.gob")
-		defer cleanup()
-		os.WriteFile(filename, []byte("NOT_GOB_DATA"), 0644)
-
-		q := NewQueue[int](1)
-		if err := q.LoadBinary(filename); err == nil {
-			t.Error("Expected error decoding garbage header")
-		}
-	}
-
-	// Error: Data decode (EOF / corruption)
-	{
-		// Create a valid file, then truncate it to simulate partial write
-		filename, cleanup := createTempFile(t, "truncated.gob")
-		defer cleanup()
-
-		qOrig := NewQueue[int](2)
-		qOrig.Push(1)
-		qOrig.Push(2)
-		qOrig.SaveBinary(filename)
-
-		// Truncate file: Keep header (size) but cut off data
-		data, _ := os.ReadFile(filename)
-		// Write back only first few bytes
-		os.WriteFile(filename, data[:10], 0644)
-
-		qLoad := NewQueue[int](1)
-		err := qLoad.LoadBinary(filename)
-
-		// Depending on where it cut, it might be unexpected EOF or a decoding error.
-		if err == nil {
-			t.Error("Expected error loading truncated file")
-		}
-	}
-}
+package queue
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+	"github.com/D4ROVAN1E/LR_3_Go/persist/persisttest"
+)
+
+// Helper Functions
+
+// createTempFile создает временный файл и возвращает путь и функцию очистки
+func createTempFile(t *testing.T, pattern string) (string, func()) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	f.Close()
+	return f.Name(), func() {
+		os.Remove(f.Name())
+	}
+}
+
+// Core Logic Tests
+
+func TestConstructor(t *testing.T) {
+	q1 := NewQueue[int](10)
+	if q1.capacity != 10 {
+		t.Errorf("expected capacity 10, got %d", q1.capacity)
+	}
+
+	// Test normalization of invalid capacity
+	q2 := NewQueue[int](-5)
+	if q2.capacity != 1 {
+		t.Errorf("expected capacity 1 (from <1), got %d", q2.capacity)
+	}
+	if !q2.IsEmpty() {
+		t.Error("new queue should be empty")
+	}
+}
+
+func TestPushPopGet(t *testing.T) {
+	q := NewQueue[int](2)
+
+	// Test Get on empty
+	if _, err := q.Get(); err != ErrQueueEmpty {
+		t.Errorf("expected ErrQueueEmpty on Get empty, got %v", err)
+	}
+
+	// Test Pop on empty
+	if _, err := q.Pop(); err != ErrQueueEmpty {
+		t.Errorf("expected ErrQueueEmpty on Pop empty, got %v", err)
+	}
+
+	// Normal flow
+	q.Push(1)
+	q.Push(2)
+
+	val, err := q.Get()
+	if err != nil || val != 1 {
+		t.Errorf("Get failed. got %d, err %v", val, err)
+	}
+
+	// Pop 1
+	val, err = q.Pop()
+	if err != nil || val != 1 {
+		t.Errorf("Pop failed. got %d, err %v", val, err)
+	}
+
+	// Size check
+	if q.Size() != 1 {
+		t.Errorf("expected size 1, got %d", q.Size())
+	}
+}
+
+func TestResizeLogic(t *testing.T) {
+	// Standard resize
+	q := NewQueue[int](2)
+	q.Push(1)
+	q.Push(2)
+	q.Push(3)
+
+	if q.capacity != 4 {
+		t.Errorf("expected capacity 4, got %d", q.capacity)
+	}
+	if q.Size() != 3 {
+		t.Errorf("expected count 3, got %d", q.Size())
+	}
+
+	// Resize with circular wrapping
+	q2 := NewQueue[int](2)
+	q2.Push(10) // [10, nil] head=0, tail=1
+	q2.Pop()    // [nil, nil] head=1, tail=1
+	q2.Push(20) // [nil, 20] head=1, tail=0 (wrapped)
+	q2.Push(30) // [30, 20] head=1, tail=1 (full)
+
+	// Trigger resize while wrapped
+	q2.Push(40)
+
+	expected := []int{20, 30, 40}
+	for i, exp := range expected {
+		val, err := q2.Pop()
+		if err != nil || val != exp {
+			t.Errorf("Index %d: expected %d, got %d (err: %v)", i, exp, val, err)
+		}
+	}
+
+	// Edge Case: Resize from 0
+	qZero := NewQueue[int](1)
+	qZero.capacity = 0 // Manually break it to test recovery
+	qZero.data = []int{}
+	qZero.resize()
+	if qZero.capacity != 1 {
+		t.Errorf("Resize from 0 should result in 1, got %d", qZero.capacity)
+	}
+}
+
+func TestClone(t *testing.T) {
+	q := NewQueue[string](5)
+	q.Push("A")
+	q.Push("B")
+
+	// Move head forward to test offset copying
+	q.Pop() // Remove A
+	q.Push("C")
+
+	clone := q.Clone()
+
+	// Check independent memory
+	clone.Push("D")
+	if q.Size() == clone.Size() {
+		t.Error("Modifying clone should not affect original size")
+	}
+
+	// Verify content of clone
+	v1, _ := clone.Pop() // B
+	v2, _ := clone.Pop() // C
+	v3, _ := clone.Pop() // D
+
+	if v1 != "B" || v2 != "C" || v3 != "D" {
+		t.Error("Clone data order mismatch")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	var buf bytes.Buffer
+	q := NewQueue[int](5)
+
+	// Empty
+	q.Print(&buf)
+	if buf.String() != "Содержимое очереди (от головы к хвосту): пусто\n" {
+		t.Errorf("Unexpected print output: %q", buf.String())
+	}
+
+	// Items
+	buf.Reset()
+	q.Push(1)
+	q.Push(2)
+	q.Print(&buf)
+	expected := "Содержимое очереди (от головы к хвосту): 1 2 \n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+// Text Serialization Tests
+
+func TestSaveText(t *testing.T) {
+	q := NewQueue[int](5)
+	q.Push(10)
+	q.Push(20)
+
+	// Success
+	filename, cleanup := createTempFile(t, "queue_text_*.txt")
+	defer cleanup()
+
+	if err := q.SaveText(filename); err != nil {
+		t.Errorf("SaveText failed: %v", err)
+	}
+
+	// Error: Invalid path
+	if err := q.SaveText(""); err == nil {
+		t.Error("Expected error saving to empty filename")
+	}
+}
+
+func TestLoadText(t *testing.T) {
+	// Success Case
+	{
+		filename, cleanup := createTempFile(t, "good_queue.txt")
+		defer cleanup()
+		content := "2\n100\n200\n" // Size 2, items 100, 200
+		os.WriteFile(filename, []byte(content), 0644)
+
+		q := NewQueue[int](1)
+		if err := q.LoadText(filename); err != nil {
+			t.Fatalf("LoadText failed: %v", err)
+		}
+		if q.Size() != 2 {
+			t.Errorf("Expected size 2, got %d", q.Size())
+		}
+		val, _ := q.Pop()
+		if val != 100 {
+			t.Errorf("Expected 100, got %d", val)
+		}
+	}
+
+	// Error: File not found
+	{
+		q := NewQueue[int](1)
+		if err := q.LoadText("non_existent_file_XYZ.txt"); err == nil {
+			t.Error("Expected error for missing file")
+		}
+	}
+
+	// Error: Corrupt Header (Size is not int)
+	{
+		filename, cleanup := createTempFile(t, "bad_header.txt")
+		defer cleanup()
+		os.WriteFile(filename, []byte("NotANumber\n"), 0644)
+
+		q := NewQueue[int](1)
+		err := q.LoadText(filename)
+		if err == nil {
+			t.Error("Expected error for bad header")
+		}
+		if err != nil && err.Error()[:13] != "error reading" {
+			// Just checking we wrap the error roughly as expected
+			t.Logf("Got expected error: %v", err)
+		}
+	}
+
+	// Error: Corrupt Data (Type mismatch)
+	{
+		filename, cleanup := createTempFile(t, "bad_data_type.txt")
+		defer cleanup()
+		os.WriteFile(filename, []byte("2\n100\nNotAnInt"), 0644)
+
+		q := NewQueue[int](1)
+		if err := q.LoadText(filename); err != ErrFileCorrupt {
+			t.Errorf("Expected ErrFileCorrupt for type mismatch, got %v", err)
+		}
+	}
+
+	// Error: EOF before all items read (Size 3, but only 1 item)
+	{
+		filename, cleanup := createTempFile(t, "short_file.txt")
+		defer cleanup()
+		os.WriteFile(filename, []byte("3\n100\n"), 0644)
+
+		q := NewQueue[int](1)
+		if err := q.LoadText(filename); err != ErrFileCorrupt {
+			t.Errorf("Expected ErrFileCorrupt for premature EOF, got %v", err)
+		}
+	}
+}
+
+// Binary Serialization Tests
+
+func TestSaveBinary(t *testing.T) {
+	q := NewQueue[float64](5)
+	q.Push(1.1)
+
+	// Success
+	filename, cleanup := createTempFile(t, "queue_bin_*.gob")
+	defer cleanup()
+	if err := q.SaveBinary(filename); err != nil {
+		t.Errorf("SaveBinary failed: %v", err)
+	}
+
+	// Error: File creation
+	if err := q.SaveBinary(""); err == nil {
+		t.Error("Expected error on invalid filename")
+	}
+
+	// Error: Encoding failure
+	qBad := NewQueue[func()](1)
+	qBad.Push(func() {})
+
+	fnameBad, cl := createTempFile(t, "bad_gob.gob")
+	defer cl()
+
+	// The header (int) will write fine, but the item (func) will fail
+	if err := qBad.SaveBinary(fnameBad); err == nil {
+		t.Error("Expected gob encoding error for function type")
+	}
+}
+
+func TestLoadBinary(t *testing.T) {
+	// Success
+	{
+		filename, cleanup := createTempFile(t, "queue_good.gob")
+		defer cleanup()
+
+		// Save valid data first
+		qOrig := NewQueue[string](2)
+		qOrig.Push("Test")
+		qOrig.SaveBinary(filename)
+
+		qLoad := NewQueue[string](1)
+		if err := qLoad.LoadBinary(filename); err != nil {
+			t.Fatalf("LoadBinary failed: %v", err)
+		}
+		if val, _ := qLoad.Pop(); val != "Test" {
+			t.Error("Data mismatch")
+		}
+	}
+
+	// Error: Open file
+	{
+		q := NewQueue[int](1)
+		if err := q.LoadBinary("missing_bin.gob"); err == nil {
+			t.Error("Expected error opening missing file")
+		}
+	}
+
+	// Error: Header decode (Empty file or garbage)
+	{
+		filename, cleanup := createTempFile(t, "garbage.gob")
+		defer cleanup()
+		os.WriteFile(filename, []byte("NOT_GOB_DATA"), 0644)
+
+		q := NewQueue[int](1)
+		if err := q.LoadBinary(filename); err == nil {
+			t.Error("Expected error decoding garbage header")
+		}
+	}
+
+	// Error: Data decode (EOF / corruption)
+	{
+		// Create a valid file, then truncate it to simulate partial write
+		filename, cleanup := createTempFile(t, "truncated.gob")
+		defer cleanup()
+
+		qOrig := NewQueue[int](2)
+		qOrig.Push(1)
+		qOrig.Push(2)
+		qOrig.SaveBinary(filename)
+
+		// Truncate file: Keep header (size) but cut off data
+		data, _ := os.ReadFile(filename)
+		// Write back only first few bytes
+		os.WriteFile(filename, data[:10], 0644)
+
+		qLoad := NewQueue[int](1)
+		err := qLoad.LoadBinary(filename)
+
+		// Depending on where it cut, it might be unexpected EOF or a decoding error.
+		if err == nil {
+			t.Error("Expected error loading truncated file")
+		}
+	}
+}
+
+// Тесты атомарного сохранения
+
+// TestSaveFailureKeepsFile обрывает запись на разных байтах и проверяет,
+// что ранее сохраненный файл не изменился, а временные файлы удалены
+func TestSaveFailureKeepsFile(t *testing.T) {
+	q := NewQueue[int32](4)
+	for i := 0; i < 100; i++ {
+		q.Push(int32(i))
+	}
+	persisttest.SaveFailureKeepsFile(t, map[string]func(string, ...persist.Option) error{
+		"text":   q.SaveText,
+		"binary": q.SaveBinary,
+	}, func() { q.Push(1000) })
+}
+
+// streamFormats - форматы потокового сохранения очереди
+func streamFormats[T any]() []persisttest.Format[*Queue[T]] {
+	return []persisttest.Format[*Queue[T]]{
+		{Name: "text", Write: (*Queue[T]).WriteTextTo, Read: (*Queue[T]).ReadTextFrom},
+		{Name: "binary", Write: (*Queue[T]).WriteTo, Read: (*Queue[T]).ReadFrom},
+		{Name: "json", Write: (*Queue[T]).WriteJSONTo, Read: (*Queue[T]).ReadJSONFrom},
+	}
+}
+
+// sameQueue сравнивает элементы очередей от головы к хвосту
+func sameQueue[T comparable](a, b *Queue[T]) bool {
+	return slices.Equal(a.ToSlice(), b.ToSlice())
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	src := NewQueue[int32](4)
+	for i := 0; i < 50; i++ {
+		src.Push(int32(i))
+	}
+	var _ io.WriterTo = src
+	var _ io.ReaderFrom = src
+
+	persisttest.RoundTrip(t, src, func() *Queue[int32] { return NewQueue[int32](4) }, streamFormats[int32](), sameQueue[int32])
+}
+
+// Fuzz-тест текстового формата: строки с пробелами, кавычками и переводами строк
+// должны читаться обратно без изменений
+func FuzzTextRoundTrip(f *testing.F) {
+	persisttest.FuzzText(f, func(a, b string) *Queue[string] {
+		src := NewQueue[string](2)
+		src.Push(a)
+		src.Push(b)
+		src.Push(a + " " + b)
+		return src
+	}, func() *Queue[string] { return NewQueue[string](2) }, streamFormats[string]()[0], sameQueue[string])
+}
+
+func TestJSON(t *testing.T) {
+	// Кольцо с перенесенной головой пишется от головы к хвосту
+	q := NewQueue[int](3)
+	for i := 0; i < 3; i++ {
+		q.Push(i)
+	}
+	q.Pop()
+	q.Push(3)
+
+	data, err := json.Marshal(q)
+	if err != nil || string(data) != "[1,2,3]" {
+		t.Fatalf("Expected [1,2,3], got %s (%v)", data, err)
+	}
+
+	var decoded struct {
+		Queue Queue[int]
+	}
+	if err := json.Unmarshal([]byte(`{"Queue":[7,8,9]}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	decoded.Queue.Push(10)
+	for want := 7; want <= 10; want++ {
+		if got, err := decoded.Queue.Pop(); err != nil || got != want {
+			t.Fatalf("Expected %d, got %d (%v)", want, got, err)
+		}
+	}
+
+	err = q.UnmarshalJSON([]byte(`[1, 2.5]`))
+	var je *codec.JSONError
+	if !errors.As(err, &je) || je.Path != "$[1]" || je.Offset != 4 {
+		t.Fatalf("Expected error at $[1] offset 4, got %v", err)
+	}
+	if q.Size() != 3 {
+		t.Errorf("Queue changed after failed unmarshal: size %d", q.Size())
+	}
+}
+
+func TestGobEmbedding(t *testing.T) {
+	src := NewQueue[string](2)
+	src.Push("first")
+	src.Push("second")
+	var _ encoding.BinaryMarshaler = src
+	var _ gob.GobDecoder = src
+
+	// Очередь внутри структуры по значению
+	persisttest.GobRoundTrip(t, *src.Clone(), func(a, b Queue[string]) bool { return sameQueue(&a, &b) })
+
+	data, _ := src.MarshalBinary()
+	data[len(data)-1] ^= 0xFF
+	dst := FromSlice([]string{"keep"})
+	if err := dst.UnmarshalBinary(data); !errors.Is(err, persist.ErrChecksum) {
+		t.Errorf("Expected ErrChecksum, got %v", err)
+	}
+	if got := dst.ToSlice(); !slices.Equal(got, []string{"keep"}) {
+		t.Errorf("Failed load changed the queue: %v", got)
+	}
+}
+
+func TestFromSliceToSlice(t *testing.T) {
+	q := FromSlice([]int{1, 2, 3})
+	if head, _ := q.Pop(); head != 1 {
+		t.Errorf("s[0] should be the head, got %d", head)
+	}
+	// Кольцо после FromSlice заполнено: вставка должна расширить буфер
+	q.Push(4)
+	q.Push(5)
+	if got := q.ToSlice(); !slices.Equal(got, []int{2, 3, 4, 5}) {
+		t.Errorf("ToSlice = %v", got)
+	}
+
+	if got := FromSeq(slices.Values([]string{"a", "b"})).ToSlice(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("FromSeq = %v", got)
+	}
+	empty := FromSlice[int](nil)
+	empty.Push(7)
+	if got := empty.ToSlice(); !slices.Equal(got, []int{7}) {
+		t.Errorf("Empty FromSlice queue: %v", got)
+	}
+}
+
+func TestIterators(t *testing.T) {
+	// Голова не в начале буфера, элементы переходят через край кольца
+	q := NewQueue[int](4)
+	for i := range 4 {
+		q.Push(i)
+	}
+	_, _ = q.Pop()
+	_, _ = q.Pop()
+	q.Push(4)
+	q.Push(5)
+
+	if got := slices.Collect(q.All()); !slices.Equal(got, []int{2, 3, 4, 5}) {
+		t.Errorf("All = %v", got)
+	}
+	if got := slices.Collect(q.Backward()); !slices.Equal(got, []int{5, 4, 3, 2}) {
+		t.Errorf("Backward = %v", got)
+	}
+	for i, v := range q.Enumerate() {
+		if v != i+2 {
+			t.Errorf("Enumerate: position %d has %d", i, v)
+		}
+	}
+
+	// Pop в цикле сдвигает голову: следующий элемент пропускается
+	var seen []int
+	for v := range q.All() {
+		seen = append(seen, v)
+		if v == 2 {
+			_, _ = q.Pop()
+		}
+	}
+	if !slices.Equal(seen, []int{2, 4, 5}) {
+		t.Errorf("All with Pop = %v", seen)
+	}
+
+	// Push в цикле продлевает обход, даже если очередь расширяется
+	seen = seen[:0]
+	for v := range q.All() {
+		seen = append(seen, v)
+		if v == 5 {
+			q.Push(6)
+			q.Push(7)
+		}
+	}
+	if !slices.Equal(seen, []int{3, 4, 5, 6, 7}) {
+		t.Errorf("All with Push = %v", seen)
+	}
+
+	empty := NewQueue[int](1)
+	for range empty.All() {
+		t.Error("All on empty queue yielded")
+	}
+}
@@ -0,0 +1,237 @@
+// Package rope реализует веревку (rope) - строку в виде сбалансированного
+// дерева фрагментов. Склейка, разрез, вставка и доступ по индексу стоят
+// O(log n) независимо от длины, поэтому веревка подходит для очень больших
+// текстов, где копирование строки на каждую правку слишком дорого.
+package rope
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// MaxLeaf - наибольшая длина фрагмента в листе. Короткие соседние фрагменты
+// склеиваются в один лист, чтобы посимвольный набор не порождал дерево из
+// однобайтовых листьев.
+const MaxLeaf = 512
+
+// node - узел дерева. Узлы неизменяемы: правки строят новые узлы на пути
+// от корня, а остальные разделяются между старой и новой версией.
+type node struct {
+	left, right *node
+	text        string // Только у листа
+	length      int
+	height      int // Высота поддерева: у листа 1
+}
+
+func height(n *node) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *node) isLeaf() bool {
+	return n.left == nil
+}
+
+func newLeaf(s string) *node {
+	if s == "" {
+		return nil
+	}
+	return &node{text: s, length: len(s), height: 1}
+}
+
+func newInner(l, r *node) *node {
+	return &node{left: l, right: r, length: l.length + r.length, height: max(l.height, r.height) + 1}
+}
+
+// build строит идеально сбалансированное дерево из строки
+func build(s string) *node {
+	if len(s) <= MaxLeaf {
+		return newLeaf(s)
+	}
+	// Середину округляем до границы листа, чтобы листья были полными
+	mid := (len(s)/MaxLeaf + 1) / 2 * MaxLeaf
+	return newInner(build(s[:mid]), build(s[mid:]))
+}
+
+// balance создает узел из l и r, поворачивая его, если высоты поддеревьев
+// различаются на 2 (как в АВЛ-дереве)
+func balance(l, r *node) *node {
+	hl, hr := height(l), height(r)
+	switch {
+	case hl > hr+1:
+		if height(l.left) >= height(l.right) {
+			return newInner(l.left, newInner(l.right, r))
+		}
+		lr := l.right
+		return newInner(newInner(l.left, lr.left), newInner(lr.right, r))
+	case hr > hl+1:
+		if height(r.right) >= height(r.left) {
+			return newInner(newInner(l, r.left), r.right)
+		}
+		rl := r.left
+		return newInner(newInner(l, rl.left), newInner(rl.right, r.right))
+	}
+	return newInner(l, r)
+}
+
+// join склеивает деревья за O(|height(l) - height(r)|): меньшее дерево
+// спускается по краю большего до равной высоты и поднимается с поворотами
+func join(l, r *node) *node {
+	switch {
+	case l == nil:
+		return r
+	case r == nil:
+		return l
+	case l.isLeaf() && r.isLeaf() && l.length+r.length <= MaxLeaf:
+		return newLeaf(l.text + r.text)
+	}
+	hl, hr := height(l), height(r)
+	switch {
+	case hl > hr+1:
+		return balance(l.left, join(l.right, r))
+	case hr > hl+1:
+		return balance(join(l, r.left), r.right)
+	}
+	return newInner(l, r)
+}
+
+// split делит дерево на первые i байт и остаток
+func split(n *node, i int) (*node, *node) {
+	switch {
+	case n == nil:
+		return nil, nil
+	case i <= 0:
+		return nil, n
+	case i >= n.length:
+		return n, nil
+	case n.isLeaf():
+		// Подстроки разделяют память листа, копирования нет
+		return newLeaf(n.text[:i]), newLeaf(n.text[i:])
+	case i < n.left.length:
+		ll, lr := split(n.left, i)
+		return ll, join(lr, n.right)
+	case i > n.left.length:
+		rl, rr := split(n.right, i-n.left.length)
+		return join(n.left, rl), rr
+	}
+	return n.left, n.right
+}
+
+// Rope - изменяемая строка на основе веревки. Индексы - смещения в байтах,
+// как у string; правка посреди многобайтового символа UTF-8 его разрежет.
+// Нулевое значение - пустая строка, готовая к использованию.
+type Rope struct {
+	root *node
+}
+
+// New создает веревку из строки за O(n)
+func New(s string) *Rope {
+	return &Rope{root: build(s)}
+}
+
+// Len возвращает длину в байтах
+func (r *Rope) Len() int {
+	if r.root == nil {
+		return 0
+	}
+	return r.root.length
+}
+
+// String собирает веревку в обычную строку за O(n)
+func (r *Rope) String() string {
+	var sb strings.Builder
+	sb.Grow(r.Len())
+	for chunk := range r.Chunks() {
+		sb.WriteString(chunk)
+	}
+	return sb.String()
+}
+
+// Clone возвращает копию веревки за O(1): узлы неизменяемы и разделяются
+func (r *Rope) Clone() *Rope {
+	return &Rope{root: r.root}
+}
+
+// Index возвращает байт с индексом i за O(log n)
+func (r *Rope) Index(i int) (byte, error) {
+	if i < 0 || i >= r.Len() {
+		return 0, fmt.Errorf("error: Index %d is out of bounds (size %d)", i, r.Len())
+	}
+	n := r.root
+	for !n.isLeaf() {
+		if i < n.left.length {
+			n = n.left
+		} else {
+			i -= n.left.length
+			n = n.right
+		}
+	}
+	return n.text[i], nil
+}
+
+// Concat дописывает other в конец за O(log n). other не меняется.
+func (r *Rope) Concat(other *Rope) {
+	r.root = join(r.root, other.root)
+}
+
+// Split оставляет в r первые i байт и возвращает остаток за O(log n)
+func (r *Rope) Split(i int) (*Rope, error) {
+	if i < 0 || i > r.Len() {
+		return nil, fmt.Errorf("error: Split position %d is out of bounds (size %d)", i, r.Len())
+	}
+	left, right := split(r.root, i)
+	r.root = left
+	return &Rope{root: right}, nil
+}
+
+// Insert вставляет s перед байтом i за O(log n + len(s))
+func (r *Rope) Insert(i int, s string) error {
+	if i < 0 || i > r.Len() {
+		return fmt.Errorf("error: Index %d is out of bounds for insertion", i)
+	}
+	left, right := split(r.root, i)
+	r.root = join(join(left, build(s)), right)
+	return nil
+}
+
+// Delete удаляет байты [i, j) за O(log n)
+func (r *Rope) Delete(i, j int) error {
+	if i < 0 || j > r.Len() || i > j {
+		return fmt.Errorf("error: Range [%d, %d) is out of bounds (size %d)", i, j, r.Len())
+	}
+	left, rest := split(r.root, i)
+	_, right := split(rest, j-i)
+	r.root = join(left, right)
+	return nil
+}
+
+// Substring возвращает байты [i, j) за O(log n + j - i)
+func (r *Rope) Substring(i, j int) (string, error) {
+	if i < 0 || j > r.Len() || i > j {
+		return "", fmt.Errorf("error: Range [%d, %d) is out of bounds (size %d)", i, j, r.Len())
+	}
+	_, rest := split(r.root, i)
+	middle, _ := split(rest, j-i)
+	return (&Rope{root: middle}).String(), nil
+}
+
+// Chunks возвращает итератор по фрагментам строки слева направо.
+// Склеенные фрагменты дают String().
+func (r *Rope) Chunks() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		walk(r.root, yield)
+	}
+}
+
+func walk(n *node, yield func(string) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.isLeaf() {
+		return yield(n.text)
+	}
+	return walk(n.left, yield) && walk(n.right, yield)
+}
@@ -0,0 +1,83 @@
+package rope
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+)
+
+const (
+	textSize = 1 << 20 // Размер текста, в который идут вставки
+	edits    = 1000    // Количество правок за итерацию
+)
+
+func text() string {
+	return strings.Repeat("abcdefghijklmnopqrstuvwxyz", textSize/26+1)[:textSize]
+}
+
+// BenchmarkInsert вставляет короткие строки в разные места большого текста:
+// веревка против array.Array[byte] и обычной строки
+func BenchmarkInsert(b *testing.B) {
+	s := text()
+	pos := func(j int) int { return (j * 7919) % textSize }
+
+	b.Run("Rope", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r := New(s)
+			for j := 0; j < edits; j++ {
+				_ = r.Insert(pos(j), "xy")
+			}
+		}
+	})
+
+	b.Run("Array", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			arr := array.FromSlice([]byte(s))
+			for j := 0; j < edits; j++ {
+				_ = arr.InsertRange(pos(j), 'x', 'y')
+			}
+		}
+	})
+
+	b.Run("String", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			str := s
+			for j := 0; j < edits; j++ {
+				p := pos(j)
+				str = str[:p] + "xy" + str[p:]
+			}
+		}
+	})
+}
+
+// BenchmarkTyping набирает текст посимвольно в середине: соседние
+// однобайтовые вставки склеиваются в листья
+func BenchmarkTyping(b *testing.B) {
+	s := text()
+	for i := 0; i < b.N; i++ {
+		r := New(s)
+		for j := 0; j < edits; j++ {
+			_ = r.Insert(textSize/2+j, "x")
+		}
+	}
+}
+
+// BenchmarkIndex измеряет доступ по индексу
+func BenchmarkIndex(b *testing.B) {
+	r := New(text())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = r.Index((i * 7919) % textSize)
+	}
+}
+
+// BenchmarkConcat склеивает большую веревку с короткой
+func BenchmarkConcat(b *testing.B) {
+	big, small := New(text()), New("tail")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := big.Clone()
+		r.Concat(small)
+	}
+}
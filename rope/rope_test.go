@@ -0,0 +1,172 @@
+package rope
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// checkTree проверяет длины, высоты и баланс АВЛ в каждом узле
+func checkTree(t *testing.T, n *node) {
+	t.Helper()
+	if n == nil {
+		return
+	}
+	if n.isLeaf() {
+		if n.right != nil || n.text == "" || n.length != len(n.text) || n.height != 1 {
+			t.Fatalf("bad leaf: len=%d text=%d height=%d", n.length, len(n.text), n.height)
+		}
+		return
+	}
+	if n.right == nil || n.text != "" {
+		t.Fatal("inner node must have two children and no text")
+	}
+	checkTree(t, n.left)
+	checkTree(t, n.right)
+	if n.length != n.left.length+n.right.length {
+		t.Fatalf("length %d != %d + %d", n.length, n.left.length, n.right.length)
+	}
+	if n.height != max(n.left.height, n.right.height)+1 {
+		t.Fatalf("height %d is wrong", n.height)
+	}
+	if d := n.left.height - n.right.height; d > 1 || d < -1 {
+		t.Fatalf("unbalanced node: heights %d and %d", n.left.height, n.right.height)
+	}
+}
+
+func TestBasics(t *testing.T) {
+	var empty Rope
+	if empty.Len() != 0 || empty.String() != "" {
+		t.Error("zero Rope is not empty")
+	}
+	if _, err := empty.Index(0); err == nil {
+		t.Error("Index on empty rope returned nil error")
+	}
+
+	text := strings.Repeat("0123456789", 1000)
+	r := New(text)
+	checkTree(t, r.root)
+	if r.Len() != len(text) || r.String() != text {
+		t.Fatal("New/String round trip failed")
+	}
+	for _, i := range []int{0, 511, 512, 5000, len(text) - 1} {
+		if b, err := r.Index(i); err != nil || b != text[i] {
+			t.Errorf("Index(%d) = %q, %v, want %q", i, b, err, text[i])
+		}
+	}
+	if _, err := r.Index(len(text)); err == nil {
+		t.Error("Index out of bounds returned nil error")
+	}
+
+	var chunks int
+	for chunk := range r.Chunks() {
+		if len(chunk) > MaxLeaf {
+			t.Errorf("chunk of %d bytes exceeds MaxLeaf", len(chunk))
+		}
+		chunks++
+	}
+	if chunks != (len(text)+MaxLeaf-1)/MaxLeaf {
+		t.Errorf("chunks = %d", chunks)
+	}
+}
+
+func TestConcatSplit(t *testing.T) {
+	a, b := New("hello, "), New(strings.Repeat("world", 300))
+	a.Concat(b)
+	want := "hello, " + strings.Repeat("world", 300)
+	if a.String() != want {
+		t.Fatal("Concat result is wrong")
+	}
+	if b.Len() != 1500 {
+		t.Error("Concat changed its argument")
+	}
+	checkTree(t, a.root)
+
+	for _, i := range []int{0, 3, 7, 700, len(want)} {
+		r := New(want)
+		tail, err := r.Split(i)
+		if err != nil {
+			t.Fatalf("Split(%d) error: %v", i, err)
+		}
+		if r.String() != want[:i] || tail.String() != want[i:] {
+			t.Errorf("Split(%d) = %d + %d bytes", i, r.Len(), tail.Len())
+		}
+		checkTree(t, r.root)
+		checkTree(t, tail.root)
+	}
+	if _, err := New("abc").Split(4); err == nil {
+		t.Error("Split out of bounds returned nil error")
+	}
+
+	// Склейка деревьев очень разной высоты остается сбалансированной
+	big := New(strings.Repeat("x", 100*MaxLeaf))
+	for i := 0; i < 50; i++ {
+		big.Concat(New(strings.Repeat("y", MaxLeaf)))
+		small := New(strings.Repeat("z", MaxLeaf))
+		small.Concat(big)
+		big = small
+	}
+	checkTree(t, big.root)
+}
+
+func TestEditsMatchStrings(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	var rp Rope
+	var model string
+
+	for step := 0; step < 3000; step++ {
+		switch op := r.IntN(10); {
+		case op < 6: // Набор текста: короткие вставки
+			i := r.IntN(len(model) + 1)
+			s := strings.Repeat(string(rune('a'+r.IntN(26))), 1+r.IntN(3))
+			if step%100 == 0 {
+				s = strings.Repeat("L", 2*MaxLeaf+7) // Изредка большая вставка
+			}
+			if err := rp.Insert(i, s); err != nil {
+				t.Fatalf("Insert error: %v", err)
+			}
+			model = model[:i] + s + model[i:]
+		case op < 9 && len(model) > 0:
+			i := r.IntN(len(model))
+			j := i + r.IntN(min(len(model)-i, 20)+1)
+			if err := rp.Delete(i, j); err != nil {
+				t.Fatalf("Delete error: %v", err)
+			}
+			model = model[:i] + model[j:]
+		case len(model) > 0:
+			i := r.IntN(len(model))
+			j := i + r.IntN(len(model)-i+1)
+			got, err := rp.Substring(i, j)
+			if err != nil || got != model[i:j] {
+				t.Fatalf("Substring(%d, %d) = %q, %v", i, j, got, err)
+			}
+		}
+		if rp.Len() != len(model) {
+			t.Fatalf("step %d: Len = %d, want %d", step, rp.Len(), len(model))
+		}
+	}
+	checkTree(t, rp.root)
+	if rp.String() != model {
+		t.Fatal("rope differs from model after random edits")
+	}
+
+	if err := rp.Insert(-1, "x"); err == nil {
+		t.Error("Insert out of bounds returned nil error")
+	}
+	if err := rp.Delete(2, 1); err == nil {
+		t.Error("Delete with i > j returned nil error")
+	}
+	if _, err := rp.Substring(0, rp.Len()+1); err == nil {
+		t.Error("Substring out of bounds returned nil error")
+	}
+}
+
+func TestClone(t *testing.T) {
+	r := New("immutable nodes")
+	c := r.Clone()
+	_ = r.Insert(0, "shared ")
+	_ = c.Delete(0, 10)
+	if r.String() != "shared immutable nodes" || c.String() != "nodes" {
+		t.Errorf("versions interfere: %q, %q", r.String(), c.String())
+	}
+}
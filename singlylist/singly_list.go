@@ -1,339 +1,572 @@
-package singlylist
-
-import (
-	"bytes"
-	"encoding/gob"
-	"fmt"
-	"io"
-	"os"
-)
-
-// SNode представляет узел списка
-type SNode[T any] struct {
-	Key  T
-	Next *SNode[T]
-}
-
-// ForwardList реализует односвязный список
-type ForwardList[T comparable] struct {
-	Head *SNode[T]
-}
-
-// NewForwardList создает новый пустой список
-func NewForwardList[T comparable]() *ForwardList[T] {
-	return &ForwardList[T]{Head: nil}
-}
-
-// DeepCopy создает глубокую копию списка
-func (l *ForwardList[T]) DeepCopy() *ForwardList[T] {
-	newList := NewForwardList[T]()
-	if l.Head == nil {
-		return newList
-	}
-
-	newList.Head = &SNode[T]{Key: l.Head.Key, Next: nil}
-	currentThis := newList.Head
-	currentOther := l.Head.Next
-
-	for currentOther != nil {
-		currentThis.Next = &SNode[T]{Key: currentOther.Key, Next: nil}
-		currentThis = currentThis.Next
-		currentOther = currentOther.Next
-	}
-	return newList
-}
-
-// PushForward добавляет узел ПОСЛЕ указанного узла ptr
-func (l *ForwardList[T]) PushForward(ptr *SNode[T], key T) error {
-	if ptr == nil {
-		return fmt.Errorf("pointer cannot be null")
-	}
-	newNode := &SNode[T]{Key: key, Next: ptr.Next}
-	ptr.Next = newNode
-	return nil
-}
-
-// Create создает список с начальным элементом (сбрасывает текущий)
-func (l *ForwardList[T]) Create(keyBegin T) {
-	l.Head = &SNode[T]{Key: keyBegin, Next: nil}
-}
-
-// Print выводит список в консоль
-func (l *ForwardList[T]) Print() {
-	if l.Head == nil {
-		fmt.Println("Список пуст")
-		return
-	}
-	current := l.Head
-	for current != nil {
-		fmt.Printf("%v -> ", current.Key)
-		current = current.Next
-	}
-	fmt.Println("nil")
-}
-
-// GetPrintString возвращает строковое представление (для тестов)
-func (l *ForwardList[T]) GetPrintString() string {
-	if l.Head == nil {
-		return "Список пуст\n"
-	}
-	var buffer bytes.Buffer
-	current := l.Head
-	for current != nil {
-		buffer.WriteString(fmt.Sprintf("%v -> ", current.Key))
-		current = current.Next
-	}
-	buffer.WriteString("nil\n")
-	return buffer.String()
-}
-
-// PushHead добавляет элемент в НАЧАЛО списка
-func (l *ForwardList[T]) PushHead(key T) {
-	newNode := &SNode[T]{Key: key, Next: l.Head}
-	l.Head = newNode
-}
-
-// PushBack добавляет элемент в КОНЕЦ списка
-func (l *ForwardList[T]) PushBack(key T) {
-	newNode := &SNode[T]{Key: key, Next: nil}
-	if l.Head == nil {
-		l.Head = newNode
-		return
-	}
-	current := l.Head
-	for current.Next != nil {
-		current = current.Next
-	}
-	current.Next = newNode
-}
-
-// PushBefore добавляет элемент ДО узла с заданным значением
-func (l *ForwardList[T]) PushBefore(targetKey T, newKey T) error {
-	if l.Head == nil {
-		return fmt.Errorf("list is empty, cannot find element")
-	}
-
-	if l.Head.Key == targetKey {
-		l.PushHead(newKey)
-		return nil
-	}
-
-	current := l.Head
-	for current.Next != nil && current.Next.Key != targetKey {
-		current = current.Next
-	}
-
-	if current.Next != nil {
-		return l.PushForward(current, newKey)
-	}
-	return fmt.Errorf("target key not found")
-}
-
-// DelHead удаляет первый элемент списка
-func (l *ForwardList[T]) DelHead() error {
-	if l.Head == nil {
-		return fmt.Errorf("attempt to delete from an empty list")
-	}
-	l.Head = l.Head.Next
-	return nil
-}
-
-// DelBack удаляет последний элемент списка
-func (l *ForwardList[T]) DelBack() error {
-	if l.Head == nil {
-		return fmt.Errorf("attempt to delete from an empty list")
-	}
-
-	if l.Head.Next == nil {
-		l.Head = nil
-		return nil
-	}
-
-	current := l.Head
-	for current.Next.Next != nil {
-		current = current.Next
-	}
-	current.Next = nil
-	return nil
-}
-
-// DelAfter удаляет элемент ПОСЛЕ указанного узла
-func (l *ForwardList[T]) DelAfter(ptr *SNode[T]) error {
-	if ptr == nil {
-		return fmt.Errorf("pointer cannot be null")
-	}
-	if ptr.Next == nil {
-		return fmt.Errorf("no element exists after the specified node")
-	}
-	ptr.Next = ptr.Next.Next
-	return nil
-}
-
-// DelBefore удаляет элемент ДО узла с заданным значением
-func (l *ForwardList[T]) DelBefore(targetKey T) error {
-	if l.Head == nil {
-		return fmt.Errorf("list is empty")
-	}
-	if l.Head.Key == targetKey {
-		return fmt.Errorf("cannot delete element before the head of the list")
-	}
-	if l.Head.Next == nil {
-		return fmt.Errorf("list is too short or element not found")
-	}
-	// Если целевой элемент - второй, нужно удалить голову
-	if l.Head.Next.Key == targetKey {
-		return l.DelHead()
-	}
-
-	current := l.Head
-	found := false
-	// Ищем узел за два шага до целевого
-	for current.Next != nil && current.Next.Next != nil {
-		if current.Next.Next.Key == targetKey {
-			l.DelAfter(current)
-			found = true
-			return nil
-		}
-		current = current.Next
-	}
-
-	if !found {
-		return fmt.Errorf("target element not found")
-	}
-	return nil
-}
-
-// DelByValue удаляет узел по значению (первое вхождение)
-func (l *ForwardList[T]) DelByValue(key T) error {
-	if l.Head == nil {
-		return fmt.Errorf("list is empty")
-	}
-
-	if l.Head.Key == key {
-		return l.DelHead()
-	}
-
-	current := l.Head
-	for current.Next != nil && current.Next.Key != key {
-		current = current.Next
-	}
-
-	if current.Next != nil {
-		current.Next = current.Next.Next
-		return nil
-	}
-	return fmt.Errorf("element with specified value not found")
-}
-
-// GetByValue ищет элемент по значению
-func (l *ForwardList[T]) GetByValue(key T) *SNode[T] {
-	current := l.Head
-	for current != nil {
-		if current.Key == key {
-			return current
-		}
-		current = current.Next
-	}
-	return nil
-}
-
-// Save сохраняет список в текстовый файл
-func (l *ForwardList[T]) Save(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error opening file for writing: %v", err)
-	}
-	defer file.Close()
-
-	current := l.Head
-	for current != nil {
-		if _, err := fmt.Fprintf(file, "%v ", current.Key); err != nil {
-			return err
-		}
-		current = current.Next
-	}
-	return nil
-}
-
-// Load загружает список из текстового файла
-func (l *ForwardList[T]) Load(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("error opening file for reading: %v", err)
-	}
-	defer file.Close()
-
-	// Очищаем список
-	l.Head = nil
-
-	var value T
-	first := true
-	for {
-		_, err := fmt.Fscan(file, &value)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("invalid data format or read error")
-		}
-
-		if first {
-			l.Create(value)
-			first = false
-		} else {
-			l.PushBack(value)
-		}
-	}
-	return nil
-}
-
-// Serialize сохраняет список в бинарный формат (gob)
-func (l *ForwardList[T]) Serialize(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error opening file for writing: %v", err)
-	}
-	defer file.Close()
-
-	var values []T
-	current := l.Head
-	for current != nil {
-		values = append(values, current.Key)
-		current = current.Next
-	}
-
-	encoder := gob.NewEncoder(file)
-	if err := encoder.Encode(values); err != nil {
-		return fmt.Errorf("error writing binary data: %v", err)
-	}
-	return nil
-}
-
-// Deserialize загружает список из бинарного формата (gob)
-func (l *ForwardList[T]) Deserialize(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("error opening file for reading: %v", err)
-	}
-	defer file.Close()
-
-	l.Head = nil
-	var values []T
-	decoder := gob.NewDecoder(file)
-	if err := decoder.Decode(&values); err != nil {
-		// Если файл пуст или EOF, это нормально, просто список будет пуст
-		if err == io.EOF {
-			return nil
-		}
-		return fmt.Errorf("error reading binary data: %v", err)
-	}
-
-	for i, val := range values {
-		if i == 0 {
-			l.Create(val)
-		} else {
-			l.PushBack(val)
-		}
-	}
-	return nil
-}
+package singlylist
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// SNode представляет узел списка
+type SNode[T any] struct {
+	Key  T
+	Next *SNode[T]
+}
+
+// ForwardList реализует односвязный список
+type ForwardList[T comparable] struct {
+	Head *SNode[T]
+}
+
+// NewForwardList создает новый пустой список
+func NewForwardList[T comparable]() *ForwardList[T] {
+	return &ForwardList[T]{Head: nil}
+}
+
+// DeepCopy создает глубокую копию списка
+func (l *ForwardList[T]) DeepCopy() *ForwardList[T] {
+	newList := NewForwardList[T]()
+	if l.Head == nil {
+		return newList
+	}
+
+	newList.Head = &SNode[T]{Key: l.Head.Key, Next: nil}
+	currentThis := newList.Head
+	currentOther := l.Head.Next
+
+	for currentOther != nil {
+		currentThis.Next = &SNode[T]{Key: currentOther.Key, Next: nil}
+		currentThis = currentThis.Next
+		currentOther = currentOther.Next
+	}
+	return newList
+}
+
+// PushForward добавляет узел ПОСЛЕ указанного узла ptr
+func (l *ForwardList[T]) PushForward(ptr *SNode[T], key T) error {
+	if ptr == nil {
+		return fmt.Errorf("pointer cannot be null")
+	}
+	newNode := &SNode[T]{Key: key, Next: ptr.Next}
+	ptr.Next = newNode
+	return nil
+}
+
+// Create создает список с начальным элементом (сбрасывает текущий)
+func (l *ForwardList[T]) Create(keyBegin T) {
+	l.Head = &SNode[T]{Key: keyBegin, Next: nil}
+}
+
+// Print выводит список в консоль
+func (l *ForwardList[T]) Print() {
+	if l.Head == nil {
+		fmt.Println("Список пуст")
+		return
+	}
+	current := l.Head
+	for current != nil {
+		fmt.Printf("%v -> ", current.Key)
+		current = current.Next
+	}
+	fmt.Println("nil")
+}
+
+// GetPrintString возвращает строковое представление (для тестов)
+func (l *ForwardList[T]) GetPrintString() string {
+	if l.Head == nil {
+		return "Список пуст\n"
+	}
+	var buffer bytes.Buffer
+	current := l.Head
+	for current != nil {
+		buffer.WriteString(fmt.Sprintf("%v -> ", current.Key))
+		current = current.Next
+	}
+	buffer.WriteString("nil\n")
+	return buffer.String()
+}
+
+// PushHead добавляет элемент в НАЧАЛО списка
+func (l *ForwardList[T]) PushHead(key T) {
+	newNode := &SNode[T]{Key: key, Next: l.Head}
+	l.Head = newNode
+}
+
+// PushBack добавляет элемент в КОНЕЦ списка
+func (l *ForwardList[T]) PushBack(key T) {
+	newNode := &SNode[T]{Key: key, Next: nil}
+	if l.Head == nil {
+		l.Head = newNode
+		return
+	}
+	current := l.Head
+	for current.Next != nil {
+		current = current.Next
+	}
+	current.Next = newNode
+}
+
+// PushBefore добавляет элемент ДО узла с заданным значением
+func (l *ForwardList[T]) PushBefore(targetKey T, newKey T) error {
+	if l.Head == nil {
+		return fmt.Errorf("list is empty, cannot find element")
+	}
+
+	if l.Head.Key == targetKey {
+		l.PushHead(newKey)
+		return nil
+	}
+
+	current := l.Head
+	for current.Next != nil && current.Next.Key != targetKey {
+		current = current.Next
+	}
+
+	if current.Next != nil {
+		return l.PushForward(current, newKey)
+	}
+	return fmt.Errorf("target key not found")
+}
+
+// DelHead удаляет первый элемент списка
+func (l *ForwardList[T]) DelHead() error {
+	if l.Head == nil {
+		return fmt.Errorf("attempt to delete from an empty list")
+	}
+	l.Head = l.Head.Next
+	return nil
+}
+
+// DelBack удаляет последний элемент списка
+func (l *ForwardList[T]) DelBack() error {
+	if l.Head == nil {
+		return fmt.Errorf("attempt to delete from an empty list")
+	}
+
+	if l.Head.Next == nil {
+		l.Head = nil
+		return nil
+	}
+
+	current := l.Head
+	for current.Next.Next != nil {
+		current = current.Next
+	}
+	current.Next = nil
+	return nil
+}
+
+// DelAfter удаляет элемент ПОСЛЕ указанного узла
+func (l *ForwardList[T]) DelAfter(ptr *SNode[T]) error {
+	if ptr == nil {
+		return fmt.Errorf("pointer cannot be null")
+	}
+	if ptr.Next == nil {
+		return fmt.Errorf("no element exists after the specified node")
+	}
+	ptr.Next = ptr.Next.Next
+	return nil
+}
+
+// DelBefore удаляет элемент ДО узла с заданным значением
+func (l *ForwardList[T]) DelBefore(targetKey T) error {
+	if l.Head == nil {
+		return fmt.Errorf("list is empty")
+	}
+	if l.Head.Key == targetKey {
+		return fmt.Errorf("cannot delete element before the head of the list")
+	}
+	if l.Head.Next == nil {
+		return fmt.Errorf("list is too short or element not found")
+	}
+	// Если целевой элемент - второй, нужно удалить голову
+	if l.Head.Next.Key == targetKey {
+		return l.DelHead()
+	}
+
+	current := l.Head
+	found := false
+	// Ищем узел за два шага до целевого
+	for current.Next != nil && current.Next.Next != nil {
+		if current.Next.Next.Key == targetKey {
+			l.DelAfter(current)
+			found = true
+			return nil
+		}
+		current = current.Next
+	}
+
+	if !found {
+		return fmt.Errorf("target element not found")
+	}
+	return nil
+}
+
+// DelByValue удаляет узел по значению (первое вхождение)
+func (l *ForwardList[T]) DelByValue(key T) error {
+	if l.Head == nil {
+		return fmt.Errorf("list is empty")
+	}
+
+	if l.Head.Key == key {
+		return l.DelHead()
+	}
+
+	current := l.Head
+	for current.Next != nil && current.Next.Key != key {
+		current = current.Next
+	}
+
+	if current.Next != nil {
+		current.Next = current.Next.Next
+		return nil
+	}
+	return fmt.Errorf("element with specified value not found")
+}
+
+// GetByValue ищет элемент по значению
+func (l *ForwardList[T]) GetByValue(key T) *SNode[T] {
+	current := l.Head
+	for current != nil {
+		if current.Key == key {
+			return current
+		}
+		current = current.Next
+	}
+	return nil
+}
+
+// Save сохраняет список в текстовый файл
+func (l *ForwardList[T]) Save(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, l.writeText, opts...)
+}
+
+// Load загружает список из текстового файла
+func (l *ForwardList[T]) Load(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error opening file for reading: %v", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, l.readText, opts...)
+}
+
+// WriteTextTo записывает список в w в текстовом формате
+func (l *ForwardList[T]) WriteTextTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, l.writeText)
+}
+
+// ReadTextFrom читает список в текстовом формате из r до конца потока
+func (l *ForwardList[T]) ReadTextFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, l.readText)
+}
+
+func (l *ForwardList[T]) writeText(w io.Writer) error {
+	current := l.Head
+	for current != nil {
+		tok, err := codec.FormatText(current.Key)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, tok); err != nil {
+			return err
+		}
+		current = current.Next
+	}
+	return nil
+}
+
+func (l *ForwardList[T]) readText(r io.Reader) error {
+	// Очищаем список
+	l.Head = nil
+
+	s := codec.NewTextScanner(r)
+	first := true
+	for {
+		value, err := codec.ScanText[T](s)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid data format or read error: %w", err)
+		}
+
+		if first {
+			l.Create(value)
+			first = false
+		} else {
+			l.PushBack(value)
+		}
+	}
+	return nil
+}
+
+// Serialize сохраняет список в бинарный формат (gob)
+func (l *ForwardList[T]) Serialize(filename string, opts ...persist.Option) error {
+	return persist.WriteFile(filename, l.writeBinary, opts...)
+}
+
+// Deserialize загружает список из бинарного формата (gob)
+func (l *ForwardList[T]) Deserialize(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error opening file for reading: %v", err)
+	}
+	defer file.Close()
+
+	return persist.Load(file, compression.NewReader, l.readBinary, opts...)
+}
+
+// WriteTo записывает список в w в бинарном формате (io.WriterTo)
+func (l *ForwardList[T]) WriteTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, l.writeBinary)
+}
+
+// ReadFrom читает список в бинарном формате из r (io.ReaderFrom)
+func (l *ForwardList[T]) ReadFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, l.readBinary)
+}
+
+// writeBinary пишет список в конверте persist с видом и типом элементов
+func (l *ForwardList[T]) writeBinary(w io.Writer) error {
+	return persist.WriteEnvelope(w, persist.KindSinglyList, persist.TypeName[T](), l.writePayload)
+}
+
+// readBinary проверяет конверт и читает список
+func (l *ForwardList[T]) readBinary(r io.Reader) error {
+	return persist.ReadEnvelope(r, persist.KindSinglyList, persist.TypeName[T](), l.readPayload)
+}
+
+func (l *ForwardList[T]) writePayload(w io.Writer) error {
+	var values []T
+	current := l.Head
+	for current != nil {
+		values = append(values, current.Key)
+		current = current.Next
+	}
+
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(values); err != nil {
+		return fmt.Errorf("error writing binary data: %w", err)
+	}
+	return nil
+}
+
+func (l *ForwardList[T]) readPayload(r io.Reader) error {
+	var values []T
+	decoder := gob.NewDecoder(r)
+	if err := decoder.Decode(&values); err != nil {
+		// Если файл пуст или EOF, это нормально, просто список будет пуст
+		if err == io.EOF {
+			l.Head = nil
+			return nil
+		}
+		return fmt.Errorf("error reading binary data: %v", err)
+	}
+
+	l.Head = nil
+	for i, val := range values {
+		if i == 0 {
+			l.Create(val)
+		} else {
+			l.PushBack(val)
+		}
+	}
+	return nil
+}
+
+// Срезы и последовательности
+
+// FromSlice создает список из элементов s: s[0] становится головой
+func FromSlice[T comparable](s []T) *ForwardList[T] {
+	return FromSeq(slices.Values(s))
+}
+
+// FromSeq создает список из элементов seq в порядке обхода
+func FromSeq[T comparable](seq iter.Seq[T]) *ForwardList[T] {
+	l := NewForwardList[T]()
+	// Запоминаем хвост, чтобы не проходить список при каждой вставке
+	var tail *SNode[T]
+	for v := range seq {
+		node := &SNode[T]{Key: v}
+		if tail == nil {
+			l.Head = node
+		} else {
+			tail.Next = node
+		}
+		tail = node
+	}
+	return l
+}
+
+// ToSlice возвращает элементы списка от головы к хвосту
+func (l *ForwardList[T]) ToSlice() []T {
+	var s []T
+	for current := l.Head; current != nil; current = current.Next {
+		s = append(s, current.Key)
+	}
+	return s
+}
+
+// Итераторы
+//
+// Обратного итератора нет: односвязный список нельзя пройти с конца без
+// копирования. Итераторы берут следующий узел после возврата из тела цикла,
+// поэтому тело может удалить текущий узел (его Next сохраняется) и добавить
+// узлы после него - они будут пройдены. Удаленные узлы дальше текущего
+// пропускаются. Список не синхронизирован: изменение из другой горутины во
+// время обхода - гонка данных.
+
+// All возвращает итератор по элементам от головы к хвосту
+func (l *ForwardList[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for current := l.Head; current != nil; current = current.Next {
+			if !yield(current.Key) {
+				return
+			}
+		}
+	}
+}
+
+// Enumerate возвращает итератор по парам (номер от головы, элемент)
+func (l *ForwardList[T]) Enumerate() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for current := l.Head; current != nil; current = current.Next {
+			if !yield(i, current.Key) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// encoding и gob
+
+// MarshalBinary возвращает список в бинарном формате с конвертом
+// (encoding.BinaryMarshaler), поэтому его можно вкладывать в другие структуры
+func (l *ForwardList[T]) MarshalBinary() ([]byte, error) {
+	return persist.Marshal(l.writeBinary)
+}
+
+// UnmarshalBinary читает список из данных MarshalBinary (encoding.BinaryUnmarshaler).
+// Данные должны содержать ровно один конверт.
+func (l *ForwardList[T]) UnmarshalBinary(data []byte) error {
+	return persist.Unmarshal(data, l.readBinary)
+}
+
+// GobEncode кодирует список для encoding/gob (gob.GobEncoder)
+func (l *ForwardList[T]) GobEncode() ([]byte, error) {
+	return l.MarshalBinary()
+}
+
+// GobDecode читает список, закодированный GobEncode (gob.GobDecoder)
+func (l *ForwardList[T]) GobDecode(data []byte) error {
+	return l.UnmarshalBinary(data)
+}
+
+// JSON
+
+// MarshalJSON кодирует список как JSON-массив от головы к хвосту (json.Marshaler)
+func (l *ForwardList[T]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := l.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON читает список из JSON-массива (json.Unmarshaler).
+// При ошибке список не меняется.
+func (l *ForwardList[T]) UnmarshalJSON(data []byte) error {
+	return l.readJSON(bytes.NewReader(data))
+}
+
+// WriteJSONTo пишет список в w как JSON-массив, не собирая его в памяти
+func (l *ForwardList[T]) WriteJSONTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, l.writeJSON)
+}
+
+// ReadJSONFrom читает список из JSON-массива в r до конца потока
+func (l *ForwardList[T]) ReadJSONFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, l.readJSON)
+}
+
+func (l *ForwardList[T]) writeJSON(w io.Writer) error {
+	return codec.EncodeJSONArray(w, func(emit func(T) error) error {
+		for current := l.Head; current != nil; current = current.Next {
+			if err := emit(current.Key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (l *ForwardList[T]) readJSON(r io.Reader) error {
+	// Запоминаем хвост, чтобы не проходить список при каждой вставке
+	var head, tail *SNode[T]
+	err := codec.ReadJSON(r, func(dec *json.Decoder) error {
+		return codec.DecodeJSONArray(dec, "$", func(val T) error {
+			node := &SNode[T]{Key: val}
+			if tail == nil {
+				head = node
+			} else {
+				tail.Next = node
+			}
+			tail = node
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	l.Head = head
+	return nil
+}
+
+// CSV
+
+// ExportCSV пишет список в w в формате CSV от головы к хвосту.
+// Колонки задаются codec.DefaultCSVMapper или опцией codec.WithCSVMapper.
+func (l *ForwardList[T]) ExportCSV(w io.Writer, opts ...codec.CSVOption) error {
+	return codec.WriteCSV(w, func(emit func(T) error) error {
+		for current := l.Head; current != nil; current = current.Next {
+			if err := emit(current.Key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts...)
+}
+
+// ImportCSV заменяет содержимое списка строками CSV из r. Строки, которые
+// не удалось разобрать, пропускаются и возвращаются в codec.RowErrors
+// с номерами строк. При ошибке чтения или заголовка список не меняется.
+func (l *ForwardList[T]) ImportCSV(r io.Reader, opts ...codec.CSVOption) error {
+	var head, tail *SNode[T]
+	rowErrs, err := codec.ReadCSV(r, func(val T) error {
+		node := &SNode[T]{Key: val}
+		if tail == nil {
+			head = node
+		} else {
+			tail.Next = node
+		}
+		tail = node
+		return nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	l.Head = head
+	return rowErrs.Err()
+}
@@ -0,0 +1,189 @@
+// Package sparse реализует разреженный массив: хранятся только ненулевые
+// элементы, поэтому почти пустой массив занимает память по числу заполненных
+// ячеек, а не по длине.
+package sparse
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"sort"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+)
+
+// SparseArray - массив фиксированной длины, в котором отсутствующие элементы
+// равны нулевому значению T. Заполненные ячейки хранятся в двух параллельных
+// срезах, отсортированных по индексу: поиск занимает O(log k), вставка в
+// середину - O(k), где k - количество заполненных ячеек. Запись по
+// возрастанию индексов (типичное построение) стоит O(1).
+type SparseArray[T comparable] struct {
+	indices []int
+	values  []T
+	length  int
+}
+
+// NewSparseArray создает пустой разреженный массив длины length
+func NewSparseArray[T comparable](length int) (*SparseArray[T], error) {
+	if length < 0 {
+		return nil, fmt.Errorf("error: Negative length %d", length)
+	}
+	return &SparseArray[T]{length: length}, nil
+}
+
+// Len возвращает длину массива
+func (s *SparseArray[T]) Len() int {
+	return s.length
+}
+
+// NonZero возвращает количество заполненных (ненулевых) ячеек
+func (s *SparseArray[T]) NonZero() int {
+	return len(s.indices)
+}
+
+// Density возвращает долю заполненных ячеек
+func (s *SparseArray[T]) Density() float64 {
+	if s.length == 0 {
+		return 0
+	}
+	return float64(len(s.indices)) / float64(s.length)
+}
+
+// find возвращает позицию индекса i в s.indices и признак, что он там есть
+func (s *SparseArray[T]) find(i int) (int, bool) {
+	// Быстрый путь для записи по возрастанию
+	if n := len(s.indices); n == 0 || s.indices[n-1] < i {
+		return n, false
+	}
+	pos := sort.SearchInts(s.indices, i)
+	return pos, s.indices[pos] == i
+}
+
+func (s *SparseArray[T]) checkIndex(i int) error {
+	if i < 0 || i >= s.length {
+		return fmt.Errorf("error: Index %d is out of bounds (size %d)", i, s.length)
+	}
+	return nil
+}
+
+// Get возвращает элемент i (нулевое значение, если ячейка пуста)
+func (s *SparseArray[T]) Get(i int) (T, error) {
+	var zero T
+	if err := s.checkIndex(i); err != nil {
+		return zero, err
+	}
+	if pos, ok := s.find(i); ok {
+		return s.values[pos], nil
+	}
+	return zero, nil
+}
+
+// Has сообщает, заполнена ли ячейка i
+func (s *SparseArray[T]) Has(i int) bool {
+	_, ok := s.find(i)
+	return ok
+}
+
+// Set записывает value в ячейку i. Запись нулевого значения освобождает ячейку.
+func (s *SparseArray[T]) Set(i int, value T) error {
+	if err := s.checkIndex(i); err != nil {
+		return err
+	}
+	var zero T
+	pos, ok := s.find(i)
+	switch {
+	case ok && value == zero:
+		s.indices = slices.Delete(s.indices, pos, pos+1)
+		s.values = slices.Delete(s.values, pos, pos+1)
+	case ok:
+		s.values[pos] = value
+	case value != zero:
+		s.indices = slices.Insert(s.indices, pos, i)
+		s.values = slices.Insert(s.values, pos, value)
+	}
+	return nil
+}
+
+// Delete освобождает ячейку i и сообщает, была ли она заполнена
+func (s *SparseArray[T]) Delete(i int) bool {
+	pos, ok := s.find(i)
+	if ok {
+		s.indices = slices.Delete(s.indices, pos, pos+1)
+		s.values = slices.Delete(s.values, pos, pos+1)
+	}
+	return ok
+}
+
+// Resize меняет длину массива. Заполненные ячейки за новой длиной удаляются.
+func (s *SparseArray[T]) Resize(length int) error {
+	if length < 0 {
+		return fmt.Errorf("error: Negative length %d", length)
+	}
+	pos := sort.SearchInts(s.indices, length)
+	clear(s.values[pos:]) // Не держим ссылки из удаленных значений
+	s.indices = s.indices[:pos]
+	s.values = s.values[:pos]
+	s.length = length
+	return nil
+}
+
+// Clear освобождает все ячейки, сохраняя длину
+func (s *SparseArray[T]) Clear() {
+	s.indices = nil
+	s.values = nil
+}
+
+// Clone создает копию массива
+func (s *SparseArray[T]) Clone() *SparseArray[T] {
+	return &SparseArray[T]{
+		indices: slices.Clone(s.indices),
+		values:  slices.Clone(s.values),
+		length:  s.length,
+	}
+}
+
+// All возвращает итератор по заполненным ячейкам (индекс, значение)
+// по возрастанию индекса
+func (s *SparseArray[T]) All() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for pos, i := range s.indices {
+			if !yield(i, s.values[pos]) {
+				return
+			}
+		}
+	}
+}
+
+// Плотное представление
+
+// FromArray создает разреженный массив из ненулевых элементов a
+func FromArray[T comparable](a *array.Array[T]) *SparseArray[T] {
+	return FromSlice(a.ToSlice())
+}
+
+// FromSlice создает разреженный массив из ненулевых элементов data
+func FromSlice[T comparable](data []T) *SparseArray[T] {
+	var zero T
+	s := &SparseArray[T]{length: len(data)}
+	for i, v := range data {
+		if v != zero {
+			s.indices = append(s.indices, i)
+			s.values = append(s.values, v)
+		}
+	}
+	return s
+}
+
+// ToSlice возвращает плотную копию: срез длины Len с нулями в пустых ячейках
+func (s *SparseArray[T]) ToSlice() []T {
+	data := make([]T, s.length)
+	for pos, i := range s.indices {
+		data[i] = s.values[pos]
+	}
+	return data
+}
+
+// ToArray возвращает плотную копию в виде array.Array
+func (s *SparseArray[T]) ToArray() *array.Array[T] {
+	return array.FromSlice(s.ToSlice())
+}
@@ -0,0 +1,114 @@
+package sparse
+
+import (
+	"math/rand/v2"
+	"testing"
+	"unsafe"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+)
+
+const (
+	Length  = 1 << 20 // Длина массива
+	Filled  = 1 << 10 // Заполненных ячеек (0.1%)
+	Spacing = Length / Filled
+)
+
+// randomIndices возвращает n случайных индексов в [0, Length)
+func randomIndices(n int) []int {
+	r := rand.New(rand.NewPCG(1, 2))
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = r.IntN(Length)
+	}
+	return idx
+}
+
+// BenchmarkBuild сравнивает построение почти пустого массива
+func BenchmarkBuild(b *testing.B) {
+	b.Run("SparseSequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s, _ := NewSparseArray[int64](Length)
+			for j := 0; j < Length; j += Spacing {
+				_ = s.Set(j, int64(j))
+			}
+		}
+		b.ReportMetric(float64(Filled*(unsafe.Sizeof(0)+unsafe.Sizeof(int64(0)))), "bytes/array")
+	})
+
+	b.Run("SparseRandom", func(b *testing.B) {
+		idx := randomIndices(Filled)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s, _ := NewSparseArray[int64](Length)
+			for _, j := range idx {
+				_ = s.Set(j, int64(j))
+			}
+		}
+	})
+
+	b.Run("Dense", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			arr := array.FromSlice(make([]int64, Length))
+			for j := 0; j < Length; j += Spacing {
+				_ = arr.Set(j, int64(j))
+			}
+		}
+		b.ReportMetric(float64(Length*unsafe.Sizeof(int64(0))), "bytes/array")
+	})
+}
+
+// BenchmarkGet сравнивает чтение по случайным индексам
+func BenchmarkGet(b *testing.B) {
+	s, _ := NewSparseArray[int64](Length)
+	dense := make([]int64, Length)
+	for j := 0; j < Length; j += Spacing {
+		_ = s.Set(j, int64(j))
+		dense[j] = int64(j)
+	}
+	arr := array.FromSlice(dense)
+	idx := randomIndices(4096)
+	b.ResetTimer()
+
+	b.Run("Sparse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = s.Get(idx[i%len(idx)])
+		}
+	})
+
+	b.Run("Dense", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = arr.Get(idx[i%len(idx)])
+		}
+	})
+}
+
+// BenchmarkSum сравнивает обход заполненных ячеек с обходом всего массива
+func BenchmarkSum(b *testing.B) {
+	s, _ := NewSparseArray[int64](Length)
+	for j := 0; j < Length; j += Spacing {
+		_ = s.Set(j, int64(j))
+	}
+	arr := s.ToArray()
+	b.ResetTimer()
+
+	b.Run("Sparse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var sum int64
+			for _, v := range s.All() {
+				sum += v
+			}
+			_ = sum
+		}
+	})
+
+	b.Run("Dense", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var sum int64
+			for v := range arr.All() {
+				sum += v
+			}
+			_ = sum
+		}
+	})
+}
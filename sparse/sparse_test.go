@@ -0,0 +1,118 @@
+package sparse
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/array"
+)
+
+func TestSetGet(t *testing.T) {
+	s, err := NewSparseArray[int](100)
+	if err != nil {
+		t.Fatalf("NewSparseArray error: %v", err)
+	}
+	if _, err := NewSparseArray[int](-1); err == nil {
+		t.Error("negative length returned nil error")
+	}
+
+	// Запись вразнобой: вставки в начало, середину и конец
+	for _, i := range []int{50, 10, 90, 30, 70} {
+		if err := s.Set(i, i*2); err != nil {
+			t.Fatalf("Set(%d) error: %v", i, err)
+		}
+	}
+	if s.NonZero() != 5 || s.Len() != 100 {
+		t.Errorf("NonZero=%d Len=%d", s.NonZero(), s.Len())
+	}
+	if v, err := s.Get(30); err != nil || v != 60 {
+		t.Errorf("Get(30) = %d, %v", v, err)
+	}
+	if v, err := s.Get(31); err != nil || v != 0 {
+		t.Errorf("Get(31) = %d, %v, want zero", v, err)
+	}
+	if !s.Has(90) || s.Has(91) {
+		t.Error("Has is wrong")
+	}
+
+	// Обновление и запись нуля
+	_ = s.Set(50, 7)
+	_ = s.Set(10, 0)
+	_ = s.Set(11, 0) // пустая ячейка остается пустой
+	got := maps.Collect(s.All())
+	want := map[int]int{30: 60, 50: 7, 70: 140, 90: 180}
+	if !maps.Equal(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+
+	var order []int
+	for i := range s.All() {
+		order = append(order, i)
+	}
+	if !slices.IsSorted(order) {
+		t.Errorf("All is not sorted by index: %v", order)
+	}
+
+	for _, i := range []int{-1, 100} {
+		if _, err := s.Get(i); err == nil {
+			t.Errorf("Get(%d) returned nil error", i)
+		}
+		if err := s.Set(i, 1); err == nil {
+			t.Errorf("Set(%d) returned nil error", i)
+		}
+	}
+	if s.Density() != 0.04 {
+		t.Errorf("Density = %v, want 0.04", s.Density())
+	}
+}
+
+func TestDeleteResize(t *testing.T) {
+	s := FromSlice([]string{"", "a", "", "b", "", "c"})
+	if s.Len() != 6 || s.NonZero() != 3 {
+		t.Fatalf("FromSlice: Len=%d NonZero=%d", s.Len(), s.NonZero())
+	}
+	if !s.Delete(3) || s.Delete(3) || s.Delete(0) {
+		t.Error("Delete results are wrong")
+	}
+
+	clone := s.Clone()
+	if err := s.Resize(4); err != nil {
+		t.Fatalf("Resize error: %v", err)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []string{"", "a", "", ""}) {
+		t.Errorf("after Resize(4) = %q", got)
+	}
+	_ = s.Resize(8)
+	if s.Has(5) || s.Len() != 8 {
+		t.Error("growing Resize restored a deleted cell")
+	}
+	if err := s.Resize(-1); err == nil {
+		t.Error("Resize(-1) returned nil error")
+	}
+
+	if got := clone.ToSlice(); !slices.Equal(got, []string{"", "a", "", "", "", "c"}) {
+		t.Errorf("clone changed: %q", got)
+	}
+	clone.Clear()
+	if clone.NonZero() != 0 || clone.Len() != 6 {
+		t.Errorf("after Clear: NonZero=%d Len=%d", clone.NonZero(), clone.Len())
+	}
+}
+
+func TestDenseConversion(t *testing.T) {
+	dense := array.FromSlice([]float64{0, 1.5, 0, 0, -2, 0})
+	s := FromArray(dense)
+	if s.NonZero() != 2 {
+		t.Errorf("NonZero = %d, want 2", s.NonZero())
+	}
+	back := s.ToArray()
+	if got := back.ToSlice(); !slices.Equal(got, dense.ToSlice()) {
+		t.Errorf("ToArray = %v", got)
+	}
+
+	empty := FromSlice([]int(nil))
+	if empty.Len() != 0 || len(empty.ToSlice()) != 0 || empty.Density() != 0 {
+		t.Error("empty conversion is wrong")
+	}
+}
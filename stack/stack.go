@@ -1,159 +1,378 @@
-package stack
-
-import (
-	"encoding/gob"
-	"errors"
-	"fmt"
-	"os"
-)
-
-// Stack реализует структуру данных стек
-type Stack[T any] struct {
-	data []T
-}
-
-// NewStack создает новый пустой стек.
-func NewStack[T any]() *Stack[T] {
-	return &Stack[T]{
-		data: make([]T, 0, 1),
-	}
-}
-
-// NewStackWithCapacity создает стек с заданной начальной емкостью.
-func NewStackWithCapacity[T any](cap int) (*Stack[T], error) {
-	if cap <= 0 {
-		return nil, errors.New("initial capacity must be greater than 0")
-	}
-	return &Stack[T]{
-		data: make([]T, 0, cap),
-	}, nil
-}
-
-// Push добавляет элемент в стек.
-func (s *Stack[T]) Push(value T) {
-	s.data = append(s.data, value)
-}
-
-// Pop извлекает элемент с вершины стека.
-func (s *Stack[T]) Pop() (T, error) {
-	var zero T
-	if len(s.data) == 0 {
-		return zero, errors.New("stack underflow: cannot pop from an empty stack")
-	}
-
-	index := len(s.data) - 1
-	val := s.data[index]
-
-	// Удаляем элемент из слайса (срезаем)
-	s.data = s.data[:index]
-
-	return val, nil
-}
-
-// Size возвращает текущее количество элементов.
-func (s *Stack[T]) Size() int {
-	return len(s.data)
-}
-
-// Print выводит содержимое стека в консоль.
-func (s *Stack[T]) Print() {
-	for _, v := range s.data {
-		fmt.Printf("%v ", v)
-	}
-	fmt.Println()
-}
-
-// Clone создает глубокую копию стека
-func (s *Stack[T]) Clone() *Stack[T] {
-	newData := make([]T, len(s.data), cap(s.data))
-	copy(newData, s.data)
-	return &Stack[T]{data: newData}
-}
-
-// SaveText сохраняет стек в текстовый файл
-func (s *Stack[T]) SaveText(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("could not open file for writing: %w", err)
-	}
-	defer file.Close()
-
-	// Записываем размер
-	if _, err := fmt.Fprintln(file, len(s.data)); err != nil {
-		return err
-	}
-
-	// Записываем данные через пробел
-	for _, v := range s.data {
-		if _, err := fmt.Fprintf(file, "%v ", v); err != nil {
-			return err
-		}
-	}
-	fmt.Println("Стек сохранён в файл:", filename)
-	return nil
-}
-
-// LoadText загружает стек из текстового файла
-func (s *Stack[T]) LoadText(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("could not open file for reading: %w", err)
-	}
-	defer file.Close()
-
-	var size int
-	if _, err := fmt.Fscan(file, &size); err != nil {
-		return fmt.Errorf("failed to read stack size: %w", err)
-	}
-
-	// Очищаем текущий стек и выделяем память
-	s.data = make([]T, 0, size)
-
-	for i := 0; i < size; i++ {
-		var val T
-		// Fscan автоматически парсит типы, разделенные пробелами
-		if _, err := fmt.Fscan(file, &val); err != nil {
-			return fmt.Errorf("failed to read data at index %d: %w", i, err)
-		}
-		s.Push(val)
-	}
-
-	fmt.Println("Стек загружен из файла:", filename)
-	return nil
-}
-
-// SaveBinary сохраняет стек в бинарном формате
-func (s *Stack[T]) SaveBinary(filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("could not open binary file for writing: %w", err)
-	}
-	defer file.Close()
-
-	encoder := gob.NewEncoder(file)
-	if err := encoder.Encode(s.data); err != nil {
-		return fmt.Errorf("failed to encode data: %w", err)
-	}
-
-	fmt.Println("Стек сохранён (bin):", filename)
-	return nil
-}
-
-// LoadBinary загружает стек из бинарного файла
-func (s *Stack[T]) LoadBinary(filename string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return fmt.Errorf("could not open binary file for reading: %w", err)
-	}
-	defer file.Close()
-
-	decoder := gob.NewDecoder(file)
-	var newData []T
-	if err := decoder.Decode(&newData); err != nil {
-		return fmt.Errorf("failed to decode data: %w", err)
-	}
-
-	s.data = newData
-	fmt.Println("Стек загружен (bin):", filename)
-	return nil
-}
+package stack
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Stack реализует структуру данных стек
+type Stack[T any] struct {
+	data []T
+}
+
+// NewStack создает новый пустой стек.
+func NewStack[T any]() *Stack[T] {
+	return &Stack[T]{
+		data: make([]T, 0, 1),
+	}
+}
+
+// NewStackWithCapacity создает стек с заданной начальной емкостью.
+func NewStackWithCapacity[T any](cap int) (*Stack[T], error) {
+	if cap <= 0 {
+		return nil, errors.New("initial capacity must be greater than 0")
+	}
+	return &Stack[T]{
+		data: make([]T, 0, cap),
+	}, nil
+}
+
+// Push добавляет элемент в стек.
+func (s *Stack[T]) Push(value T) {
+	s.data = append(s.data, value)
+}
+
+// Pop извлекает элемент с вершины стека.
+func (s *Stack[T]) Pop() (T, error) {
+	var zero T
+	if len(s.data) == 0 {
+		return zero, errors.New("stack underflow: cannot pop from an empty stack")
+	}
+
+	index := len(s.data) - 1
+	val := s.data[index]
+
+	// Удаляем элемент из слайса (срезаем)
+	s.data = s.data[:index]
+
+	return val, nil
+}
+
+// Size возвращает текущее количество элементов.
+func (s *Stack[T]) Size() int {
+	return len(s.data)
+}
+
+// Print выводит содержимое стека в консоль.
+func (s *Stack[T]) Print() {
+	for _, v := range s.data {
+		fmt.Printf("%v ", v)
+	}
+	fmt.Println()
+}
+
+// Clone создает глубокую копию стека
+func (s *Stack[T]) Clone() *Stack[T] {
+	newData := make([]T, len(s.data), cap(s.data))
+	copy(newData, s.data)
+	return &Stack[T]{data: newData}
+}
+
+// SaveText сохраняет стек в текстовый файл
+func (s *Stack[T]) SaveText(filename string, opts ...persist.Option) error {
+	if err := persist.WriteFile(filename, s.writeText, opts...); err != nil {
+		return err
+	}
+	fmt.Println("Стек сохранён в файл:", filename)
+	return nil
+}
+
+// LoadText загружает стек из текстового файла
+func (s *Stack[T]) LoadText(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("could not open file for reading: %w", err)
+	}
+	defer file.Close()
+
+	if err := persist.Load(file, compression.NewReader, s.readText, opts...); err != nil {
+		return err
+	}
+	fmt.Println("Стек загружен из файла:", filename)
+	return nil
+}
+
+// WriteTextTo записывает стек в w в текстовом формате
+func (s *Stack[T]) WriteTextTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, s.writeText)
+}
+
+// ReadTextFrom читает стек в текстовом формате из r
+func (s *Stack[T]) ReadTextFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, s.readText)
+}
+
+func (s *Stack[T]) writeText(w io.Writer) error {
+	// Записываем размер
+	if _, err := fmt.Fprintln(w, len(s.data)); err != nil {
+		return err
+	}
+
+	// Записываем данные по одному в строке
+	for _, v := range s.data {
+		tok, err := codec.FormatText(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, tok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Stack[T]) readText(r io.Reader) error {
+	sc := codec.NewTextScanner(r)
+	size, err := codec.ScanText[int](sc)
+	if err != nil {
+		return fmt.Errorf("failed to read stack size: %w", err)
+	}
+	if size < 0 {
+		return fmt.Errorf("invalid stack size %d", size)
+	}
+
+	// Очищаем текущий стек; растим по частям, как codec.DecodeN
+	s.data = make([]T, 0, min(size, codec.ChunkSize))
+
+	for i := 0; i < size; i++ {
+		val, err := codec.ScanText[T](sc)
+		if err != nil {
+			return fmt.Errorf("failed to read data at index %d: %w", i, err)
+		}
+		s.Push(val)
+	}
+	return nil
+}
+
+// SaveBinary сохраняет стек в бинарном формате
+func (s *Stack[T]) SaveBinary(filename string, opts ...persist.Option) error {
+	if err := persist.WriteFile(filename, s.writeBinary, opts...); err != nil {
+		return err
+	}
+	fmt.Println("Стек сохранён (bin):", filename)
+	return nil
+}
+
+// LoadBinary загружает стек из бинарного файла
+func (s *Stack[T]) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("could not open binary file for reading: %w", err)
+	}
+	defer file.Close()
+
+	if err := persist.Load(file, compression.NewReader, s.readBinary, opts...); err != nil {
+		return err
+	}
+	fmt.Println("Стек загружен (bin):", filename)
+	return nil
+}
+
+// WriteTo записывает стек в w в бинарном формате (io.WriterTo)
+func (s *Stack[T]) WriteTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, s.writeBinary)
+}
+
+// ReadFrom читает стек в бинарном формате из r (io.ReaderFrom).
+// gob может прочитать из r больше байт, чем занимает стек, если r не io.ByteReader.
+func (s *Stack[T]) ReadFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, s.readBinary)
+}
+
+// writeBinary пишет стек в конверте persist с видом и типом элементов
+func (s *Stack[T]) writeBinary(w io.Writer) error {
+	return persist.WriteEnvelope(w, persist.KindStack, persist.TypeName[T](), s.writePayload)
+}
+
+// readBinary проверяет конверт и читает стек
+func (s *Stack[T]) readBinary(r io.Reader) error {
+	return persist.ReadEnvelope(r, persist.KindStack, persist.TypeName[T](), s.readPayload)
+}
+
+// writePayload пишет стек сообщениями gob по codec.ChunkSize элементов, чтобы
+// загрузка не декодировала весь стек одним сообщением
+func (s *Stack[T]) writePayload(w io.Writer) error {
+	encoder := gob.NewEncoder(w)
+	for start := 0; start < len(s.data); start += codec.ChunkSize {
+		chunk := s.data[start:min(start+codec.ChunkSize, len(s.data))]
+		if err := encoder.Encode(chunk); err != nil {
+			return fmt.Errorf("failed to encode data: %w", err)
+		}
+	}
+	return nil
+}
+
+// readPayload читает сообщения gob до конца данных. Старые файлы с одним
+// сообщением на весь стек читаются так же.
+func (s *Stack[T]) readPayload(r io.Reader) error {
+	decoder := gob.NewDecoder(r)
+	var newData []T
+	for {
+		var chunk []T
+		if err := decoder.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("failed to decode data: %w", err)
+		}
+		newData = append(newData, chunk...)
+	}
+	s.data = newData
+	return nil
+}
+
+// Срезы и последовательности
+
+// FromSlice создает стек из копии s: s[0] оказывается на дне, последний элемент - на вершине
+func FromSlice[T any](s []T) *Stack[T] {
+	data := make([]T, len(s), max(len(s), 1))
+	copy(data, s)
+	return &Stack[T]{data: data}
+}
+
+// FromSeq помещает элементы seq в новый стек по порядку: последний становится вершиной
+func FromSeq[T any](seq iter.Seq[T]) *Stack[T] {
+	s := NewStack[T]()
+	for v := range seq {
+		s.Push(v)
+	}
+	return s
+}
+
+// ToSlice возвращает элементы стека от дна к вершине
+func (s *Stack[T]) ToSlice() []T {
+	return slices.Clone(s.data)
+}
+
+// Итераторы
+//
+// All и Enumerate идут от дна к вершине, как ToSlice, а Backward - от вершины
+// к дну, в порядке Pop. Итераторы не копируют стек: шаг i читает элемент с
+// индексом i от дна в текущем состоянии. Поэтому Push в теле цикла All
+// продлевает обход, а Pop его укорачивает. Backward начинает с вершины на
+// момент вызова и пропускает снятые элементы. Стек не синхронизирован:
+// изменение из другой горутины во время обхода - гонка данных.
+
+// All возвращает итератор по элементам от дна к вершине
+func (s *Stack[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := 0; i < len(s.data); i++ {
+			if !yield(s.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Backward возвращает итератор по элементам от вершины к дну
+func (s *Stack[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := len(s.data) - 1; i >= 0; i-- {
+			if i >= len(s.data) {
+				continue
+			}
+			if !yield(s.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Enumerate возвращает итератор по парам (индекс от дна, элемент)
+func (s *Stack[T]) Enumerate() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i := 0; i < len(s.data); i++ {
+			if !yield(i, s.data[i]) {
+				return
+			}
+		}
+	}
+}
+
+// encoding и gob
+
+// MarshalBinary возвращает стек в бинарном формате с конвертом
+// (encoding.BinaryMarshaler), поэтому его можно вкладывать в другие структуры
+func (s *Stack[T]) MarshalBinary() ([]byte, error) {
+	return persist.Marshal(s.writeBinary)
+}
+
+// UnmarshalBinary читает стек из данных MarshalBinary (encoding.BinaryUnmarshaler).
+// Данные должны содержать ровно один конверт.
+func (s *Stack[T]) UnmarshalBinary(data []byte) error {
+	return persist.Unmarshal(data, s.readBinary)
+}
+
+// GobEncode кодирует стек для encoding/gob (gob.GobEncoder)
+func (s *Stack[T]) GobEncode() ([]byte, error) {
+	return s.MarshalBinary()
+}
+
+// GobDecode читает стек, закодированный GobEncode (gob.GobDecoder)
+func (s *Stack[T]) GobDecode(data []byte) error {
+	return s.UnmarshalBinary(data)
+}
+
+// JSON
+
+// MarshalJSON кодирует стек как JSON-массив от дна к вершине (json.Marshaler)
+func (s *Stack[T]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON читает стек из JSON-массива (json.Unmarshaler).
+// Последний элемент массива становится вершиной. При ошибке стек не меняется.
+func (s *Stack[T]) UnmarshalJSON(data []byte) error {
+	return s.readJSON(bytes.NewReader(data))
+}
+
+// WriteJSONTo пишет стек в w как JSON-массив, не собирая его в памяти
+func (s *Stack[T]) WriteJSONTo(w io.Writer) (int64, error) {
+	return persist.WriteCounted(w, s.writeJSON)
+}
+
+// ReadJSONFrom читает стек из JSON-массива в r до конца потока
+func (s *Stack[T]) ReadJSONFrom(r io.Reader) (int64, error) {
+	return persist.ReadCounted(r, s.readJSON)
+}
+
+func (s *Stack[T]) writeJSON(w io.Writer) error {
+	return codec.EncodeJSONArray(w, func(emit func(T) error) error {
+		for _, val := range s.data {
+			if err := emit(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Stack[T]) readJSON(r io.Reader) error {
+	data := make([]T, 0, 1)
+	err := codec.ReadJSON(r, func(dec *json.Decoder) error {
+		return codec.DecodeJSONArray(dec, "$", func(val T) error {
+			data = append(data, val)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.data = data
+	return nil
+}
@@ -1,281 +1,484 @@
-package stack
-
-import (
-	"bytes"
-	"io"
-	"math"
-	"os"
-	"path/filepath"
-	"strings"
-	"testing"
-)
-
-// Вспомогательные функции
-
-// captureOutput перехватывает вывод в stdout для проверки метода Print
-func captureOutput(f func()) string {
-	old := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-
-	f()
-
-	w.Close()
-	os.Stdout = old
-
-	var buf bytes.Buffer
-	io.Copy(&buf, r)
-	return buf.String()
-}
-
-// Тесты базовой функциональности
-
-func TestNewStack(t *testing.T) {
-	s := NewStack[int]()
-	if s == nil {
-		t.Fatal("NewStack returned nil")
-	}
-	if s.Size() != 0 {
-		t.Errorf("Expected size 0, got %d", s.Size())
-	}
-	// Проверка начальной емкости
-}
-
-func TestNewStackWithCapacity(t *testing.T) {
-	// Успешное создание
-	s, err := NewStackWithCapacity[string](10)
-	if err != nil {
-		t.Fatalf("Unexpected error: %v", err)
-	}
-	if s.Size() != 0 {
-		t.Errorf("Expected size 0, got %d", s.Size())
-	}
-
-	// Ошибка (capacity <= 0)
-	_, err = NewStackWithCapacity[string](0)
-	if err == nil {
-		t.Error("Expected error for capacity 0, got nil")
-	}
-	if err.Error() != "initial capacity must be greater than 0" {
-		t.Errorf("Incorrect error message: %v", err)
-	}
-
-	_, err = NewStackWithCapacity[string](-5)
-	if err == nil {
-		t.Error("Expected error for negative capacity, got nil")
-	}
-}
-
-func TestPushAndPop(t *testing.T) {
-	s := NewStack[int]()
-
-	// Push
-	s.Push(10)
-	s.Push(20)
-	if s.Size() != 2 {
-		t.Errorf("Expected size 2, got %d", s.Size())
-	}
-
-	// Pop успешно
-	val, err := s.Pop()
-	if err != nil {
-		t.Errorf("Unexpected error on Pop: %v", err)
-	}
-	if val != 20 {
-		t.Errorf("Expected 20, got %d", val)
-	}
-
-	val, err = s.Pop()
-	if err != nil {
-		t.Errorf("Unexpected error on Pop: %v", err)
-	}
-	if val != 10 {
-		t.Errorf("Expected 10, got %d", val)
-	}
-
-	// Pop из пустого стека (Ошибка)
-	_, err = s.Pop()
-	if err == nil {
-		t.Error("Expected error when popping from empty stack")
-	}
-	if err.Error() != "stack underflow: cannot pop from an empty stack" {
-		t.Errorf("Incorrect error message: %v", err)
-	}
-}
-
-func TestAutoResize(t *testing.T) {
-	s, _ := NewStackWithCapacity[int](1)
-	// Добавляем больше элементов, чем начальная емкость
-	for i := 0; i < 10; i++ {
-		s.Push(i)
-	}
-	if s.Size() != 10 {
-		t.Errorf("Expected size 10, got %d", s.Size())
-	}
-}
-
-func TestClone(t *testing.T) {
-	original := NewStack[int]()
-	original.Push(1)
-	original.Push(2)
-
-	clone := original.Clone()
-
-	// Проверка независимости
-	clone.Pop()
-	clone.Push(999)
-
-	if original.Size() != 2 {
-		t.Error("Original stack modified after clone manipulation")
-	}
-
-	val, _ := original.Pop()
-	if val != 2 {
-		t.Error("Original stack content corrupted")
-	}
-}
-
-func TestPrint(t *testing.T) {
-	output := captureOutput(func() {
-		s := NewStack[string]()
-		s.Push("A")
-		s.Push("B")
-		s.Print()
-	})
-
-	expected := "A B \n"
-	if output != expected {
-		if !strings.Contains(output, "A B") {
-			t.Errorf("Expected output containing 'A B', got %q", output)
-		}
-	}
-}
-
-// Тесты сохранения/загрузки (включая ошибки)
-
-func TestSaveText(t *testing.T) {
-	tmpDir := t.TempDir()
-	goodFile := filepath.Join(tmpDir, "stack.txt")
-
-	// Успешное сохранение
-	s := NewStack[int]()
-	s.Push(100)
-	s.Push(200)
-	if err := s.SaveText(goodFile); err != nil {
-		t.Fatalf("SaveText failed: %v", err)
-	}
-
-	// Проверяем содержимое файла вручную, чтобы убедиться в формате
-	content, _ := os.ReadFile(goodFile)
-	strContent := string(content)
-	if !strings.Contains(strContent, "2") || !strings.Contains(strContent, "100 200") {
-		t.Errorf("File content unexpected: %s", strContent)
-	}
-
-	// Ошибка создания файла
-	if err := s.SaveText(tmpDir); err == nil {
-		t.Error("Expected error when saving to a directory path, got nil")
-	}
-}
-
-func TestLoadText(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Успешная загрузка
-	goodFile := filepath.Join(tmpDir, "good_load.txt")
-	// Создаем валидный файл: Размер 2, данные 10 20
-	os.WriteFile(goodFile, []byte("2\n10 20"), 0644)
-
-	s := NewStack[int]()
-	if err := s.LoadText(goodFile); err != nil {
-		t.Fatalf("LoadText failed: %v", err)
-	}
-	if s.Size() != 2 {
-		t.Errorf("Expected size 2, got %d", s.Size())
-	}
-
-	// Ошибка открытия файла (нет файла)
-	if err := s.LoadText(filepath.Join(tmpDir, "non_existent.txt")); err == nil {
-		t.Error("Expected error loading non-existent file")
-	}
-
-	// Ошибка чтения размера (файл пустой или мусор)
-	badSizeFile := filepath.Join(tmpDir, "bad_size.txt")
-	os.WriteFile(badSizeFile, []byte("NOT_A_NUMBER"), 0644)
-	if err := s.LoadText(badSizeFile); err == nil {
-		t.Error("Expected error parsing invalid size")
-	} else if !strings.Contains(err.Error(), "failed to read stack size") {
-		t.Errorf("Unexpected error message: %v", err)
-	}
-
-	// Ошибка чтения данных (размер указан больше, чем данных)
-	badDataFile := filepath.Join(tmpDir, "bad_data.txt")
-	// Обещаем 5 элементов, даем 1
-	os.WriteFile(badDataFile, []byte("5\n10"), 0644)
-	if err := s.LoadText(badDataFile); err == nil {
-		t.Error("Expected error reading insufficient data")
-	} else if !strings.Contains(err.Error(), "failed to read data at index") {
-		t.Errorf("Unexpected error message: %v", err)
-	}
-}
-
-func TestSaveBinary(t *testing.T) {
-	tmpDir := t.TempDir()
-	goodFile := filepath.Join(tmpDir, "stack.bin")
-
-	s := NewStack[float64]()
-	s.Push(3.14)
-
-	// Успешное сохранение
-	if err := s.SaveBinary(goodFile); err != nil {
-		t.Fatalf("SaveBinary failed: %v", err)
-	}
-
-	// Ошибка создания файла (путь - директория)
-	if err := s.SaveBinary(tmpDir); err == nil {
-		t.Error("Expected error saving binary to directory")
-	}
-
-	sFunc := NewStack[func()]()
-	sFunc.Push(func() {}) // Функции нельзя сериализовать
-
-	binFileFunc := filepath.Join(tmpDir, "func.bin")
-	if err := sFunc.SaveBinary(binFileFunc); err == nil {
-		t.Error("Expected gob encode error for unserializable type (func)")
-	}
-}
-
-func TestLoadBinary(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Подготовка файла для успешной загрузки
-	goodFile := filepath.Join(tmpDir, "stack.bin")
-	sOrig := NewStack[float64]()
-	sOrig.Push(1.23)
-	sOrig.SaveBinary(goodFile)
-
-	// Успешная загрузка
-	sLoad := NewStack[float64]()
-	if err := sLoad.LoadBinary(goodFile); err != nil {
-		t.Fatalf("LoadBinary failed: %v", err)
-	}
-	val, _ := sLoad.Pop()
-	if math.Abs(val-1.23) > 0.0001 {
-		t.Errorf("Expected 1.23, got %f", val)
-	}
-
-	// Ошибка открытия (файл не существует)
-	if err := sLoad.LoadBinary("non_existent.bin"); err == nil {
-		t.Error("Expected error opening non-existent binary file")
-	}
-
-	// Ошибка декодирования (коррумпированный файл)
-	badFile := filepath.Join(tmpDir, "corrupt.bin")
-	os.WriteFile(badFile, []byte("THIS IS NOT GOB DATA"), 0644)
-
-	if err := sLoad.LoadBinary(badFile); err == nil {
-		t.Error("Expected error decoding corrupt binary file")
-	} else if !strings.Contains(err.Error(), "failed to decode data") {
-		t.Errorf("Unexpected error message: %v", err)
-	}
-}
+package stack
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+	"github.com/D4ROVAN1E/LR_3_Go/persist/persisttest"
+)
+
+// Вспомогательные функции
+
+// captureOutput перехватывает вывод в stdout для проверки метода Print
+func captureOutput(f func()) string {
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	f()
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+// Тесты базовой функциональности
+
+func TestNewStack(t *testing.T) {
+	s := NewStack[int]()
+	if s == nil {
+		t.Fatal("NewStack returned nil")
+	}
+	if s.Size() != 0 {
+		t.Errorf("Expected size 0, got %d", s.Size())
+	}
+	// Проверка начальной емкости
+}
+
+func TestNewStackWithCapacity(t *testing.T) {
+	// Успешное создание
+	s, err := NewStackWithCapacity[string](10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.Size() != 0 {
+		t.Errorf("Expected size 0, got %d", s.Size())
+	}
+
+	// Ошибка (capacity <= 0)
+	_, err = NewStackWithCapacity[string](0)
+	if err == nil {
+		t.Error("Expected error for capacity 0, got nil")
+	}
+	if err.Error() != "initial capacity must be greater than 0" {
+		t.Errorf("Incorrect error message: %v", err)
+	}
+
+	_, err = NewStackWithCapacity[string](-5)
+	if err == nil {
+		t.Error("Expected error for negative capacity, got nil")
+	}
+}
+
+func TestPushAndPop(t *testing.T) {
+	s := NewStack[int]()
+
+	// Push
+	s.Push(10)
+	s.Push(20)
+	if s.Size() != 2 {
+		t.Errorf("Expected size 2, got %d", s.Size())
+	}
+
+	// Pop успешно
+	val, err := s.Pop()
+	if err != nil {
+		t.Errorf("Unexpected error on Pop: %v", err)
+	}
+	if val != 20 {
+		t.Errorf("Expected 20, got %d", val)
+	}
+
+	val, err = s.Pop()
+	if err != nil {
+		t.Errorf("Unexpected error on Pop: %v", err)
+	}
+	if val != 10 {
+		t.Errorf("Expected 10, got %d", val)
+	}
+
+	// Pop из пустого стека (Ошибка)
+	_, err = s.Pop()
+	if err == nil {
+		t.Error("Expected error when popping from empty stack")
+	}
+	if err.Error() != "stack underflow: cannot pop from an empty stack" {
+		t.Errorf("Incorrect error message: %v", err)
+	}
+}
+
+func TestAutoResize(t *testing.T) {
+	s, _ := NewStackWithCapacity[int](1)
+	// Добавляем больше элементов, чем начальная емкость
+	for i := 0; i < 10; i++ {
+		s.Push(i)
+	}
+	if s.Size() != 10 {
+		t.Errorf("Expected size 10, got %d", s.Size())
+	}
+}
+
+func TestClone(t *testing.T) {
+	original := NewStack[int]()
+	original.Push(1)
+	original.Push(2)
+
+	clone := original.Clone()
+
+	// Проверка независимости
+	clone.Pop()
+	clone.Push(999)
+
+	if original.Size() != 2 {
+		t.Error("Original stack modified after clone manipulation")
+	}
+
+	val, _ := original.Pop()
+	if val != 2 {
+		t.Error("Original stack content corrupted")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	output := captureOutput(func() {
+		s := NewStack[string]()
+		s.Push("A")
+		s.Push("B")
+		s.Print()
+	})
+
+	expected := "A B \n"
+	if output != expected {
+		if !strings.Contains(output, "A B") {
+			t.Errorf("Expected output containing 'A B', got %q", output)
+		}
+	}
+}
+
+// Тесты сохранения/загрузки (включая ошибки)
+
+func TestSaveText(t *testing.T) {
+	tmpDir := t.TempDir()
+	goodFile := filepath.Join(tmpDir, "stack.txt")
+
+	// Успешное сохранение
+	s := NewStack[int]()
+	s.Push(100)
+	s.Push(200)
+	if err := s.SaveText(goodFile); err != nil {
+		t.Fatalf("SaveText failed: %v", err)
+	}
+
+	// Проверяем содержимое файла вручную, чтобы убедиться в формате
+	content, _ := os.ReadFile(goodFile)
+	strContent := string(content)
+	if strContent != "2\n100\n200\n" {
+		t.Errorf("File content unexpected: %s", strContent)
+	}
+
+	// Ошибка создания файла
+	if err := s.SaveText(tmpDir); err == nil {
+		t.Error("Expected error when saving to a directory path, got nil")
+	}
+}
+
+func TestLoadText(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Успешная загрузка
+	goodFile := filepath.Join(tmpDir, "good_load.txt")
+	// Создаем валидный файл: Размер 2, данные 10 20
+	os.WriteFile(goodFile, []byte("2\n10 20"), 0644)
+
+	s := NewStack[int]()
+	if err := s.LoadText(goodFile); err != nil {
+		t.Fatalf("LoadText failed: %v", err)
+	}
+	if s.Size() != 2 {
+		t.Errorf("Expected size 2, got %d", s.Size())
+	}
+
+	// Ошибка открытия файла (нет файла)
+	if err := s.LoadText(filepath.Join(tmpDir, "non_existent.txt")); err == nil {
+		t.Error("Expected error loading non-existent file")
+	}
+
+	// Ошибка чтения размера (файл пустой или мусор)
+	badSizeFile := filepath.Join(tmpDir, "bad_size.txt")
+	os.WriteFile(badSizeFile, []byte("NOT_A_NUMBER"), 0644)
+	if err := s.LoadText(badSizeFile); err == nil {
+		t.Error("Expected error parsing invalid size")
+	} else if !strings.Contains(err.Error(), "failed to read stack size") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+
+	// Ошибка чтения данных (размер указан больше, чем данных)
+	badDataFile := filepath.Join(tmpDir, "bad_data.txt")
+	// Обещаем 5 элементов, даем 1
+	os.WriteFile(badDataFile, []byte("5\n10"), 0644)
+	if err := s.LoadText(badDataFile); err == nil {
+		t.Error("Expected error reading insufficient data")
+	} else if !strings.Contains(err.Error(), "failed to read data at index") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestSaveBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+	goodFile := filepath.Join(tmpDir, "stack.bin")
+
+	s := NewStack[float64]()
+	s.Push(3.14)
+
+	// Успешное сохранение
+	if err := s.SaveBinary(goodFile); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+
+	// Ошибка создания файла (путь - директория)
+	if err := s.SaveBinary(tmpDir); err == nil {
+		t.Error("Expected error saving binary to directory")
+	}
+
+	sFunc := NewStack[func()]()
+	sFunc.Push(func() {}) // Функции нельзя сериализовать
+
+	binFileFunc := filepath.Join(tmpDir, "func.bin")
+	if err := sFunc.SaveBinary(binFileFunc); err == nil {
+		t.Error("Expected gob encode error for unserializable type (func)")
+	}
+}
+
+func TestLoadBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Подготовка файла для успешной загрузки
+	goodFile := filepath.Join(tmpDir, "stack.bin")
+	sOrig := NewStack[float64]()
+	sOrig.Push(1.23)
+	sOrig.SaveBinary(goodFile)
+
+	// Успешная загрузка
+	sLoad := NewStack[float64]()
+	if err := sLoad.LoadBinary(goodFile); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+	val, _ := sLoad.Pop()
+	if math.Abs(val-1.23) > 0.0001 {
+		t.Errorf("Expected 1.23, got %f", val)
+	}
+
+	// Ошибка открытия (файл не существует)
+	if err := sLoad.LoadBinary("non_existent.bin"); err == nil {
+		t.Error("Expected error opening non-existent binary file")
+	}
+
+	// Ошибка декодирования (коррумпированный файл)
+	badFile := filepath.Join(tmpDir, "corrupt.bin")
+	os.WriteFile(badFile, persisttest.Envelope[float64](persist.KindStack, []byte("THIS IS NOT GOB DATA")), 0644)
+
+	if err := sLoad.LoadBinary(badFile); err == nil {
+		t.Error("Expected error decoding corrupt binary file")
+	} else if !strings.Contains(err.Error(), "failed to decode data") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+// Тесты атомарного сохранения
+
+// TestSaveFailureKeepsFile обрывает запись на разных байтах и проверяет,
+// что ранее сохраненный файл не изменился, а временные файлы удалены
+func TestSaveFailureKeepsFile(t *testing.T) {
+	s := NewStack[int32]()
+	for i := 0; i < 100; i++ {
+		s.Push(int32(i))
+	}
+	persisttest.SaveFailureKeepsFile(t, map[string]func(string, ...persist.Option) error{
+		"text":   s.SaveText,
+		"binary": s.SaveBinary,
+	}, func() { s.Push(1000) })
+}
+
+// streamFormats - форматы потокового сохранения стека
+func streamFormats[T any]() []persisttest.Format[*Stack[T]] {
+	return []persisttest.Format[*Stack[T]]{
+		{Name: "text", Write: (*Stack[T]).WriteTextTo, Read: (*Stack[T]).ReadTextFrom},
+		{Name: "binary", Write: (*Stack[T]).WriteTo, Read: (*Stack[T]).ReadFrom},
+		{Name: "json", Write: (*Stack[T]).WriteJSONTo, Read: (*Stack[T]).ReadJSONFrom},
+	}
+}
+
+// sameStack сравнивает элементы стеков
+func sameStack[T comparable](a, b *Stack[T]) bool {
+	return slices.Equal(a.ToSlice(), b.ToSlice())
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	src := NewStack[int32]()
+	for i := 0; i < 50; i++ {
+		src.Push(int32(i))
+	}
+	var _ io.WriterTo = src
+	var _ io.ReaderFrom = src
+
+	persisttest.RoundTrip(t, src, NewStack[int32], streamFormats[int32](), sameStack[int32])
+}
+
+// Fuzz-тест текстового формата: строки с пробелами, кавычками и переводами строк
+// должны читаться обратно без изменений
+func FuzzTextRoundTrip(f *testing.F) {
+	persisttest.FuzzText(f, func(a, b string) *Stack[string] {
+		src := NewStack[string]()
+		src.Push(a)
+		src.Push(b)
+		src.Push(a + " " + b)
+		return src
+	}, NewStack[string], streamFormats[string]()[0], sameStack[string])
+}
+
+func TestJSON(t *testing.T) {
+	s := NewStack[int]()
+	s.Push(1)
+	s.Push(2)
+	s.Push(3)
+
+	data, err := json.Marshal(s)
+	if err != nil || string(data) != "[1,2,3]" {
+		t.Fatalf("Expected [1,2,3] (bottom to top), got %s (%v)", data, err)
+	}
+
+	var decoded struct {
+		Stack Stack[int]
+	}
+	if err := json.Unmarshal([]byte(`{"Stack":[4,5]}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if top, _ := decoded.Stack.Pop(); top != 5 {
+		t.Errorf("Expected top 5, got %d", top)
+	}
+
+	err = s.UnmarshalJSON([]byte(`[1, true]`))
+	var je *codec.JSONError
+	if !errors.As(err, &je) || je.Path != "$[1]" {
+		t.Fatalf("Expected error at $[1], got %v", err)
+	}
+	if s.Size() != 3 {
+		t.Errorf("Stack changed after failed unmarshal: size %d", s.Size())
+	}
+}
+
+func TestGobEmbedding(t *testing.T) {
+	src := NewStack[int]()
+	src.Push(1)
+	src.Push(2)
+	var _ encoding.BinaryUnmarshaler = src
+	var _ gob.GobEncoder = src
+
+	persisttest.GobRoundTrip(t, src, sameStack[int])
+
+	data, _ := src.MarshalBinary()
+	if err := NewStack[int]().UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, persist.ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+}
+
+func TestBinaryChunks(t *testing.T) {
+	dir := t.TempDir()
+
+	// Стек больше одной части пишется несколькими сообщениями gob
+	s := NewStack[int]()
+	for i := 0; i < 2*codec.ChunkSize+3; i++ {
+		s.Push(i)
+	}
+	path := filepath.Join(dir, "chunks.bin")
+	captureOutput(func() { s.SaveBinary(path) })
+	loaded := NewStack[int]()
+	var err error
+	captureOutput(func() { err = loaded.LoadBinary(path, persist.WithLimit(1<<20)) })
+	if err != nil || loaded.Size() != s.Size() {
+		t.Fatalf("LoadBinary: size %d, %v", loaded.Size(), err)
+	}
+	if top, _ := loaded.Pop(); top != 2*codec.ChunkSize+2 {
+		t.Errorf("Expected top %d, got %d", 2*codec.ChunkSize+2, top)
+	}
+
+	// Старый формат: весь стек одним сообщением
+	var legacy bytes.Buffer
+	gob.NewEncoder(&legacy).Encode([]int{1, 2, 3})
+	legacyFile := filepath.Join(dir, "legacy.bin")
+	os.WriteFile(legacyFile, persisttest.Envelope[int](persist.KindStack, legacy.Bytes()), 0644)
+	captureOutput(func() { err = loaded.LoadBinary(legacyFile) })
+	if err != nil || loaded.Size() != 3 {
+		t.Errorf("Legacy file: size %d, %v", loaded.Size(), err)
+	}
+
+	// Заявленный размер текста больше данных
+	textFile := filepath.Join(dir, "huge.txt")
+	os.WriteFile(textFile, []byte("2000000000\n1\n"), 0644)
+	if err := loaded.LoadText(textFile); err == nil {
+		t.Error("LoadText should fail for size larger than data")
+	}
+}
+
+func TestFromSliceToSlice(t *testing.T) {
+	s := FromSlice([]int{1, 2, 3})
+	if top, _ := s.Pop(); top != 3 {
+		t.Errorf("Last element should be on top, got %d", top)
+	}
+	if got := s.ToSlice(); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("ToSlice = %v", got)
+	}
+
+	words := FromSeq(slices.Values([]string{"bottom", "top"}))
+	if top, _ := words.Pop(); top != "top" {
+		t.Errorf("FromSeq: expected top, got %q", top)
+	}
+	if FromSlice[int](nil).Size() != 0 {
+		t.Error("Expected empty stack")
+	}
+}
+
+func TestIterators(t *testing.T) {
+	s := FromSlice([]int{1, 2, 3})
+	if got := slices.Collect(s.All()); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("All = %v, want bottom to top", got)
+	}
+	if got := slices.Collect(s.Backward()); !slices.Equal(got, []int{3, 2, 1}) {
+		t.Errorf("Backward = %v, want pop order", got)
+	}
+	for i, v := range s.Enumerate() {
+		if v != i+1 {
+			t.Errorf("Enumerate: index %d has %d", i, v)
+		}
+	}
+
+	// Pop в цикле Backward: снятые элементы пропускаются
+	var seen []int
+	for v := range s.Backward() {
+		seen = append(seen, v)
+		if v == 3 {
+			_, _ = s.Pop()
+			_, _ = s.Pop()
+		}
+	}
+	if !slices.Equal(seen, []int{3, 1}) {
+		t.Errorf("Backward with Pop = %v", seen)
+	}
+
+	// Push в цикле All продлевает обход
+	seen = seen[:0]
+	for v := range s.All() {
+		seen = append(seen, v)
+		if v < 3 {
+			s.Push(v + 1)
+		}
+	}
+	if !slices.Equal(seen, []int{1, 2, 3}) {
+		t.Errorf("All with Push = %v", seen)
+	}
+}
@@ -0,0 +1,412 @@
+package ttlmap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/compression"
+	"github.com/D4ROVAN1E/LR_3_Go/dhash"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+	"github.com/D4ROVAN1E/LR_3_Go/queue"
+)
+
+// Ошибки, которые может вернуть таблица
+var (
+	ErrInvalidTTL     = errors.New("ttl must be greater than zero")
+	ErrSweeperRunning = errors.New("sweeper is already running")
+)
+
+// Clock абстрагирует источник времени, чтобы тесты могли управлять им вручную
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock использует системное время
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock возвращает часы на основе time.Now
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// minTableSize - наименьший размер внутренней таблицы. Шаг второй хеш-функции
+// берется по модулю size-1, поэтому таблица из одной ячейки недопустима.
+const minTableSize = 8
+
+// entry хранит значение вместе с абсолютным сроком жизни (UnixNano)
+type entry[T any] struct {
+	value    T
+	deadline int64
+}
+
+// Record - запись в снимке таблицы. TTL хранит остаток времени жизни в наносекундах,
+// поэтому после загрузки ключи живут ровно столько, сколько им оставалось.
+// В файл запись пишется кодеком значений таблицы, за которым следует TTL (int64).
+type Record[T any] struct {
+	Value T
+	TTL   int64
+}
+
+// recordCodec кодирует записи снимка: значение - кодеком T, затем остаток TTL
+type recordCodec[T any] struct {
+	value codec.ElementCodec[T]
+}
+
+func (c recordCodec[T]) Encode(w io.Writer, r Record[T]) error {
+	if err := c.value.Encode(w, r.Value); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, r.TTL)
+}
+
+func (c recordCodec[T]) Decode(r io.Reader) (Record[T], error) {
+	var rec Record[T]
+	value, err := c.value.Decode(r)
+	if err != nil {
+		return rec, err
+	}
+	rec.Value = value
+	if err := binary.Read(r, binary.LittleEndian, &rec.TTL); err != nil {
+		// Значение прочитано, значит запись оборвана
+		if err == io.EOF {
+			return rec, io.ErrUnexpectedEOF
+		}
+		return rec, err
+	}
+	return rec, nil
+}
+
+// expiry - элемент кучи сроков. После обновления ключа в куче остается
+// устаревшая запись, она распознается по несовпадению deadline.
+type expiry struct {
+	key      string
+	deadline int64
+}
+
+// TTLMap реализует хеш-таблицу, в которой у каждого ключа есть срок жизни.
+// Просроченные ключи не видны через Find (ленивое удаление), а ExpireNow
+// и фоновый сборщик удаляют их активно, выбирая ближайшие сроки из кучи.
+type TTLMap[T any] struct {
+	mu      sync.Mutex
+	table   *dhash.DoubleHash[entry[T]]
+	expires *queue.PriorityQueue[expiry]
+	clock   Clock
+	codec   codec.ElementCodec[T]
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTTLMap создает таблицу заданного начального размера. Ненулевой размер
+// меньше minTableSize увеличивается до minTableSize.
+// Если clock равен nil, используется системное время.
+func NewTTLMap[T any](size uint32, clock Clock) (*TTLMap[T], error) {
+	if size != 0 {
+		size = max(size, minTableSize)
+	}
+	table, err := dhash.NewDoubleHash[entry[T]](size)
+	if err != nil {
+		return nil, err
+	}
+	if clock == nil {
+		clock = SystemClock()
+	}
+	return &TTLMap[T]{
+		table:   table,
+		expires: newExpiryQueue(),
+		clock:   clock,
+	}, nil
+}
+
+func newExpiryQueue() *queue.PriorityQueue[expiry] {
+	return queue.NewPriorityQueue(func(a, b expiry) bool {
+		return a.deadline < b.deadline
+	})
+}
+
+// Insert вставляет элемент или обновляет значение и срок жизни существующего
+func (m *TTLMap[T]) Insert(key string, value T, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deadline := m.clock.Now().Add(ttl).UnixNano()
+	if err := m.table.Insert(key, entry[T]{value: value, deadline: deadline}); err != nil {
+		return err
+	}
+	m.expires.Push(expiry{key: key, deadline: deadline})
+	m.compactLocked()
+	return nil
+}
+
+// Find возвращает копию значения, если ключ существует и не просрочен.
+// Просроченный ключ удаляется при обращении.
+func (m *TTLMap[T]) Find(key string) (T, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var empty T
+	e := m.table.Find(key)
+	if e == nil {
+		return empty, false
+	}
+	if e.deadline <= m.clock.Now().UnixNano() {
+		m.table.Remove(key)
+		return empty, false
+	}
+	return e.value, true
+}
+
+// TTL возвращает оставшееся время жизни ключа
+func (m *TTLMap[T]) TTL(key string) (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.table.Find(key)
+	if e == nil {
+		return 0, false
+	}
+	remaining := e.deadline - m.clock.Now().UnixNano()
+	if remaining <= 0 {
+		m.table.Remove(key)
+		return 0, false
+	}
+	return time.Duration(remaining), true
+}
+
+// Remove удаляет элемент по ключу. Запись в куче сроков станет устаревшей
+// и будет отброшена при следующей очистке.
+func (m *TTLMap[T]) Remove(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.table.Remove(key)
+}
+
+// Size возвращает количество элементов, включая просроченные, но еще не удаленные.
+// Для точного значения вызовите ExpireNow.
+func (m *TTLMap[T]) Size() uint32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.table.Size()
+}
+
+// ExpireNow удаляет все просроченные ключи и возвращает их количество
+func (m *TTLMap[T]) ExpireNow() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.expireLocked(m.clock.Now().UnixNano())
+}
+
+// expireLocked извлекает из кучи все сроки не позже now.
+// Вызывающий должен держать мьютекс.
+func (m *TTLMap[T]) expireLocked(now int64) int {
+	removed := 0
+	for !m.expires.IsEmpty() {
+		next, _ := m.expires.Peek()
+		if next.deadline > now {
+			break
+		}
+		_, _ = m.expires.Pop()
+
+		// Ключ мог быть обновлен или удален после постановки в кучу
+		e := m.table.Find(next.key)
+		if e == nil || e.deadline != next.deadline {
+			continue
+		}
+		m.table.Remove(next.key)
+		removed++
+	}
+	return removed
+}
+
+// compactLocked перестраивает кучу, если устаревших записей стало больше, чем живых.
+// Вызывающий должен держать мьютекс.
+func (m *TTLMap[T]) compactLocked() {
+	live := int(m.table.Size())
+	if m.expires.Size() <= 2*live+16 {
+		return
+	}
+	m.expires = newExpiryQueue()
+	m.table.Range(func(key string, e entry[T]) bool {
+		m.expires.Push(expiry{key: key, deadline: e.deadline})
+		return true
+	})
+}
+
+// StartSweeper запускает фоновую горутину, которая раз в interval вызывает ExpireNow
+func (m *TTLMap[T]) StartSweeper(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("sweeper interval must be greater than zero")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != nil {
+		return ErrSweeperRunning
+	}
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+
+	go m.sweep(interval, m.stop, m.done)
+	return nil
+}
+
+func (m *TTLMap[T]) sweep(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.ExpireNow()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// StopSweeper останавливает фоновую горутину и дожидается ее завершения
+func (m *TTLMap[T]) StopSweeper() {
+	m.mu.Lock()
+	stop, done := m.stop, m.done
+	m.stop, m.done = nil, nil
+	m.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// SetCodec задает кодек значений для бинарного снимка.
+// По умолчанию используется codec.Default.
+func (m *TTLMap[T]) SetCodec(c codec.ElementCodec[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.codec = c
+}
+
+// newSnapshot создает таблицу снимка, которая пишет записи кодеком значений.
+// Вызывающий должен держать мьютекс.
+func (m *TTLMap[T]) newSnapshot(size uint32) (*dhash.DoubleHash[Record[T]], error) {
+	valueCodec := m.codec
+	if valueCodec == nil {
+		var err error
+		if valueCodec, err = codec.Default[T](); err != nil {
+			return nil, err
+		}
+	}
+	snapshot, err := dhash.NewDoubleHash[Record[T]](size)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.SetCodec(recordCodec[T]{value: valueCodec})
+	return snapshot, nil
+}
+
+// SaveBinary сохраняет живые ключи в формате DoubleHash.
+// Вместо абсолютного срока пишется остаток TTL на момент сохранения.
+func (m *TTLMap[T]) SaveBinary(filename string, opts ...persist.Option) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot, err := m.newSnapshot(m.table.Size() + 1)
+	if err != nil {
+		return err
+	}
+
+	now := m.clock.Now().UnixNano()
+	var insertErr error
+	m.table.Range(func(key string, e entry[T]) bool {
+		remaining := e.deadline - now
+		if remaining <= 0 {
+			return true
+		}
+		insertErr = snapshot.Insert(key, Record[T]{Value: e.value, TTL: remaining})
+		return insertErr == nil
+	})
+	if insertErr != nil {
+		return insertErr
+	}
+
+	return persist.WriteFile(filename, func(w io.Writer) error {
+		_, err := snapshot.WriteTo(w)
+		return err
+	}, opts...)
+}
+
+// LoadBinary загружает снимок, созданный SaveBinary.
+// Сроки жизни отсчитываются от текущего времени часов.
+func (m *TTLMap[T]) LoadBinary(filename string, opts ...persist.LoadOption) error {
+	m.mu.Lock()
+	snapshot, err := m.newSnapshot(1)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error: Could not open binary file for reading: %w", err)
+	}
+	defer file.Close()
+
+	err = persist.Load(file, compression.NewReader, func(r io.Reader) error {
+		_, err := snapshot.ReadFrom(r)
+		return err
+	}, opts...)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Шаг пробирования не определен для таблицы из одной ячейки,
+	// поэтому даже для пустого снимка берем запас
+	table, err := dhash.NewDoubleHash[entry[T]](max(snapshot.Size()+1, minTableSize))
+	if err != nil {
+		return err
+	}
+	expires := newExpiryQueue()
+
+	now := m.clock.Now().UnixNano()
+	var insertErr error
+	snapshot.Range(func(key string, r Record[T]) bool {
+		if r.TTL <= 0 {
+			return true
+		}
+		deadline := now + r.TTL
+		if insertErr = table.Insert(key, entry[T]{value: r.Value, deadline: deadline}); insertErr != nil {
+			return false
+		}
+		expires.Push(expiry{key: key, deadline: deadline})
+		return true
+	})
+	if insertErr != nil {
+		return insertErr
+	}
+
+	m.table = table
+	m.expires = expires
+	return nil
+}
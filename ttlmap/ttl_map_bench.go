@@ -0,0 +1,48 @@
+package ttlmap
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+const NumElements = 10000 // Количество ключей для теста
+
+// benchClock - часы, которые двигаются только вручную
+type benchClock struct {
+	now time.Time
+}
+
+func (c *benchClock) Now() time.Time { return c.now }
+
+// BenchmarkInsert измеряет вставку с постановкой срока в кучу.
+func BenchmarkInsert(b *testing.B) {
+	keys := make([]string, NumElements)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m, _ := NewTTLMap[int](100, nil)
+		for j, k := range keys {
+			m.Insert(k, j, time.Minute)
+		}
+	}
+}
+
+// BenchmarkExpireNow измеряет активное удаление всех ключей разом.
+func BenchmarkExpireNow(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		clock := &benchClock{now: time.Unix(0, 0)}
+		m, _ := NewTTLMap[int](uint32(NumElements*2), clock)
+		for j := 0; j < NumElements; j++ {
+			m.Insert(fmt.Sprintf("key%d", j), j, time.Duration(j+1)*time.Millisecond)
+		}
+		clock.now = clock.now.Add(time.Hour)
+		b.StartTimer()
+
+		m.ExpireNow()
+	}
+}
@@ -0,0 +1,295 @@
+package ttlmap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Вспомогательные функции
+
+// fakeClock - управляемые вручную часы для детерминированных тестов
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1700000000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Основные функциональные тесты
+
+func TestConstructor(t *testing.T) {
+	if _, err := NewTTLMap[int](0, nil); err == nil {
+		t.Error("Expected error when creating map with size 0")
+	}
+
+	m, err := NewTTLMap[int](5, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if m.Size() != 0 {
+		t.Errorf("Expected size 0, got %d", m.Size())
+	}
+
+	// Размер 1 допустим: таблица увеличивается, вставка не должна паниковать
+	tiny, err := NewTTLMap[int](1, nil)
+	if err != nil {
+		t.Fatalf("Expected no error for size 1, got %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := tiny.Insert(fmt.Sprint(i), i, time.Minute); err != nil {
+			t.Fatalf("Insert failed: %v", err)
+		}
+	}
+	if v, ok := tiny.Find("7"); !ok || v != 7 {
+		t.Errorf("Expected 7 in size-1 map, got %v, %v", v, ok)
+	}
+}
+
+func TestInsertFindTTL(t *testing.T) {
+	clock := newFakeClock()
+	m, _ := NewTTLMap[string](5, clock)
+
+	if err := m.Insert("k", "v", 0); err != ErrInvalidTTL {
+		t.Errorf("Expected ErrInvalidTTL, got %v", err)
+	}
+
+	if err := m.Insert("k", "v", 10*time.Second); err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if val, ok := m.Find("k"); !ok || val != "v" {
+		t.Error("Find returned wrong value")
+	}
+	if ttl, ok := m.TTL("k"); !ok || ttl != 10*time.Second {
+		t.Errorf("Expected TTL 10s, got %v", ttl)
+	}
+
+	clock.Advance(9 * time.Second)
+	if _, ok := m.Find("k"); !ok {
+		t.Error("Key expired too early")
+	}
+
+	// Ленивое удаление: срок вышел, Find ничего не возвращает и чистит ключ
+	clock.Advance(time.Second)
+	if _, ok := m.Find("k"); ok {
+		t.Error("Expired key is still visible")
+	}
+	if m.Size() != 0 {
+		t.Errorf("Expected size 0 after lazy expiration, got %d", m.Size())
+	}
+	if _, ok := m.TTL("k"); ok {
+		t.Error("TTL of expired key should be unavailable")
+	}
+}
+
+func TestUpdateExtendsDeadline(t *testing.T) {
+	clock := newFakeClock()
+	m, _ := NewTTLMap[int](5, clock)
+
+	m.Insert("k", 1, 5*time.Second)
+	clock.Advance(4 * time.Second)
+	m.Insert("k", 2, 5*time.Second)
+	clock.Advance(4 * time.Second)
+
+	// Старая запись в куче устарела и не должна удалить обновленный ключ
+	if n := m.ExpireNow(); n != 0 {
+		t.Errorf("Expected nothing to expire, got %d", n)
+	}
+	if val, ok := m.Find("k"); !ok || val != 2 {
+		t.Error("Updated key lost")
+	}
+}
+
+func TestExpireNow(t *testing.T) {
+	clock := newFakeClock()
+	m, _ := NewTTLMap[int](5, clock)
+
+	for i := 1; i <= 10; i++ {
+		m.Insert(fmt.Sprintf("k%d", i), i, time.Duration(i)*time.Second)
+	}
+	m.Remove("k2")
+
+	clock.Advance(5 * time.Second)
+	// k1, k3, k4, k5 (k2 удален вручную)
+	if n := m.ExpireNow(); n != 4 {
+		t.Errorf("Expected 4 expired keys, got %d", n)
+	}
+	if m.Size() != 5 {
+		t.Errorf("Expected size 5, got %d", m.Size())
+	}
+	for i := 6; i <= 10; i++ {
+		if val, ok := m.Find(fmt.Sprintf("k%d", i)); !ok || val != i {
+			t.Errorf("Key k%d should still be alive", i)
+		}
+	}
+}
+
+func TestHeapCompaction(t *testing.T) {
+	clock := newFakeClock()
+	m, _ := NewTTLMap[int](5, clock)
+
+	// Многократное обновление одного ключа не должно раздувать кучу
+	for i := 0; i < 1000; i++ {
+		m.Insert("hot", i, time.Minute)
+	}
+	if m.expires.Size() > 2*int(m.Size())+16 {
+		t.Errorf("Expiry heap was not compacted: %d entries", m.expires.Size())
+	}
+}
+
+func TestSweeper(t *testing.T) {
+	clock := newFakeClock()
+	m, _ := NewTTLMap[int](5, clock)
+	m.Insert("a", 1, time.Second)
+	m.Insert("b", 2, time.Hour)
+
+	if err := m.StartSweeper(0); err == nil {
+		t.Error("Expected error for zero interval")
+	}
+	if err := m.StartSweeper(time.Millisecond); err != nil {
+		t.Fatalf("StartSweeper failed: %v", err)
+	}
+	if err := m.StartSweeper(time.Millisecond); err != ErrSweeperRunning {
+		t.Errorf("Expected ErrSweeperRunning, got %v", err)
+	}
+
+	clock.Advance(2 * time.Second)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Size() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	m.StopSweeper()
+	// Повторная остановка безопасна
+	m.StopSweeper()
+
+	if m.Size() != 1 {
+		t.Errorf("Sweeper did not reclaim expired key, size %d", m.Size())
+	}
+}
+
+// Тесты сериализации
+
+func TestSaveLoadBinary(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "ttl.bin")
+	clock := newFakeClock()
+
+	m, _ := NewTTLMap[int32](5, clock)
+	m.Insert("short", 1, 2*time.Second)
+	m.Insert("long", 2, 10*time.Second)
+	m.Insert("gone", 3, time.Second)
+
+	clock.Advance(time.Second)
+	if err := m.SaveBinary(tmpFile); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+
+	// Загружаем "позже": часы нового экземпляра идут независимо
+	otherClock := newFakeClock()
+	otherClock.Advance(time.Hour)
+	loaded, _ := NewTTLMap[int32](1, otherClock)
+	if err := loaded.LoadBinary(tmpFile); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+
+	if loaded.Size() != 2 {
+		t.Errorf("Expected 2 keys (expired one skipped), got %d", loaded.Size())
+	}
+	if ttl, ok := loaded.TTL("short"); !ok || ttl != time.Second {
+		t.Errorf("Expected remaining TTL 1s, got %v", ttl)
+	}
+	if ttl, ok := loaded.TTL("long"); !ok || ttl != 9*time.Second {
+		t.Errorf("Expected remaining TTL 9s, got %v", ttl)
+	}
+
+	otherClock.Advance(time.Second)
+	if n := loaded.ExpireNow(); n != 1 {
+		t.Errorf("Expected 1 key to expire after load, got %d", n)
+	}
+}
+
+func TestLoadBinary_Errors(t *testing.T) {
+	m, _ := NewTTLMap[int32](5, nil)
+	if err := m.LoadBinary(filepath.Join(t.TempDir(), "missing.bin")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestSaveLoadBinary_String(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "ttl_string.bin")
+	clock := newFakeClock()
+
+	m, _ := NewTTLMap[string](5, clock)
+	m.Insert("greeting", "hello, world", 5*time.Second)
+	m.Insert("empty", "", 5*time.Second)
+	if err := m.SaveBinary(tmpFile); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+
+	loaded, _ := NewTTLMap[string](1, clock)
+	if err := loaded.LoadBinary(tmpFile); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+	if v, ok := loaded.Find("greeting"); !ok || v != "hello, world" {
+		t.Errorf("Find(greeting) = %q, %v", v, ok)
+	}
+	if v, ok := loaded.Find("empty"); !ok || v != "" {
+		t.Errorf("Find(empty) = %q, %v", v, ok)
+	}
+	if ttl, ok := loaded.TTL("greeting"); !ok || ttl != 5*time.Second {
+		t.Errorf("Expected remaining TTL 5s, got %v", ttl)
+	}
+}
+
+func TestLoadBinary_Truncated(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "ttl_cut.bin")
+	m, _ := NewTTLMap[string](5, nil)
+	m.Insert("key", "value", time.Minute)
+	if err := m.SaveBinary(tmpFile); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+	data, _ := os.ReadFile(tmpFile)
+	if err := os.WriteFile(tmpFile, data[:len(data)-3], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, _ := NewTTLMap[string](5, nil)
+	loaded.Insert("kept", "old", time.Minute)
+	if err := loaded.LoadBinary(tmpFile); err == nil {
+		t.Error("Expected error for truncated file")
+	}
+	if v, ok := loaded.Find("kept"); !ok || v != "old" {
+		t.Error("Failed load changed the map")
+	}
+}
+
+func TestLoadBinary_EmptySnapshot(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "ttl_empty.bin")
+	m, _ := NewTTLMap[string](5, nil)
+	if err := m.SaveBinary(tmpFile); err != nil {
+		t.Fatalf("SaveBinary failed: %v", err)
+	}
+	if err := m.LoadBinary(tmpFile); err != nil {
+		t.Fatalf("LoadBinary failed: %v", err)
+	}
+	if err := m.Insert("key", "value", time.Minute); err != nil {
+		t.Errorf("Insert after loading empty snapshot failed: %v", err)
+	}
+}
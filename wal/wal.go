@@ -0,0 +1,325 @@
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+	"github.com/D4ROVAN1E/LR_3_Go/persist"
+)
+
+// Формат кадра журнала и снимка:
+//
+//	length(4) crc32c(4) payload[length]
+//	payload: op(1) keyLen(4) key value
+//
+// Снимок начинается с сигнатуры и содержит только записи OpInsert.
+const (
+	frameHeader   = 8
+	payloadHeader = 5
+
+	// Имена файлов в каталоге журнала
+	SnapshotFile = "snapshot"
+	LogFile      = "wal.log"
+)
+
+var snapshotMagic = [8]byte{'W', 'A', 'L', 'S', 'N', 'A', 'P', '1'}
+
+// Op - вид операции в журнале
+type Op uint8
+
+const (
+	OpInsert Op = 1
+	OpRemove Op = 2
+	OpClear  Op = 3
+)
+
+// Ошибки пакета
+var (
+	ErrCorrupt = errors.New("snapshot is corrupted")
+	ErrBadOp   = errors.New("unknown log operation")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Record - одна операция над таблицей
+type Record struct {
+	Op    Op
+	Key   string
+	Value []byte
+}
+
+// encodeFrame собирает кадр записи
+func encodeFrame(rec Record) []byte {
+	payloadLen := payloadHeader + len(rec.Key) + len(rec.Value)
+	buf := make([]byte, frameHeader+payloadLen)
+	payload := buf[frameHeader:]
+	payload[0] = byte(rec.Op)
+	binary.LittleEndian.PutUint32(payload[1:5], uint32(len(rec.Key)))
+	copy(payload[payloadHeader:], rec.Key)
+	copy(payload[payloadHeader+len(rec.Key):], rec.Value)
+
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(payloadLen))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.Checksum(payload, castagnoli))
+	return buf
+}
+
+// readFrame читает следующий кадр. limit - сколько байт осталось в файле:
+// длина, превышающая его, считается порванной записью без выделения памяти.
+// Возвращает io.EOF в конце данных и io.ErrUnexpectedEOF для порванного кадра.
+func readFrame(r io.Reader, limit int64) (Record, int64, error) {
+	var header [frameHeader]byte
+	n, err := io.ReadFull(r, header[:])
+	if n == 0 && err == io.EOF {
+		return Record{}, 0, io.EOF
+	}
+	if err != nil {
+		return Record{}, 0, io.ErrUnexpectedEOF
+	}
+
+	payloadLen := binary.LittleEndian.Uint32(header[0:4])
+	if int64(payloadLen) > limit-frameHeader || payloadLen < payloadHeader {
+		return Record{}, 0, io.ErrUnexpectedEOF
+	}
+	payload := make([]byte, payloadLen)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return Record{}, 0, io.ErrUnexpectedEOF
+	}
+	if crc32.Checksum(payload, castagnoli) != binary.LittleEndian.Uint32(header[4:8]) {
+		return Record{}, 0, io.ErrUnexpectedEOF
+	}
+
+	keyLen := binary.LittleEndian.Uint32(payload[1:5])
+	if uint64(keyLen) > uint64(payloadLen-payloadHeader) {
+		return Record{}, 0, io.ErrUnexpectedEOF
+	}
+	rec := Record{
+		Op:    Op(payload[0]),
+		Key:   string(payload[payloadHeader : payloadHeader+keyLen]),
+		Value: payload[payloadHeader+keyLen:],
+	}
+	return rec, frameHeader + int64(payloadLen), nil
+}
+
+// Log - журнал упреждающей записи: файл, в конец которого дописываются кадры
+type Log struct {
+	file       *os.File
+	size       int64
+	syncWrites bool
+}
+
+// OpenLog открывает журнал для дозаписи. Порванный хвост (частично записанный
+// или испорченный кадр и все, что после него) отрезается.
+func OpenLog(path string) (*Log, error) {
+	valid, err := Replay(path, func(Record) error { return nil })
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return openLogAt(path, valid)
+}
+
+// openLogAt открывает журнал, оставляя в нем первые valid байт
+func openLogAt(path string, valid int64) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error: Could not open log file: %w", err)
+	}
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Log{file: file, size: valid, syncWrites: true}, nil
+}
+
+// SetSyncWrites включает или выключает fsync после каждой записи.
+// Без него последние операции могут пропасть при сбое питания.
+func (l *Log) SetSyncWrites(enabled bool) {
+	l.syncWrites = enabled
+}
+
+// Append дописывает запись в журнал
+func (l *Log) Append(rec Record) error {
+	frame := encodeFrame(rec)
+	if _, err := l.file.WriteAt(frame, l.size); err != nil {
+		// Недописанный кадр будет отрезан при следующем открытии
+		return fmt.Errorf("error: Could not append to log: %w", err)
+	}
+	l.size += int64(len(frame))
+	if l.syncWrites {
+		return l.file.Sync()
+	}
+	return nil
+}
+
+// Size возвращает размер журнала в байтах
+func (l *Log) Size() int64 {
+	return l.size
+}
+
+// Reset очищает журнал после успешного снимка
+func (l *Log) Reset() error {
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	l.size = 0
+	return l.file.Sync()
+}
+
+// Close закрывает журнал
+func (l *Log) Close() error {
+	if err := l.file.Sync(); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
+
+// Replay вызывает fn для каждой целой записи журнала по порядку.
+// Чтение останавливается на первом порванном кадре; возвращается
+// длина корректного префикса. Ошибка fn прерывает воспроизведение.
+func Replay(path string, fn func(Record) error) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	r := bufio.NewReader(file)
+	var valid int64
+	for {
+		rec, n, err := readFrame(r, info.Size()-valid)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return valid, nil
+		}
+		if err := fn(rec); err != nil {
+			return valid, err
+		}
+		valid += n
+	}
+}
+
+// WriteSnapshot атомарно записывает снимок через persist.WriteFile.
+// records должна вызвать emit для каждой пары ключ-значение.
+func WriteSnapshot(path string, records func(emit func(key string, value []byte) error) error) error {
+	return persist.WriteFile(path, func(w io.Writer) error {
+		return writeSnapshot(w, records)
+	})
+}
+
+func writeSnapshot(w io.Writer, records func(emit func(key string, value []byte) error) error) error {
+	if _, err := w.Write(snapshotMagic[:]); err != nil {
+		return err
+	}
+	return records(func(key string, value []byte) error {
+		_, err := w.Write(encodeFrame(Record{Op: OpInsert, Key: key, Value: value}))
+		return err
+	})
+}
+
+// ReadSnapshot вызывает fn для каждой записи снимка. В отличие от журнала,
+// снимок пишется атомарно, поэтому любая порча - ошибка ErrCorrupt.
+func ReadSnapshot(path string, fn func(key string, value []byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(file)
+	var magic [8]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil || magic != snapshotMagic {
+		return fmt.Errorf("%w: bad signature", ErrCorrupt)
+	}
+
+	offset := int64(len(magic))
+	for {
+		rec, n, err := readFrame(r, info.Size()-offset)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil || rec.Op != OpInsert {
+			return fmt.Errorf("%w: bad record at offset %d", ErrCorrupt, offset)
+		}
+		if err := fn(rec.Key, rec.Value); err != nil {
+			return err
+		}
+		offset += n
+	}
+}
+
+// Recover восстанавливает состояние из каталога dir: применяет снимок
+// (если он есть), затем записи журнала, и открывает журнал для дозаписи.
+// Повторное применение записи, уже попавшей в снимок, не меняет результат,
+// поэтому сбой между записью снимка и очисткой журнала безопасен.
+func Recover(dir string, apply func(Record) error) (*Log, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	err := ReadSnapshot(filepath.Join(dir, SnapshotFile), func(key string, value []byte) error {
+		return apply(Record{Op: OpInsert, Key: key, Value: value})
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	logPath := filepath.Join(dir, LogFile)
+	valid, err := Replay(logPath, apply)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return openLogAt(logPath, valid)
+}
+
+// Checkpoint пишет снимок в каталог dir и очищает журнал
+func Checkpoint(dir string, log *Log, records func(emit func(key string, value []byte) error) error) error {
+	if err := WriteSnapshot(filepath.Join(dir, SnapshotFile), records); err != nil {
+		return err
+	}
+	return log.Reset()
+}
+
+// EncodeValue кодирует значение для журнала кодеком c. Таблицы передают
+// свой кодек элементов, поэтому журнал, снимок и бинарный файл таблицы
+// пишут значения в одном формате.
+func EncodeValue[T any](c codec.ElementCodec[T], value T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, value); err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeValue восстанавливает значение, записанное EncodeValue тем же кодеком
+func DecodeValue[T any](c codec.ElementCodec[T], data []byte) (T, error) {
+	r := bytes.NewReader(data)
+	value, err := c.Decode(r)
+	if err != nil {
+		return value, fmt.Errorf("failed to decode value: %w", err)
+	}
+	if r.Len() != 0 {
+		return value, fmt.Errorf("failed to decode value: %d trailing bytes", r.Len())
+	}
+	return value, nil
+}
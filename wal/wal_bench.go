@@ -0,0 +1,53 @@
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// openBenchLog создает журнал во временном каталоге
+func openBenchLog(b *testing.B) (*Log, string, func()) {
+	dir, err := os.MkdirTemp("", "wal-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	path := filepath.Join(dir, LogFile)
+	log, err := OpenLog(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return log, path, func() {
+		log.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// BenchmarkAppend измеряет дозапись без fsync: кодирование кадра, CRC и write
+func BenchmarkAppend(b *testing.B) {
+	log, _, cleanup := openBenchLog(b)
+	defer cleanup()
+	log.SetSyncWrites(false)
+	rec := Record{Op: OpInsert, Key: "benchmark-key", Value: make([]byte, 64)}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Append(rec)
+	}
+}
+
+// BenchmarkReplay измеряет чтение журнала из 10000 записей
+func BenchmarkReplay(b *testing.B) {
+	log, path, cleanup := openBenchLog(b)
+	defer cleanup()
+	log.SetSyncWrites(false)
+	rec := Record{Op: OpInsert, Key: "benchmark-key", Value: make([]byte, 64)}
+	for i := 0; i < 10000; i++ {
+		log.Append(rec)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Replay(path, func(Record) error { return nil })
+	}
+}
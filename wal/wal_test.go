@@ -0,0 +1,300 @@
+package wal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/D4ROVAN1E/LR_3_Go/codec"
+)
+
+// Вспомогательные функции
+
+// testRecords возвращает набор записей разных видов и длин
+func testRecords() []Record {
+	return []Record{
+		{Op: OpInsert, Key: "alpha", Value: []byte("1")},
+		{Op: OpInsert, Key: "", Value: []byte("empty key")},
+		{Op: OpRemove, Key: "alpha"},
+		{Op: OpInsert, Key: "beta", Value: bytes.Repeat([]byte{0xAB}, 40)},
+		{Op: OpClear},
+		{Op: OpInsert, Key: "gamma", Value: nil},
+	}
+}
+
+// writeLog записывает записи в новый журнал и возвращает его байты
+// и смещения концов кадров
+func writeLog(t *testing.T, path string, records []Record) ([]byte, []int64) {
+	t.Helper()
+	log, err := OpenLog(path)
+	if err != nil {
+		t.Fatalf("OpenLog failed: %v", err)
+	}
+	ends := make([]int64, 0, len(records))
+	for _, rec := range records {
+		if err := log.Append(rec); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		ends = append(ends, log.Size())
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	return data, ends
+}
+
+// replayAll возвращает все записи журнала
+func replayAll(t *testing.T, path string) ([]Record, int64) {
+	t.Helper()
+	var got []Record
+	valid, err := Replay(path, func(rec Record) error {
+		got = append(got, rec)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	return got, valid
+}
+
+// sameRecords сравнивает записи, считая nil и пустой срез одинаковыми
+func sameRecords(a, b []Record) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Op != b[i].Op || a[i].Key != b[i].Key || !bytes.Equal(a[i].Value, b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Основные функциональные тесты
+
+func TestAppendReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LogFile)
+	records := testRecords()
+	data, _ := writeLog(t, path, records)
+
+	got, valid := replayAll(t, path)
+	if !sameRecords(got, records) {
+		t.Errorf("Replayed records mismatch:\n got %v\nwant %v", got, records)
+	}
+	if valid != int64(len(data)) {
+		t.Errorf("Expected valid prefix %d, got %d", len(data), valid)
+	}
+}
+
+func TestReopenContinuesLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LogFile)
+	records := testRecords()
+	writeLog(t, path, records[:3])
+	writeLog(t, path, records[3:])
+
+	got, _ := replayAll(t, path)
+	if !sameRecords(got, records) {
+		t.Errorf("Expected both sessions in log, got %v", got)
+	}
+}
+
+// Тесты внедрения сбоев
+
+func TestTruncateAtEveryOffset(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source.log")
+	records := testRecords()
+	data, ends := writeLog(t, src, records)
+
+	path := filepath.Join(dir, LogFile)
+	for cut := 0; cut <= len(data); cut++ {
+		os.WriteFile(path, data[:cut], 0644)
+
+		// Ожидаем ровно те записи, кадры которых целиком поместились
+		want := 0
+		for want < len(ends) && ends[want] <= int64(cut) {
+			want++
+		}
+		got, _ := replayAll(t, path)
+		if !sameRecords(got, records[:want]) {
+			t.Fatalf("cut at %d: expected %d records, got %d", cut, want, len(got))
+		}
+
+		// Открытие отрезает хвост, после чего журнал снова пригоден для записи
+		log, err := OpenLog(path)
+		if err != nil {
+			t.Fatalf("cut at %d: OpenLog failed: %v", cut, err)
+		}
+		log.Append(Record{Op: OpInsert, Key: "after", Value: []byte("crash")})
+		log.Close()
+
+		got, _ = replayAll(t, path)
+		if len(got) != want+1 || got[want].Key != "after" {
+			t.Fatalf("cut at %d: append after recovery lost, got %v", cut, got)
+		}
+	}
+}
+
+func TestCorruptAtEveryOffset(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source.log")
+	records := testRecords()
+	data, ends := writeLog(t, src, records)
+
+	path := filepath.Join(dir, LogFile)
+	for pos := range data {
+		corrupted := append([]byte(nil), data...)
+		corrupted[pos] ^= 0x5A
+		os.WriteFile(path, corrupted, 0644)
+
+		// Испорченный кадр и все последующие отбрасываются
+		broken := 0
+		for ends[broken] <= int64(pos) {
+			broken++
+		}
+		got, valid := replayAll(t, path)
+		if !sameRecords(got, records[:broken]) {
+			t.Fatalf("corrupt byte %d: expected %d records, got %d", pos, broken, len(got))
+		}
+		wantValid := int64(0)
+		if broken > 0 {
+			wantValid = ends[broken-1]
+		}
+		if valid != wantValid {
+			t.Fatalf("corrupt byte %d: expected valid prefix %d, got %d", pos, wantValid, valid)
+		}
+	}
+}
+
+// Тесты снимков
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SnapshotFile)
+	want := map[string]string{"a": "1", "b": "", "": "empty"}
+
+	err := WriteSnapshot(path, func(emit func(string, []byte) error) error {
+		for k, v := range want {
+			if err := emit(k, []byte(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WriteSnapshot failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Temporary snapshot file left behind")
+	}
+
+	got := make(map[string]string)
+	if err := ReadSnapshot(path, func(k string, v []byte) error {
+		got[k] = string(v)
+		return nil
+	}); err != nil {
+		t.Fatalf("ReadSnapshot failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Порча снимка - ошибка, а не молчаливая потеря данных
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 0xFF
+	os.WriteFile(path, data, 0644)
+	if err := ReadSnapshot(path, func(string, []byte) error { return nil }); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt, got %v", err)
+	}
+}
+
+func TestRecoverAndCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	state := make(map[string]string)
+	apply := func(rec Record) error {
+		switch rec.Op {
+		case OpInsert:
+			state[rec.Key] = string(rec.Value)
+		case OpRemove:
+			delete(state, rec.Key)
+		case OpClear:
+			clear(state)
+		default:
+			return ErrBadOp
+		}
+		return nil
+	}
+	snapshot := func(emit func(string, []byte) error) error {
+		for k, v := range state {
+			if err := emit(k, []byte(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	log, err := Recover(dir, apply)
+	if err != nil {
+		t.Fatalf("Recover on empty dir failed: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		rec := Record{Op: OpInsert, Key: fmt.Sprint(i), Value: []byte(fmt.Sprint(i * i))}
+		log.Append(rec)
+		apply(rec)
+	}
+	if err := Checkpoint(dir, log, snapshot); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	if log.Size() != 0 {
+		t.Errorf("Expected empty log after checkpoint, got %d bytes", log.Size())
+	}
+	rec := Record{Op: OpRemove, Key: "2"}
+	log.Append(rec)
+	apply(rec)
+	log.Close()
+
+	want := state
+	state = make(map[string]string)
+	log, err = Recover(dir, apply)
+	if err != nil {
+		t.Fatalf("Recover failed: %v", err)
+	}
+	defer log.Close()
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("Expected %v after recovery, got %v", want, state)
+	}
+}
+
+func TestEncodeValue(t *testing.T) {
+	data, err := EncodeValue(codec.Fixed[int64]{}, -42)
+	if err != nil {
+		t.Fatalf("EncodeValue failed: %v", err)
+	}
+	if v, err := DecodeValue(codec.Fixed[int64]{}, data); err != nil || v != -42 {
+		t.Errorf("Expected -42, got %v (%v)", v, err)
+	}
+
+	data, _ = EncodeValue(codec.Int{}, -7)
+	if v, err := DecodeValue(codec.Int{}, data); err != nil || v != -7 {
+		t.Errorf("Expected int round trip, got %v (%v)", v, err)
+	}
+
+	data, _ = EncodeValue(codec.String{}, "строка")
+	if v, _ := DecodeValue(codec.String{}, data); v != "строка" {
+		t.Errorf("Expected string round trip, got %q", v)
+	}
+
+	if _, err := DecodeValue(codec.Fixed[int32]{}, data); err == nil {
+		t.Error("Expected error for trailing bytes")
+	}
+	if _, err := DecodeValue(codec.String{}, data[:3]); err == nil {
+		t.Error("Expected error for truncated value")
+	}
+	if _, err := EncodeValue(codec.Fixed[map[string]int]{}, map[string]int{}); err == nil {
+		t.Error("Expected error for variable-size type")
+	}
+}